@@ -0,0 +1,56 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day01 solves AoC 2015 day 1.
+package day01
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 1, glue.Puzzle{
+		Title: "Not Quite Lisp",
+		Tags:  []string{"strings"},
+	})
+	glue.RegisterSolver(2015, 1, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	floor, basement := climb(lines[0])
+	return glue.Ints(floor, basement), nil
+}
+
+// climb follows the instructions, and returns the final floor and the position (1-based) of the
+// instruction that first enters the basement, or -1 if it's never entered.
+func climb(steps string) (floor, basement int) {
+	basement = -1
+	for i, c := range steps {
+		switch c {
+		case '(':
+			floor++
+		case ')':
+			floor--
+		}
+		if floor < 0 && basement < 0 {
+			basement = i + 1
+		}
+	}
+	return floor, basement
+}
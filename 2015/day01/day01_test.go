@@ -0,0 +1,38 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day01
+
+import "testing"
+
+func TestClimb(t *testing.T) {
+	tests := []struct {
+		steps           string
+		floor, basement int
+	}{
+		{steps: "(())", floor: 0, basement: -1},
+		{steps: "()()", floor: 0, basement: -1},
+		{steps: "(((", floor: 3, basement: -1},
+		{steps: "(()(()(", floor: 3, basement: -1},
+		{steps: "))(((((", floor: 3, basement: 1},
+		{steps: "())", floor: -1, basement: 3},
+		{steps: ")())())", floor: -3, basement: 1},
+		{steps: "()())", floor: -1, basement: 5},
+	}
+	for _, test := range tests {
+		if floor, basement := climb(test.steps); floor != test.floor || basement != test.basement {
+			t.Errorf("climb(%s) = (%d, %d), want (%d, %d)", test.steps, floor, basement, test.floor, test.basement)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day02 solves AoC 2015 day 2.
+package day02
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 2, glue.Puzzle{
+		Title: "I Was Told There Would Be No Math",
+		Tags:  []string{"geometry"},
+	})
+	glue.RegisterSolver(2015, 2, glue.IntSolver(solve))
+}
+
+func solve(dims []int) ([]string, error) {
+	if len(dims)%3 != 0 {
+		return nil, fmt.Errorf("expected triples of dimensions, got %d numbers", len(dims))
+	}
+	paper, ribbon := 0, 0
+	for i := 0; i < len(dims); i += 3 {
+		box := [3]int(dims[i : i+3])
+		paper += wrapping(box)
+		ribbon += bow(box)
+	}
+	return glue.Ints(paper, ribbon), nil
+}
+
+// wrapping returns the area of paper needed for a box: its surface, plus the area of the smallest
+// side as slack.
+func wrapping(box [3]int) int {
+	slices.Sort(box[:])
+	l, w, h := box[0], box[1], box[2]
+	return 2*(l*w+w*h+h*l) + l*w
+}
+
+// bow returns the length of ribbon needed for a box: the smallest perimeter, plus the volume for
+// the bow.
+func bow(box [3]int) int {
+	slices.Sort(box[:])
+	l, w, h := box[0], box[1], box[2]
+	return 2*(l+w) + l*w*h
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day02
+
+import "testing"
+
+func TestBox(t *testing.T) {
+	tests := []struct {
+		box           [3]int
+		paper, ribbon int
+	}{
+		{box: [3]int{2, 3, 4}, paper: 58, ribbon: 34},
+		{box: [3]int{1, 1, 10}, paper: 43, ribbon: 14},
+	}
+	for _, test := range tests {
+		if got := wrapping(test.box); got != test.paper {
+			t.Errorf("wrapping(%v) = %d, want %d", test.box, got, test.paper)
+		}
+		if got := bow(test.box); got != test.ribbon {
+			t.Errorf("bow(%v) = %d, want %d", test.box, got, test.ribbon)
+		}
+	}
+}
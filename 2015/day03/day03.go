@@ -0,0 +1,62 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day03 solves AoC 2015 day 3.
+package day03
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 3, glue.Puzzle{
+		Title: "Perfectly Spherical Houses in a Vacuum",
+		Tags:  []string{"grid", "simulation"},
+	})
+	glue.RegisterSolver(2015, 3, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	p1 := deliver(lines[0], 1)
+	p2 := deliver(lines[0], 2)
+	return glue.Ints(p1, p2), nil
+}
+
+// deliver returns the number of houses that get at least one present, when the moves are taken in
+// turns by the given number of santas.
+func deliver(moves string, santas int) int {
+	at := make([]util.P, santas)
+	visited := map[util.P]struct{}{{0, 0}: {}}
+	for i, m := range moves {
+		p := &at[i%santas]
+		switch m {
+		case '^':
+			p.Y--
+		case 'v':
+			p.Y++
+		case '<':
+			p.X--
+		case '>':
+			p.X++
+		}
+		visited[*p] = struct{}{}
+	}
+	return len(visited)
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day03
+
+import "testing"
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		moves  string
+		santas int
+		want   int
+	}{
+		{moves: ">", santas: 1, want: 2},
+		{moves: "^>v<", santas: 1, want: 4},
+		{moves: "^v^v^v^v^v", santas: 1, want: 2},
+		{moves: "^v", santas: 2, want: 3},
+		{moves: "^>v<", santas: 2, want: 3},
+		{moves: "^v^v^v^v^v", santas: 2, want: 11},
+	}
+	for _, test := range tests {
+		if got := deliver(test.moves, test.santas); got != test.want {
+			t.Errorf("deliver(%s, %d) = %d, want %d", test.moves, test.santas, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day04 solves AoC 2015 day 4.
+package day04
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 4, glue.Puzzle{
+		Title: "The Ideal Stocking Stuffer",
+		Tags:  []string{"hashing"},
+	})
+	glue.RegisterSolver(2015, 4, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	p1 := mine(lines[0], 5, 1)
+	p2 := mine(lines[0], 6, p1)
+	return glue.Ints(p1, p2), nil
+}
+
+// mine returns the lowest number, starting from the given one, whose MD5 hash (when appended to
+// the key) starts with the given number of zero hex digits.
+func mine(key string, zeros, from int) int {
+	buf := []byte(key)
+	for n := from; ; n++ {
+		sum := md5.Sum(strconv.AppendInt(buf, int64(n), 10))
+		if leadingZeros(sum[:], zeros) {
+			return n
+		}
+	}
+}
+
+func leadingZeros(sum []byte, zeros int) bool {
+	for i := 0; i < zeros/2; i++ {
+		if sum[i] != 0 {
+			return false
+		}
+	}
+	return zeros%2 == 0 || sum[zeros/2]>>4 == 0
+}
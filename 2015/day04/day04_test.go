@@ -0,0 +1,32 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day04
+
+import "testing"
+
+func TestMine(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "abcdef", want: 609043},
+		{key: "pqrstuv", want: 1048970},
+	}
+	for _, test := range tests {
+		if got := mine(test.key, 5, 1); got != test.want {
+			t.Errorf("mine(%s, 5) = %d, want %d", test.key, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day05 solves AoC 2015 day 5.
+package day05
+
+import (
+	"strings"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 5, glue.Puzzle{
+		Title: "Doesn't He Have Intern-Elves For This?",
+		Tags:  []string{"strings"},
+	})
+	glue.RegisterSolver(2015, 5, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	p1, p2 := 0, 0
+	for _, line := range lines {
+		if nice1(line) {
+			p1++
+		}
+		if nice2(line) {
+			p2++
+		}
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+// nice1 checks a string against the original rules: at least three vowels, at least one letter
+// twice in a row, and none of the naughty pairs.
+func nice1(s string) bool {
+	vowels, double := 0, false
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte("aeiou", s[i]) >= 0 {
+			vowels++
+		}
+		if i > 0 {
+			switch s[i-1 : i+1] {
+			case "ab", "cd", "pq", "xy":
+				return false
+			}
+			double = double || s[i] == s[i-1]
+		}
+	}
+	return vowels >= 3 && double
+}
+
+// nice2 checks a string against the better rules: a pair of letters that appears twice without
+// overlapping, and a letter repeated with exactly one letter between.
+func nice2(s string) bool {
+	pair, repeat := false, false
+	for i := 0; i+2 < len(s) && !(pair && repeat); i++ {
+		pair = pair || strings.Contains(s[i+2:], s[i:i+2])
+		repeat = repeat || s[i] == s[i+2]
+	}
+	return pair && repeat
+}
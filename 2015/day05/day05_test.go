@@ -0,0 +1,53 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day05
+
+import "testing"
+
+func TestNice1(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "ugknbfddgicrmopn", want: true},
+		{s: "aaa", want: true},
+		{s: "jchzalrnumimnmhp", want: false},
+		{s: "haegwjzuvuyypxyu", want: false},
+		{s: "dvszwmarrgswjxmb", want: false},
+	}
+	for _, test := range tests {
+		if got := nice1(test.s); got != test.want {
+			t.Errorf("nice1(%s) = %t, want %t", test.s, got, test.want)
+		}
+	}
+}
+
+func TestNice2(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "qjhvhtzxzqqjkmpb", want: true},
+		{s: "xxyxx", want: true},
+		{s: "uurcxstgmygtbstg", want: false},
+		{s: "ieodomkazucvgmuy", want: false},
+		{s: "aaa", want: false},
+	}
+	for _, test := range tests {
+		if got := nice2(test.s); got != test.want {
+			t.Errorf("nice2(%s) = %t, want %t", test.s, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day06 solves AoC 2015 day 6.
+package day06
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 6, glue.Puzzle{
+		Title: "Probably a Fire Hazard",
+		Tags:  []string{"grid", "geometry"},
+	})
+	glue.RegisterSolver(2015, 6, glue.RegexpSolver{
+		Solver: solve,
+		Regexp: `^(turn on|turn off|toggle) (\d+),(\d+) through (\d+),(\d+)$`,
+	})
+}
+
+func solve(lines [][]string) ([]string, error) {
+	cmds, err := parseCommands(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := lit(cmds, binary)
+	p2 := lit(cmds, dimmer)
+	return glue.Ints(p1, p2), nil
+}
+
+const gridSize = 1000
+
+type action int
+
+const (
+	turnOn action = iota
+	turnOff
+	toggle
+)
+
+type command struct {
+	act      action
+	min, max util.P
+}
+
+// binary is the light behavior in the first part: lights are just on or off.
+func binary(light int, act action) int {
+	switch act {
+	case turnOn:
+		return 1
+	case turnOff:
+		return 0
+	default:
+		return 1 - light
+	}
+}
+
+// dimmer is the light behavior in the second part: each light has a brightness.
+func dimmer(light int, act action) int {
+	switch act {
+	case turnOn:
+		return light + 1
+	case turnOff:
+		return max(light-1, 0)
+	default:
+		return light + 2
+	}
+}
+
+// lit runs the commands on the grid of lights, and returns the total brightness.
+func lit(cmds []command, light func(int, action) int) int {
+	grid := make([]int, gridSize*gridSize)
+	for _, cmd := range cmds {
+		for y := cmd.min.Y; y <= cmd.max.Y; y++ {
+			row := grid[y*gridSize : (y+1)*gridSize]
+			for x := cmd.min.X; x <= cmd.max.X; x++ {
+				row[x] = light(row[x], cmd.act)
+			}
+		}
+	}
+	total := 0
+	for _, b := range grid {
+		total += b
+	}
+	return total
+}
+
+func parseCommands(lines [][]string) ([]command, error) {
+	cmds := make([]command, len(lines))
+	for i, line := range lines {
+		switch line[0] {
+		case "turn on":
+			cmds[i].act = turnOn
+		case "turn off":
+			cmds[i].act = turnOff
+		case "toggle":
+			cmds[i].act = toggle
+		}
+		var coords [4]int
+		for j := range coords {
+			coords[j], _ = strconv.Atoi(line[1+j])
+			if coords[j] >= gridSize {
+				return nil, fmt.Errorf("coordinate out of range: %d", coords[j])
+			}
+		}
+		cmds[i].min = util.P{min(coords[0], coords[2]), min(coords[1], coords[3])}
+		cmds[i].max = util.P{max(coords[0], coords[2]), max(coords[1], coords[3])}
+	}
+	return cmds, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day06
+
+import "testing"
+
+func TestLit(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines [][]string
+		light func(int, action) int
+		want  int
+	}{
+		{
+			name:  "binary",
+			lines: [][]string{{"turn on", "0", "0", "999", "999"}, {"toggle", "0", "0", "999", "0"}, {"turn off", "499", "499", "500", "500"}},
+			light: binary,
+			want:  1000000 - 1000 - 4,
+		},
+		{
+			name:  "dimmer",
+			lines: [][]string{{"turn on", "0", "0", "0", "0"}, {"toggle", "0", "0", "999", "999"}},
+			light: dimmer,
+			want:  1 + 2000000,
+		},
+	}
+	for _, test := range tests {
+		cmds, err := parseCommands(test.lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lit(cmds, test.light); got != test.want {
+			t.Errorf("lit(%s) = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day07 solves AoC 2015 day 7.
+package day07
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/graph"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 7, glue.Puzzle{
+		Title: "Some Assembly Required",
+		Tags:  []string{"graph", "bits", "parsing"},
+	})
+	glue.RegisterSolver(2015, 7, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	c, err := parseCircuit(lines)
+	if err != nil {
+		return nil, err
+	}
+	a, ok1 := c.g.V("a")
+	b, ok2 := c.g.V("b")
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("wires a and b not both present")
+	}
+	p1 := c.eval(nil)[a]
+	p2 := c.eval(map[int]uint16{b: p1})[a]
+	return glue.Ints(int(p1), int(p2)), nil
+}
+
+// circuit is a set of gates, one driving each wire, and an order to evaluate them in.
+type circuit struct {
+	g     *graph.Dense
+	gates []gate
+	order []int
+}
+
+type gate struct {
+	op   string
+	args [2]operand
+}
+
+// operand is an input to a gate: either another wire (vertex) or a constant signal.
+type operand struct {
+	wire  int
+	value uint16
+}
+
+// eval computes the signals on all the wires, with the given wires overridden to a fixed signal.
+func (c *circuit) eval(override map[int]uint16) []uint16 {
+	signals := make([]uint16, len(c.gates))
+	get := func(o operand) uint16 {
+		if o.wire < 0 {
+			return o.value
+		}
+		return signals[o.wire]
+	}
+	for _, v := range c.order {
+		if s, ok := override[v]; ok {
+			signals[v] = s
+			continue
+		}
+		g := &c.gates[v]
+		x, y := get(g.args[0]), get(g.args[1])
+		switch g.op {
+		case "":
+			signals[v] = x
+		case "NOT":
+			signals[v] = ^x
+		case "AND":
+			signals[v] = x & y
+		case "OR":
+			signals[v] = x | y
+		case "LSHIFT":
+			signals[v] = x << y
+		case "RSHIFT":
+			signals[v] = x >> y
+		}
+	}
+	return signals
+}
+
+// parseCircuit reads the gates, and sorts them topologically using the wires between them as
+// edges, so that each gate is evaluated only after its inputs.
+func parseCircuit(lines []string) (*circuit, error) {
+	gb := graph.NewBuilder()
+	type parsed struct {
+		out  int
+		op   string
+		args [2]string
+	}
+	var gates []parsed
+	for _, line := range lines {
+		expr, out, ok := strings.Cut(line, " -> ")
+		if !ok {
+			return nil, fmt.Errorf("invalid instruction: %s", line)
+		}
+		p := parsed{out: gb.V(out)}
+		switch f := strings.Fields(expr); len(f) {
+		case 1:
+			p.args[0] = f[0]
+		case 2:
+			p.op, p.args[0] = f[0], f[1]
+		case 3:
+			p.op, p.args[0], p.args[1] = f[1], f[0], f[2]
+		default:
+			return nil, fmt.Errorf("invalid expression: %s", expr)
+		}
+		switch p.op {
+		case "", "NOT", "AND", "OR", "LSHIFT", "RSHIFT":
+		default:
+			return nil, fmt.Errorf("invalid gate: %s", p.op)
+		}
+		for _, arg := range p.args {
+			if _, err := strconv.ParseUint(arg, 10, 16); arg != "" && err != nil {
+				gb.AddEdgeL(arg, out)
+			}
+		}
+		gates = append(gates, p)
+	}
+
+	c := &circuit{g: gb.DenseDigraph(), gates: make([]gate, gb.Len())}
+	driven := make([]bool, gb.Len())
+	for _, p := range gates {
+		if driven[p.out] {
+			return nil, fmt.Errorf("wire %s driven twice", c.g.Label(p.out))
+		}
+		driven[p.out] = true
+		c.gates[p.out].op = p.op
+		for i, arg := range p.args {
+			if n, err := strconv.ParseUint(arg, 10, 16); err == nil || arg == "" {
+				c.gates[p.out].args[i] = operand{wire: -1, value: uint16(n)}
+			} else {
+				v, _ := c.g.V(arg)
+				c.gates[p.out].args[i] = operand{wire: v}
+			}
+		}
+	}
+	for v, d := range driven {
+		if !d {
+			return nil, fmt.Errorf("wire %s not driven", c.g.Label(v))
+		}
+	}
+	c.order = c.g.TopoSort(true)
+	if len(c.order) != len(c.gates) {
+		return nil, fmt.Errorf("circuit has a loop")
+	}
+	return c, nil
+}
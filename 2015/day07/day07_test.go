@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day07
+
+import "testing"
+
+var ex = []string{
+	"123 -> x",
+	"456 -> y",
+	"x AND y -> d",
+	"x OR y -> e",
+	"x LSHIFT 2 -> f",
+	"y RSHIFT 2 -> g",
+	"NOT x -> h",
+	"NOT y -> i",
+}
+
+func TestEval(t *testing.T) {
+	c, err := parseCircuit(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]uint16{"d": 72, "e": 507, "f": 492, "g": 114, "h": 65412, "i": 65079, "x": 123, "y": 456}
+	signals := c.eval(nil)
+	for wire, w := range want {
+		v, _ := c.g.V(wire)
+		if got := signals[v]; got != w {
+			t.Errorf("signal on %s = %d, want %d", wire, got, w)
+		}
+	}
+	x, _ := c.g.V("x")
+	h, _ := c.g.V("h")
+	if got := c.eval(map[int]uint16{x: 0})[h]; got != 65535 {
+		t.Errorf("signal on h with x = 0: %d, want 65535", got)
+	}
+}
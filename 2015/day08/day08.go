@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day08 solves AoC 2015 day 8.
+package day08
+
+import (
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 8, glue.Puzzle{
+		Title: "Matchsticks",
+		Tags:  []string{"strings", "parsing"},
+	})
+	glue.RegisterSolver(2015, 8, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	p1, p2 := 0, 0
+	for _, line := range lines {
+		p1 += len(line) - decodedLen(line)
+		p2 += encodedLen(line) - len(line)
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+// decodedLen returns the number of characters in the string a string literal represents.
+func decodedLen(lit string) (n int) {
+	for i := 1; i < len(lit)-1; i++ {
+		if lit[i] == '\\' {
+			if lit[i+1] == 'x' {
+				i += 3
+			} else {
+				i++
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// encodedLen returns the length of a string when written as a string literal.
+func encodedLen(s string) int {
+	n := len(s) + 2
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			n++
+		}
+	}
+	return n
+}
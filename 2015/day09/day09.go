@@ -0,0 +1,73 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day09 solves AoC 2015 day 9.
+package day09
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/graph"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 9, glue.Puzzle{
+		Title: "All in a Single Night",
+		Tags:  []string{"graph", "combinatorics"},
+	})
+	glue.RegisterSolver(2015, 9, glue.RegexpSolver{
+		Solver: solve,
+		Regexp: `^(\w+) to (\w+) = (\d+)$`,
+	})
+}
+
+func solve(lines [][]string) ([]string, error) {
+	gb := graph.NewBuilder()
+	for _, line := range lines {
+		d, _ := strconv.Atoi(line[2])
+		gb.AddEdgeWL(line[0], line[1], d)
+	}
+	shortest, longest := routes(gb.DenseGraphW())
+	return glue.Ints(shortest, longest), nil
+}
+
+// routes returns the lengths of the shortest and longest routes that visit each location exactly
+// once. The locations are few enough to just try them all.
+func routes(g *graph.DenseW) (shortest, longest int) {
+	shortest, longest = math.MaxInt, 0
+	n := g.Len()
+	visited := make([]bool, n)
+	var walk func(at, left, dist int)
+	walk = func(at, left, dist int) {
+		if left == 0 {
+			shortest, longest = min(shortest, dist), max(longest, dist)
+			return
+		}
+		for next := 0; next < n; next++ {
+			if !visited[next] && g.E(at, next) {
+				visited[next] = true
+				walk(next, left-1, dist+g.W(at, next))
+				visited[next] = false
+			}
+		}
+	}
+	for start := 0; start < n; start++ {
+		visited[start] = true
+		walk(start, n-1, 0)
+		visited[start] = false
+	}
+	return shortest, longest
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day09
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var ex = [][]string{
+	{"London", "Dublin", "464"},
+	{"London", "Belfast", "518"},
+	{"Dublin", "Belfast", "141"},
+}
+
+func TestSolve(t *testing.T) {
+	want := []string{"605", "982"}
+	got, err := solve(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("solve(ex) = %v, want %v", got, want)
+	}
+}
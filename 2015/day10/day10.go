@@ -0,0 +1,60 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day10 solves AoC 2015 day 10.
+package day10
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 10, glue.Puzzle{
+		Title: "Elves Look, Elves Say",
+		Tags:  []string{"strings", "simulation"},
+	})
+	glue.RegisterSolver(2015, 10, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	seq := []byte(lines[0])
+	seq = lookAndSay(seq, 40)
+	p1 := len(seq)
+	seq = lookAndSay(seq, 10)
+	p2 := len(seq)
+	return glue.Ints(p1, p2), nil
+}
+
+// lookAndSay applies the given number of rounds of the look-and-say process to a digit sequence.
+func lookAndSay(seq []byte, rounds int) []byte {
+	next := make([]byte, 0, 2*len(seq))
+	for ; rounds > 0; rounds-- {
+		next = next[:0]
+		for i := 0; i < len(seq); {
+			j := i + 1
+			for j < len(seq) && seq[j] == seq[i] {
+				j++
+			}
+			next = append(next, byte('0'+j-i), seq[i])
+			i = j
+		}
+		seq, next = next, seq
+	}
+	return seq
+}
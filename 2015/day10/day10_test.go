@@ -0,0 +1,38 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day10
+
+import "testing"
+
+func TestLookAndSay(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want string
+	}{
+		{seq: "1", want: "11"},
+		{seq: "11", want: "21"},
+		{seq: "21", want: "1211"},
+		{seq: "1211", want: "111221"},
+		{seq: "111221", want: "312211"},
+	}
+	for _, test := range tests {
+		if got := string(lookAndSay([]byte(test.seq), 1)); got != test.want {
+			t.Errorf("lookAndSay(%s) = %s, want %s", test.seq, got, test.want)
+		}
+	}
+	if got := string(lookAndSay([]byte("1"), 5)); got != "312211" {
+		t.Errorf("lookAndSay(1, 5) = %s, want 312211", got)
+	}
+}
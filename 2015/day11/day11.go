@@ -0,0 +1,95 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day11 solves AoC 2015 day 11.
+package day11
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 11, glue.Puzzle{
+		Title: "Corporate Policy",
+		Tags:  []string{"strings"},
+	})
+	glue.RegisterSolver(2015, 11, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	p1 := next(lines[0])
+	p2 := next(p1)
+	return []string{p1, p2}, nil
+}
+
+// next returns the next password after the given one that meets the security requirements.
+func next(password string) string {
+	pw := []byte(password)
+	for {
+		increment(pw)
+		if valid(pw) {
+			return string(pw)
+		}
+	}
+}
+
+// increment moves to the next candidate password. As a shortcut, it skips straight past any
+// forbidden letter, since no password containing it can be valid.
+func increment(pw []byte) {
+	for i, c := range pw {
+		if forbidden(c) {
+			pw[i]++
+			for j := i + 1; j < len(pw); j++ {
+				pw[j] = 'a'
+			}
+			return
+		}
+	}
+	for i := len(pw) - 1; i >= 0; i-- {
+		if pw[i] < 'z' {
+			pw[i]++
+			if forbidden(pw[i]) {
+				pw[i]++
+			}
+			return
+		}
+		pw[i] = 'a'
+	}
+}
+
+func forbidden(c byte) bool { return c == 'i' || c == 'o' || c == 'l' }
+
+// valid checks the requirements: a straight of three increasing letters, none of the forbidden
+// letters, and two different pairs of the same letter.
+func valid(pw []byte) bool {
+	straight, pairs := false, 0
+	for i := 0; i < len(pw); i++ {
+		if forbidden(pw[i]) {
+			return false
+		}
+		if i >= 2 && pw[i-2]+1 == pw[i-1] && pw[i-1]+1 == pw[i] {
+			straight = true
+		}
+		if i >= 1 && pw[i-1] == pw[i] {
+			pairs++
+			i++
+		}
+	}
+	return straight && pairs >= 2
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day11
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		pw   string
+		want bool
+	}{
+		{pw: "hijklmmn", want: false},
+		{pw: "abbceffg", want: false},
+		{pw: "abbcegjk", want: false},
+		{pw: "abcdffaa", want: true},
+		{pw: "ghjaabcc", want: true},
+	}
+	for _, test := range tests {
+		if got := valid([]byte(test.pw)); got != test.want {
+			t.Errorf("valid(%s) = %t, want %t", test.pw, got, test.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		pw   string
+		want string
+	}{
+		{pw: "abcdefgh", want: "abcdffaa"},
+		{pw: "ghijklmn", want: "ghjaabcc"},
+	}
+	for _, test := range tests {
+		if got := next(test.pw); got != test.want {
+			t.Errorf("next(%s) = %s, want %s", test.pw, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day12 solves AoC 2015 day 12.
+package day12
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 12, glue.Puzzle{
+		Title: "JSAbacusFramework.io",
+		Tags:  []string{"parsing"},
+	})
+	glue.RegisterSolver(2015, 12, glue.GenericSolver(solve))
+}
+
+func solve(r io.Reader) ([]string, error) {
+	var doc any
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	p1 := sum(doc, false)
+	p2 := sum(doc, true)
+	return glue.Ints(p1, p2), nil
+}
+
+// sum adds up all the numbers in a JSON document. If skipRed is set, objects with any property
+// with the value "red" are ignored, along with all their children.
+func sum(doc any, skipRed bool) int {
+	switch doc := doc.(type) {
+	case json.Number:
+		n, _ := doc.Int64()
+		return int(n)
+	case []any:
+		s := 0
+		for _, v := range doc {
+			s += sum(v, skipRed)
+		}
+		return s
+	case map[string]any:
+		s := 0
+		for _, v := range doc {
+			if skipRed && v == "red" {
+				return 0
+			}
+			s += sum(v, skipRed)
+		}
+		return s
+	}
+	return 0
+}
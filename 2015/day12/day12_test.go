@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day12
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{doc: `[1,2,3]`, want: []string{"6", "6"}},
+		{doc: `{"a":2,"b":4}`, want: []string{"6", "6"}},
+		{doc: `[[[3]]]`, want: []string{"3", "3"}},
+		{doc: `{"a":{"b":4},"c":-1}`, want: []string{"3", "3"}},
+		{doc: `{"a":[-1,1]}`, want: []string{"0", "0"}},
+		{doc: `[]`, want: []string{"0", "0"}},
+		{doc: `[1,{"c":"red","b":2},3]`, want: []string{"6", "4"}},
+		{doc: `{"d":"red","e":[1,2,3,4],"f":5}`, want: []string{"15", "0"}},
+		{doc: `[1,"red",5]`, want: []string{"6", "6"}},
+	}
+	for _, test := range tests {
+		got, err := solve(strings.NewReader(test.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("solve(%s) = %v, want %v", test.doc, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day13 solves AoC 2015 day 13.
+package day13
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/graph"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 13, glue.Puzzle{
+		Title: "Knights of the Dinner Table",
+		Tags:  []string{"graph", "combinatorics"},
+	})
+	glue.RegisterSolver(2015, 13, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
+}
+
+const inputRegexp = `^(\w+) would (gain|lose) (\d+) happiness units? by sitting next to (\w+)\.$`
+
+func solve(lines [][]string) ([]string, error) {
+	gb := graph.NewBuilder()
+	for _, line := range lines {
+		h, _ := strconv.Atoi(line[2])
+		if line[1] == "lose" {
+			h = -h
+		}
+		gb.AddEdgeWL(line[0], line[3], h)
+	}
+	p1 := bestSeating(gb.DenseGraphW())
+	me := gb.V("me")
+	for u := 0; u < me; u++ {
+		gb.AddEdgeW(u, me, 0)
+	}
+	p2 := bestSeating(gb.DenseGraphW())
+	return glue.Ints(p1, p2), nil
+}
+
+// bestSeating returns the total change in happiness for the best arrangement around the table.
+// The edge weights of the graph are the happiness changes of both neighbors added together.
+func bestSeating(g *graph.DenseW) int {
+	n := g.Len()
+	if n < 2 {
+		return 0
+	}
+	best := math.MinInt
+	seated := make([]bool, n)
+	var seat func(at, left, total int)
+	seat = func(at, left, total int) {
+		if left == 0 {
+			best = max(best, total+g.W(at, 0))
+			return
+		}
+		for next := 1; next < n; next++ {
+			if !seated[next] {
+				seated[next] = true
+				seat(next, left-1, total+g.W(at, next))
+				seated[next] = false
+			}
+		}
+	}
+	// the table is round, so the first person can always sit in the same seat
+	seated[0] = true
+	seat(0, n-1, 0)
+	return best
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day14 solves AoC 2015 day 14.
+package day14
+
+import (
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 14, glue.Puzzle{
+		Title: "Reindeer Olympics",
+		Tags:  []string{"simulation"},
+	})
+	glue.RegisterSolver(2015, 14, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
+}
+
+const inputRegexp = `^(\w+) can fly (\d+) km/s for (\d+) seconds?, but then must rest for (\d+) seconds?\.$`
+
+const raceTime = 2503
+
+func solve(lines [][]string) ([]string, error) {
+	herd := make([]reindeer, len(lines))
+	for i, line := range lines {
+		herd[i].speed, _ = strconv.Atoi(line[1])
+		herd[i].fly, _ = strconv.Atoi(line[2])
+		herd[i].rest, _ = strconv.Atoi(line[3])
+	}
+	p1, p2 := race(herd, raceTime)
+	return glue.Ints(p1, p2), nil
+}
+
+type reindeer struct {
+	speed, fly, rest int
+}
+
+// distance returns how far a reindeer has flown after the given number of seconds.
+func (r reindeer) distance(t int) int {
+	period := r.fly + r.rest
+	flown := t/period*r.fly + min(t%period, r.fly)
+	return flown * r.speed
+}
+
+// race returns the distance flown by the winner of the race, and the number of points of the winner
+// under the new scoring system, where the leaders get a point each second.
+func race(herd []reindeer, duration int) (distance, points int) {
+	score := make([]int, len(herd))
+	for t := 1; t <= duration; t++ {
+		lead := 0
+		for _, r := range herd {
+			lead = max(lead, r.distance(t))
+		}
+		for i, r := range herd {
+			if r.distance(t) == lead {
+				score[i]++
+			}
+		}
+	}
+	for i, r := range herd {
+		distance, points = max(distance, r.distance(duration)), max(points, score[i])
+	}
+	return distance, points
+}
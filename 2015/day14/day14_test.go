@@ -0,0 +1,29 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day14
+
+import "testing"
+
+var ex = []reindeer{
+	{speed: 14, fly: 10, rest: 127},
+	{speed: 16, fly: 11, rest: 162},
+}
+
+func TestRace(t *testing.T) {
+	wantDistance, wantPoints := 1120, 689
+	if distance, points := race(ex, 1000); distance != wantDistance || points != wantPoints {
+		t.Errorf("race(ex, 1000) = (%d, %d), want (%d, %d)", distance, points, wantDistance, wantPoints)
+	}
+}
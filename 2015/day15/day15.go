@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day15 solves AoC 2015 day 15.
+package day15
+
+import (
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 15, glue.Puzzle{
+		Title: "Science for Hungry People",
+		Tags:  []string{"combinatorics"},
+	})
+	glue.RegisterSolver(2015, 15, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
+}
+
+const inputRegexp = `^(\w+): capacity (-?\d+), durability (-?\d+), flavor (-?\d+), texture (-?\d+), calories (-?\d+)$`
+
+const (
+	teaspoons = 100
+	calories  = 500
+)
+
+func solve(lines [][]string) ([]string, error) {
+	ingredients := make([]ingredient, len(lines))
+	for i, line := range lines {
+		for j := range ingredients[i] {
+			ingredients[i][j], _ = strconv.Atoi(line[1+j])
+		}
+	}
+	p1, p2 := bestCookie(ingredients)
+	return glue.Ints(p1, p2), nil
+}
+
+// ingredient lists the properties of an ingredient, per teaspoon. The last one is calories.
+type ingredient [5]int
+
+// bestCookie tries all the ways to divide the teaspoons between the ingredients, and returns the
+// best total score, and the best score of a cookie with exactly the right amount of calories.
+func bestCookie(ingredients []ingredient) (best, bestDiet int) {
+	var mix func(i, left int, sum ingredient)
+	mix = func(i, left int, sum ingredient) {
+		if i == len(ingredients)-1 {
+			for j, p := range ingredients[i] {
+				sum[j] += left * p
+			}
+			s := score(sum)
+			best = max(best, s)
+			if sum[4] == calories {
+				bestDiet = max(bestDiet, s)
+			}
+			return
+		}
+		for n := 0; n <= left; n++ {
+			next := sum
+			for j, p := range ingredients[i] {
+				next[j] += n * p
+			}
+			mix(i+1, left-n, next)
+		}
+	}
+	if len(ingredients) > 0 {
+		mix(0, teaspoons, ingredient{})
+	}
+	return best, bestDiet
+}
+
+// score multiplies together the properties (except calories), with negative totals counting as zero.
+func score(sum ingredient) int {
+	s := 1
+	for _, p := range sum[:4] {
+		s *= max(p, 0)
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day15
+
+import "testing"
+
+var ex = []ingredient{
+	{-1, -2, 6, 3, 8},
+	{2, 3, -2, -1, 3},
+}
+
+func TestBestCookie(t *testing.T) {
+	wantBest, wantDiet := 62842880, 57600000
+	if best, diet := bestCookie(ex); best != wantBest || diet != wantDiet {
+		t.Errorf("bestCookie(ex) = (%d, %d), want (%d, %d)", best, diet, wantBest, wantDiet)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day16 solves AoC 2015 day 16.
+package day16
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 16, glue.Puzzle{
+		Title: "Aunt Sue",
+		Tags:  []string{"parsing"},
+	})
+	glue.RegisterSolver(2015, 16, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	sues := make([]map[string]int, len(lines))
+	for i, line := range lines {
+		sue, err := parseSue(line)
+		if err != nil {
+			return nil, err
+		}
+		sues[i] = sue
+	}
+	p1 := find(sues, exact)
+	p2 := find(sues, ranged)
+	return glue.Ints(p1, p2), nil
+}
+
+// reading is the output of the My First Crime Scene Analysis Machine.
+var reading = map[string]int{
+	"children":    3,
+	"cats":        7,
+	"samoyeds":    2,
+	"pomeranians": 3,
+	"akitas":      0,
+	"vizslas":     0,
+	"goldfish":    5,
+	"trees":       3,
+	"cars":        2,
+	"perfumes":    1,
+}
+
+// find returns the number of the (first) aunt whose remembered things all match the reading.
+func find(sues []map[string]int, match func(thing string, have int) bool) int {
+outer:
+	for i, sue := range sues {
+		for thing, have := range sue {
+			if !match(thing, have) {
+				continue outer
+			}
+		}
+		return i + 1
+	}
+	return -1
+}
+
+// exact is the matching rule of part 1, where the reading is a precise count of each thing.
+func exact(thing string, have int) bool {
+	return reading[thing] == have
+}
+
+// ranged is the matching rule of part 2, with the outdated retroencabulator.
+func ranged(thing string, have int) bool {
+	switch thing {
+	case "cats", "trees":
+		return have > reading[thing]
+	case "pomeranians", "goldfish":
+		return have < reading[thing]
+	}
+	return reading[thing] == have
+}
+
+func parseSue(line string) (map[string]int, error) {
+	_, things, ok := strings.Cut(line, ": ")
+	if !ok {
+		return nil, fmt.Errorf("invalid line: %s", line)
+	}
+	sue := make(map[string]int)
+	for _, thing := range strings.Split(things, ", ") {
+		name, count, ok := strings.Cut(thing, ": ")
+		if !ok {
+			return nil, fmt.Errorf("invalid thing: %s", thing)
+		}
+		if _, known := reading[name]; !known {
+			return nil, fmt.Errorf("unknown thing: %s", name)
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, err
+		}
+		sue[name] = n
+	}
+	return sue, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day17 solves AoC 2015 day 17.
+package day17
+
+import (
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 17, glue.Puzzle{
+		Title: "No Such Thing as Too Much",
+		Tags:  []string{"combinatorics", "dp"},
+	})
+	glue.RegisterSolver(2015, 17, glue.IntSolver(solve))
+}
+
+const eggnog = 150
+
+func solve(containers []int) ([]string, error) {
+	p1, p2 := combinations(containers, eggnog)
+	return glue.Ints(p1, p2), nil
+}
+
+// combinations returns the number of ways to fill the containers with exactly the given volume,
+// and the number of ways to do that with the smallest possible number of containers.
+func combinations(containers []int, volume int) (all, fewest int) {
+	// ways[k][v] is the number of ways to make volume v out of k containers.
+	ways := make([][]int, len(containers)+1)
+	for k := range ways {
+		ways[k] = make([]int, volume+1)
+	}
+	ways[0][0] = 1
+	for i, c := range containers {
+		for k := i + 1; k >= 1; k-- {
+			for v := volume; v >= c; v-- {
+				ways[k][v] += ways[k-1][v-c]
+			}
+		}
+	}
+	for k := range ways {
+		all += ways[k][volume]
+		if fewest == 0 {
+			fewest = ways[k][volume]
+		}
+	}
+	return all, fewest
+}
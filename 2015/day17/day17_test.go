@@ -0,0 +1,25 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day17
+
+import "testing"
+
+func TestCombinations(t *testing.T) {
+	containers := []int{20, 15, 10, 5, 5}
+	wantAll, wantFewest := 4, 3
+	if all, fewest := combinations(containers, 25); all != wantAll || fewest != wantFewest {
+		t.Errorf("combinations(%v, 25) = (%d, %d), want (%d, %d)", containers, all, fewest, wantAll, wantFewest)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day18 solves AoC 2015 day 18.
+package day18
+
+import (
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 18, glue.Puzzle{
+		Title: "Like a GIF For Your Yard",
+		Tags:  []string{"grid", "automaton"},
+	})
+	glue.RegisterSolver(2015, 18, glue.FixedLevelSolver(solve))
+}
+
+const steps = 100
+
+func solve(l *util.FixedLevel) ([]string, error) {
+	p1 := animate(l, steps, false)
+	p2 := animate(l, steps, true)
+	return glue.Ints(p1, p2), nil
+}
+
+// animate runs the game of life on the lights for the given number of steps, and returns the number
+// of lights left on. If stuck is set, the corner lights are always on.
+func animate(l *util.FixedLevel, steps int, stuck bool) int {
+	cur := &util.FixedLevel{W: l.W, H: l.H, Data: append([]byte(nil), l.Data...)}
+	next := util.EmptyFixedLevel(l.W, l.H, '.')
+	if stuck {
+		stickCorners(cur)
+	}
+	for ; steps > 0; steps-- {
+		for y := 0; y < cur.H; y++ {
+			for x := 0; x < cur.W; x++ {
+				n := 0
+				for _, p := range (util.P{x, y}).Neigh8() {
+					if cur.InBounds(p.X, p.Y) && cur.AtP(p) == '#' {
+						n++
+					}
+				}
+				if n == 3 || n == 2 && cur.At(x, y) == '#' {
+					next.Set(x, y, '#')
+				} else {
+					next.Set(x, y, '.')
+				}
+			}
+		}
+		cur, next = next, cur
+		if stuck {
+			stickCorners(cur)
+		}
+	}
+	on := 0
+	for _, b := range cur.Data {
+		if b == '#' {
+			on++
+		}
+	}
+	return on
+}
+
+func stickCorners(l *util.FixedLevel) {
+	l.Set(0, 0, '#')
+	l.Set(l.W-1, 0, '#')
+	l.Set(0, l.H-1, '#')
+	l.Set(l.W-1, l.H-1, '#')
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day18
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/util"
+)
+
+var ex = `
+.#.#.#
+...##.
+#....#
+..#...
+#.#..#
+####..
+`
+
+func TestAnimate(t *testing.T) {
+	l := util.ParseFixedLevel([]byte(strings.TrimPrefix(ex, "\n")))
+	tests := []struct {
+		steps int
+		stuck bool
+		want  int
+	}{
+		{steps: 4, stuck: false, want: 4},
+		{steps: 5, stuck: true, want: 17},
+	}
+	for _, test := range tests {
+		if got := animate(l, test.steps, test.stuck); got != test.want {
+			t.Errorf("animate(ex, %d, %t) = %d, want %d", test.steps, test.stuck, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day19 solves AoC 2015 day 19.
+package day19
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 19, glue.Puzzle{
+		Title:         "Medicine for Rudolph",
+		Tags:          []string{"strings", "parsing"},
+		InputSpecific: true,
+	})
+	glue.RegisterSolver(2015, 19, glue.ChunkSolver(solve))
+}
+
+func solve(chunks []string) ([]string, error) {
+	if len(chunks) != 2 {
+		return nil, fmt.Errorf("expected 2 chunks, got %d", len(chunks))
+	}
+	g, err := parseGrammar(strings.Split(chunks[0], "\n"))
+	if err != nil {
+		return nil, err
+	}
+	molecule := tokenize(strings.TrimSpace(chunks[1]))
+	p1 := g.calibrate(molecule)
+	p2, err := g.steps(molecule)
+	if err != nil {
+		return nil, err
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+// grammar holds the replacements, indexed by the element they replace.
+type grammar map[string][][]string
+
+// calibrate returns the number of distinct molecules that can be made with one replacement.
+func (g grammar) calibrate(molecule []string) int {
+	seen := make(map[string]struct{})
+	for i, elem := range molecule {
+		prefix, suffix := strings.Join(molecule[:i], ""), strings.Join(molecule[i+1:], "")
+		for _, rep := range g[elem] {
+			seen[prefix+strings.Join(rep, "")+suffix] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+// steps returns the number of replacements needed to make the molecule starting from "e".
+//
+// Searching for this is hopeless in general, but the grammar of the puzzle has a special shape.
+// Each replacement is either X => AB, or X => A Rn B Ar, with optionally up to two more elements
+// separated by Y inside the Rn...Ar parentheses. Weighing Rn and Ar as 0, Y as -1 and all other
+// elements as 1, each replacement increases the total weight by exactly 1, no matter where it's
+// done. So the number of steps is the difference between the weights of the molecule and e. This
+// also covers the simple grammars where every replacement adds one element.
+func (g grammar) steps(molecule []string) (int, error) {
+	weight := func(elems []string) (w int) {
+		for _, elem := range elems {
+			switch elem {
+			case "Rn", "Ar":
+			case "Y":
+				w--
+			default:
+				w++
+			}
+		}
+		return w
+	}
+	if len(g["e"]) == 0 {
+		return 0, fmt.Errorf("no replacements for e")
+	}
+	we := weight(g["e"][0]) - 1
+	for from, reps := range g {
+		wf := weight([]string{from})
+		if from == "e" {
+			wf = we
+		}
+		for _, rep := range reps {
+			if weight(rep)-wf != 1 {
+				return 0, fmt.Errorf("unsupported grammar: %s => %s", from, strings.Join(rep, ""))
+			}
+		}
+	}
+	return weight(molecule) - we, nil
+}
+
+func parseGrammar(lines []string) (grammar, error) {
+	g := make(grammar)
+	for _, line := range lines {
+		from, to, ok := strings.Cut(line, " => ")
+		if !ok {
+			return nil, fmt.Errorf("invalid replacement: %s", line)
+		}
+		g[from] = append(g[from], tokenize(to))
+	}
+	return g, nil
+}
+
+// tokenize splits a molecule into elements: an uppercase letter followed by any lowercase ones.
+func tokenize(molecule string) (elems []string) {
+	for i := 0; i < len(molecule); {
+		j := i + 1
+		for j < len(molecule) && molecule[j] >= 'a' && molecule[j] <= 'z' {
+			j++
+		}
+		elems = append(elems, molecule[i:j])
+		i = j
+	}
+	return elems
+}
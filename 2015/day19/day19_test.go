@@ -0,0 +1,69 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day19
+
+import "testing"
+
+var ex = []string{
+	"e => H",
+	"e => O",
+	"H => HO",
+	"H => OH",
+	"O => HH",
+}
+
+func TestCalibrate(t *testing.T) {
+	tests := []struct {
+		molecule string
+		want     int
+	}{
+		{molecule: "HOH", want: 4},
+		{molecule: "HOHOHO", want: 7},
+	}
+	g, err := parseGrammar(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		if got := g.calibrate(tokenize(test.molecule)); got != test.want {
+			t.Errorf("calibrate(%s) = %d, want %d", test.molecule, got, test.want)
+		}
+	}
+}
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		grammar  []string
+		molecule string
+		want     int
+	}{
+		{grammar: ex, molecule: "HOH", want: 3},
+		{grammar: ex, molecule: "HOHOHO", want: 6},
+		{
+			grammar:  []string{"e => HF", "e => NAl", "H => CRnAlAr", "H => HCa", "F => CaF", "Al => ThRnFYFAr", "Ca => CaCa"},
+			molecule: "CRnThRnCaFYFArArCaCaF", // e => HF => CRnAlArF => CRnThRnFYFArArF => ...
+			want:     6,
+		},
+	}
+	for _, test := range tests {
+		g, err := parseGrammar(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := g.steps(tokenize(test.molecule)); err != nil || got != test.want {
+			t.Errorf("steps(%s) = (%d, %v), want %d", test.molecule, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day20 solves AoC 2015 day 20.
+package day20
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 20, glue.Puzzle{
+		Title: "Infinite Elves and Infinite Houses",
+		Tags:  []string{"number-theory"},
+	})
+	glue.RegisterSolver(2015, 20, glue.IntSolver(solve))
+}
+
+func solve(input []int) ([]string, error) {
+	if len(input) != 1 {
+		return nil, fmt.Errorf("expected 1 number, got %d", len(input))
+	}
+	p1 := firstHouse(input[0], 10, 0)
+	p2 := firstHouse(input[0], 11, 50)
+	return glue.Ints(p1, p2), nil
+}
+
+// firstHouse returns the lowest-numbered house that gets at least the target number of presents,
+// when each elf delivers the given number of presents per house, to at most limit houses (or to
+// infinitely many if limit is 0).
+func firstHouse(target, presents, limit int) int {
+	// Elf n delivers at least n*presents to house n, so that's as far as it needs to go.
+	n := target/presents + 1
+	houses := make([]int, n+1)
+	for elf := 1; elf <= n; elf++ {
+		last := n
+		if limit > 0 {
+			last = min(n, elf*limit)
+		}
+		for h := elf; h <= last; h += elf {
+			houses[h] += elf * presents
+		}
+	}
+	for h := 1; h <= n; h++ {
+		if houses[h] >= target {
+			return h
+		}
+	}
+	return -1
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day20
+
+import "testing"
+
+func TestFirstHouse(t *testing.T) {
+	tests := []struct {
+		target int
+		want   int
+	}{
+		{target: 10, want: 1},
+		{target: 70, want: 4},
+		{target: 120, want: 6},
+		{target: 130, want: 8},
+		{target: 150, want: 8},
+	}
+	for _, test := range tests {
+		if got := firstHouse(test.target, 10, 0); got != test.want {
+			t.Errorf("firstHouse(%d) = %d, want %d", test.target, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day21 solves AoC 2015 day 21.
+package day21
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/ix"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 21, glue.Puzzle{
+		Title: "RPG Simulator 20XX",
+		Tags:  []string{"combinatorics", "simulation"},
+	})
+	glue.RegisterSolver(2015, 21, glue.IntSolver(solve))
+}
+
+const playerHP = 100
+
+func solve(input []int) ([]string, error) {
+	if len(input) != 3 {
+		return nil, fmt.Errorf("expected boss hit points, damage and armor, got %d numbers", len(input))
+	}
+	boss := fighter{hp: input[0], damage: input[1], armor: input[2]}
+	p1, p2 := shop(boss)
+	return glue.Ints(p1, p2), nil
+}
+
+type fighter struct {
+	hp, damage, armor int
+}
+
+type item struct {
+	cost, damage, armor int
+}
+
+var (
+	weapons = []item{{8, 4, 0}, {10, 5, 0}, {25, 6, 0}, {40, 7, 0}, {74, 8, 0}}
+	armors  = []item{{0, 0, 0}, {13, 0, 1}, {31, 0, 2}, {53, 0, 3}, {75, 0, 4}, {102, 0, 5}}
+	rings   = []item{{0, 0, 0}, {0, 0, 0}, {25, 1, 0}, {50, 2, 0}, {100, 3, 0}, {20, 0, 1}, {40, 0, 2}, {80, 0, 3}}
+)
+
+// shop tries all the ways to equip the player: exactly one weapon, at most one armor, and up to two
+// rings. It returns the least gold spent to win, and the most gold spent to still lose.
+func shop(boss fighter) (cheapestWin, dearestLoss int) {
+	cheapestWin = math.MaxInt
+	for _, w := range weapons {
+		for _, a := range armors {
+			for i, r1 := range rings {
+				for _, r2 := range rings[i+1:] {
+					cost := w.cost + a.cost + r1.cost + r2.cost
+					player := fighter{
+						hp:     playerHP,
+						damage: w.damage + r1.damage + r2.damage,
+						armor:  a.armor + r1.armor + r2.armor,
+					}
+					if wins(player, boss) {
+						cheapestWin = min(cheapestWin, cost)
+					} else {
+						dearestLoss = max(dearestLoss, cost)
+					}
+				}
+			}
+		}
+	}
+	return cheapestWin, dearestLoss
+}
+
+// wins checks if the player beats the boss. The player goes first, so wins any tie in the number of
+// turns it takes to win.
+func wins(player, boss fighter) bool {
+	playerTurns := ix.CeilDiv(boss.hp, max(1, player.damage-boss.armor))
+	bossTurns := ix.CeilDiv(player.hp, max(1, boss.damage-player.armor))
+	return playerTurns <= bossTurns
+}
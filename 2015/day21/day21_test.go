@@ -0,0 +1,29 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day21
+
+import "testing"
+
+func TestWins(t *testing.T) {
+	player := fighter{hp: 8, damage: 5, armor: 5}
+	boss := fighter{hp: 12, damage: 7, armor: 2}
+	if !wins(player, boss) {
+		t.Errorf("wins(%v, %v) = false, want true", player, boss)
+	}
+	player.hp = 6
+	if wins(player, boss) {
+		t.Errorf("wins(%v, %v) = true, want false", player, boss)
+	}
+}
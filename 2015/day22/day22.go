@@ -0,0 +1,139 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day22 solves AoC 2015 day 22.
+package day22
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 22, glue.Puzzle{
+		Title: "Wizard Simulator 20XX",
+		Tags:  []string{"pathfinding", "simulation"},
+	})
+	glue.RegisterSolver(2015, 22, glue.IntSolver(solve))
+}
+
+const (
+	playerHP   = 50
+	playerMana = 500
+)
+
+func solve(input []int) ([]string, error) {
+	if len(input) != 2 {
+		return nil, fmt.Errorf("expected boss hit points and damage, got %d numbers", len(input))
+	}
+	start := state{hp: playerHP, mana: playerMana, bossHP: input[0]}
+	p1 := leastMana(start, input[1], false)
+	p2 := leastMana(start, input[1], true)
+	return glue.Ints(p1, p2), nil
+}
+
+type state struct {
+	hp, mana, bossHP         int
+	shield, poison, recharge int // remaining effect timers
+}
+
+type spell struct {
+	cost, damage, heal       int
+	shield, poison, recharge int // effect durations started by the spell
+}
+
+var spells = []spell{
+	{cost: 53, damage: 4},
+	{cost: 73, damage: 2, heal: 2},
+	{cost: 113, shield: 6},
+	{cost: 173, poison: 6},
+	{cost: 229, recharge: 5},
+}
+
+// effects applies the active effects at the start of a turn, and returns the player's armor.
+func (s *state) effects() (armor int) {
+	if s.shield > 0 {
+		armor = 7
+		s.shield--
+	}
+	if s.poison > 0 {
+		s.bossHP -= 3
+		s.poison--
+	}
+	if s.recharge > 0 {
+		s.mana += 101
+		s.recharge--
+	}
+	return armor
+}
+
+// cast casts a spell, if it's possible, and returns the resulting state.
+func (s state) cast(sp spell) (state, bool) {
+	if sp.cost > s.mana || sp.shield > 0 && s.shield > 0 || sp.poison > 0 && s.poison > 0 || sp.recharge > 0 && s.recharge > 0 {
+		return s, false
+	}
+	s.mana -= sp.cost
+	s.bossHP -= sp.damage
+	s.hp += sp.heal
+	s.shield += sp.shield
+	s.poison += sp.poison
+	s.recharge += sp.recharge
+	return s, true
+}
+
+// leastMana returns the least amount of mana the player can spend and still win, or -1 if there's no
+// way to win. The fight is a shortest path search over the states at the start of the player's
+// turns, where the edge weights are the costs of the spells cast. In hard mode, the player loses a
+// hit point at the start of each of their turns.
+func leastMana(start state, bossDamage int, hard bool) int {
+	q := util.NewBucketQ[state](256)
+	q.Push(0, start)
+	seen := map[state]struct{}{}
+	for q.Len() > 0 {
+		spent, s := q.Pop()
+		if s.bossHP <= 0 {
+			return spent
+		}
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		if hard {
+			if s.hp--; s.hp <= 0 {
+				continue
+			}
+		}
+		if s.effects(); s.bossHP <= 0 {
+			return spent
+		}
+		for _, sp := range spells {
+			next, ok := s.cast(sp)
+			if !ok {
+				continue
+			}
+			if next.bossHP > 0 {
+				armor := next.effects()
+				if next.bossHP > 0 {
+					if next.hp -= max(1, bossDamage-armor); next.hp <= 0 {
+						continue
+					}
+				}
+			}
+			q.Push(spent+sp.cost, next)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day22
+
+import "testing"
+
+func TestLeastMana(t *testing.T) {
+	tests := []struct {
+		bossHP int
+		want   int
+	}{
+		{bossHP: 13, want: 173 + 53},
+		{bossHP: 14, want: 229 + 113 + 73 + 173 + 53},
+	}
+	for _, test := range tests {
+		start := state{hp: 10, mana: 250, bossHP: test.bossHP}
+		if got := leastMana(start, 8, false); got != test.want {
+			t.Errorf("leastMana(boss HP %d) = %d, want %d", test.bossHP, got, test.want)
+		}
+	}
+}
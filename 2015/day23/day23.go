@@ -0,0 +1,85 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day23 solves AoC 2015 day 23.
+package day23
+
+import (
+	"strings"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 23, glue.Puzzle{
+		Title: "Opening the Turing Lock",
+		Tags:  []string{"vm"},
+	})
+	glue.RegisterSolver(2015, 23, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	prog, err := isa.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := run(prog, 0)
+	p2 := run(prog, 1)
+	return glue.Ints(p1, p2), nil
+}
+
+// run executes the program with the given initial value of register a, and returns the final value
+// of register b.
+func run(prog *regvm.Prog[struct{}], a int) int {
+	m := regvm.NewMachine(prog, struct{}{})
+	m.Regs[regA] = a
+	m.Run()
+	return m.Regs[regB]
+}
+
+const (
+	regA = iota
+	regB
+)
+
+type vm = regvm.Machine[struct{}]
+
+var isa = &regvm.ISA[struct{}]{
+	Regs: regvm.LetterRegs(2),
+	Ops: []regvm.Op[struct{}]{
+		{Name: "hlf", Args: "r", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])/2) }},
+		{Name: "tpl", Args: "r", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])*3) }},
+		{Name: "inc", Args: "r", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])+1) }},
+		{Name: "jmp", Args: "i", Exec: func(m *vm, a []regvm.Operand) { m.JumpRel(a[0].Val) }},
+		{Name: "jie", Args: "ri", Exec: func(m *vm, a []regvm.Operand) {
+			if m.Get(a[0])%2 == 0 {
+				m.JumpRel(a[1].Val)
+			}
+		}},
+		{Name: "jio", Args: "ri", Exec: func(m *vm, a []regvm.Operand) {
+			if m.Get(a[0]) == 1 {
+				m.JumpRel(a[1].Val)
+			}
+		}},
+	},
+	// the operands are separated by commas, as in "jie a, +4"
+	Syntax: func(line string) (name string, args []string, err error) {
+		fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+		if len(fields) == 0 {
+			return "", nil, nil
+		}
+		return fields[0], fields[1:], nil
+	},
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day23
+
+import "testing"
+
+var ex = []string{
+	"inc b",
+	"jio b, +2",
+	"tpl b",
+	"inc b",
+}
+
+func TestRun(t *testing.T) {
+	prog, err := isa.Parse(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 2
+	if got := run(prog, 0); got != want {
+		t.Errorf("run(ex) = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day24 solves AoC 2015 day 24.
+package day24
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 24, glue.Puzzle{
+		Title: "It Hangs in the Balance",
+		Tags:  []string{"combinatorics"},
+	})
+	glue.RegisterSolver(2015, 24, glue.IntSolver(solve))
+}
+
+func solve(weights []int) ([]string, error) {
+	p1, ok1 := balance(weights, 3)
+	p2, ok2 := balance(weights, 4)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("packages can't be balanced")
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+// balance splits the packages into the given number of groups of equal weight, such that the first
+// group has as few packages as possible, and among those, the smallest quantum entanglement (the
+// product of the weights). It returns that quantum entanglement.
+func balance(weights []int, groups int) (qe int, ok bool) {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total%groups != 0 || len(weights) > 64 {
+		return 0, false
+	}
+	target := total / groups
+	sorted := slices.Clone(weights)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	for size := 1; size <= len(sorted); size++ {
+		type candidate struct {
+			qe   int
+			used uint64
+		}
+		var cands []candidate
+		var pick func(i, left, sum, qe int, used uint64)
+		pick = func(i, left, sum, qe int, used uint64) {
+			if sum == target && left == 0 {
+				cands = append(cands, candidate{qe, used})
+				return
+			}
+			if left == 0 || sum > target {
+				return
+			}
+			for j := i; j < len(sorted); j++ {
+				pick(j+1, left-1, sum+sorted[j], qe*sorted[j], used|1<<j)
+			}
+		}
+		pick(0, size, 0, 1, 0)
+		slices.SortFunc(cands, func(a, b candidate) int { return cmp.Compare(a.qe, b.qe) })
+		for _, c := range cands {
+			if splits(sorted, c.used, groups-1, target) {
+				return c.qe, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// splits checks if the unused packages can be split into the given number of groups of the target
+// weight.
+func splits(weights []int, used uint64, groups, target int) bool {
+	if groups <= 1 {
+		return true // the rest must add up to the target, since the total does
+	}
+	var fill func(i, sum int, used uint64) bool
+	fill = func(i, sum int, used uint64) bool {
+		if sum == target {
+			return splits(weights, used, groups-1, target)
+		}
+		for j := i; j < len(weights); j++ {
+			if used&(1<<j) == 0 && sum+weights[j] <= target && fill(j+1, sum+weights[j], used|1<<j) {
+				return true
+			}
+		}
+		return false
+	}
+	return fill(0, 0, used)
+}
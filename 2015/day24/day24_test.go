@@ -0,0 +1,34 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day24
+
+import "testing"
+
+var ex = []int{1, 2, 3, 4, 5, 7, 8, 9, 10, 11}
+
+func TestBalance(t *testing.T) {
+	tests := []struct {
+		groups int
+		want   int
+	}{
+		{groups: 3, want: 99},
+		{groups: 4, want: 44},
+	}
+	for _, test := range tests {
+		if got, ok := balance(ex, test.groups); !ok || got != test.want {
+			t.Errorf("balance(ex, %d) = (%d, %t), want %d", test.groups, got, ok, test.want)
+		}
+	}
+}
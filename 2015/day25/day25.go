@@ -0,0 +1,59 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day25 solves AoC 2015 day 25.
+package day25
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2015, 25, glue.Puzzle{
+		Title: "Let It Snow",
+		Tags:  []string{"number-theory"},
+	})
+	glue.RegisterSolver(2015, 25, glue.IntSolver(solve))
+}
+
+func solve(input []int) ([]string, error) {
+	if len(input) != 2 {
+		return nil, fmt.Errorf("expected row and column, got %d numbers", len(input))
+	}
+	return glue.Ints(code(input[0], input[1])), nil
+}
+
+const (
+	firstCode = 20151125
+	mul       = 252533
+	mod       = 33554393
+)
+
+// code returns the code at the given (1-based) row and column. The codes are filled in along the
+// diagonals, each one the previous one times mul (modulo mod), so it's enough to know the position
+// of the code in that order, and raise mul to that power.
+func code(row, col int) int {
+	diag := row + col - 1
+	n := diag*(diag-1)/2 + col - 1
+	c, m := firstCode, mul
+	for ; n > 0; n >>= 1 {
+		if n&1 != 0 {
+			c = c * m % mod
+		}
+		m = m * m % mod
+	}
+	return c
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day25
+
+import "testing"
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		row, col int
+		want     int
+	}{
+		{row: 1, col: 1, want: 20151125},
+		{row: 2, col: 1, want: 31916031},
+		{row: 1, col: 2, want: 18749137},
+		{row: 4, col: 3, want: 21345942},
+		{row: 6, col: 6, want: 27995004},
+	}
+	for _, test := range tests {
+		if got := code(test.row, test.col); got != test.want {
+			t.Errorf("code(%d, %d) = %d, want %d", test.row, test.col, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package y2015 contains the glue and tests for all AoC 2015 days.
+package y2015
+
+import (
+	_ "github.com/fis/aoc/2015/day01" // solvers
+	_ "github.com/fis/aoc/2015/day02" // solvers
+	_ "github.com/fis/aoc/2015/day03" // solvers
+	_ "github.com/fis/aoc/2015/day04" // solvers
+	_ "github.com/fis/aoc/2015/day05" // solvers
+	_ "github.com/fis/aoc/2015/day06" // solvers
+	_ "github.com/fis/aoc/2015/day07" // solvers
+	_ "github.com/fis/aoc/2015/day08" // solvers
+	_ "github.com/fis/aoc/2015/day09" // solvers
+	_ "github.com/fis/aoc/2015/day10" // solvers
+	_ "github.com/fis/aoc/2015/day11" // solvers
+	_ "github.com/fis/aoc/2015/day12" // solvers
+	_ "github.com/fis/aoc/2015/day13" // solvers
+	_ "github.com/fis/aoc/2015/day14" // solvers
+	_ "github.com/fis/aoc/2015/day15" // solvers
+	_ "github.com/fis/aoc/2015/day16" // solvers
+	_ "github.com/fis/aoc/2015/day17" // solvers
+	_ "github.com/fis/aoc/2015/day18" // solvers
+	_ "github.com/fis/aoc/2015/day19" // solvers
+	_ "github.com/fis/aoc/2015/day20" // solvers
+	_ "github.com/fis/aoc/2015/day21" // solvers
+	_ "github.com/fis/aoc/2015/day22" // solvers
+	_ "github.com/fis/aoc/2015/day23" // solvers
+	_ "github.com/fis/aoc/2015/day24" // solvers
+	_ "github.com/fis/aoc/2015/day25" // solvers
+)
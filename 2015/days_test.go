@@ -0,0 +1,41 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package y2015
+
+import (
+	"testing"
+
+	"github.com/fis/aoc/glue"
+)
+
+func TestAllDays(t *testing.T) {
+	glue.RunTests(t, "../testdata", 2015)
+}
+
+func TestVariants(t *testing.T) {
+	glue.RunVariantTests(t, "../testdata", 2015)
+}
+
+func TestGenerated(t *testing.T) {
+	glue.RunGeneratedTests(t, 2015)
+}
+
+func TestCatalogue(t *testing.T) {
+	glue.RunCatalogueTests(t, 2015)
+}
+
+func BenchmarkAllDays(b *testing.B) {
+	glue.RunBenchmarks(b, "../testdata", 2015)
+}
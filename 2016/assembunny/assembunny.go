@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package assembunny implements the Easter Bunny's assembly language from AoC 2016.
+//
+// The language is used by days 12, 23 and 25. Day 12 introduces the base instruction set (cpy, inc,
+// dec and jnz), day 23 adds the self-modifying tgl instruction, and day 25 adds out for producing a
+// clock signal.
+//
+// Executing the programs naïvely is slow, as they implement addition and multiplication with nested
+// counting loops. The machine recognizes those loops on the fly, and executes them in one go.
+package assembunny
+
+import (
+	"github.com/fis/aoc/util/regvm"
+)
+
+// Prog is a parsed assembunny program.
+type Prog = regvm.Prog[state]
+
+// Parse parses the text of an assembunny program.
+func Parse(lines []string) (*Prog, error) {
+	return isa.Parse(lines)
+}
+
+// Machine holds the execution state of an assembunny program.
+type Machine struct {
+	*regvm.Machine[state]
+}
+
+// state is the language-specific part of the machine state.
+type state struct {
+	// out is called for each output. If it returns false, the machine halts.
+	out func(v int) bool
+	// loops caches the recognized loop (if any) starting at each instruction. It's reset whenever
+	// the code is modified.
+	loops []loop
+}
+
+// NewMachine returns a machine ready to execute a program, with all the registers set to zero.
+func NewMachine(p *Prog) *Machine {
+	return &Machine{regvm.NewMachine(p, state{})}
+}
+
+// SetOutput sets the function called for each value produced by the out instruction. If the
+// function returns false, the machine halts.
+func (m *Machine) SetOutput(out func(v int) bool) {
+	m.Env.out = out
+}
+
+// Reset restores the machine to its initial state, including undoing any toggled instructions.
+func (m *Machine) Reset() {
+	m.Machine.Reset()
+	m.Env.loops = nil
+}
+
+// Run executes the program until it halts. Recognized arithmetic loops are executed in one go, and
+// do not contribute to the Steps and Cycles counters of the machine.
+func (m *Machine) Run() {
+	for !m.Halted() {
+		if !m.runLoop() {
+			m.Step()
+		}
+	}
+}
+
+// The register indices.
+const (
+	RegA = iota
+	RegB
+	RegC
+	RegD
+)
+
+const (
+	opCpy = iota
+	opInc
+	opDec
+	opJnz
+	opTgl
+	opOut
+)
+
+type vm = regvm.Machine[state]
+
+var isa = &regvm.ISA[state]{
+	Regs: regvm.LetterRegs(4),
+	Ops: []regvm.Op[state]{
+		opCpy: {Name: "cpy", Args: "vv", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[1], m.Get(a[0])) }},
+		opInc: {Name: "inc", Args: "v", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])+1) }},
+		opDec: {Name: "dec", Args: "v", Exec: func(m *vm, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])-1) }},
+		opJnz: {Name: "jnz", Args: "vv", Exec: func(m *vm, a []regvm.Operand) {
+			if m.Get(a[0]) != 0 {
+				m.JumpRel(m.Get(a[1]))
+			}
+		}},
+		opTgl: {Name: "tgl", Args: "v", Exec: execTgl},
+		opOut: {Name: "out", Args: "v", Exec: func(m *vm, a []regvm.Operand) {
+			if m.Env.out != nil && !m.Env.out(m.Get(a[0])) {
+				m.Halt()
+			}
+		}},
+	},
+}
+
+// execTgl implements the toggle instruction. Toggled instructions that make no sense (like a cpy to
+// an immediate) are left as is, as the machine will ignore them when executed.
+func execTgl(m *vm, a []regvm.Operand) {
+	at := m.IP + m.Get(a[0])
+	if at < 0 || at >= len(m.Code) {
+		return
+	}
+	in := &m.Code[at]
+	switch {
+	case in.N == 1 && in.Op == opInc:
+		in.Op = opDec
+	case in.N == 1:
+		in.Op = opInc
+	case in.Op == opJnz:
+		in.Op = opCpy
+	default:
+		in.Op = opJnz
+	}
+	m.Env.loops = nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assembunny
+
+import (
+	"testing"
+)
+
+// factorial is a program with the same structure as the AoC 2016 day 23 inputs. It computes
+// a! + 73*79, using tgl to rewrite its own control flow, and nested loops for multiplication.
+var factorial = []string{
+	"cpy a b",
+	"dec b",
+	"cpy a d",
+	"cpy 0 a",
+	"cpy b c",
+	"inc a",
+	"dec c",
+	"jnz c -2",
+	"dec d",
+	"jnz d -5",
+	"dec b",
+	"cpy b c",
+	"cpy c d",
+	"dec d",
+	"inc c",
+	"jnz d -2",
+	"tgl c",
+	"cpy -16 c",
+	"jnz 1 c",
+	"cpy 73 c",
+	"jnz 79 d",
+	"inc a",
+	"inc d",
+	"jnz d -2",
+	"inc c",
+	"jnz c -5",
+}
+
+func TestLoops(t *testing.T) {
+	prog, err := Parse(factorial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		a, want int
+		slow    bool
+	}{
+		{a: 6, want: 720 + 73*79, slow: true},
+		{a: 7, want: 5040 + 73*79, slow: true},
+		{a: 12, want: 479001600 + 73*79},
+	}
+	for _, test := range tests {
+		m := NewMachine(prog)
+		m.Regs[RegA] = test.a
+		m.Run()
+		if got := m.Regs[RegA]; got != test.want {
+			t.Errorf("Run(a=%d) = %d, want %d", test.a, got, test.want)
+		}
+		if !test.slow {
+			continue
+		}
+		m.Reset()
+		m.Regs[RegA] = test.a
+		m.Machine.Run() // without loop recognition
+		if got := m.Regs[RegA]; got != test.want {
+			t.Errorf("slow Run(a=%d) = %d, want %d", test.a, got, test.want)
+		}
+	}
+}
+
+func TestOut(t *testing.T) {
+	prog, err := Parse([]string{"cpy 3 a", "out a", "dec a", "jnz a -2", "out 9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	m := NewMachine(prog)
+	m.SetOutput(func(v int) bool {
+		got = append(got, v)
+		return len(got) < 3
+	})
+	m.Run()
+	if len(got) != 3 || got[0] != 3 || got[1] != 2 || got[2] != 1 || m.IP != 2 {
+		t.Errorf("out = %v, IP = %d, want [3 2 1], 2", got, m.IP)
+	}
+}
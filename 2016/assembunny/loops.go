@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assembunny
+
+import (
+	"github.com/fis/aoc/util/regvm"
+)
+
+// loop describes a recognized arithmetic loop.
+type loop struct {
+	kind loopKind
+	// The loop is equivalent to: dst += sign * src * iters(counter); counter = 0; tmp = 0.
+	// For plain addition loops, src is the immediate 1 and there's no tmp register.
+	dst, counter, tmp int
+	src               regvm.Operand
+	sign              int
+	counterStep       int
+	innerStep         int
+	size              int
+}
+
+type loopKind uint8
+
+const (
+	loopUnknown loopKind = iota
+	loopNone
+	loopAdd
+	loopMul
+)
+
+// runLoop executes the recognized loop starting at the current instruction, if there is one.
+// It returns false if there was no loop (or it could not be executed in one go).
+func (m *Machine) runLoop() bool {
+	if m.Env.loops == nil {
+		m.Env.loops = make([]loop, len(m.Code))
+	}
+	l := &m.Env.loops[m.IP]
+	if l.kind == loopUnknown {
+		*l = m.findLoop(m.IP)
+	}
+	if l.kind == loopNone {
+		return false
+	}
+	iters := m.Regs[l.counter] * -l.counterStep
+	if iters <= 0 {
+		return false // wouldn't terminate, or isn't actually a loop at all
+	}
+	src := m.Get(l.src)
+	if l.kind == loopMul && src*-l.innerStep <= 0 {
+		return false // the inner loop wouldn't terminate
+	}
+	m.Regs[l.dst] += l.sign * src * iters
+	m.Regs[l.counter] = 0
+	if l.kind == loopMul {
+		m.Regs[l.tmp] = 0
+	}
+	m.IP += l.size
+	return true
+}
+
+// findLoop looks for one of the following loop shapes at the given address:
+//
+//	inc/dec dst        cpy src tmp
+//	inc/dec counter    inc/dec dst
+//	jnz counter -2     inc/dec tmp
+//	                   jnz tmp -2
+//	                   inc/dec counter
+//	                   jnz counter -5
+//
+// The increment and decrement instructions of an inner loop can be in either order.
+func (m *Machine) findLoop(at int) loop {
+	if l, ok := m.matchAdd(at); ok {
+		return l
+	}
+	code := m.Code
+	if at+6 > len(code) {
+		return loop{kind: loopNone}
+	}
+	cpy := code[at]
+	if cpy.Op != opCpy || !cpy.Args[1].Reg {
+		return loop{kind: loopNone}
+	}
+	inner, ok := m.matchAdd(at + 1)
+	if !ok || inner.counter != cpy.Args[1].Val {
+		return loop{kind: loopNone}
+	}
+	step, jnz := code[at+4], code[at+5]
+	counter, ok := matchStep(step)
+	if !ok || !isJump(jnz, counter, -5) || !distinct(counter, inner.dst, inner.counter) {
+		return loop{kind: loopNone}
+	}
+	if src := cpy.Args[0]; src.Reg && (src.Val == counter || src.Val == inner.dst || src.Val == inner.counter) {
+		return loop{kind: loopNone}
+	}
+	return loop{
+		kind: loopMul, dst: inner.dst, counter: counter, tmp: inner.counter,
+		src: cpy.Args[0], sign: inner.sign * -inner.counterStep,
+		counterStep: stepOf(step), innerStep: inner.counterStep, size: 6,
+	}
+}
+
+func (m *Machine) matchAdd(at int) (loop, bool) {
+	code := m.Code
+	if at+3 > len(code) {
+		return loop{}, false
+	}
+	for _, order := range [][2]int{{0, 1}, {1, 0}} {
+		dstStep, counterStep, jnz := code[at+order[0]], code[at+order[1]], code[at+2]
+		dst, ok1 := matchStep(dstStep)
+		counter, ok2 := matchStep(counterStep)
+		if ok1 && ok2 && dst != counter && isJump(jnz, counter, -2) {
+			return loop{
+				kind: loopAdd, dst: dst, counter: counter,
+				src: regvm.I(1), sign: stepOf(dstStep), counterStep: stepOf(counterStep), size: 3,
+			}, true
+		}
+	}
+	return loop{}, false
+}
+
+// matchStep checks if an instruction is an increment or decrement of a register.
+func matchStep(in regvm.Inst) (reg int, ok bool) {
+	if (in.Op == opInc || in.Op == opDec) && in.Args[0].Reg {
+		return in.Args[0].Val, true
+	}
+	return 0, false
+}
+
+func stepOf(in regvm.Inst) int {
+	if in.Op == opInc {
+		return 1
+	}
+	return -1
+}
+
+// isJump checks if an instruction is a conditional jump on a register by a fixed offset.
+func isJump(in regvm.Inst, reg, offset int) bool {
+	return in.Op == opJnz && in.Args[0] == regvm.R(reg) && in.Args[1] == regvm.I(offset)
+}
+
+func distinct(a, b, c int) bool {
+	return a != b && a != c && b != c
+}
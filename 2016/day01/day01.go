@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 1, glue.Puzzle{
+		Title: "No Time for a Taxicab",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2016, 1, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 2, glue.Puzzle{
+		Title: "Bathroom Security",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2016, 2, glue.LineSolver(solve))
 }
 
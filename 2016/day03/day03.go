@@ -18,6 +18,10 @@ package day03
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2016, 3, glue.Puzzle{
+		Title: "Squares With Three Sides",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2016, 3, glue.IntSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 4, glue.Puzzle{
+		Title: "Security Through Obscurity",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2016, 4, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 5, glue.Puzzle{
+		Title: "How About a Nice Game of Chess?",
+		Tags:  []string{"hashing"},
+	})
 	glue.RegisterSolver(2016, 5, glue.LineSolver(solve))
 }
 
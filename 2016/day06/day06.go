@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 6, glue.Puzzle{
+		Title: "Signals and Noise",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2016, 6, glue.LineSolver(solve))
 }
 
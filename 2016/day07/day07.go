@@ -18,6 +18,10 @@ package day07
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2016, 7, glue.Puzzle{
+		Title: "Internet Protocol Version 7",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2016, 7, glue.LineSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 8, glue.Puzzle{
+		Title: "Two-Factor Authentication",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2016, 8, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
 }
 
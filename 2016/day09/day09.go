@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 9, glue.Puzzle{
+		Title: "Explosives in Cyberspace",
+		Tags:  []string{"strings", "parsing"},
+	})
 	glue.RegisterSolver(2016, 9, glue.LineSolver(solve))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 10, glue.Puzzle{
+		Title: "Balance Bots",
+		Tags:  []string{"graph", "simulation"},
+	})
 	glue.RegisterSolver(2016, 10, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
 	glue.RegisterPlotter(2016, 10, "", plotter{}, map[string]string{"ex": ex})
 }
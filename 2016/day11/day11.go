@@ -0,0 +1,199 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day11 solves AoC 2016 day 11.
+package day11
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 11, glue.Puzzle{
+		Title: "Radioisotope Thermoelectric Generators",
+		Tags:  []string{"pathfinding", "combinatorics"},
+	})
+	glue.RegisterSolver(2016, 11, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	start, err := parseFacility(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1, ok := shortest(start)
+	if !ok {
+		return nil, fmt.Errorf("no way to bring everything to the top floor")
+	}
+	// part 2 adds the elerium and dilithium generators and microchips on the first floor
+	p2, ok := shortest(append(start, pair{}, pair{}))
+	if !ok {
+		return nil, fmt.Errorf("no way to bring everything to the top floor with the extra parts")
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+const floors = 4
+
+// pair holds the floors of a generator and its matching microchip.
+type pair struct {
+	gen, chip int
+}
+
+// state is a canonical encoding of the facility: the elevator floor, and the floors of all the
+// pairs, in sorted order. The pairs are interchangeable, so states that only differ in which element
+// is where are equivalent, and encoded the same way.
+type state uint64
+
+func encode(elevator int, pairs []pair) state {
+	sorted := slices.Clone(pairs)
+	slices.SortFunc(sorted, func(a, b pair) int {
+		if a.gen != b.gen {
+			return a.gen - b.gen
+		}
+		return a.chip - b.chip
+	})
+	s := state(elevator)
+	for _, p := range sorted {
+		s = s<<4 | state(p.gen<<2|p.chip)
+	}
+	return s
+}
+
+func (s state) decode(n int) (elevator int, pairs []pair) {
+	pairs = make([]pair, n)
+	for i := n - 1; i >= 0; i-- {
+		pairs[i] = pair{gen: int(s>>2) & 3, chip: int(s) & 3}
+		s >>= 4
+	}
+	return int(s), pairs
+}
+
+// safe checks that no microchip is on the same floor as another generator, without its own
+// generator to protect it.
+func safe(pairs []pair) bool {
+	var gens [floors]bool
+	for _, p := range pairs {
+		gens[p.gen] = true
+	}
+	for _, p := range pairs {
+		if p.chip != p.gen && gens[p.chip] {
+			return false
+		}
+	}
+	return true
+}
+
+// shortest returns the number of elevator trips to bring everything to the top floor, by a
+// breadth-first search over the canonical states. If it can't be done, it returns false.
+func shortest(pairs []pair) (int, bool) {
+	n := len(pairs)
+	done := make([]pair, n)
+	for i := range done {
+		done[i] = pair{gen: floors - 1, chip: floors - 1}
+	}
+	goal := encode(floors-1, done)
+	start := encode(0, pairs)
+	seen := map[state]struct{}{start: {}}
+	q := []state{start}
+	for steps := 0; len(q) > 0; steps++ {
+		var next []state
+		for _, s := range q {
+			if s == goal {
+				return steps, true
+			}
+			elevator, pairs := s.decode(n)
+			// the items on the elevator floor: even indices are generators, odd ones microchips
+			var here []int
+			for i, p := range pairs {
+				if p.gen == elevator {
+					here = append(here, 2*i)
+				}
+				if p.chip == elevator {
+					here = append(here, 2*i+1)
+				}
+			}
+			lowest := floors
+			for _, p := range pairs {
+				lowest = min(lowest, p.gen, p.chip)
+			}
+			for _, to := range [2]int{elevator + 1, elevator - 1} {
+				if to < 0 || to >= floors || to < lowest {
+					continue
+				}
+				for i, a := range here {
+					for _, b := range here[i:] {
+						moved := slices.Clone(pairs)
+						move(moved, a, to)
+						move(moved, b, to)
+						if !safe(moved) {
+							continue
+						}
+						ns := encode(to, moved)
+						if _, ok := seen[ns]; !ok {
+							seen[ns] = struct{}{}
+							next = append(next, ns)
+						}
+					}
+				}
+			}
+		}
+		q = next
+	}
+	return 0, false
+}
+
+func move(pairs []pair, item, to int) {
+	if item%2 == 0 {
+		pairs[item/2].gen = to
+	} else {
+		pairs[item/2].chip = to
+	}
+}
+
+var (
+	reGen  = regexp.MustCompile(`(\w+) generator`)
+	reChip = regexp.MustCompile(`(\w+)-compatible microchip`)
+)
+
+func parseFacility(lines []string) ([]pair, error) {
+	if len(lines) != floors {
+		return nil, fmt.Errorf("expected %d floors, got %d", floors, len(lines))
+	}
+	gens, chips := map[string]int{}, map[string]int{}
+	for floor, line := range lines {
+		for _, m := range reGen.FindAllStringSubmatch(line, -1) {
+			gens[m[1]] = floor
+		}
+		for _, m := range reChip.FindAllStringSubmatch(line, -1) {
+			chips[m[1]] = floor
+		}
+	}
+	var pairs []pair
+	for elem, gen := range gens {
+		chip, ok := chips[elem]
+		if !ok {
+			return nil, fmt.Errorf("no microchip for %s generator", elem)
+		}
+		pairs = append(pairs, pair{gen: gen, chip: chip})
+	}
+	if len(pairs) != len(chips) {
+		return nil, fmt.Errorf("microchips without generators")
+	}
+	return pairs, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day11
+
+import "testing"
+
+var ex = []string{
+	"The first floor contains a hydrogen-compatible microchip and a lithium-compatible microchip.",
+	"The second floor contains a hydrogen generator.",
+	"The third floor contains a lithium generator.",
+	"The fourth floor contains nothing relevant.",
+}
+
+func TestShortest(t *testing.T) {
+	pairs, err := parseFacility(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 11
+	if got, ok := shortest(pairs); !ok || got != want {
+		t.Errorf("shortest(ex) = %d, %t, want %d, true", got, ok, want)
+	}
+}
@@ -17,7 +17,7 @@ package day12
 import (
 	"testing"
 
-	"github.com/fis/aoc/util/fn"
+	"github.com/fis/aoc/2016/assembunny"
 )
 
 func TestRunProgram(t *testing.T) {
@@ -29,7 +29,7 @@ func TestRunProgram(t *testing.T) {
 		"jnz a 2",
 		"dec a",
 	}
-	prog, err := fn.MapE(ex, parseInst)
+	prog, err := assembunny.Parse(ex)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 13, glue.Puzzle{
+		Title: "A Maze of Twisty Little Cubicles",
+		Tags:  []string{"grid", "pathfinding", "bits"},
+	})
 	glue.RegisterSolver(2016, 13, glue.IntSolver(solve))
 }
 
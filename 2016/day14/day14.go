@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2016, 14, glue.Puzzle{
+		Title: "One-Time Pad",
+		Tags:  []string{"hashing"},
+	})
 	glue.RegisterSolver(2016, 14, glue.LineSolver(solve))
 }
 
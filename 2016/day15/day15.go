@@ -0,0 +1,64 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day15 solves AoC 2016 day 15.
+package day15
+
+import (
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/ix"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 15, glue.Puzzle{
+		Title: "Timing is Everything",
+		Tags:  []string{"number-theory"},
+	})
+	glue.RegisterSolver(2016, 15, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
+}
+
+const inputRegexp = `^Disc #(\d+) has (\d+) positions; at time=0, it is at position (\d+)\.$`
+
+func solve(lines [][]string) ([]string, error) {
+	discs := make([]disc, len(lines))
+	for i, line := range lines {
+		discs[i].depth, _ = strconv.Atoi(line[0])
+		discs[i].size, _ = strconv.Atoi(line[1])
+		discs[i].pos, _ = strconv.Atoi(line[2])
+	}
+	p1 := firstTime(discs)
+	p2 := firstTime(append(discs, disc{depth: len(discs) + 1, size: 11, pos: 0}))
+	return glue.Ints(p1, p2), nil
+}
+
+type disc struct {
+	depth, size, pos int
+}
+
+// firstTime returns the first time the button can be pressed to get a capsule through all the discs.
+// A disc at depth d is reached at t+d, so it must satisfy t ≡ -(pos+d) (mod size). The constraints
+// are combined one at a time by sieving: once t satisfies the first k discs, stepping it by the
+// least common multiple of their sizes keeps them satisfied.
+func firstTime(discs []disc) int {
+	t, step := 0, 1
+	for _, d := range discs {
+		for (t+d.depth+d.pos)%d.size != 0 {
+			t += step
+		}
+		step = ix.LCM(step, d.size)
+	}
+	return t
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day15
+
+import "testing"
+
+var ex = []disc{
+	{depth: 1, size: 5, pos: 4},
+	{depth: 2, size: 2, pos: 1},
+}
+
+func TestFirstTime(t *testing.T) {
+	want := 5
+	if got := firstTime(ex); got != want {
+		t.Errorf("firstTime(ex) = %d, want %d", got, want)
+	}
+}
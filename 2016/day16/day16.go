@@ -0,0 +1,68 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day16 solves AoC 2016 day 16.
+package day16
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 16, glue.Puzzle{
+		Title: "Dragon Checksum",
+		Tags:  []string{"bits", "strings"},
+	})
+	glue.RegisterSolver(2016, 16, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	p1 := checksum(fill(lines[0], 272))
+	p2 := checksum(fill(lines[0], 35651584))
+	return []string{p1, p2}, nil
+}
+
+// fill generates data with the modified dragon curve until there's enough for the disk.
+func fill(initial string, size int) []byte {
+	data := make([]byte, len(initial), 2*size+1)
+	copy(data, initial)
+	for len(data) < size {
+		n := len(data)
+		data = append(data, '0')
+		for i := n - 1; i >= 0; i-- {
+			data = append(data, '0'+'1'-data[i])
+		}
+	}
+	return data[:size]
+}
+
+// checksum reduces pairs of bits until an odd length is reached. The data buffer is reused.
+func checksum(data []byte) string {
+	for len(data)%2 == 0 {
+		for i := 0; i < len(data)/2; i++ {
+			if data[2*i] == data[2*i+1] {
+				data[i] = '1'
+			} else {
+				data[i] = '0'
+			}
+		}
+		data = data[:len(data)/2]
+	}
+	return string(data)
+}
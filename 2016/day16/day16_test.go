@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day16
+
+import "testing"
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		initial, want string
+	}{
+		{initial: "1", want: "100"},
+		{initial: "0", want: "001"},
+		{initial: "11111", want: "11111000000"},
+		{initial: "111100001010", want: "1111000010100101011110000"},
+	}
+	for _, test := range tests {
+		size := 2*len(test.initial) + 1
+		if got := string(fill(test.initial, size)); got != test.want {
+			t.Errorf("fill(%s, %d) = %s, want %s", test.initial, size, got, test.want)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		data, want string
+	}{
+		{data: "110010110100", want: "100"},
+		{data: string(fill("10000", 20)), want: "01100"},
+	}
+	for _, test := range tests {
+		if got := checksum([]byte(test.data)); got != test.want {
+			t.Errorf("checksum(%s) = %s, want %s", test.data, got, test.want)
+		}
+	}
+}
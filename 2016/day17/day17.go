@@ -0,0 +1,87 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day17 solves AoC 2016 day 17.
+package day17
+
+import (
+	"crypto/md5"
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 17, glue.Puzzle{
+		Title: "Two Steps Forward",
+		Tags:  []string{"hashing", "pathfinding"},
+	})
+	glue.RegisterSolver(2016, 17, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	shortest, longest := paths(lines[0])
+	return []string{shortest, fmt.Sprint(len(longest))}, nil
+}
+
+const size = 4
+
+var dirs = [4]struct {
+	name byte
+	d    util.P
+}{
+	{'U', util.P{0, -1}},
+	{'D', util.P{0, 1}},
+	{'L', util.P{-1, 0}},
+	{'R', util.P{1, 0}},
+}
+
+// paths explores all the paths through the vault, and returns the shortest and longest ones that
+// reach the bottom right corner. The doors depend on the path taken so far, so each path is a
+// distinct state, but the maze is small enough to go through them all.
+func paths(passcode string) (shortest, longest string) {
+	type state struct {
+		at   util.P
+		path []byte
+	}
+	found := false
+	q := []state{{at: util.P{0, 0}, path: []byte(passcode)}}
+	for len(q) > 0 {
+		s := q[0]
+		q = q[1:]
+		if s.at == (util.P{size - 1, size - 1}) {
+			path := string(s.path[len(passcode):])
+			if !found {
+				shortest, found = path, true
+			}
+			longest = path // BFS finds them in order of length
+			continue
+		}
+		hash := md5.Sum(s.path)
+		for i, dir := range dirs {
+			nibble := hash[i/2] >> (4 * (1 - i%2)) & 0xf
+			next := s.at.Add(dir.d)
+			if nibble <= 0xa || next.X < 0 || next.X >= size || next.Y < 0 || next.Y >= size {
+				continue
+			}
+			path := append(s.path[:len(s.path):len(s.path)], dir.name)
+			q = append(q, state{at: next, path: path})
+		}
+	}
+	return shortest, longest
+}
@@ -0,0 +1,34 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day17
+
+import "testing"
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		passcode string
+		shortest string
+		longest  int
+	}{
+		{passcode: "ihgpwlah", shortest: "DDRRRD", longest: 370},
+		{passcode: "kglvqrro", shortest: "DDUDRLRRUDRD", longest: 492},
+		{passcode: "ulqzkmiv", shortest: "DRURDRUDDLLDLUURRDULRLDUUDDDRR", longest: 830},
+	}
+	for _, test := range tests {
+		if shortest, longest := paths(test.passcode); shortest != test.shortest || len(longest) != test.longest {
+			t.Errorf("paths(%s) = (%s, %d), want (%s, %d)", test.passcode, shortest, len(longest), test.shortest, test.longest)
+		}
+	}
+}
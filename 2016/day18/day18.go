@@ -0,0 +1,61 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day18 solves AoC 2016 day 18.
+package day18
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 18, glue.Puzzle{
+		Title: "Like a Rogue",
+		Tags:  []string{"automaton"},
+	})
+	glue.RegisterSolver(2016, 18, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	if len(lines) != 1 {
+		return nil, fmt.Errorf("expected 1 line, got %d", len(lines))
+	}
+	p1 := safeTiles(lines[0], 40)
+	p2 := safeTiles(lines[0], 400000)
+	return glue.Ints(p1, p2), nil
+}
+
+// safeTiles counts the safe tiles in the given number of rows, starting from the first row. All
+// four trap rules boil down to the left and right neighbors in the previous row being different.
+func safeTiles(first string, rows int) (safe int) {
+	row, next := []byte(first), make([]byte, len(first))
+	for ; rows > 0; rows-- {
+		for i, c := range row {
+			if c == '.' {
+				safe++
+			}
+			left := i > 0 && row[i-1] == '^'
+			right := i+1 < len(row) && row[i+1] == '^'
+			if left != right {
+				next[i] = '^'
+			} else {
+				next[i] = '.'
+			}
+		}
+		row, next = next, row
+	}
+	return safe
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day18
+
+import "testing"
+
+func TestSafeTiles(t *testing.T) {
+	tests := []struct {
+		first string
+		rows  int
+		want  int
+	}{
+		{first: "..^^.", rows: 3, want: 6},
+		{first: ".^^.^.^^^^", rows: 10, want: 38},
+	}
+	for _, test := range tests {
+		if got := safeTiles(test.first, test.rows); got != test.want {
+			t.Errorf("safeTiles(%s, %d) = %d, want %d", test.first, test.rows, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day19 solves AoC 2016 day 19.
+package day19
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 19, glue.Puzzle{
+		Title: "An Elephant Named Joseph",
+		Tags:  []string{"number-theory"},
+	})
+	glue.RegisterSolver(2016, 19, glue.IntSolver(solve))
+}
+
+func solve(nums []int) ([]string, error) {
+	if len(nums) != 1 || nums[0] < 1 {
+		return nil, fmt.Errorf("expected 1 positive number, got %v", nums)
+	}
+	p1 := stealLeft(nums[0])
+	p2 := stealAcross(nums[0])
+	return glue.Ints(p1, p2), nil
+}
+
+// stealLeft returns the winning elf when each elf steals from the one to their left. This is the
+// Josephus problem with every second person eliminated: writing n = 2^k + m, the winner is 2m+1.
+func stealLeft(n int) int {
+	p := 1
+	for p*2 <= n {
+		p *= 2
+	}
+	return 2*(n-p) + 1
+}
+
+// stealAcross returns the winning elf when each elf steals from the one directly across the circle.
+// The circle is kept in two queues, starting from the current elf: the left half, and the right half
+// that starts with the elf across. That makes each steal a constant time operation.
+func stealAcross(n int) int {
+	var left, right []int
+	for i := 1; i <= n; i++ {
+		if i <= n/2 {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+	for total := n; total > 1; total-- {
+		right = right[1:]
+		right = append(right, left[0])
+		left = left[1:]
+		if len(left) < (total-1)/2 {
+			left = append(left, right[0])
+			right = right[1:]
+		}
+	}
+	return right[0]
+}
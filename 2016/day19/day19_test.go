@@ -0,0 +1,37 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day19
+
+import "testing"
+
+func TestSteal(t *testing.T) {
+	tests := []struct {
+		n           int
+		left, cross int
+	}{
+		{n: 1, left: 1, cross: 1},
+		{n: 2, left: 1, cross: 1},
+		{n: 5, left: 3, cross: 2},
+		{n: 6, left: 5, cross: 3},
+	}
+	for _, test := range tests {
+		if got := stealLeft(test.n); got != test.left {
+			t.Errorf("stealLeft(%d) = %d, want %d", test.n, got, test.left)
+		}
+		if got := stealAcross(test.n); got != test.cross {
+			t.Errorf("stealAcross(%d) = %d, want %d", test.n, got, test.cross)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day20 solves AoC 2016 day 20.
+package day20
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 20, glue.Puzzle{
+		Title: "Firewall Rules",
+		Tags:  []string{"geometry"},
+	})
+	glue.RegisterSolver(2016, 20, glue.RegexpSolver{Solver: solve, Regexp: `^(\d+)-(\d+)$`})
+}
+
+const maxIP = 4294967295
+
+func solve(lines [][]string) ([]string, error) {
+	blocked := make([]ipRange, len(lines))
+	for i, line := range lines {
+		blocked[i].lo, _ = strconv.Atoi(line[0])
+		blocked[i].hi, _ = strconv.Atoi(line[1])
+	}
+	lowest, count := allowed(blocked, maxIP)
+	return glue.Ints(lowest, count), nil
+}
+
+type ipRange struct {
+	lo, hi int
+}
+
+// allowed returns the lowest IP not in any of the blocked ranges, and the number of such IPs up to
+// (and including) last. It sorts the ranges, and sweeps through them looking for gaps.
+func allowed(blocked []ipRange, last int) (lowest, count int) {
+	sorted := slices.Clone(blocked)
+	slices.SortFunc(sorted, func(a, b ipRange) int { return cmp.Compare(a.lo, b.lo) })
+	lowest, next := -1, 0 // next is the first IP not yet known to be blocked
+	for _, r := range sorted {
+		if r.lo > next {
+			if lowest < 0 {
+				lowest = next
+			}
+			count += r.lo - next
+		}
+		next = max(next, r.hi+1)
+	}
+	if next <= last {
+		if lowest < 0 {
+			lowest = next
+		}
+		count += last - next + 1
+	}
+	return lowest, count
+}
@@ -0,0 +1,26 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day20
+
+import "testing"
+
+var ex = []ipRange{{5, 8}, {0, 2}, {4, 7}}
+
+func TestAllowed(t *testing.T) {
+	wantLowest, wantCount := 3, 2
+	if lowest, count := allowed(ex, 9); lowest != wantLowest || count != wantCount {
+		t.Errorf("allowed(ex, 9) = (%d, %d), want (%d, %d)", lowest, count, wantLowest, wantCount)
+	}
+}
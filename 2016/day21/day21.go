@@ -0,0 +1,183 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day21 solves AoC 2016 day 21.
+package day21
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 21, glue.Puzzle{
+		Title: "Scrambled Letters and Hash",
+		Tags:  []string{"strings"},
+	})
+	glue.RegisterSolver(2016, 21, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	ops, err := parseOps(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := scramble("abcdefgh", ops)
+	p2 := unscramble("fbgdceah", ops)
+	return []string{p1, p2}, nil
+}
+
+type opKind int
+
+const (
+	swapPos opKind = iota
+	swapLetter
+	rotateLeft
+	rotateRight
+	rotateLetter
+	reverse
+	move
+)
+
+type op struct {
+	kind opKind
+	x, y int  // positions or step counts
+	a, b byte // letters
+}
+
+// scramble applies the operations to a password, in order.
+func scramble(password string, ops []op) string {
+	s := []byte(password)
+	for _, o := range ops {
+		o.apply(s)
+	}
+	return string(s)
+}
+
+// unscramble finds the password that scrambles to the given one, by undoing the operations in
+// reverse order.
+func unscramble(scrambled string, ops []op) string {
+	s := []byte(scrambled)
+	for i := len(ops) - 1; i >= 0; i-- {
+		ops[i].undo(s)
+	}
+	return string(s)
+}
+
+func (o op) apply(s []byte) {
+	switch o.kind {
+	case swapPos:
+		s[o.x], s[o.y] = s[o.y], s[o.x]
+	case swapLetter:
+		i, j := slices.Index(s, o.a), slices.Index(s, o.b)
+		s[i], s[j] = s[j], s[i]
+	case rotateLeft:
+		rotate(s, o.x)
+	case rotateRight:
+		rotate(s, -o.x)
+	case rotateLetter:
+		i := slices.Index(s, o.a)
+		n := 1 + i
+		if i >= 4 {
+			n++
+		}
+		rotate(s, -n)
+	case reverse:
+		slices.Reverse(s[o.x : o.y+1])
+	case move:
+		c := s[o.x]
+		copy(s[o.x:], s[o.x+1:])
+		copy(s[o.y+1:], s[o.y:len(s)-1])
+		s[o.y] = c
+	}
+}
+
+func (o op) undo(s []byte) {
+	switch o.kind {
+	case swapPos, swapLetter, reverse:
+		o.apply(s)
+	case rotateLeft:
+		rotate(s, -o.x)
+	case rotateRight:
+		rotate(s, o.x)
+	case rotateLetter:
+		// The rotation depends on where the letter was before, so just try all the starting points
+		// to find the one that leads to the current state.
+		t := make([]byte, len(s))
+		for n := 0; n < len(s); n++ {
+			copy(t, s)
+			rotate(t, n)
+			o.apply(t)
+			if string(t) == string(s) {
+				rotate(s, n)
+				return
+			}
+		}
+		panic(fmt.Sprintf("can't undo rotation based on %c in %s", o.a, s))
+	case move:
+		op{kind: move, x: o.y, y: o.x}.apply(s)
+	}
+}
+
+// rotate rotates the bytes left by n steps; negative n rotates right.
+func rotate(s []byte, n int) {
+	n = (n%len(s) + len(s)) % len(s)
+	slices.Reverse(s[:n])
+	slices.Reverse(s[n:])
+	slices.Reverse(s)
+}
+
+func parseOps(lines []string) ([]op, error) {
+	ops := make([]op, len(lines))
+	for i, line := range lines {
+		o := &ops[i]
+		var (
+			dir     string
+			n, want = 0, 2
+			err     error
+		)
+		switch {
+		case strings.HasPrefix(line, "swap position"):
+			o.kind = swapPos
+			n, err = fmt.Sscanf(line, "swap position %d with position %d", &o.x, &o.y)
+		case strings.HasPrefix(line, "swap letter"):
+			o.kind = swapLetter
+			n, err = fmt.Sscanf(line, "swap letter %c with letter %c", &o.a, &o.b)
+		case strings.HasPrefix(line, "rotate based"):
+			o.kind = rotateLetter
+			n, err = fmt.Sscanf(line, "rotate based on position of letter %c", &o.a)
+			want = 1
+		case strings.HasPrefix(line, "rotate"):
+			n, err = fmt.Sscanf(line, "rotate %s %d", &dir, &o.x)
+			if dir == "left" {
+				o.kind = rotateLeft
+			} else {
+				o.kind = rotateRight
+			}
+		case strings.HasPrefix(line, "reverse"):
+			o.kind = reverse
+			n, err = fmt.Sscanf(line, "reverse positions %d through %d", &o.x, &o.y)
+		case strings.HasPrefix(line, "move"):
+			o.kind = move
+			n, err = fmt.Sscanf(line, "move position %d to position %d", &o.x, &o.y)
+		}
+		if err != nil || n != want {
+			return nil, fmt.Errorf("invalid operation: %s", line)
+		}
+	}
+	return ops, nil
+}
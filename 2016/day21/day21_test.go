@@ -0,0 +1,51 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day21
+
+import "testing"
+
+var ex = []string{
+	"swap position 4 with position 0",
+	"swap letter d with letter b",
+	"reverse positions 0 through 4",
+	"rotate left 1 step",
+	"move position 1 to position 4",
+	"move position 3 to position 0",
+	"rotate based on position of letter b",
+	"rotate based on position of letter d",
+}
+
+func TestScramble(t *testing.T) {
+	ops, err := parseOps(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "decab"
+	if got := scramble("abcde", ops); got != want {
+		t.Errorf("scramble(abcde) = %s, want %s", got, want)
+	}
+}
+
+func TestUnscramble(t *testing.T) {
+	ops, err := parseOps(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, password := range []string{"abcdefgh", "hgfedcba", "cabhgdef"} {
+		if got := unscramble(scramble(password, ops), ops); got != password {
+			t.Errorf("unscramble(scramble(%s)) = %s", password, got)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day22 solves AoC 2016 day 22.
+package day22
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 22, glue.Puzzle{
+		Title:         "Grid Computing",
+		Tags:          []string{"grid", "pathfinding"},
+		InputSpecific: true,
+	})
+	glue.RegisterSolver(2016, 22, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	g, err := parseGrid(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := g.viablePairs()
+	p2, err := g.fewestSteps()
+	if err != nil {
+		return nil, err
+	}
+	return glue.Ints(p1, p2), nil
+}
+
+type node struct {
+	size, used int
+}
+
+type grid struct {
+	w, h  int
+	nodes []node // row-major
+}
+
+// viablePairs counts the pairs of nodes (A, B) where all the data of a non-empty A fits on B.
+func (g *grid) viablePairs() (count int) {
+	for i, a := range g.nodes {
+		if a.used == 0 {
+			continue
+		}
+		for j, b := range g.nodes {
+			if i != j && a.used <= b.size-b.used {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// fewestSteps returns the number of moves to get the data from the top right node to the top left
+// one. In practice the only possible moves are into the single empty node, and so the puzzle is a
+// sliding tile game, where some of the nodes are too full to ever move. The state of the game is
+// just the positions of the goal data and the empty node, so it can be searched exhaustively.
+func (g *grid) fewestSteps() (int, error) {
+	empty := -1
+	for i, n := range g.nodes {
+		if n.used == 0 {
+			if empty >= 0 {
+				return 0, fmt.Errorf("more than one empty node")
+			}
+			empty = i
+		}
+	}
+	if empty < 0 {
+		return 0, fmt.Errorf("no empty node")
+	}
+	wall := make([]bool, len(g.nodes))
+	for i, n := range g.nodes {
+		wall[i] = n.used > g.nodes[empty].size
+	}
+
+	N := len(g.nodes)
+	type state struct{ goal, empty int }
+	start := state{goal: g.w - 1, empty: empty}
+	seen := make([]bool, N*N)
+	seen[start.goal*N+start.empty] = true
+	q := []state{start}
+	for steps := 0; len(q) > 0; steps++ {
+		var next []state
+		for _, s := range q {
+			if s.goal == 0 {
+				return steps, nil
+			}
+			at := util.P{s.empty % g.w, s.empty / g.w}
+			for _, n := range at.Neigh() {
+				if n.X < 0 || n.X >= g.w || n.Y < 0 || n.Y >= g.h {
+					continue
+				}
+				ni := n.Y*g.w + n.X
+				if wall[ni] {
+					continue
+				}
+				ns := state{goal: s.goal, empty: ni}
+				if ni == s.goal {
+					ns.goal = s.empty
+				}
+				if !seen[ns.goal*N+ns.empty] {
+					seen[ns.goal*N+ns.empty] = true
+					next = append(next, ns)
+				}
+			}
+		}
+		q = next
+	}
+	return 0, fmt.Errorf("goal data can't be moved")
+}
+
+var reNode = regexp.MustCompile(`^/dev/grid/node-x(\d+)-y(\d+)\s+(\d+)T\s+(\d+)T\s+(\d+)T\s+(\d+)%$`)
+
+func parseGrid(lines []string) (*grid, error) {
+	type parsed struct {
+		p util.P
+		n node
+	}
+	var nodes []parsed
+	var last util.P
+	for _, line := range lines {
+		m := reNode.FindStringSubmatch(line)
+		if m == nil {
+			continue // the command and the header line
+		}
+		var pn parsed
+		pn.p.X, _ = strconv.Atoi(m[1])
+		pn.p.Y, _ = strconv.Atoi(m[2])
+		pn.n.size, _ = strconv.Atoi(m[3])
+		pn.n.used, _ = strconv.Atoi(m[4])
+		last.X, last.Y = max(last.X, pn.p.X), max(last.Y, pn.p.Y)
+		nodes = append(nodes, pn)
+	}
+	g := &grid{w: last.X + 1, h: last.Y + 1}
+	if len(nodes) != g.w*g.h {
+		return nil, fmt.Errorf("expected %d nodes for a %dx%d grid, got %d", g.w*g.h, g.w, g.h, len(nodes))
+	}
+	g.nodes = make([]node, len(nodes))
+	for _, pn := range nodes {
+		g.nodes[pn.p.Y*g.w+pn.p.X] = pn.n
+	}
+	return g, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day23 solves AoC 2016 day 23.
+package day23
+
+import (
+	"github.com/fis/aoc/2016/assembunny"
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 23, glue.Puzzle{
+		Title: "Safe Cracking",
+		Tags:  []string{"vm"},
+	})
+	glue.RegisterSolver(2016, 23, glue.LineSolver(solve))
+}
+
+func solve(lines []string) ([]string, error) {
+	prog, err := assembunny.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := runProgram(prog, 7)
+	p2 := runProgram(prog, 12)
+	return glue.Ints(p1, p2), nil
+}
+
+func runProgram(prog *assembunny.Prog, eggs int) int {
+	m := assembunny.NewMachine(prog)
+	m.Regs[assembunny.RegA] = eggs
+	m.Run()
+	return m.Regs[assembunny.RegA]
+}
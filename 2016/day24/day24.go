@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day24 solves AoC 2016 day 24.
+package day24
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 24, glue.Puzzle{
+		Title: "Air Duct Spelunking",
+		Tags:  []string{"grid", "pathfinding", "combinatorics"},
+	})
+	glue.RegisterSolver(2016, 24, glue.FixedLevelSolver(solve))
+}
+
+func solve(level *util.FixedLevel) ([]string, error) {
+	dist, err := distances(level)
+	if err != nil {
+		return nil, err
+	}
+	p1 := shortestTour(dist, false)
+	p2 := shortestTour(dist, true)
+	return glue.Ints(p1, p2), nil
+}
+
+// distances finds the numbered locations of the map, and returns the matrix of shortest walking
+// distances between each pair of them.
+func distances(level *util.FixedLevel) ([][]int, error) {
+	var points []util.P
+	for y := 0; y < level.H; y++ {
+		for x := 0; x < level.W; x++ {
+			if b := level.At(x, y); b >= '0' && b <= '9' {
+				i := int(b - '0')
+				for len(points) <= i {
+					points = append(points, util.P{-1, -1})
+				}
+				points[i] = util.P{x, y}
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no locations on map")
+	}
+	for i, p := range points {
+		if p.X < 0 {
+			return nil, fmt.Errorf("location %d missing", i)
+		}
+	}
+
+	dist := make([][]int, len(points))
+	for i, from := range points {
+		dist[i] = make([]int, len(points))
+		steps := make([]int, level.W*level.H)
+		for j := range steps {
+			steps[j] = -1
+		}
+		steps[from.Y*level.W+from.X] = 0
+		q := []util.P{from}
+		for len(q) > 0 {
+			p := q[0]
+			q = q[1:]
+			s := steps[p.Y*level.W+p.X]
+			for _, n := range p.Neigh() {
+				if !level.InBounds(n.X, n.Y) || level.AtP(n) == '#' || steps[n.Y*level.W+n.X] >= 0 {
+					continue
+				}
+				steps[n.Y*level.W+n.X] = s + 1
+				q = append(q, n)
+			}
+		}
+		for j, to := range points {
+			d := steps[to.Y*level.W+to.X]
+			if d < 0 {
+				return nil, fmt.Errorf("location %d unreachable from %d", j, i)
+			}
+			dist[i][j] = d
+		}
+	}
+	return dist, nil
+}
+
+// shortestTour returns the length of the shortest route that starts from location 0 and visits all
+// the others, optionally also returning back to 0. It's a small travelling salesman problem, solved
+// with the usual dynamic programming over subsets.
+func shortestTour(dist [][]int, ret bool) int {
+	N := len(dist)
+	// best[set*N+at] = shortest walk from 0 that has visited the set and ended at location at
+	best := make([]int, (1<<N)*N)
+	for i := range best {
+		best[i] = math.MaxInt
+	}
+	best[1*N+0] = 0
+	for set := 1; set < 1<<N; set += 2 {
+		for at := 0; at < N; at++ {
+			d := best[set*N+at]
+			if d == math.MaxInt {
+				continue
+			}
+			for next := 1; next < N; next++ {
+				if set&(1<<next) != 0 {
+					continue
+				}
+				ns := set | 1<<next
+				best[ns*N+next] = min(best[ns*N+next], d+dist[at][next])
+			}
+		}
+	}
+	shortest, all := math.MaxInt, (1<<N)-1
+	for at := 0; at < N; at++ {
+		if d := best[all*N+at]; d != math.MaxInt {
+			if ret {
+				d += dist[at][0]
+			}
+			shortest = min(shortest, d)
+		}
+	}
+	return shortest
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day24
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/util"
+)
+
+var ex = `
+###########
+#0.1.....2#
+#.#######.#
+#4.......3#
+###########
+`
+
+func TestShortestTour(t *testing.T) {
+	level := util.ParseFixedLevel([]byte(strings.TrimPrefix(ex, "\n")))
+	dist, err := distances(level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 14
+	if got := shortestTour(dist, false); got != want {
+		t.Errorf("shortestTour(dist, false) = %d, want %d", got, want)
+	}
+}
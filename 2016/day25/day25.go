@@ -0,0 +1,79 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package day25 solves AoC 2016 day 25.
+package day25
+
+import (
+	"fmt"
+
+	"github.com/fis/aoc/2016/assembunny"
+	"github.com/fis/aoc/glue"
+)
+
+func init() {
+	glue.RegisterPuzzle(2016, 25, glue.Puzzle{
+		Title: "Clock Signal",
+		Tags:  []string{"vm"},
+	})
+	glue.RegisterSolver(2016, 25, glue.LineSolver(solve))
+}
+
+const maxInit = 1 << 20
+
+func solve(lines []string) ([]string, error) {
+	prog, err := assembunny.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	for a := 1; a < maxInit; a++ {
+		if isClock(prog, a) {
+			return glue.Ints(a), nil
+		}
+	}
+	return nil, fmt.Errorf("no clock signal for a < %d", maxInit)
+}
+
+// isClock checks whether the program produces the infinite signal 0, 1, 0, 1, ... when started with
+// the given value in the a register. The machine is deterministic, so the signal is known to repeat
+// forever once the machine is in the same state when producing the same bit twice.
+func isClock(prog *assembunny.Prog, a int) (ok bool) {
+	type snapshot struct {
+		ip   int
+		regs [4]int
+		want int
+	}
+	seen := make(map[snapshot]bool)
+
+	m := assembunny.NewMachine(prog)
+	m.Regs[assembunny.RegA] = a
+	want := 0
+	m.SetOutput(func(v int) bool {
+		if v != want {
+			return false
+		}
+		var s snapshot
+		s.ip, s.want = m.IP, want
+		copy(s.regs[:], m.Regs)
+		if seen[s] {
+			ok = true
+			return false
+		}
+		seen[s] = true
+		want = 1 - want
+		return true
+	})
+	m.Run()
+	return ok
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day25
+
+import (
+	"testing"
+
+	"github.com/fis/aoc/2016/assembunny"
+)
+
+// clock is a program with the same structure as the puzzle inputs: it outputs the bits of a+2*3,
+// least significant first, over and over again.
+var clock = []string{
+	"cpy a d",
+	"cpy 2 c",
+	"cpy 3 b",
+	"inc d",
+	"dec b",
+	"jnz b -2",
+	"dec c",
+	"jnz c -5",
+	"cpy d a",
+	"jnz 0 0",
+	"cpy a b",
+	"cpy 0 a",
+	"cpy 2 c",
+	"jnz b 2",
+	"jnz 1 6",
+	"dec b",
+	"dec c",
+	"jnz c -4",
+	"inc a",
+	"jnz 1 -7",
+	"cpy 2 b",
+	"jnz c 2",
+	"jnz 1 4",
+	"dec b",
+	"dec c",
+	"jnz 1 -4",
+	"jnz 0 0",
+	"out b",
+	"jnz a -19",
+	"jnz 1 -21",
+}
+
+func TestIsClock(t *testing.T) {
+	prog, err := assembunny.Parse(clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for a := 1; a < 100; a++ {
+		want := a == 4 || a == 36 // 0b1010, 0b101010
+		if got := isClock(prog, a); got != want {
+			t.Errorf("isClock(%d) = %t, want %t", a, got, want)
+		}
+	}
+}
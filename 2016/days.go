@@ -26,7 +26,19 @@ import (
 	_ "github.com/fis/aoc/2016/day08" // solvers
 	_ "github.com/fis/aoc/2016/day09" // solvers
 	_ "github.com/fis/aoc/2016/day10" // solvers
+	_ "github.com/fis/aoc/2016/day11" // solvers
 	_ "github.com/fis/aoc/2016/day12" // solvers
 	_ "github.com/fis/aoc/2016/day13" // solvers
 	_ "github.com/fis/aoc/2016/day14" // solvers
+	_ "github.com/fis/aoc/2016/day15" // solvers
+	_ "github.com/fis/aoc/2016/day16" // solvers
+	_ "github.com/fis/aoc/2016/day17" // solvers
+	_ "github.com/fis/aoc/2016/day18" // solvers
+	_ "github.com/fis/aoc/2016/day19" // solvers
+	_ "github.com/fis/aoc/2016/day20" // solvers
+	_ "github.com/fis/aoc/2016/day21" // solvers
+	_ "github.com/fis/aoc/2016/day22" // solvers
+	_ "github.com/fis/aoc/2016/day23" // solvers
+	_ "github.com/fis/aoc/2016/day24" // solvers
+	_ "github.com/fis/aoc/2016/day25" // solvers
 )
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 1, glue.Puzzle{
+		Title: "Inverse Captcha",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2017, 1, glue.LineSolver(solve))
 }
 
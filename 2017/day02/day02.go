@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 2, glue.Puzzle{
+		Title: "Corruption Checksum",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2017, 2, glue.LineSolver(solve))
 }
 
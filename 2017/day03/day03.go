@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 3, glue.Puzzle{
+		Title: "Spiral Memory",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2017, 3, glue.IntSolver(solve))
 }
 
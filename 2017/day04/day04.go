@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 4, glue.Puzzle{
+		Title: "High-Entropy Passphrases",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2017, 4, glue.LineSolver(solve))
 }
 
@@ -18,6 +18,10 @@ package day05
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2017, 5, glue.Puzzle{
+		Title: "A Maze of Twisty Trampolines, All Alike",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2017, 5, glue.IntSolver(solve))
 }
 
@@ -18,6 +18,10 @@ package day06
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2017, 6, glue.Puzzle{
+		Title: "Memory Reallocation",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2017, 6, glue.IntSolver(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 7, glue.Puzzle{
+		Title: "Recursive Circus",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2017, 7, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^(\w+) \((\d+)\)(?: -> (\w+(?:, \w+)*))?$`,
@@ -18,13 +18,17 @@ package day08
 import (
 	"fmt"
 	"math"
-	"regexp"
-	"strconv"
+	"strings"
 
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 8, glue.Puzzle{
+		Title: "I Heard You Like Registers",
+		Tags:  []string{"vm"},
+	})
 	glue.RegisterSolver(2017, 8, glue.LineSolver(solve))
 }
 
@@ -37,82 +41,62 @@ func solve(lines []string) ([]string, error) {
 }
 
 func maxRegs(lines []string) (maxFinal, maxEver int, err error) {
-	insts, err := parseInsts(lines)
+	prog, err := isa.Parse(lines)
 	if err != nil {
 		return 0, 0, err
 	}
 	maxFinal, maxEver = math.MinInt, math.MinInt
-	regs := make(map[string]int)
-	for _, inst := range insts {
-		inst.eval(regs)
-		if r := regs[inst.reg]; r > maxEver {
-			maxEver = r
-		}
+	m := regvm.NewMachine(prog, struct{}{})
+	for !m.Halted() {
+		r := m.Code[m.IP].Args[0].Val
+		m.Step()
+		maxEver = max(maxEver, m.Regs[r])
 	}
-	for _, val := range regs {
-		if val > maxFinal {
-			maxFinal = val
-		}
+	for _, val := range m.Regs {
+		maxFinal = max(maxFinal, val)
 	}
 	return maxFinal, maxEver, nil
 }
 
-type inst struct {
-	reg     string
-	inc     bool
-	arg     int
-	condReg string
-	cond    condFunc
-	condArg int
-}
+// isa describes the conditional increment language as a register machine. Each combination of
+// increment/decrement and a comparison is its own operation, named like "inc<=", with operands
+// (reg, amount, condReg, condArg).
+var isa = &regvm.ISA[struct{}]{Syntax: syntax}
 
-func parseInsts(lines []string) (insts []*inst, err error) {
-	insts = make([]*inst, len(lines))
-	for i, line := range lines {
-		insts[i], err = parseInst(line)
-		if err != nil {
-			return nil, err
+func init() {
+	for _, cond := range []struct {
+		name string
+		f    func(a, b int) bool
+	}{
+		{"==", func(a, b int) bool { return a == b }},
+		{"!=", func(a, b int) bool { return a != b }},
+		{"<", func(a, b int) bool { return a < b }},
+		{"<=", func(a, b int) bool { return a <= b }},
+		{">=", func(a, b int) bool { return a >= b }},
+		{">", func(a, b int) bool { return a > b }},
+	} {
+		for _, sign := range []struct {
+			name string
+			s    int
+		}{{"inc", 1}, {"dec", -1}} {
+			f, s := cond.f, sign.s
+			isa.Ops = append(isa.Ops, regvm.Op[struct{}]{
+				Name: sign.name + cond.name,
+				Args: "rirv",
+				Exec: func(m *regvm.Machine[struct{}], a []regvm.Operand) {
+					if f(m.Regs[a[2].Val], m.Get(a[3])) {
+						m.Regs[a[0].Val] += s * a[1].Val
+					}
+				},
+			})
 		}
 	}
-	return insts, nil
-}
-
-var instRe = regexp.MustCompile(`^(\w+) (inc|dec) (-?\d+) if (\w+) ([=!<>]=|[<>]) (-?\d+)`)
-
-func parseInst(line string) (*inst, error) {
-	parts := instRe.FindStringSubmatch(line)
-	if len(parts) != 7 {
-		return nil, fmt.Errorf("invalid instruction: %q", line)
-	}
-	arg, _ := strconv.Atoi(parts[3])
-	condArg, _ := strconv.Atoi(parts[6])
-	return &inst{
-		reg:     parts[1],
-		inc:     parts[2] == "inc",
-		arg:     arg,
-		condReg: parts[4],
-		cond:    condFuncs[parts[5]],
-		condArg: condArg,
-	}, nil
 }
 
-func (i *inst) eval(regs map[string]int) {
-	if i.cond(regs[i.condReg], i.condArg) {
-		if i.inc {
-			regs[i.reg] = regs[i.reg] + i.arg
-		} else {
-			regs[i.reg] = regs[i.reg] - i.arg
-		}
+func syntax(line string) (name string, args []string, err error) {
+	f := strings.Fields(line)
+	if len(f) != 7 || f[3] != "if" {
+		return "", nil, fmt.Errorf("invalid instruction: %q", line)
 	}
-}
-
-type condFunc func(a, b int) bool
-
-var condFuncs = map[string]condFunc{
-	"==": func(a, b int) bool { return a == b },
-	"!=": func(a, b int) bool { return a != b },
-	"<":  func(a, b int) bool { return a < b },
-	"<=": func(a, b int) bool { return a <= b },
-	">=": func(a, b int) bool { return a >= b },
-	">":  func(a, b int) bool { return a > b },
+	return f[1] + f[5], []string{f[0], f[2], f[4], f[6]}, nil
 }
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 9, glue.Puzzle{
+		Title: "Stream Processing",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2017, 9, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 10, glue.Puzzle{
+		Title: "Knot Hash",
+		Tags:  []string{"hashing"},
+	})
 	glue.RegisterSolver(2017, 10, glue.LineSolver(solve))
 }
 
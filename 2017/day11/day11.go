@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 11, glue.Puzzle{
+		Title: "Hex Ed",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2017, 11, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 12, glue.Puzzle{
+		Title: "Digital Plumber",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2017, 12, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 13, glue.Puzzle{
+		Title: "Packet Scanners",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2017, 13, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 14, glue.Puzzle{
+		Title: "Disk Defragmentation",
+		Tags:  []string{"hashing", "grid"},
+	})
 	glue.RegisterSolver(2017, 14, glue.LineSolver(solve))
 }
 
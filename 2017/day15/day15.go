@@ -23,21 +23,29 @@ import (
 )
 
 func init() {
-	glue.RegisterSolver(2017, 15, glue.RegexpSolver{
-		Solver: solve,
-		Regexp: `^Generator ([AB]) starts with (\d+)$`,
+	glue.RegisterPuzzle(2017, 15, glue.Puzzle{
+		Title: "Dueling Generators",
+		Tags:  []string{"number-theory"},
 	})
+	glue.RegisterSolver(2017, 15, solver(judge2p))
+	glue.RegisterVariant(2017, 15, "judge2", solver(judge2))
+	glue.RegisterVariant(2017, 15, "judge2p", solver(judge2p))
 }
 
-func solve(input [][]string) ([]string, error) {
-	if len(input) != 2 || input[0][0] != "A" || input[1][0] != "B" {
-		return nil, fmt.Errorf("invalid input: expected initial values for generators A and B")
+func solver(judge2 func(xA, xB, N int) int) glue.Solver {
+	return glue.RegexpSolver{
+		Solver: func(input [][]string) ([]string, error) {
+			if len(input) != 2 || input[0][0] != "A" || input[1][0] != "B" {
+				return nil, fmt.Errorf("invalid input: expected initial values for generators A and B")
+			}
+			xA, _ := strconv.Atoi(input[0][1])
+			xB, _ := strconv.Atoi(input[1][1])
+			p1 := judge(xA, xB, 40000000)
+			p2 := judge2(xA, xB, 5000000)
+			return glue.Ints(p1, p2), nil
+		},
+		Regexp: `^Generator ([AB]) starts with (\d+)$`,
 	}
-	xA, _ := strconv.Atoi(input[0][1])
-	xB, _ := strconv.Atoi(input[1][1])
-	p1 := judge(xA, xB, 40000000)
-	p2 := judge2p(xA, xB, 5000000)
-	return glue.Ints(p1, p2), nil
 }
 
 const (
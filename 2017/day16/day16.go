@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 16, glue.Puzzle{
+		Title: "Permutation Promenade",
+		Tags:  []string{"strings", "simulation"},
+	})
 	glue.RegisterSolver(2017, 16, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 17, glue.Puzzle{
+		Title: "Spinlock",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2017, 17, glue.IntSolver(solve))
 }
 
@@ -16,27 +16,29 @@
 package day18
 
 import (
-	"strconv"
-	"strings"
-
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
 )
 
 func init() {
-	glue.RegisterSolver(2017, 18, glue.RegexpSolver{
-		Solver: solve,
-		Regexp: `^(set|add|mul|mod|snd|rcv|jgz) ([a-z]|-?\d+)(?: ([a-z]|-?\d+))?$`,
+	glue.RegisterPuzzle(2017, 18, glue.Puzzle{
+		Title: "Duet",
+		Tags:  []string{"vm"},
 	})
+	glue.RegisterSolver(2017, 18, glue.LineSolver(solve))
 }
 
-func solve(code [][]string) ([]string, error) {
-	prog := parseCode(code)
+func solve(lines []string) ([]string, error) {
+	prog, err := isa.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
 	p1 := part1(prog)
 	p2 := part2(prog)
 	return glue.Ints(p1, p2), nil
 }
 
-func part1(prog []instruction) int {
+func part1(prog *regvm.Prog[ports]) int {
 	lastSnd := -1
 	snd := func(val int) { lastSnd = val }
 	rcv := func(dst int) (val int, terminate bool) {
@@ -46,16 +48,11 @@ func part1(prog []instruction) int {
 			return lastSnd, true
 		}
 	}
-	st := state{snd: snd, rcv: rcv}
-	for st.ip >= 0 && st.ip < len(prog) {
-		if terminate := st.step(prog[st.ip]); terminate {
-			break
-		}
-	}
+	regvm.NewMachine(prog, ports{snd: snd, rcv: rcv}).Run()
 	return lastSnd
 }
 
-func part2(code []instruction) int {
+func part2(code *regvm.Prog[ports]) int {
 	const (
 		reqSnd int = iota
 		reqRcv
@@ -83,14 +80,10 @@ func part2(code []instruction) int {
 			resp := <-respChans[progId]
 			return resp.val, resp.terminate
 		}
-		st := state{snd: snd, rcv: rcv}
-		st.regs['p'-'a'] = progId
+		m := regvm.NewMachine(code, ports{snd: snd, rcv: rcv})
+		m.Regs['p'-'a'] = progId
 		go func() {
-			for st.ip >= 0 && st.ip < len(code) {
-				if terminate := st.step(code[st.ip]); terminate {
-					break
-				}
-			}
+			m.Run()
 			reqChan <- request{code: reqTrm, prog: progId}
 		}()
 	}
@@ -142,102 +135,34 @@ func part2(code []instruction) int {
 	return prog[1].sendCount
 }
 
-type opCode int
-
-const (
-	opSet opCode = iota
-	opAdd
-	opMul
-	opMod
-	opSnd
-	opRcv
-	opJgz
-)
-
-var opMap = map[string]struct {
-	code   opCode
-	dstVal bool
-	oneArg bool
-}{
-	"set": {code: opSet},
-	"add": {code: opAdd},
-	"mul": {code: opMul},
-	"mod": {code: opMod},
-	"snd": {code: opSnd, dstVal: true, oneArg: true},
-	"rcv": {code: opRcv, dstVal: true, oneArg: true},
-	"jgz": {code: opJgz, dstVal: true},
-}
-
-type instruction struct {
-	op     opCode
-	dst    int
-	dstReg bool
-	src    int
-	srcReg bool
-}
-
-type state struct {
-	ip   int
-	regs [26]int
-	snd  func(int)
-	rcv  func(dst int) (val int, terminate bool)
-}
-
-func (s *state) step(inst instruction) (terminate bool) {
-	dst, src := inst.dst, inst.src
-	if inst.dstReg {
-		dst = s.regs[dst]
-	}
-	if inst.srcReg {
-		src = s.regs[src]
-	}
-	switch inst.op {
-	case opSet:
-		s.regs[inst.dst] = src
-	case opAdd:
-		s.regs[inst.dst] += src
-	case opMul:
-		s.regs[inst.dst] *= src
-	case opMod:
-		s.regs[inst.dst] %= src
-	case opSnd:
-		s.snd(dst)
-	case opRcv:
-		s.regs[inst.dst], terminate = s.rcv(dst)
-	case opJgz:
-		if dst > 0 {
-			s.ip += src
-		} else {
-			s.ip++
-		}
-	}
-	if inst.op != opJgz {
-		s.ip++
-	}
-	return terminate
+// ports holds the sound (or message) input and output channels of a duet machine.
+type ports struct {
+	snd func(int)
+	rcv func(dst int) (val int, terminate bool)
 }
 
-func parseCode(code [][]string) (insts []instruction) {
-	insts = make([]instruction, len(code))
-	for i, row := range code {
-		opInfo := opMap[row[0]]
-		insts[i] = instruction{op: opInfo.code}
-		if len(row[1]) == 1 && row[1][0] >= 'a' && row[1][0] <= 'z' {
-			insts[i].dst = int(row[1][0] - 'a')
-			insts[i].dstReg = true
-		} else if opInfo.dstVal {
-			insts[i].dst, _ = strconv.Atoi(row[1])
-		} else {
-			panic("invalid instruction: dst reg required: " + strings.Join(row, " "))
-		}
-		if opInfo.oneArg != (len(row[2]) == 0) {
-			panic("invalid instruction: arg count mismatch")
-		} else if len(row[2]) == 1 && row[2][0] >= 'a' && row[2][0] <= 'z' {
-			insts[i].src = int(row[2][0] - 'a')
-			insts[i].srcReg = true
-		} else if len(row[2]) > 0 {
-			insts[i].src, _ = strconv.Atoi(row[2])
-		}
-	}
-	return insts
+type machine = regvm.Machine[ports]
+
+var isa = &regvm.ISA[ports]{
+	Regs: regvm.LetterRegs(26),
+	Ops: []regvm.Op[ports]{
+		{Name: "set", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Set(a[0], m.Get(a[1])) }},
+		{Name: "add", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Regs[a[0].Val] += m.Get(a[1]) }},
+		{Name: "mul", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Regs[a[0].Val] *= m.Get(a[1]) }},
+		{Name: "mod", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Regs[a[0].Val] %= m.Get(a[1]) }},
+		{Name: "snd", Args: "v", Exec: func(m *machine, a []regvm.Operand) { m.Env.snd(m.Get(a[0])) }},
+		{Name: "rcv", Args: "r", Exec: func(m *machine, a []regvm.Operand) {
+			val, terminate := m.Env.rcv(m.Get(a[0]))
+			if terminate {
+				m.Halt()
+			} else {
+				m.Set(a[0], val)
+			}
+		}},
+		{Name: "jgz", Args: "vv", Exec: func(m *machine, a []regvm.Operand) {
+			if m.Get(a[0]) > 0 {
+				m.JumpRel(m.Get(a[1]))
+			}
+		}},
+	},
 }
@@ -19,19 +19,22 @@ import (
 )
 
 func TestPart1(t *testing.T) {
-	code := [][]string{
-		{"set", "a", "1"},
-		{"add", "a", "2"},
-		{"mul", "a", "a"},
-		{"mod", "a", "5"},
-		{"snd", "a", ""},
-		{"set", "a", "0"},
-		{"rcv", "a", ""},
-		{"jgz", "a", "-1"},
-		{"set", "a", "1"},
-		{"jgz", "a", "-2"},
+	code := []string{
+		"set a 1",
+		"add a 2",
+		"mul a a",
+		"mod a 5",
+		"snd a",
+		"set a 0",
+		"rcv a",
+		"jgz a -1",
+		"set a 1",
+		"jgz a -2",
+	}
+	prog, err := isa.Parse(code)
+	if err != nil {
+		t.Fatal(err)
 	}
-	prog := parseCode(code)
 	want := 4
 	if got := part1(prog); got != want {
 		t.Errorf("part1 = %d, want %d", got, want)
@@ -39,16 +42,19 @@ func TestPart1(t *testing.T) {
 }
 
 func TestPart2(t *testing.T) {
-	code := [][]string{
-		{"snd", "1", ""},
-		{"snd", "2", ""},
-		{"snd", "p", ""},
-		{"rcv", "a", ""},
-		{"rcv", "b", ""},
-		{"rcv", "c", ""},
-		{"rcv", "d", ""},
+	code := []string{
+		"snd 1",
+		"snd 2",
+		"snd p",
+		"rcv a",
+		"rcv b",
+		"rcv c",
+		"rcv d",
+	}
+	prog, err := isa.Parse(code)
+	if err != nil {
+		t.Fatal(err)
 	}
-	prog := parseCode(code)
 	want := 3
 	if got := part2(prog); got != want {
 		t.Errorf("part2 = %d, want %d", got, want)
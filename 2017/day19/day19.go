@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 19, glue.Puzzle{
+		Title: "A Series of Tubes",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2017, 19, glue.LevelSolver{
 		Solver: solve,
 		Empty:  ' ',
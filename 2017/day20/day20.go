@@ -27,6 +27,10 @@ import (
 const inputRegexp = `^p=<(-?\d+),(-?\d+),(-?\d+)>, v=<(-?\d+),(-?\d+),(-?\d+)>, a=<(-?\d+),(-?\d+),(-?\d+)>$`
 
 func init() {
+	glue.RegisterPuzzle(2017, 20, glue.Puzzle{
+		Title: "Particle Swarm",
+		Tags:  []string{"geometry", "simulation"},
+	})
 	glue.RegisterSolver(2017, 20, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: inputRegexp,
@@ -24,12 +24,18 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 21, glue.Puzzle{
+		Title: "Fractal Art",
+		Tags:  []string{"grid", "bits"},
+	})
 	glue.RegisterSolver(2017, 21, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^(\S+) => (\S+)$`,
 	})
 }
 
+var diag = util.NewDiag("2017/21")
+
 func solve(lines [][]string) ([]string, error) {
 	book, err := parseBook(lines)
 	if err != nil {
@@ -49,13 +55,13 @@ func solve(lines [][]string) ([]string, error) {
 const rootTile tile3 = 0b010_001_111
 
 func iterate(src *bitmap, book *ruleBook, n int) *bitmap {
-	diag := util.IsDiag()
+	enabled := diag.Enabled()
 
 	for i := 0; i < n; i++ {
-		if diag {
-			util.Diagf("i = %d:\n", i)
+		if enabled {
+			diag.Printf("i = %d:\n", i)
 			src.print()
-			util.Diagln()
+			diag.Println()
 		}
 		if (src.size & 1) == 0 {
 			src = book.expand2(src)
@@ -63,10 +69,10 @@ func iterate(src *bitmap, book *ruleBook, n int) *bitmap {
 			src = book.expand3(src)
 		}
 	}
-	if diag {
-		util.Diagf("i = %d:\n", n)
+	if enabled {
+		diag.Printf("i = %d:\n", n)
 		src.print()
-		util.Diagln()
+		diag.Println()
 	}
 
 	return src
@@ -287,13 +293,14 @@ func (b *bitmap) popCount() (count int) {
 }
 
 func (b *bitmap) print() {
+	row := make([]byte, b.size)
 	for y := 0; y < b.size; y++ {
 		for xo, v := range b.data[y*b.rowSize : (y+1)*b.rowSize] {
 			for x := 0; x < 32 && (xo<<5)+x < b.size; x++ {
-				util.Diagf("%c", [2]rune{'.', '#'}[v>>31])
+				row[(xo<<5)+x] = ".#"[v>>31]
 				v <<= 1
 			}
 		}
-		util.Diagln()
+		diag.Println(string(row))
 	}
 }
@@ -21,16 +21,24 @@ import (
 )
 
 func init() {
-	glue.RegisterSolver(2017, 22, glue.LevelSolver{
-		Solver: solve,
-		Empty:  '.',
+	glue.RegisterPuzzle(2017, 22, glue.Puzzle{
+		Title: "Sporifica Virus",
+		Tags:  []string{"grid", "simulation"},
 	})
+	glue.RegisterSolver(2017, 22, solver(simulateEvolvedArray))
+	glue.RegisterVariant(2017, 22, "level", solver(simulateEvolvedLevel))
+	glue.RegisterVariant(2017, 22, "array", solver(simulateEvolvedArray))
 }
 
-func solve(l *util.Level) ([]string, error) {
-	p1 := simulateSimple(l.Copy(), 10000)
-	p2 := simulateEvolvedArray(l, 10000000)
-	return glue.Ints(p1, p2), nil
+func solver(simulateEvolved func(*util.Level, int) int) glue.Solver {
+	return glue.LevelSolver{
+		Solver: func(l *util.Level) ([]string, error) {
+			p1 := simulateSimple(l.Copy(), 10000)
+			p2 := simulateEvolved(l, 10000000)
+			return glue.Ints(p1, p2), nil
+		},
+		Empty: '.',
+	}
 }
 
 func simulateSimple(l *util.Level, rounds int) (infected int) {
@@ -16,72 +16,87 @@
 package day23
 
 import (
-	"errors"
-	"strconv"
-	"strings"
+	"fmt"
 
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
+	"github.com/fis/aoc/util/symex"
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 23, glue.Puzzle{
+		Title: "Coprocessor Conflagration",
+		Tags:  []string{"vm"},
+	})
 	glue.RegisterSolver(2017, 23, glue.LineSolver(solve))
 }
 
 func solve(lines []string) ([]string, error) {
-	test, low, high, skip, err := extractParams(lines)
+	prog, err := isa.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	p1 := part1(prog)
+	p2, err := part2(prog)
 	if err != nil {
 		return nil, err
 	}
-	p1 := part1(test)
-	p2 := countComposite(low, high, skip)
 	return glue.Ints(p1, p2), nil
 }
 
-func part1(n int) int {
-	return (n - 2) * (n - 2)
+func part1(prog *regvm.Prog[int]) int {
+	m := regvm.NewMachine(prog, 0)
+	m.Run()
+	return m.Env
 }
 
-func countComposite(low, high, skip int) (composites int) {
-	for n := low; n <= high; n += skip {
-		if !isPrime(n) {
-			composites++
+// part2 runs the program in debug-mode-off mode. Executing it directly would take far too long, but
+// all of its loops can be summarized, which makes the run take next to no time at all.
+func part2(prog *regvm.Prog[int]) (int, error) {
+	m := regvm.NewMachine(prog, 0)
+	m.Regs[regA] = 1
+	a := symex.NewAnalyzer(symex.NewProgram(m, sem))
+	for h := range a.Headers() {
+		if s := a.Summary(h); s.Err != nil {
+			return 0, fmt.Errorf("loop at %d: %w", h, s.Err)
 		}
 	}
-	return composites
+	symex.Run(m, a)
+	return m.Regs[regH], nil
 }
 
-func isPrime(n int) bool {
-	if n%2 == 0 {
-		return false
-	}
-	for f := 3; f*f <= n; f += 2 {
-		if n%f == 0 {
-			return false
-		}
-	}
-	return true
+const (
+	regA = 0
+	regH = 'h' - 'a'
+)
+
+// isa is the instruction set of the coprocessor. The environment counts the executed mul
+// instructions.
+var isa = &regvm.ISA[int]{
+	Regs: regvm.LetterRegs(8),
+	Ops: []regvm.Op[int]{
+		{Name: "set", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Set(a[0], m.Get(a[1])) }},
+		{Name: "sub", Args: "rv", Exec: func(m *machine, a []regvm.Operand) { m.Regs[a[0].Val] -= m.Get(a[1]) }},
+		{Name: "mul", Args: "rv", Exec: func(m *machine, a []regvm.Operand) {
+			m.Regs[a[0].Val] *= m.Get(a[1])
+			m.Env++
+		}},
+		{Name: "jnz", Args: "vv", Exec: func(m *machine, a []regvm.Operand) {
+			if m.Get(a[0]) != 0 {
+				m.JumpRel(m.Get(a[1]))
+			}
+		}},
+	},
 }
 
-func extractParams(lines []string) (test, low, high, skip int, err error) {
-	var mb, ib, ic int
-	for _, ex := range []struct {
-		line   int
-		prefix string
-		out    *int
-	}{
-		{line: 0, prefix: "set b ", out: &test},
-		{line: 4, prefix: "mul b ", out: &mb},
-		{line: 5, prefix: "sub b -", out: &ib},
-		{line: 7, prefix: "sub c -", out: &ic},
-		{line: 30, prefix: "sub b -", out: &skip},
-	} {
-		line := lines[ex.line]
-		if !strings.HasPrefix(line, ex.prefix) {
-			return 0, 0, 0, 0, errors.New("bad program")
-		}
-		*ex.out, _ = strconv.Atoi(strings.TrimPrefix(line, ex.prefix))
-	}
-	low = test*mb + ib
-	high = low + ic
-	return test, low, high, skip, nil
+type machine = regvm.Machine[int]
+
+// sem describes the coprocessor instructions symbolically.
+var sem = symex.Semantics{
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], s.Get(a[1])) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Minus(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Times(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) {
+		s.Jump(symex.IfThenElse(s.Get(a[0]), symex.Plus(symex.C(s.IP), s.Get(a[1])), symex.C(s.IP+1)))
+	},
 }
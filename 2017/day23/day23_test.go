@@ -15,21 +15,107 @@
 package day23
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/fis/aoc/util/regvm"
 )
 
+// program returns a program in the shape of the puzzle input, with the given parameters.
+func program(b, mulB, addB, addC, step int) []string {
+	return strings.Split(fmt.Sprintf(`set b %d
+set c b
+jnz a 2
+jnz 1 5
+mul b %d
+sub b %d
+set c b
+sub c %d
+set f 1
+set d 2
+set e 2
+set g d
+mul g e
+sub g b
+jnz g 2
+set f 0
+sub e -1
+set g e
+sub g b
+jnz g -8
+sub d -1
+set g d
+sub g b
+jnz g -13
+jnz f 2
+sub h -1
+set g b
+sub g c
+jnz g 2
+jnz 1 3
+sub b %d
+jnz 1 -23`, b, mulB, -addB, -addC, -step), "\n")
+}
+
 func TestPart1(t *testing.T) {
-	n := 57
+	prog, err := isa.Parse(program(57, 100, 100000, 17000, 17))
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := 3025
-	if got := part1(n); got != want {
-		t.Errorf("part1(%d) = %d, want %d", n, got, want)
+	if got := part1(prog); got != want {
+		t.Errorf("part1 = %d, want %d", got, want)
+	}
+}
+
+func TestPart2(t *testing.T) {
+	tests := []struct {
+		b, mulB, addB, addC, step int
+	}{
+		{b: 7, mulB: 2, addB: 3, addC: 34, step: 17},
+		{b: 10, mulB: 3, addB: 1, addC: 100, step: 5},
+		{b: 57, mulB: 100, addB: 100000, addC: 17000, step: 17},
+	}
+	for _, test := range tests {
+		prog, err := isa.Parse(program(test.b, test.mulB, test.addB, test.addC, test.step))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := part2(prog)
+		if err != nil {
+			t.Errorf("part2(%+v): %v", test, err)
+			continue
+		}
+		want := countComposite(test.b*test.mulB+test.addB, test.addC, test.step)
+		if got != want {
+			t.Errorf("part2(%+v) = %d, want %d", test, got, want)
+		}
+	}
+}
+
+func TestPart2Slow(t *testing.T) {
+	prog, err := isa.Parse(program(7, 2, 3, 34, 17))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := regvm.NewMachine(prog, 0)
+	m.Regs[regA] = 1
+	m.Run()
+	want := m.Regs[regH]
+	if got, err := part2(prog); err != nil || got != want {
+		t.Errorf("part2 = (%d, %v), want %d", got, err, want)
 	}
 }
 
-func TestCountComposite(t *testing.T) {
-	low, high, skip := 105700, 122700, 17
-	want := 915
-	if got := countComposite(low, high, skip); got != want {
-		t.Errorf("countComposite(%d, %d, %d) = %d, want %d", low, high, skip, got, want)
+func countComposite(low, span, step int) (composites int) {
+	for n := low; n <= low+span; n += step {
+		for f := 2; f*f <= n; f++ {
+			if n%f == 0 {
+				composites++
+				break
+			}
+		}
 	}
+	return composites
 }
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 24, glue.Puzzle{
+		Title: "Electromagnetic Moat",
+		Tags:  []string{"graph", "combinatorics"},
+	})
 	glue.RegisterSolver(2017, 24, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^(\d+)/(\d+)$`,
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2017, 25, glue.Puzzle{
+		Title: "The Halting Problem",
+		Tags:  []string{"simulation", "parsing"},
+	})
 	glue.RegisterSolver(2017, 25, glue.ChunkSolver(solve))
 }
 
@@ -17,6 +17,9 @@ package cpu
 
 import (
 	"fmt"
+
+	"github.com/fis/aoc/util/regvm"
+	"github.com/fis/aoc/util/symex"
 )
 
 // Op represents one of the device's 16 opcodes.
@@ -88,6 +91,80 @@ func OpNamed(s string) (op Op, ok bool) {
 	return 0, false
 }
 
+// ISA is the instruction set of the device as a register machine. The operations are in opcode
+// order, so the index of an operation is its Op value.
+var ISA = &regvm.ISA[struct{}]{
+	Regs: regvm.NumberedRegs(6),
+	Ops: []regvm.Op[struct{}]{
+		AddR: {Name: "addr", Args: "rrr", Exec: execAdd},
+		AddI: {Name: "addi", Args: "rir", Exec: execAdd},
+		MulR: {Name: "mulr", Args: "rrr", Exec: execMul},
+		MulI: {Name: "muli", Args: "rir", Exec: execMul},
+		BanR: {Name: "banr", Args: "rrr", Exec: execBan},
+		BanI: {Name: "bani", Args: "rir", Exec: execBan},
+		BorR: {Name: "borr", Args: "rrr", Exec: execBor},
+		BorI: {Name: "bori", Args: "rir", Exec: execBor},
+		SetR: {Name: "setr", Args: "rir", Exec: execSet},
+		SetI: {Name: "seti", Args: "iir", Exec: execSet},
+		GtIR: {Name: "gtir", Args: "irr", Exec: execGt},
+		GtRI: {Name: "gtri", Args: "rir", Exec: execGt},
+		GtRR: {Name: "gtrr", Args: "rrr", Exec: execGt},
+		EqIR: {Name: "eqir", Args: "irr", Exec: execEq},
+		EqRI: {Name: "eqri", Args: "rir", Exec: execEq},
+		EqRR: {Name: "eqrr", Args: "rrr", Exec: execEq},
+	},
+}
+
+// Machine is the register machine type used for executing programs of the device.
+type Machine = regvm.Machine[struct{}]
+
+func execAdd(m *Machine, a []regvm.Operand) { m.Set(a[2], m.Get(a[0])+m.Get(a[1])) }
+func execMul(m *Machine, a []regvm.Operand) { m.Set(a[2], m.Get(a[0])*m.Get(a[1])) }
+func execBan(m *Machine, a []regvm.Operand) { m.Set(a[2], m.Get(a[0])&m.Get(a[1])) }
+func execBor(m *Machine, a []regvm.Operand) { m.Set(a[2], m.Get(a[0])|m.Get(a[1])) }
+func execSet(m *Machine, a []regvm.Operand) { m.Set(a[2], m.Get(a[0])) }
+func execGt(m *Machine, a []regvm.Operand)  { m.Set(a[2], asInt(m.Get(a[0]) > m.Get(a[1]))) }
+func execEq(m *Machine, a []regvm.Operand)  { m.Set(a[2], asInt(m.Get(a[0]) == m.Get(a[1]))) }
+
+// Semantics describes the operations of ISA symbolically, for use with the symex package.
+var Semantics = symex.Semantics{
+	AddR: symAdd, AddI: symAdd, MulR: symMul, MulI: symMul, BanR: symBan, BanI: symBan, BorR: symBor, BorI: symBor,
+	SetR: symSet, SetI: symSet, GtIR: symGt, GtRI: symGt, GtRR: symGt, EqIR: symEq, EqRI: symEq, EqRR: symEq,
+}
+
+func symAdd(s *symex.State, a []regvm.Operand) { s.Set(a[2], symex.Plus(s.Get(a[0]), s.Get(a[1]))) }
+func symMul(s *symex.State, a []regvm.Operand) { s.Set(a[2], symex.Times(s.Get(a[0]), s.Get(a[1]))) }
+func symBan(s *symex.State, a []regvm.Operand) {
+	s.Set(a[2], symex.BitwiseAnd(s.Get(a[0]), s.Get(a[1])))
+}
+func symBor(s *symex.State, a []regvm.Operand) {
+	s.Set(a[2], symex.BitwiseOr(s.Get(a[0]), s.Get(a[1])))
+}
+func symSet(s *symex.State, a []regvm.Operand) { s.Set(a[2], s.Get(a[0])) }
+func symGt(s *symex.State, a []regvm.Operand)  { s.Set(a[2], symex.Greater(s.Get(a[0]), s.Get(a[1]))) }
+func symEq(s *symex.State, a []regvm.Operand)  { s.Set(a[2], symex.Equals(s.Get(a[0]), s.Get(a[1]))) }
+
+// Analyze returns a loop analyzer for the program loaded in the machine.
+func Analyze(m *Machine) *symex.Analyzer {
+	return symex.NewAnalyzer(symex.NewProgram(m, Semantics))
+}
+
+// Operands returns the A, B and C operands of an instruction in the form used by the ISA.
+func Operands(op Op, a, b, c int) []regvm.Operand {
+	var args [3]regvm.Operand
+	setOperands(&args, op, a, b, c)
+	return args[:]
+}
+
+func setOperands(args *[3]regvm.Operand, op Op, a, b, c int) {
+	*args = [3]regvm.Operand{regvm.I(a), regvm.I(b), regvm.I(c)}
+	for i, kind := range ISA.Ops[op].Args {
+		if regvm.ArgKind(kind) == regvm.ArgReg {
+			args[i] = regvm.R(args[i].Val)
+		}
+	}
+}
+
 // Inst represents a single instruction: an opcode, and the A, B and C operands.
 type Inst struct {
 	Op      Op
@@ -121,17 +198,30 @@ func ParseProg(lines []string) (p Prog, err error) {
 		p.IPBound = true
 		lines = lines[1:]
 	}
-	p.Code = make([]Inst, len(lines))
-	for i, line := range lines {
-		var ok bool
-		p.Code[i], ok = ParseInst(line)
-		if !ok {
-			return Prog{}, fmt.Errorf("invalid instruction: %s", line)
-		}
+	vp, err := ISA.Parse(lines)
+	if err != nil {
+		return Prog{}, err
+	}
+	p.Code = make([]Inst, len(vp.Code))
+	for i, in := range vp.Code {
+		p.Code[i] = Inst{Op: Op(in.Op), A: in.Args[0].Val, B: in.Args[1].Val, C: in.Args[2].Val}
 	}
 	return p, nil
 }
 
+// NewMachine returns a register machine ready to execute the program from the start.
+// The IP binding of the machine is set up to match the program.
+func NewMachine(p Prog) *Machine {
+	vp := &regvm.Prog[struct{}]{ISA: ISA, Code: make([]regvm.Inst, len(p.Code))}
+	for i, in := range p.Code {
+		vp.Code[i] = regvm.Inst{Op: int(in.Op), N: 3}
+		setOperands((*[3]regvm.Operand)(vp.Code[i].Args[:3]), in.Op, in.A, in.B, in.C)
+	}
+	m := regvm.NewMachine(vp, struct{}{})
+	m.IPBound, m.IPReg = p.IPBound, p.IPR
+	return m
+}
+
 // State holds the entire CPU state: 6 registers and the instruction pointer,
 // as well as the current IP/register binding state.
 type State struct {
@@ -139,18 +229,22 @@ type State struct {
 	IP      int
 	IPBound bool
 	IPR     int
+
+	// m and args are the scratch space of Step, kept here so that stepping doesn't allocate.
+	m    Machine
+	args [3]regvm.Operand
 }
 
 // Run executes an entire program until it halts. The IP binding state is reset to be that of the program,
 // but the IP (and other registers) are itself not reset to zero.
 func (s *State) Run(p Prog) {
 	s.IPBound, s.IPR = p.IPBound, p.IPR
-	for s.IP >= 0 && s.IP < len(p.Code) {
-		i := p.Code[s.IP]
-		//fmt.Printf("ip=%d %v %v %d %d %d", s.IP, s.R, i.Op, i.A, i.B, i.C)
-		s.Step(i.Op, i.A, i.B, i.C)
-		//fmt.Printf(" %v\n", s.R)
-	}
+	m := NewMachine(p)
+	copy(m.Regs, s.R[:])
+	m.IP = s.IP
+	m.Run()
+	copy(s.R[:], m.Regs)
+	s.IP = m.IP
 }
 
 // Step executes one CPU cycle, given the operation to execute.
@@ -158,40 +252,9 @@ func (s *State) Step(op Op, a, b, c int) {
 	if s.IPBound {
 		s.R[s.IPR] = s.IP
 	}
-	switch op {
-	case AddR:
-		s.R[c] = s.R[a] + s.R[b]
-	case AddI:
-		s.R[c] = s.R[a] + b
-	case MulR:
-		s.R[c] = s.R[a] * s.R[b]
-	case MulI:
-		s.R[c] = s.R[a] * b
-	case BanR:
-		s.R[c] = s.R[a] & s.R[b]
-	case BanI:
-		s.R[c] = s.R[a] & b
-	case BorR:
-		s.R[c] = s.R[a] | s.R[b]
-	case BorI:
-		s.R[c] = s.R[a] | b
-	case SetR:
-		s.R[c] = s.R[a]
-	case SetI:
-		s.R[c] = a
-	case GtIR:
-		s.R[c] = asInt(a > s.R[b])
-	case GtRI:
-		s.R[c] = asInt(s.R[a] > b)
-	case GtRR:
-		s.R[c] = asInt(s.R[a] > s.R[b])
-	case EqIR:
-		s.R[c] = asInt(a == s.R[b])
-	case EqRI:
-		s.R[c] = asInt(s.R[a] == b)
-	case EqRR:
-		s.R[c] = asInt(s.R[a] == s.R[b])
-	}
+	s.m.Regs = s.R[:]
+	setOperands(&s.args, op, a, b, c)
+	ISA.Ops[op].Exec(&s.m, s.args[:])
 	if s.IPBound {
 		s.IP = s.R[s.IPR]
 	}
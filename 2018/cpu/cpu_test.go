@@ -0,0 +1,30 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpu
+
+import "testing"
+
+func TestStep(t *testing.T) {
+	s := &State{R: [6]int{0, 3, 4}, IPBound: true, IPR: 5}
+	s.Step(MulR, 1, 2, 0)  // r0 = 3 * 4
+	s.Step(AddI, 5, 10, 5) // jump ahead by 10
+	s.Step(GtRI, 0, 11, 3) // r3 = 12 > 11
+	if want := [6]int{12, 3, 4, 1, 0, 12}; s.R != want || s.IP != 13 {
+		t.Errorf("R = %v, IP = %d, want %v, 13", s.R, s.IP, want)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { s.Step(AddR, 0, 1, 0) }); allocs != 0 {
+		t.Errorf("Step: %v allocations, want 0", allocs)
+	}
+}
@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 1, glue.Puzzle{
+		Title: "Chronal Calibration",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2018, 1, glue.IntSolver(solve))
 }
 
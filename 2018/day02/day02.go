@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 2, glue.Puzzle{
+		Title: "Inventory Management System",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2018, 2, glue.LineSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 3, glue.Puzzle{
+		Title: "No Matter How You Slice It",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2018, 3, glue.LineSolver(solve))
 }
 
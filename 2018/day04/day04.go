@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 4, glue.Puzzle{
+		Title: "Repose Record",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2018, 4, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 5, glue.Puzzle{
+		Title: "Alchemical Reduction",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2018, 5, glue.LineSolver(solve))
 }
 
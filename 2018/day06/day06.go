@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 6, glue.Puzzle{
+		Title: "Chronal Coordinates",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2018, 6, glue.LineSolver(solve))
 }
 
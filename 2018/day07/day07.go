@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 7, glue.Puzzle{
+		Title: "The Sum of Its Parts",
+		Tags:  []string{"graph", "simulation"},
+	})
 	glue.RegisterSolver(2018, 7, glue.LineSolver(solve))
 	glue.RegisterPlotter(2018, 7, "", glue.LinePlotter(plotDeps), map[string]string{"ex": example})
 }
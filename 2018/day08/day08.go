@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 8, glue.Puzzle{
+		Title: "Memory Maneuver",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2018, 8, glue.IntSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 9, glue.Puzzle{
+		Title: "Marble Mania",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2018, 9, glue.LineSolver(solve))
 }
 
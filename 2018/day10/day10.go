@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 10, glue.Puzzle{
+		Title: "The Stars Align",
+		Tags:  []string{"geometry", "simulation"},
+	})
 	glue.RegisterSolver(2018, 10, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 11, glue.Puzzle{
+		Title: "Chronal Charge",
+		Tags:  []string{"grid", "dp"},
+	})
 	glue.RegisterSolver(2018, 11, glue.IntSolver(solve))
 }
 
@@ -24,6 +24,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 12, glue.Puzzle{
+		Title:         "Subterranean Sustainability",
+		Tags:          []string{"automaton"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2018, 12, glue.ChunkSolver(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 13, glue.Puzzle{
+		Title: "Mine Cart Madness",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2018, 13, glue.LevelSolver{
 		Solver: solve,
 		Empty:  ' ',
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 14, glue.Puzzle{
+		Title: "Chocolate Charts",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2018, 14, glue.IntSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 15, glue.Puzzle{
+		Title: "Beverage Bandits",
+		Tags:  []string{"grid", "simulation", "pathfinding"},
+	})
 	glue.RegisterSolver(2018, 15, glue.LevelSolver{Solver: solve, Empty: '#'})
 }
 
@@ -29,6 +29,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 16, glue.Puzzle{
+		Title: "Chronal Classification",
+		Tags:  []string{"vm"},
+	})
 	glue.RegisterSolver(2018, 16, glue.GenericSolver(solve))
 }
 
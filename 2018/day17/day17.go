@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 17, glue.Puzzle{
+		Title: "Reservoir Research",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2018, 17, glue.LineSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 18, glue.Puzzle{
+		Title: "Settlers of The North Pole",
+		Tags:  []string{"grid", "automaton"},
+	})
 	glue.RegisterSolver(2018, 18, glue.LevelSolver{Solver: solve, Empty: ' '})
 }
 
@@ -18,9 +18,14 @@ package day19
 import (
 	"github.com/fis/aoc/2018/cpu"
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/symex"
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 19, glue.Puzzle{
+		Title: "Go With The Flow",
+		Tags:  []string{"vm"},
+	})
 	glue.RegisterSolver(2018, 19, glue.LineSolver(solve))
 }
 
@@ -29,25 +34,19 @@ func solve(lines []string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	s := cpu.State{}
-	s.Run(prog)
-	part1 := s.R[0]
-
-	// For part 2, see the annotated assembly code below, as actually executing it would take too long.
-	part2 := sumDiv(10551339)
-
+	part1 := run(prog, 0)
+	part2 := run(prog, 1)
 	return glue.Ints(part1, part2), nil
 }
 
-func sumDiv(n int) (sum int) {
-	for i := 1; i*i <= n; i++ {
-		if n%i == 0 {
-			sum += i
-			sum += n / i
-		}
-	}
-	return sum
+// run executes the program with the given initial value for register 0, and returns its final
+// value. For part 2, actually executing the program would take too long, but the loops (see the
+// annotated assembly code below) can be summarized by symbolic execution.
+func run(prog cpu.Prog, r0 int) int {
+	m := cpu.NewMachine(prog)
+	m.Regs[0] = r0
+	symex.Run(m, cpu.Analyze(m))
+	return m.Regs[0]
 }
 
 /*
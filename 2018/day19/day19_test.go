@@ -15,6 +15,7 @@
 package day19
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -46,9 +47,50 @@ func TestRun(t *testing.T) {
 	}
 }
 
+// sumDivProg is the main loop of the puzzle input, with the initialization code replaced by just
+// setting the target number.
+const sumDivProg = `#ip 4
+addi 4 16 4
+seti 1 3 3
+seti 1 4 2
+mulr 3 2 1
+eqrr 1 5 1
+addr 1 4 4
+addi 4 1 4
+addr 3 0 0
+addi 2 1 2
+gtrr 2 5 1
+addr 4 1 4
+seti 2 2 4
+addi 3 1 3
+gtrr 3 5 1
+addr 1 4 4
+seti 1 6 4
+mulr 4 4 4
+seti %d 0 5
+seti 0 0 4`
+
 func TestSumDiv(t *testing.T) {
-	got := sumDiv(939)
-	if got != 1256 {
-		t.Errorf("sumDiv(939) = %d, want 1256", got)
+	for _, n := range []int{939, 60, 97, 10551339} {
+		prog, err := cpu.ParseProg(util.Lines(fmt.Sprintf(sumDivProg, n)))
+		if err != nil {
+			t.Fatalf("ParseProg: %v", err)
+		}
+		want := 0
+		for d := 1; d <= n; d++ {
+			if n%d == 0 {
+				want += d
+			}
+		}
+		if got := run(prog, 0); got != want {
+			t.Errorf("run(%d) = %d, want %d", n, got, want)
+		}
+		if n < 1000 {
+			s := cpu.State{}
+			s.Run(prog)
+			if s.R[0] != want {
+				t.Errorf("Run(%d) -> %d, want %d", n, s.R[0], want)
+			}
+		}
 	}
 }
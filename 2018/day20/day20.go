@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 20, glue.Puzzle{
+		Title: "A Regular Map",
+		Tags:  []string{"parsing", "grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2018, 20, glue.LineSolver(solve))
 }
 
@@ -16,11 +16,20 @@
 package day21
 
 import (
+	"errors"
+
 	"github.com/fis/aoc/2018/cpu"
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
+	"github.com/fis/aoc/util/symex"
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 21, glue.Puzzle{
+		Title:         "Chronal Conversion",
+		Tags:          []string{"vm"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2018, 21, glue.LineSolver(solve))
 }
 
@@ -29,46 +38,63 @@ func solve(lines []string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	p1 := part1(prog)
-	p2 := part2()
-	return glue.Ints(p1, p2), nil
-}
-
-func part1(prog cpu.Prog) int {
-	s := cpu.State{}
-	s.IPBound, s.IPR = prog.IPBound, prog.IPR
-	for s.IP != 28 {
-		i := prog.Code[s.IP]
-		s.Step(i.Op, i.A, i.B, i.C)
+	check, reg, err := findCheck(prog)
+	if err != nil {
+		return nil, err
 	}
-	return s.R[3]
+	p1, p2 := run(prog, check, reg)
+	return glue.Ints(p1, p2), nil
 }
 
-func part2() int {
-	prev := iter(0)
-	seen := map[int]struct{}{prev: {}}
-	for {
-		next := iter(prev)
-		if _, ok := seen[next]; ok {
-			return prev
+// findCheck locates the comparison that decides whether the program halts. It must be the only
+// instruction that reads register 0. The returned values are its address, and the register that
+// register 0 is compared with.
+func findCheck(prog cpu.Prog) (ip, reg int, err error) {
+	ip = -1
+	for i, in := range prog.Code {
+		args := cpu.Operands(in.Op, in.A, in.B, in.C)
+		for j, arg := range args[:2] {
+			if !arg.Reg || arg.Val != 0 {
+				continue
+			}
+			if ip >= 0 || in.Op != cpu.EqRR || !args[1-j].Reg || args[1-j].Val == 0 {
+				return 0, 0, errors.New("register 0 not used as expected")
+			}
+			ip, reg = i, args[1-j].Val
 		}
-		seen[next] = struct{}{}
-		prev = next
 	}
+	if ip < 0 {
+		return 0, 0, errors.New("register 0 not used")
+	}
+	return ip, reg, nil
 }
 
-func iter(r3 int) int {
-	r4 := r3 | 0x10000
-	r3 = 2176960
-	for {
-		r3 = (r3 + (r4 & 0xff)) & 0xffffff
-		r3 = (r3 * 65899) & 0xffffff
-		if r4 < 256 {
-			break
+// run executes the program, recording the values that register 0 is compared with. It returns the
+// first one (the value that makes the program halt the soonest), and the last one before the values
+// start repeating (the value that makes the program halt the latest). The loops of the program are
+// summarized using symbolic execution, except for the ones that contain the check.
+func run(prog cpu.Prog, check, reg int) (first, last int) {
+	m := cpu.NewMachine(prog)
+	a := cpu.Analyze(m)
+	a.Barriers = []int{check}
+	seen := make(map[int]struct{})
+	first = -1
+	symex.RunUntil(m, a, func(m *regvm.Machine[struct{}]) bool {
+		if m.IP != check {
+			return false
 		}
-		r4 >>= 8
-	}
-	return r3
+		v := m.Regs[reg]
+		if _, ok := seen[v]; ok {
+			return true
+		}
+		if first < 0 {
+			first = v
+		}
+		seen[v] = struct{}{}
+		last = v
+		return false
+	})
+	return first, last
 }
 
 /*
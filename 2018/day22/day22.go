@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 22, glue.Puzzle{
+		Title: "Mode Maze",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2018, 22, glue.LineSolver(solve))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 23, glue.Puzzle{
+		Title: "Experimental Emergency Teleportation",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2018, 23, glue.LineSolver(solve))
 }
 
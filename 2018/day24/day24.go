@@ -27,6 +27,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 24, glue.Puzzle{
+		Title:         "Immune System Simulator 20XX",
+		Tags:          []string{"simulation", "parsing"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2018, 24, glue.ChunkSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2018, 25, glue.Puzzle{
+		Title: "Four-Dimensional Adventure",
+		Tags:  []string{"geometry", "graph"},
+	})
 	glue.RegisterSolver(2018, 25, glue.LineSolver(solve))
 }
 
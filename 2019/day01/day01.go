@@ -20,6 +20,9 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 1, glue.Puzzle{
+		Title: "The Tyranny of the Rocket Equation",
+	})
 	glue.RegisterSolver(2019, 1, glue.IntSolver(solve))
 }
 
@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 2, glue.Puzzle{
+		Title: "1202 Program Alarm",
+		Tags:  []string{"intcode"},
+	})
 	glue.RegisterSolver(2019, 2, intcode.Solver(solve))
 }
 
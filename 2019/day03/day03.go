@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 3, glue.Puzzle{
+		Title: "Crossed Wires",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2019, 3, glue.LineSolver(solve))
 }
 
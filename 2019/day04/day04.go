@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 4, glue.Puzzle{
+		Title: "Secure Container",
+		Tags:  []string{"combinatorics"},
+	})
 	glue.RegisterSolver(2019, 4, glue.IntSolver(solve))
 }
 
@@ -22,9 +22,15 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 5, glue.Puzzle{
+		Title: "Sunny with a Chance of Asteroids",
+		Tags:  []string{"intcode"},
+	})
 	glue.RegisterSolver(2019, 5, intcode.Solver(solve))
 }
 
+var diag = util.NewDiag("2019/5")
+
 func solve(prog []int64) ([]int64, error) {
 	return []int64{part1(prog), part2(prog)}, nil
 }
@@ -32,7 +38,7 @@ func solve(prog []int64) ([]int64, error) {
 func part1(prog []int64) int64 {
 	out, _ := intcode.Run(prog, []int64{1})
 	for _, i := range out {
-		util.Diagf("out: %d\n", i)
+		diag.Printf("out: %d\n", i)
 	}
 	return out[len(out)-1]
 }
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 6, glue.Puzzle{
+		Title: "Universal Orbit Map",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2019, 6, glue.LineSolver(solve))
 }
 
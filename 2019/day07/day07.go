@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 7, glue.Puzzle{
+		Title: "Amplification Circuit",
+		Tags:  []string{"intcode", "combinatorics"},
+	})
 	glue.RegisterSolver(2019, 7, intcode.Solver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 8, glue.Puzzle{
+		Title: "Space Image Format",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2019, 8, glue.GenericSolver(solve))
 }
 
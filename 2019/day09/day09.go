@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 9, glue.Puzzle{
+		Title: "Sensor Boost",
+		Tags:  []string{"intcode"},
+	})
 	glue.RegisterSolver(2019, 9, intcode.Solver(solve))
 }
 
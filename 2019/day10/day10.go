@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 10, glue.Puzzle{
+		Title: "Monitoring Station",
+		Tags:  []string{"grid", "geometry", "number-theory"},
+	})
 	glue.RegisterSolver(2019, 10, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 11, glue.Puzzle{
+		Title: "Space Police",
+		Tags:  []string{"intcode", "grid"},
+	})
 	glue.RegisterSolver(2019, 11, intcode.SolverS(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 12, glue.Puzzle{
+		Title: "The N-Body Problem",
+		Tags:  []string{"simulation", "number-theory"},
+	})
 	glue.RegisterSolver(2019, 12, glue.LineSolver(solve))
 }
 
@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 13, glue.Puzzle{
+		Title: "Care Package",
+		Tags:  []string{"intcode", "grid"},
+	})
 	glue.RegisterSolver(2019, 13, intcode.Solver(solve))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 14, glue.Puzzle{
+		Title: "Space Stoichiometry",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2019, 14, glue.LineSolver(solve))
 }
 
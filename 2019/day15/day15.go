@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 15, glue.Puzzle{
+		Title: "Oxygen System",
+		Tags:  []string{"intcode", "grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2019, 15, intcode.Solver(solve))
 }
 
@@ -21,6 +21,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 16, glue.Puzzle{
+		Title:         "Flawed Frequency Transmission",
+		Tags:          []string{"simulation"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2019, 16, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 17, glue.Puzzle{
+		Title:         "Set and Forget",
+		Tags:          []string{"intcode", "grid", "strings"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2019, 17, intcode.Solver(solve))
 }
 
@@ -26,9 +26,15 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 18, glue.Puzzle{
+		Title: "Many-Worlds Interpretation",
+		Tags:  []string{"grid", "graph", "pathfinding"},
+	})
 	glue.RegisterSolver(2019, 18, glue.LevelSolver{Solver: solve, Empty: '#'})
 }
 
+var diag = util.NewDiag("2019/18")
+
 func solve(level *util.Level) ([]string, error) {
 	part1 := solveLevel(level)
 
@@ -100,7 +106,7 @@ func (g *graph) collect1() int {
 		f := heap.Pop(&front).(*frontierNode)
 		if f.d > maxD {
 			maxD = f.d
-			util.Diagf("exploring at %d, frontier size %d\n", maxD, len(front))
+			diag.Logger().Debug("exploring", "distance", maxD, "frontier", len(front))
 		}
 		if f.keys == g.keys {
 			return f.d
@@ -146,7 +152,7 @@ func (g *graph) collect4() int {
 		f := heap.Pop(&front).(*frontierNode)
 		if f.d > maxD {
 			maxD = f.d
-			util.Diagf("exploring at %d, frontier size %d\n", maxD, len(front))
+			diag.Logger().Debug("exploring", "distance", maxD, "frontier", len(front))
 		}
 		if f.keys == g.keys {
 			return f.d
@@ -410,10 +416,10 @@ func (v *graphV) String() string {
 
 func (g *graph) dump() {
 	for _, v := range g.verts {
-		util.Diagf("%v:", v)
+		diag.Printf("%v:", v)
 		for _, e := range v.edges {
-			util.Diagf(" %s/%v/%v/%d", e.v, e.keys, e.doors, e.d)
+			diag.Printf(" %s/%v/%v/%d", e.v, e.keys, e.doors, e.d)
 		}
-		util.Diagln()
+		diag.Println()
 	}
 }
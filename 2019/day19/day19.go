@@ -22,9 +22,15 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 19, glue.Puzzle{
+		Title: "Tractor Beam",
+		Tags:  []string{"intcode", "grid"},
+	})
 	glue.RegisterSolver(2019, 19, intcode.Solver(solve))
 }
 
+var diag = util.NewDiag("2019/19")
+
 func solve(prog []int64) ([]int64, error) {
 	probe := prober(prog)
 	return []int64{
@@ -35,16 +41,16 @@ func solve(prog []int64) ([]int64, error) {
 
 func part1(size int, probe func(x, y int) bool) int64 {
 	var count int64
+	row := make([]byte, size)
 	for y := 0; y < size; y++ {
 		for x := 0; x < size; x++ {
-			c := " "
+			row[x] = ' '
 			if probe(x, y) {
 				count++
-				c = "#"
+				row[x] = '#'
 			}
-			util.Diag(c)
 		}
-		util.Diag("\n")
+		diag.Println(string(row))
 	}
 	return count
 }
@@ -82,8 +88,11 @@ func part2(start, size int, probe func(x, y int) bool) int64 {
 }
 
 func prober(prog []int64) func(x, y int) bool {
+	vm := intcode.VM{}
+	vm.Precompile()
 	return func(x, y int) bool {
-		out, _ := intcode.Run(prog, []int64{int64(x), int64(y)})
+		vm.Load(prog)
+		out := vm.Run([]int64{int64(x), int64(y)})
 		return out[0] != 0
 	}
 }
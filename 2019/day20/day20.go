@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 20, glue.Puzzle{
+		Title: "Donut Maze",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2019, 20, glue.LevelSolver{Solver: solve, Empty: ' '})
 }
 
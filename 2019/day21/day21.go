@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 21, glue.Puzzle{
+		Title: "Springdroid Adventure",
+		Tags:  []string{"intcode"},
+	})
 	glue.RegisterSolver(2019, 21, intcode.Solver(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 22, glue.Puzzle{
+		Title: "Slam Shuffle",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2019, 22, glue.LineSolver(solve))
 }
 
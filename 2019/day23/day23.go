@@ -22,9 +22,15 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 23, glue.Puzzle{
+		Title: "Category Six",
+		Tags:  []string{"intcode", "simulation"},
+	})
 	glue.RegisterSolver(2019, 23, intcode.Solver(solve))
 }
 
+var diag = util.NewDiag("2019/23")
+
 func solve(prog []int64) ([]int64, error) {
 	var sw netSwitch
 	p1, p2 := sw.run(prog)
@@ -84,7 +90,7 @@ func (sw *netSwitch) run(prog []int64) (int64, int64) {
 			}
 		}
 		if idle {
-			util.Diagf("NAT -> 0: %v\n", sw.natPacket)
+			diag.Printf("NAT -> 0: %v\n", sw.natPacket)
 			if sw.natPacket.y == sw.part2 {
 				return sw.part1, sw.part2
 			}
@@ -101,7 +107,7 @@ func (sw *netSwitch) send(m *computer) {
 	p.x = m.token.ReadOutput()
 	m.vm.Walk(&m.token)
 	p.y = m.token.ReadOutput()
-	util.Diagf("%d -> %d: %v\n", m.addr, addr, p)
+	diag.Printf("%d -> %d: %v\n", m.addr, addr, p)
 
 	if addr == 255 {
 		if !sw.part1Set {
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 24, glue.Puzzle{
+		Title: "Planet of Discord",
+		Tags:  []string{"grid", "automaton", "bits"},
+	})
 	glue.RegisterSolver(2019, 24, glue.LineSolver(solve))
 }
 
@@ -17,6 +17,7 @@ package day25
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fis/aoc/2019/intcode"
@@ -24,36 +25,50 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2019, 25, glue.Puzzle{
+		Title: "Cryostasis",
+		Tags:  []string{"intcode", "graph"},
+	})
 	glue.RegisterSolver(2019, 25, intcode.SolverS(solve))
 }
 
-// TODO: Write a generalized solver for this puzzle.
-
-var motions = []string{
-	"east", "take antenna",
-	"north", "north", "take asterisk",
-	"south", "west", "south", "take hologram",
-	"north", "west", "take astronaut ice cream",
-	"east", "east", "south", "east", "take ornament",
-	"north", "west", "take fixed point",
-	"east", "south", "west", "west", "south", "south", "south", "take dark matter",
-	"north", "west", "north", "take monolith",
-	"north", "north", // at checkpoint
+// traps lists the items that must not be picked up, as doing so ends the game in one way or another.
+var traps = map[string]bool{
+	"infinite loop":       true,
+	"photons":             true,
+	"escape pod":          true,
+	"molten lava":         true,
+	"giant electromagnet": true,
 }
 
+var opposite = map[string]string{"north": "south", "south": "north", "east": "west", "west": "east"}
+
+const (
+	checkpointRoom = "Security Checkpoint"
+	floorRoom      = "Pressure-Sensitive Floor"
+)
+
 func solve(prog []int64) ([]string, error) {
 	term := terminal{}
+	term.vm.Precompile()
 	term.vm.Load(prog)
 
-	var items []string
-	term.read()
-	for _, cmd := range motions {
-		if strings.HasPrefix(cmd, "take ") {
-			items = append(items, cmd[5:])
-		}
-		term.write(cmd)
+	ship := explorer{term: &term, rooms: make(map[string]*room)}
+	start := parseRoom(term.read())
+	if start == nil {
+		return nil, fmt.Errorf("no starting room")
+	}
+	if ship.explore(start); ship.result != nil {
+		return ship.result, nil
+	}
+	if ship.floorDir == "" {
+		return nil, fmt.Errorf("security checkpoint not found")
+	}
+	for _, dir := range ship.path(start.name, checkpointRoom) {
+		term.write(dir)
 		term.read()
 	}
+	items := ship.items
 
 	dropped := 0
 	for _, attempt := range grayCodes(len(items)) {
@@ -68,13 +83,144 @@ func solve(prog []int64) ([]string, error) {
 			}
 		}
 		dropped = attempt
-		term.write("east")
+		term.write(ship.floorDir)
 		out := term.read()
 		if !strings.Contains(out, "== Security Checkpoint ==") {
 			return extract(out), nil
 		}
 	}
-	panic("access denied")
+	return nil, fmt.Errorf("access denied")
+}
+
+// room holds the parsed description of one room of the ship.
+type room struct {
+	name  string
+	doors map[string]string // direction -> room name, or "" if not yet visited
+	items []string
+}
+
+// parseRoom parses the description of the last room mentioned in the output of a command.
+// If the output does not contain a room description, nil is returned.
+func parseRoom(out string) *room {
+	i := strings.LastIndex(out, "== ")
+	if i < 0 {
+		return nil
+	}
+	lines := strings.Split(out[i:], "\n")
+	r := &room{name: strings.TrimSuffix(strings.TrimPrefix(lines[0], "== "), " =="), doors: make(map[string]string)}
+	var list *[]string
+	var doors []string
+	for _, line := range lines[1:] {
+		switch {
+		case line == "Doors here lead:":
+			list = &doors
+		case line == "Items here:":
+			list = &r.items
+		case strings.HasPrefix(line, "- ") && list != nil:
+			*list = append(*list, line[2:])
+		default:
+			list = nil
+		}
+	}
+	for _, dir := range doors {
+		r.doors[dir] = ""
+	}
+	return r
+}
+
+// explorer maps out the ship by walking through all its rooms.
+type explorer struct {
+	term     *terminal
+	rooms    map[string]*room
+	items    []string
+	floorDir string
+	result   []string
+}
+
+// explore performs a depth-first walk starting from (and returning to) the current room, picking up
+// all the safe items along the way. The pressure-sensitive floor is never entered, but the direction
+// leading to it from the checkpoint is recorded. If the floor happens to be entered successfully,
+// the exploration stops and the final message is stored as the result.
+func (e *explorer) explore(here *room) {
+	e.rooms[here.name] = here
+	for _, item := range here.items {
+		if traps[item] {
+			continue
+		}
+		e.term.write("take " + item)
+		e.term.read()
+		e.items = append(e.items, item)
+	}
+	for _, dir := range sortedKeys(here.doors) {
+		if here.doors[dir] != "" {
+			continue
+		}
+		e.term.write(dir)
+		out := e.term.read()
+		next := parseRoom(out)
+		if next == nil || next.name == floorRoom {
+			// accidentally carrying just the right items
+			e.result = extract(out)
+			return
+		}
+		if next.name == here.name {
+			// ejected back by the pressure-sensitive floor
+			here.doors[dir] = floorRoom
+			e.floorDir = dir
+			continue
+		}
+		here.doors[dir] = next.name
+		if seen, ok := e.rooms[next.name]; ok {
+			seen.doors[opposite[dir]] = here.name
+		} else {
+			next.doors[opposite[dir]] = here.name
+			if e.explore(next); e.result != nil {
+				return
+			}
+		}
+		e.term.write(opposite[dir])
+		e.term.read()
+	}
+}
+
+// path finds the shortest sequence of moves between two explored rooms.
+func (e *explorer) path(from, to string) []string {
+	type step struct {
+		prev string
+		dir  string
+	}
+	seen := map[string]step{from: {}}
+	for q := []string{from}; len(q) > 0; q = q[1:] {
+		at := q[0]
+		if at == to {
+			var path []string
+			for at != from {
+				s := seen[at]
+				path = append(path, s.dir)
+				at = s.prev
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, dir := range sortedKeys(e.rooms[at].doors) {
+			next := e.rooms[at].doors[dir]
+			if _, ok := seen[next]; ok || e.rooms[next] == nil {
+				continue
+			}
+			seen[next] = step{prev: at, dir: dir}
+			q = append(q, next)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
 }
 
 func extract(out string) []string {
@@ -0,0 +1,90 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day25
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRoom(t *testing.T) {
+	tests := []struct {
+		out  string
+		want *room
+	}{
+		{
+			out: `
+
+
+== Holodeck ==
+Someone seems to have left it on the Giant Grid setting.
+
+Doors here lead:
+- north
+- east
+- west
+
+Items here:
+- antenna
+
+Command?
+`,
+			want: &room{name: "Holodeck", doors: map[string]string{"north": "", "east": "", "west": ""}, items: []string{"antenna"}},
+		},
+		{
+			out: `
+
+
+== Pressure-Sensitive Floor ==
+Analyzing...
+
+Doors here lead:
+- west
+
+A loud, robotic voice says "Alert! Droids on this ship are heavier than the detected value!" and you are ejected back to the checkpoint.
+
+
+
+== Security Checkpoint ==
+In the next room, a pressure-sensitive floor will verify your identity.
+
+Doors here lead:
+- east
+- south
+
+Command?
+`,
+			want: &room{name: "Security Checkpoint", doors: map[string]string{"east": "", "south": ""}},
+		},
+		{
+			out:  "\nYou take the antenna.\n\nCommand?\n",
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		got := parseRoom(test.out)
+		if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(room{})); diff != "" {
+			t.Errorf("parseRoom mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestGrayCodes(t *testing.T) {
+	want := []int{0, 1, 3, 2, 6, 7, 5, 4}
+	if got := grayCodes(3); !cmp.Equal(got, want) {
+		t.Errorf("grayCodes(3) = %v, want %v", got, want)
+	}
+}
@@ -24,6 +24,18 @@ func TestAllDays(t *testing.T) {
 	glue.RunTests(t, "../testdata", 2019)
 }
 
+func TestVariants(t *testing.T) {
+	glue.RunVariantTests(t, "../testdata", 2019)
+}
+
+func TestGenerated(t *testing.T) {
+	glue.RunGeneratedTests(t, 2019)
+}
+
+func TestCatalogue(t *testing.T) {
+	glue.RunCatalogueTests(t, 2019)
+}
+
 func BenchmarkAllDays(b *testing.B) {
 	glue.RunBenchmarks(b, "../testdata", 2019)
 }
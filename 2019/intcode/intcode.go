@@ -56,6 +56,8 @@ func (s SolverS) Solve(input io.Reader) ([]string, error) {
 	return s(prog)
 }
 
+var diag = util.NewDiag("intcode")
+
 // Load will read an Intcode program in the standard format (comma-separated integers) from a stream.
 func Load(input io.Reader) ([]int64, error) {
 	lines, err := util.ScanAll(input, bufio.ScanLines)
@@ -72,6 +74,7 @@ func Load(input io.Reader) ([]int64, error) {
 			p = append(p, val)
 		}
 	}
+	diag.Logger().Debug("loaded program", "size", len(p))
 	return p, nil
 }
 
@@ -88,6 +91,8 @@ func Run(prog, input []int64) (output, mem []int64) {
 // A VM represents the state of an Intcode computer.
 type VM struct {
 	data   []int64
+	cache  []decoded
+	cached bool
 	ip     int
 	base   int
 	Stdin  Reader
@@ -117,6 +122,12 @@ type arg struct {
 	mode argMode
 }
 
+// decoded is a cached pre-decoded instruction. A nil op means the entry is not (or no longer) valid.
+type decoded struct {
+	op   *opcode
+	args [maxArgs]arg
+}
+
 type argMode uint
 
 const (
@@ -128,20 +139,39 @@ const (
 const maxArgs = 3
 
 // Load resets the computer and initializes its memory to be a copy of the program.
+//
+// In the cached execution mode, decoded instructions are retained for any memory that is left
+// unchanged by the load, which makes repeatedly reloading the same program cheap.
 func (vm *VM) Load(p []int64) {
-	vm.data = make([]int64, len(p))
+	data := make([]int64, len(p))
 	for i, val := range p {
-		vm.data[i] = val
+		data[i] = val
 	}
+	if vm.cached {
+		vm.reloadCache(data)
+	}
+	vm.data = data
 	vm.Reset()
 }
 
 // Use resets the computer and uses the program directly as its memory.
+// If the decode cache is enabled, the caller must not modify the memory other than via the VM.
 func (vm *VM) Use(p []int64) {
 	vm.data = p
+	vm.flushCache()
 	vm.Reset()
 }
 
+// Precompile switches the computer to the cached execution mode. In this mode, each instruction is
+// decoded only once, on first execution, and the decoded form (operation and argument modes) is kept
+// in a cache indexed by the instruction pointer. Any write to memory invalidates the cached
+// instructions it overlaps, so self-modifying programs behave exactly as in the default mode. The
+// mode persists over subsequent calls to Load and Use.
+func (vm *VM) Precompile() {
+	vm.cached = true
+	vm.flushCache()
+}
+
 // Reset resets the IP and base pointers, but not the memory.
 func (vm *VM) Reset() {
 	vm.ip = 0
@@ -160,8 +190,10 @@ func (vm *VM) Run(in []int64) []int64 {
 }
 
 // Mem returns a pointer to the specified memory cell of the computer.
+// In the cached execution mode, the returned pointer must not be retained past the next instruction.
 func (vm *VM) Mem(offset int) *int64 {
 	vm.page(offset)
+	vm.invalidate(offset)
 	return &vm.data[offset]
 }
 
@@ -171,6 +203,10 @@ func (vm *VM) Dump() []int64 {
 }
 
 func (vm *VM) execute(steps int) {
+	if vm.cached {
+		vm.executeCached(steps)
+		return
+	}
 	var args [maxArgs]arg
 	for steps != 0 {
 		op := vm.fetch(&args)
@@ -187,6 +223,82 @@ func (vm *VM) execute(steps int) {
 	}
 }
 
+func (vm *VM) executeCached(steps int) {
+	for steps != 0 {
+		d := vm.fetchCached()
+		if d == nil {
+			return
+		}
+		op := d.op
+		op.act(vm, d.args[0:op.narg])
+		if !op.jump {
+			vm.ip += 1 + op.narg
+		}
+		if steps > 0 {
+			steps--
+		}
+	}
+}
+
+// fetchCached returns the decoded instruction at the current IP, decoding it into the cache if needed.
+// The returned pointer is only valid until the next write to memory.
+func (vm *VM) fetchCached() *decoded {
+	if uint(vm.ip) < uint(len(vm.cache)) {
+		if d := &vm.cache[vm.ip]; d.op != nil {
+			return d
+		}
+	}
+	var args [maxArgs]arg
+	op := vm.fetch(&args)
+	if op == nil {
+		return nil
+	}
+	if len(vm.cache) < len(vm.data) {
+		vm.cache = append(vm.cache, make([]decoded, len(vm.data)-len(vm.cache))...)
+	}
+	d := &vm.cache[vm.ip]
+	d.op, d.args = op, args
+	return d
+}
+
+// invalidate drops any cached instructions that would overlap the given memory address.
+func (vm *VM) invalidate(i int) {
+	lo := i - maxArgs
+	if lo < 0 {
+		lo = 0
+	}
+	for j := min(i, len(vm.cache)-1); j >= lo; j-- {
+		vm.cache[j].op = nil
+	}
+}
+
+// reloadCache invalidates the cached instructions affected by replacing the memory with data.
+func (vm *VM) reloadCache(data []int64) {
+	for i, n := 0, max(len(vm.data), len(data)); i < n; i++ {
+		var was, is int64
+		if i < len(vm.data) {
+			was = vm.data[i]
+		}
+		if i < len(data) {
+			is = data[i]
+		}
+		if was != is {
+			vm.invalidate(i)
+		}
+	}
+	if len(vm.cache) > len(data) {
+		vm.cache = vm.cache[:len(data)]
+	}
+}
+
+func (vm *VM) flushCache() {
+	if vm.cached {
+		vm.cache = make([]decoded, len(vm.data))
+	} else {
+		vm.cache = nil
+	}
+}
+
 func (vm *VM) fetch(args *[maxArgs]arg) *opcode {
 	vm.page(vm.ip)
 	inst := uint64(vm.data[vm.ip])
@@ -232,6 +344,9 @@ func (vm *VM) write(a arg, val int64) {
 	i += int(a.val)
 	vm.page(i)
 	vm.data[i] = val
+	if vm.cached {
+		vm.invalidate(i)
+	}
 }
 
 func (vm *VM) getIO() (Reader, Writer) {
@@ -382,7 +497,7 @@ func (vm *VM) Walk(token *WalkToken) bool {
 
 	var args [maxArgs]arg
 	for {
-		op := vm.fetch(&args)
+		op := vm.fetchWalk(&args)
 		switch {
 		case op == nil:
 			*token = WalkToken{}
@@ -401,6 +516,19 @@ func (vm *VM) Walk(token *WalkToken) bool {
 	}
 }
 
+// fetchWalk is like fetch, but goes through the decode cache in the cached execution mode.
+func (vm *VM) fetchWalk(args *[maxArgs]arg) *opcode {
+	if !vm.cached {
+		return vm.fetch(args)
+	}
+	d := vm.fetchCached()
+	if d == nil {
+		return nil
+	}
+	*args = d.args
+	return d.op
+}
+
 // WalkToken is used for holding invocation state when running Intcode via the Walk() API.
 type WalkToken struct {
 	kind int
@@ -0,0 +1,158 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intcode
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelfModifying(t *testing.T) {
+	tests := []struct {
+		name string
+		prog []int64
+		in   []int64
+		want []int64
+	}{
+		{
+			name: "operand",
+			prog: []int64{
+				104, 1, // out 1 (the immediate is the loop counter)
+				1001, 1, 1, 1, // counter++
+				1008, 1, 4, 20, // [20] = counter == 4
+				1006, 20, 0, // loop if not
+				99,
+			},
+			want: []int64{1, 2, 3},
+		},
+		{
+			name: "opcode",
+			prog: []int64{
+				3, 12, // [12] = in
+				1101, 3, 4, 20, // [20] = 3+4 (becomes 3*4 on second pass)
+				4, 20, // out [20]
+				1105, 1, 13, // jump to patch
+				99, 0, // halt (the 0 is the input slot)
+				1006, 12, 11, // halt if input was zero
+				1101, 0, 1102, 2, // patch add -> mul
+				1101, 0, 0, 12, // clear input
+				1105, 1, 2, // loop
+			},
+			in:   []int64{1},
+			want: []int64{7, 12},
+		},
+	}
+	for _, test := range tests {
+		for _, cached := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/cached=%t", test.name, cached), func(t *testing.T) {
+				vm := VM{}
+				if cached {
+					vm.Precompile()
+				}
+				vm.Load(test.prog)
+				got := vm.Run(test.in)
+				if !cmp.Equal(got, test.want) {
+					t.Errorf("Run -> %v, want %v", got, test.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCachedMatchesPlain(t *testing.T) {
+	tests := []struct {
+		day int
+		in  []int64
+	}{
+		{day: 5, in: []int64{5}},
+		{day: 9, in: []int64{2}},
+	}
+	for _, test := range tests {
+		prog := loadDay(t, test.day)
+		t.Run(fmt.Sprintf("day=%02d/Run", test.day), func(t *testing.T) {
+			plain, cached := VM{}, VM{}
+			cached.Precompile()
+			plain.Load(prog)
+			cached.Load(prog)
+			want, got := plain.Run(test.in), cached.Run(test.in)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("output mismatch (-plain +cached):\n%s", diff)
+			}
+			if diff := cmp.Diff(plain.Dump(), cached.Dump()); diff != "" {
+				t.Errorf("memory mismatch (-plain +cached):\n%s", diff)
+			}
+		})
+		t.Run(fmt.Sprintf("day=%02d/Walk", test.day), func(t *testing.T) {
+			want, _ := Run(prog, test.in)
+			vm, tok, in := VM{}, WalkToken{}, test.in
+			vm.Precompile()
+			vm.Load(prog)
+			var got []int64
+			for vm.Walk(&tok) {
+				if tok.IsInput() {
+					tok.ProvideInput(in[0])
+					in = in[1:]
+				} else {
+					got = append(got, tok.ReadOutput())
+				}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("output mismatch (-Run +Walk):\n%s", diff)
+			}
+		})
+	}
+}
+
+func BenchmarkExecute(b *testing.B) {
+	tests := []struct {
+		day int
+		in  []int64
+	}{
+		{day: 9, in: []int64{2}},
+		{day: 19, in: []int64{20, 30}},
+	}
+	for _, test := range tests {
+		prog := loadDay(b, test.day)
+		for _, cached := range []bool{false, true} {
+			b.Run(fmt.Sprintf("day=%02d/cached=%t", test.day, cached), func(b *testing.B) {
+				vm := VM{}
+				if cached {
+					vm.Precompile()
+				}
+				for i := 0; i < b.N; i++ {
+					vm.Load(prog)
+					vm.Run(test.in)
+				}
+			})
+		}
+	}
+}
+
+func loadDay(tb testing.TB, day int) []int64 {
+	tb.Helper()
+	f, err := os.Open(fmt.Sprintf("../../testdata/2019/day%02d.txt", day))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	prog, err := Load(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return prog
+}
@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 1, glue.Puzzle{
+		Title: "Report Repair",
+		Tags:  []string{"combinatorics"},
+	})
 	glue.RegisterSolver(2020, 1, glue.IntSolver(solve))
 }
 
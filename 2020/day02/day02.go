@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 2, glue.Puzzle{
+		Title: "Password Philosophy",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2020, 2, glue.LineSolver(solve))
 }
 
@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 3, glue.Puzzle{
+		Title: "Toboggan Trajectory",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2020, 3, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 4, glue.Puzzle{
+		Title: "Passport Processing",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2020, 4, glue.ChunkSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 5, glue.Puzzle{
+		Title: "Binary Boarding",
+		Tags:  []string{"bits"},
+	})
 	glue.RegisterSolver(2020, 5, glue.LineSolver(solve))
 }
 
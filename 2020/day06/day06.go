@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 6, glue.Puzzle{
+		Title: "Custom Customs",
+		Tags:  []string{"bits", "strings"},
+	})
 	glue.RegisterSolver(2020, 6, glue.ChunkSolver(solve))
 }
 
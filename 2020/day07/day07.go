@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 7, glue.Puzzle{
+		Title: "Handy Haversacks",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2020, 7, glue.LineSolver(solve))
 	glue.RegisterPlotter(2020, 7, "", glue.LinePlotter(plotRules), map[string]string{"ex1": ex1, "ex2": ex2})
 }
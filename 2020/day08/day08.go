@@ -18,14 +18,17 @@ package day08
 import (
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
 
 	"github.com/fis/aoc/glue"
 	"github.com/fis/aoc/util/graph"
+	"github.com/fis/aoc/util/regvm"
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 8, glue.Puzzle{
+		Title: "Handheld Halting",
+		Tags:  []string{"vm"},
+	})
 	glue.RegisterSolver(2020, 8, glue.LineSolver(solve))
 	glue.RegisterPlotter(2020, 8, "", glue.LinePlotter(plotFlow), map[string]string{"ex": example})
 }
@@ -42,101 +45,67 @@ func solve(lines []string) ([]string, error) {
 	return glue.Ints(part1, part2), nil
 }
 
-type opcode int
-
+// The indices of the operations in the instruction set.
 const (
-	opAcc opcode = iota
+	opAcc = iota
 	opJmp
 	opNop
 )
 
-type instruction struct {
-	op  opcode
-	arg int
+type machine = regvm.Machine[int]
+
+// isa is the instruction set of the handheld game console. The environment of the machine is the
+// accumulator.
+var isa = &regvm.ISA[int]{
+	Regs: regvm.NamedRegs(),
+	Ops: []regvm.Op[int]{
+		opAcc: {Name: "acc", Args: "i", Exec: func(m *machine, a []regvm.Operand) { m.Env += a[0].Val }},
+		opJmp: {Name: "jmp", Args: "i", Exec: func(m *machine, a []regvm.Operand) { m.JumpRel(a[0].Val) }},
+		opNop: {Name: "nop", Args: "i", Exec: func(m *machine, a []regvm.Operand) {}},
+	},
 }
 
-func parseCode(lines []string) (out []instruction, err error) {
-	var mnemonics = map[string]opcode{"acc": opAcc, "jmp": opJmp, "nop": opNop}
-
-	for _, line := range lines {
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid instruction: %s", line)
-		}
-		op, ok := mnemonics[parts[0]]
-		if !ok {
-			return nil, fmt.Errorf("invalid opcode: %s", parts[0])
-		}
-		arg, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid argument: %s", parts[1])
-		}
-		out = append(out, instruction{op: op, arg: arg})
-	}
-
-	return out, nil
+func parseCode(lines []string) (*regvm.Prog[int], error) {
+	return isa.Parse(lines)
 }
 
-func loopCheck(code []instruction) (loop bool, acc int) {
-	seen := make([]bool, len(code))
-	for at := 0; at < len(code); {
-		if seen[at] {
-			return true, acc
-		}
-		seen[at] = true
-		switch code[at].op {
-		case opAcc:
-			acc += code[at].arg
-			at++
-		case opJmp:
-			at += code[at].arg
-		case opNop:
-			at++
-		}
-	}
-	return false, acc
+func loopCheck(prog *regvm.Prog[int]) (loop bool, acc int) {
+	m := regvm.NewMachine(prog, 0)
+	loop = m.RunLoopCheck()
+	return loop, m.Env
 }
 
-func repair(code []instruction) int {
+func repair(prog *regvm.Prog[int]) int {
 	type branch struct{ to, acc int }
 	var branches []branch
 
+	code := prog.Code
 	seen := make([]bool, len(code))
 
 	for at, acc := 0, 0; !seen[at]; {
 		seen[at] = true
-		switch code[at].op {
+		switch in := code[at]; in.Op {
 		case opAcc:
-			acc += code[at].arg
+			acc += in.Args[0].Val
 			at++
 		case opJmp:
 			branches = append(branches, branch{to: at + 1, acc: acc})
-			at += code[at].arg
+			at += in.Args[0].Val
 		case opNop:
-			branches = append(branches, branch{to: at + code[at].arg, acc: acc})
+			branches = append(branches, branch{to: at + in.Args[0].Val, acc: acc})
 			at++
 		}
 	}
 
 	for _, branch := range branches {
-		at, acc := branch.to, branch.acc
-		for {
-			if at >= len(code) {
-				return acc
-			}
-			if seen[at] {
-				break
-			}
-			seen[at] = true
-			switch code[at].op {
-			case opAcc:
-				acc += code[at].arg
-				at++
-			case opJmp:
-				at += code[at].arg
-			case opNop:
-				at++
-			}
+		m := regvm.NewMachine(prog, branch.acc)
+		m.IP = branch.to
+		for !m.Halted() && !seen[m.IP] {
+			seen[m.IP] = true
+			m.Step()
+		}
+		if m.Halted() {
+			return m.Env
 		}
 	}
 
@@ -155,30 +124,29 @@ acc +6
 `
 
 func plotFlow(lines []string, out io.Writer) error {
-	var mnemonics = map[opcode]string{opAcc: "acc", opJmp: "jmp", opNop: "nop"}
-
-	code, err := parseCode(lines)
+	prog, err := parseCode(lines)
 	if err != nil {
 		return err
 	}
+	code := prog.Code
 
 	gb := graph.NewBuilder()
 	verts := make([]int, len(code)+1)
 	for i, inst := range code {
-		verts[i] = gb.V(fmt.Sprintf("%d: %s %+d", i, mnemonics[inst.op], inst.arg))
+		verts[i] = gb.V(fmt.Sprintf("%d: %s %+d", i, isa.Ops[inst.Op].Name, inst.Args[0].Val))
 	}
 	verts[len(code)] = gb.V("halt")
 
 	for i, inst := range code {
-		switch inst.op {
+		switch arg := inst.Args[0].Val; inst.Op {
 		case opAcc:
 			gb.AddEdgeW(verts[i], verts[i+1], 0)
 		case opJmp:
-			gb.AddEdgeW(verts[i], verts[i+inst.arg], 0)
+			gb.AddEdgeW(verts[i], verts[i+arg], 0)
 			gb.AddEdgeW(verts[i], verts[i+1], 1)
 		case opNop:
 			gb.AddEdgeW(verts[i], verts[i+1], 0)
-			gb.AddEdgeW(verts[i], verts[i+inst.arg], 1)
+			gb.AddEdgeW(verts[i], verts[i+arg], 1)
 		}
 	}
 
@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 9, glue.Puzzle{
+		Title: "Encoding Error",
+		Tags:  []string{"combinatorics"},
+	})
 	glue.RegisterSolver(2020, 9, glue.IntSolver(solve))
 }
 
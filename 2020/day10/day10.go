@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 10, glue.Puzzle{
+		Title: "Adapter Array",
+		Tags:  []string{"dp"},
+	})
 	glue.RegisterSolver(2020, 10, glue.IntSolver(solve))
 }
 
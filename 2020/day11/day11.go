@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 11, glue.Puzzle{
+		Title: "Seating System",
+		Tags:  []string{"grid", "automaton"},
+	})
 	glue.RegisterSolver(2020, 11, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 12, glue.Puzzle{
+		Title: "Rain Risk",
+		Tags:  []string{"geometry", "simulation"},
+	})
 	glue.RegisterSolver(2020, 12, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 13, glue.Puzzle{
+		Title:         "Shuttle Search",
+		Tags:          []string{"number-theory"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2020, 13, glue.LineSolver(solve))
 }
 
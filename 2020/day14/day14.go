@@ -19,9 +19,14 @@ import (
 	"fmt"
 
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/regvm"
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 14, glue.Puzzle{
+		Title: "Docking Data",
+		Tags:  []string{"bits", "vm"},
+	})
 	glue.RegisterSolver(2020, 14, glue.LineSolver(solve))
 }
 
@@ -37,77 +42,99 @@ func solve(lines []string) ([]string, error) {
 	return glue.Ints(int(p1), int(p2)), nil
 }
 
-type opcode int
-
+// The indices of the operations in the instruction set.
 const (
-	opMask opcode = iota
-	opSet
+	opMask = iota
+	opMem
 )
 
-type instruction struct {
-	op         opcode
-	a1, a2, a3 uint
+type machine = regvm.Machine[*docker]
+
+// isa is the instruction set of the initialization program. The mask operation takes the bits of
+// its mask that are 0, 1 and X as three separate operands; the mem operation the address and value.
+var isa = &regvm.ISA[*docker]{
+	Regs: regvm.NamedRegs(),
+	Ops: []regvm.Op[*docker]{
+		opMask: {Name: "mask", Args: "iii", Exec: execMask},
+		opMem:  {Name: "mem", Args: "ii", Exec: execMem},
+	},
+	Syntax: syntax,
 }
 
-func parseCode(lines []string) (out []instruction, err error) {
-	for _, line := range lines {
-		var (
-			mask       string
-			a1, a2, a3 uint
-		)
-		if _, err := fmt.Sscanf(line, "mask = %s", &mask); err == nil {
-			a1, a2, a3 = 0, 0, 0
-			for i, c := range mask {
-				bit := uint(1) << (35 - i)
-				switch c {
-				case '0':
-					a1 |= bit
-				case '1':
-					a2 |= bit
-				case 'X':
-					a3 |= bit
-				}
-			}
-			out = append(out, instruction{op: opMask, a1: a1, a2: a2, a3: a3})
-		} else if _, err := fmt.Sscanf(line, "mem[%d] = %d", &a1, &a2); err == nil {
-			out = append(out, instruction{op: opSet, a1: a1, a2: a2, a3: 0})
-		} else {
-			return nil, fmt.Errorf("invalid instruction: %s", line)
-		}
+// docker is the environment of the machine: the memory of the docking program, the current mask,
+// and the version of the decoder chip interpreting it.
+type docker struct {
+	version             int
+	mem                 map[uint]uint
+	zeros, ones, floats uint
+	floatBits           []uint
+}
+
+func execMask(m *machine, a []regvm.Operand) {
+	d := m.Env
+	d.zeros, d.ones, d.floats = uint(a[0].Val), uint(a[1].Val), uint(a[2].Val)
+	if d.version == 2 {
+		d.floatBits = makeFloatBits(d.floats, d.floatBits)
 	}
-	return out, nil
 }
 
-func evaluate1(code []instruction) map[uint]uint {
-	mem := make(map[uint]uint)
-	orMask, andMask := uint(0), ^uint(0)
-	for _, inst := range code {
-		switch inst.op {
-		case opMask:
-			orMask, andMask = inst.a2, ^inst.a1
-		case opSet:
-			mem[inst.a1] = (inst.a2 | orMask) & andMask
-		}
+func execMem(m *machine, a []regvm.Operand) {
+	d, addr, val := m.Env, uint(a[0].Val), uint(a[1].Val)
+	if d.version == 1 {
+		d.mem[addr] = (val | d.ones) &^ d.zeros
+		return
+	}
+	base := (addr | d.ones) &^ d.floats
+	for _, f := range d.floatBits {
+		d.mem[base|f] = val
 	}
-	return mem
 }
 
-func evaluate2(code []instruction) map[uint]uint {
-	mem := make(map[uint]uint)
-	orMask, floatMask, floatBits := uint(0), ^uint(0), []uint{}
-	for _, inst := range code {
-		switch inst.op {
-		case opMask:
-			orMask, floatMask = inst.a2, ^inst.a3
-			floatBits = makeFloatBits(inst.a3, floatBits)
-		case opSet:
-			baseAddr := (inst.a1 | orMask) & floatMask
-			for _, f := range floatBits {
-				mem[baseAddr|f] = inst.a2
+// syntax splits a line of the program into the operation and its operands.
+func syntax(line string) (name string, args []string, err error) {
+	var (
+		mask    string
+		addr, v uint
+		bits    [3]uint
+	)
+	if _, err := fmt.Sscanf(line, "mask = %s", &mask); err == nil {
+		for i, c := range mask {
+			bit := uint(1) << (len(mask) - 1 - i)
+			switch c {
+			case '0':
+				bits[0] |= bit
+			case '1':
+				bits[1] |= bit
+			case 'X':
+				bits[2] |= bit
+			default:
+				return "", nil, fmt.Errorf("invalid mask: %s", mask)
 			}
 		}
+		return "mask", []string{fmt.Sprint(bits[0]), fmt.Sprint(bits[1]), fmt.Sprint(bits[2])}, nil
+	} else if _, err := fmt.Sscanf(line, "mem[%d] = %d", &addr, &v); err == nil {
+		return "mem", []string{fmt.Sprint(addr), fmt.Sprint(v)}, nil
 	}
-	return mem
+	return "", nil, fmt.Errorf("invalid instruction: %s", line)
+}
+
+func parseCode(lines []string) (*regvm.Prog[*docker], error) {
+	return isa.Parse(lines)
+}
+
+func evaluate1(code *regvm.Prog[*docker]) map[uint]uint {
+	return evaluate(code, 1)
+}
+
+func evaluate2(code *regvm.Prog[*docker]) map[uint]uint {
+	return evaluate(code, 2)
+}
+
+// evaluate runs the program with the given version of the decoder chip, and returns the memory.
+func evaluate(code *regvm.Prog[*docker], version int) map[uint]uint {
+	d := &docker{version: version, mem: make(map[uint]uint)}
+	regvm.NewMachine(code, d).Run()
+	return d.mem
 }
 
 func makeFloatBits(mask uint, buf []uint) []uint {
@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 15, glue.Puzzle{
+		Title: "Rambunctious Recitation",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2020, 15, glue.IntSolver(solve))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 16, glue.Puzzle{
+		Title: "Ticket Translation",
+		Tags:  []string{"parsing", "combinatorics"},
+	})
 	glue.RegisterSolver(2020, 16, glue.ChunkSolver(solve))
 }
 
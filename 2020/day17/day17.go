@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 17, glue.Puzzle{
+		Title: "Conway Cubes",
+		Tags:  []string{"automaton"},
+	})
 	glue.RegisterSolver(2020, 17, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 18, glue.Puzzle{
+		Title: "Operation Order",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2020, 18, glue.LineSolver(solve))
 }
 
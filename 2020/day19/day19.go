@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 19, glue.Puzzle{
+		Title: "Monster Messages",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2020, 19, glue.ChunkSolver(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 20, glue.Puzzle{
+		Title: "Jurassic Jigsaw",
+		Tags:  []string{"grid", "combinatorics"},
+	})
 	glue.RegisterSolver(2020, 20, glue.ChunkSolver(solve))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 21, glue.Puzzle{
+		Title: "Allergen Assessment",
+		Tags:  []string{"combinatorics"},
+	})
 	glue.RegisterSolver(2020, 21, glue.LineSolver(solve))
 }
 
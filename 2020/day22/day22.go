@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 22, glue.Puzzle{
+		Title: "Crab Combat",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2020, 22, glue.ChunkSolver(solve))
 }
 
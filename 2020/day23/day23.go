@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 23, glue.Puzzle{
+		Title: "Crab Cups",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2020, 23, glue.LineSolver(solve))
 }
 
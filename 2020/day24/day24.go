@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2020, 24, glue.Puzzle{
+		Title: "Lobby Layout",
+		Tags:  []string{"geometry", "automaton"},
+	})
 	glue.RegisterSolver(2020, 24, glue.LineSolver(solve))
 }
 
@@ -17,21 +17,33 @@ package day25
 
 import (
 	"fmt"
+	"io"
 	"math"
+	"math/rand"
 
 	"github.com/fis/aoc/glue"
 )
 
 func init() {
-	glue.RegisterSolver(2020, 25, glue.IntSolver(solve))
+	glue.RegisterPuzzle(2020, 25, glue.Puzzle{
+		Title: "Combo Breaker",
+		Tags:  []string{"number-theory"},
+	})
+	glue.RegisterSolver(2020, 25, solver(pohligHellman))
+	glue.RegisterVariant(2020, 25, "trialMultiplication", solver(trialMultiplication))
+	glue.RegisterVariant(2020, 25, "babyStep", solver(babyStep))
+	glue.RegisterVariant(2020, 25, "pohligHellman", solver(pohligHellman))
+	glue.RegisterGenerator(2020, 25, glue.GeneratorFunc(generate))
 }
 
-func solve(input []int) ([]string, error) {
-	if len(input) != 2 {
-		return nil, fmt.Errorf("expected two numbers, got %d", len(input))
-	}
-	key := findKey(input[0], input[1], pohligHellman)
-	return glue.Ints(key), nil
+func solver(log func(b, a, m int) int) glue.Solver {
+	return glue.IntSolver(func(input []int) ([]string, error) {
+		if len(input) != 2 {
+			return nil, fmt.Errorf("expected two numbers, got %d", len(input))
+		}
+		key := findKey(input[0], input[1], log)
+		return glue.Ints(key), nil
+	})
 }
 
 func findKey(pub1, pub2 int, log func(b, a, m int) int) (key int) {
@@ -109,3 +121,12 @@ func pow(b, e, m int) (p int) {
 	}
 	return p
 }
+
+// input generator
+
+// generate writes the public keys of a card and a door with random loop sizes up to 1000*size.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	card, door := 1+rng.Intn(1000*size), 1+rng.Intn(1000*size)
+	_, err := fmt.Fprintf(w, "%d\n%d\n", pow(7, card, 20201227), pow(7, door, 20201227))
+	return err
+}
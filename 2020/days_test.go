@@ -24,6 +24,18 @@ func TestAllDays(t *testing.T) {
 	glue.RunTests(t, "../testdata", 2020)
 }
 
+func TestVariants(t *testing.T) {
+	glue.RunVariantTests(t, "../testdata", 2020)
+}
+
+func TestGenerated(t *testing.T) {
+	glue.RunGeneratedTests(t, 2020)
+}
+
+func TestCatalogue(t *testing.T) {
+	glue.RunCatalogueTests(t, 2020)
+}
+
 func BenchmarkAllDays(b *testing.B) {
 	glue.RunBenchmarks(b, "../testdata", 2020)
 }
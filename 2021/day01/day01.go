@@ -18,6 +18,9 @@ package day01
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2021, 1, glue.Puzzle{
+		Title: "Sonar Sweep",
+	})
 	glue.RegisterSolver(2021, 1, glue.IntSolver(solve))
 }
 
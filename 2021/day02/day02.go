@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 2, glue.Puzzle{
+		Title: "Dive!",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2021, 2, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^(forward|down|up) (\d+)$`,
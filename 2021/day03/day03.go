@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 3, glue.Puzzle{
+		Title: "Binary Diagnostic",
+		Tags:  []string{"bits"},
+	})
 	glue.RegisterSolver(2021, 3, glue.LineSolver(solve))
 }
 
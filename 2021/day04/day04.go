@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 4, glue.Puzzle{
+		Title: "Giant Squid",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2021, 4, glue.ChunkSolver(solve))
 }
 
@@ -16,6 +16,9 @@
 package day05
 
 import (
+	"fmt"
+	"io"
+	"math/rand"
 	"strconv"
 
 	"github.com/fis/aoc/glue"
@@ -26,18 +29,30 @@ import (
 const inputRegexp = `^(\d+),(\d+) -> (\d+),(\d+)$`
 
 func init() {
-	glue.RegisterSolver(2021, 5, glue.RegexpSolver{
-		Solver: solve,
-		Regexp: inputRegexp,
+	glue.RegisterPuzzle(2021, 5, glue.Puzzle{
+		Title: "Hydrothermal Venture",
+		Tags:  []string{"grid", "geometry"},
 	})
+	glue.RegisterSolver(2021, 5, solver(hvOverlapsArray, hvdOverlapsTypewise))
+	// The variants are named after the part that differs from the default solver.
+	glue.RegisterVariant(2021, 5, "array", solver(hvOverlapsArray, hvdOverlapsArray))
+	glue.RegisterVariant(2021, 5, "counting", solver(hvOverlapsCounting, hvdOverlapsTypewise))
+	glue.RegisterVariant(2021, 5, "pairwise", solver(hvOverlapsPairwise, hvdOverlapsTypewise))
+	glue.RegisterVariant(2021, 5, "typewise", solver(hvOverlapsArray, hvdOverlapsTypewise))
+	glue.RegisterGenerator(2021, 5, glue.GeneratorFunc(generate))
 }
 
-func solve(input [][]string) ([]string, error) {
-	lines := parseInput(input)
-	canonicalize(lines)
-	p1 := hvOverlapsArray(lines)
-	p2 := hvdOverlapsTypewise(lines)
-	return glue.Ints(p1, p2), nil
+func solver(hvOverlaps, hvdOverlaps func([][2]util.P) int) glue.Solver {
+	return glue.RegexpSolver{
+		Solver: func(input [][]string) ([]string, error) {
+			lines := parseInput(input)
+			canonicalize(lines)
+			p1 := hvOverlaps(lines)
+			p2 := hvdOverlaps(lines)
+			return glue.Ints(p1, p2), nil
+		},
+		Regexp: inputRegexp,
+	}
 }
 
 // Before you look below: yes, the "pairwise" function is entirely ridiculous, and you should have
@@ -300,3 +315,44 @@ func canonicalize(lines [][2]util.P) {
 		}
 	}
 }
+
+// input generator
+
+// generate writes the given number of random horizontal, vertical and diagonal lines. The grid is
+// kept small relative to the number of lines, so there's plenty of overlap.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	gridSize := 5*size + 5
+	for i := 0; i < size; i++ {
+		var p1, p2 util.P
+		switch rng.Intn(3) {
+		case 0:
+			p1 = util.P{rng.Intn(gridSize), rng.Intn(gridSize)}
+			p2 = util.P{(p1.X + 1 + rng.Intn(gridSize-1)) % gridSize, p1.Y}
+		case 1:
+			p1 = util.P{rng.Intn(gridSize), rng.Intn(gridSize)}
+			p2 = util.P{p1.X, (p1.Y + 1 + rng.Intn(gridSize-1)) % gridSize}
+		default:
+			// pick a diagonal through a random point, and a random segment of it
+			var d util.P
+			var room int
+			for room < 2 {
+				p1, d = util.P{rng.Intn(gridSize), rng.Intn(gridSize)}, util.P{1 - 2*rng.Intn(2), 1 - 2*rng.Intn(2)}
+				room = 1 + min(extent(p1.X, d.X, gridSize), extent(p1.Y, d.Y, gridSize))
+			}
+			n := 1 + rng.Intn(room-1)
+			p2 = util.P{p1.X + n*d.X, p1.Y + n*d.Y}
+		}
+		if _, err := fmt.Fprintf(w, "%d,%d -> %d,%d\n", p1.X, p1.Y, p2.X, p2.Y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extent returns how many steps there are from x in direction d (1 or -1) before leaving [0, n).
+func extent(x, d, n int) int {
+	if d > 0 {
+		return n - 1 - x
+	}
+	return x
+}
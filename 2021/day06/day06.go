@@ -18,6 +18,10 @@ package day06
 import "github.com/fis/aoc/glue"
 
 func init() {
+	glue.RegisterPuzzle(2021, 6, glue.Puzzle{
+		Title: "Lanternfish",
+		Tags:  []string{"simulation", "dp"},
+	})
 	glue.RegisterSolver(2021, 6, glue.IntSolver(solve))
 }
 
@@ -16,8 +16,12 @@
 package day07
 
 import (
+	"fmt"
+	"io"
 	"math"
+	"math/rand"
 	"sort"
+	"strings"
 
 	"github.com/fis/aoc/glue"
 	"github.com/fis/aoc/util"
@@ -25,13 +29,24 @@ import (
 )
 
 func init() {
-	glue.RegisterSolver(2021, 7, glue.IntSolver(solve))
+	glue.RegisterPuzzle(2021, 7, glue.Puzzle{
+		Title: "The Treachery of Whales",
+		Tags:  []string{"geometry"},
+	})
+	glue.RegisterSolver(2021, 7, solver(align1MedianQS, align2Mean))
+	glue.RegisterVariant(2021, 7, "bruteForce", solver(align1BruteForce, align2BruteForce))
+	glue.RegisterVariant(2021, 7, "points", solver(align1Points, align2Mean))
+	glue.RegisterVariant(2021, 7, "medianSort", solver(align1MedianSort, align2Mean))
+	glue.RegisterVariant(2021, 7, "medianQS", solver(align1MedianQS, align2Mean))
+	glue.RegisterGenerator(2021, 7, glue.GeneratorFunc(generate))
 }
 
-func solve(input []int) ([]string, error) {
-	_, p1 := align1MedianQS(input)
-	_, p2 := align2Mean(input)
-	return glue.Ints(p1, p2), nil
+func solver(align1, align2 func(input []int) (x, cost int)) glue.Solver {
+	return glue.IntSolver(func(input []int) ([]string, error) {
+		_, p1 := align1(input)
+		_, p2 := align2(input)
+		return glue.Ints(p1, p2), nil
+	})
 }
 
 func align(input []int, f func(n, x int) int) (x, cost int) {
@@ -55,6 +70,9 @@ func cost2(n, x int) int {
 	return d * (d + 1) / 2
 }
 
+func align1BruteForce(input []int) (x, cost int) { return align(input, cost1) }
+func align2BruteForce(input []int) (x, cost int) { return align(input, cost2) }
+
 func align1Points(input []int) (x, cost int) {
 	min, max := bounds(input)
 	costs := make([]int, max-min+1)
@@ -136,3 +154,15 @@ func argmin(input []int) (minI, minN int) {
 	}
 	return minI, minN
 }
+
+// input generator
+
+// generate writes the positions of the given number of crabs, spread over a range 100 times that.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	crabs := make([]string, size)
+	for i := range crabs {
+		crabs[i] = fmt.Sprint(rng.Intn(100 * size))
+	}
+	_, err := fmt.Fprintf(w, "%s\n", strings.Join(crabs, ","))
+	return err
+}
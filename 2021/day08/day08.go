@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 8, glue.Puzzle{
+		Title: "Seven Segment Search",
+		Tags:  []string{"bits", "combinatorics"},
+	})
 	glue.RegisterSolver(2021, 8, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^([a-g]+(?: [a-g]+)*) \| ([a-g]+(?: [a-g]+)*)$`,
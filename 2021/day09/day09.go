@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 9, glue.Puzzle{
+		Title: "Smoke Basin",
+		Tags:  []string{"grid", "graph"},
+	})
 	glue.RegisterSolver(2021, 9, glue.LevelSolver{
 		Solver: solve,
 		Empty:  ' ',
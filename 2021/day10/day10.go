@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 10, glue.Puzzle{
+		Title: "Syntax Scoring",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2021, 10, glue.LineSolver(solve))
 }
 
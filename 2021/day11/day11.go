@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 11, glue.Puzzle{
+		Title: "Dumbo Octopus",
+		Tags:  []string{"grid", "automaton"},
+	})
 	glue.RegisterSolver(2021, 11, glue.LineSolver(solve))
 }
 
@@ -28,6 +28,10 @@ import (
 const inputRegexp = `^([^-]+)-([^-]+)$`
 
 func init() {
+	glue.RegisterPuzzle(2021, 12, glue.Puzzle{
+		Title: "Passage Pathing",
+		Tags:  []string{"graph", "combinatorics"},
+	})
 	glue.RegisterSolver(2021, 12, glue.RegexpSolver{Solver: solve, Regexp: inputRegexp})
 	glue.RegisterPlotter(2021, 12, "", glue.LinePlotter(plot), map[string]string{
 		"ex1": edgesToText(ex1),
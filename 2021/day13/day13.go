@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 13, glue.Puzzle{
+		Title: "Transparent Origami",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2021, 13, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^(?:(\d+),(\d+)|fold along ([xy])=(\d+)|)$`,
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 14, glue.Puzzle{
+		Title: "Extended Polymerization",
+		Tags:  []string{"strings", "dp"},
+	})
 	glue.RegisterSolver(2021, 14, glue.LineSolver(solve))
 }
 
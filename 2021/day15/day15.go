@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 15, glue.Puzzle{
+		Title: "Chiton",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2021, 15, glue.LineSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 16, glue.Puzzle{
+		Title: "Packet Decoder",
+		Tags:  []string{"parsing", "bits"},
+	})
 	glue.RegisterSolver(2021, 16, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 17, glue.Puzzle{
+		Title:         "Trick Shot",
+		Tags:          []string{"geometry", "simulation"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2021, 17, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^target area: x=(-?\d+)\.\.(-?\d+), y=(-?\d+)\.\.(-?\d+)$`,
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 18, glue.Puzzle{
+		Title: "Snailfish",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2021, 18, glue.LineSolver(solve))
 }
 
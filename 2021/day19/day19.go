@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 19, glue.Puzzle{
+		Title: "Beacon Scanner",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2021, 19, glue.ChunkSolver(solve))
 }
 
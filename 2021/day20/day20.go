@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 20, glue.Puzzle{
+		Title: "Trench Map",
+		Tags:  []string{"grid", "automaton"},
+	})
 	glue.RegisterSolver(2021, 20, glue.ChunkSolver(solve))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 21, glue.Puzzle{
+		Title: "Dirac Dice",
+		Tags:  []string{"dp", "simulation"},
+	})
 	glue.RegisterSolver(2021, 21, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: `^Player ([12]) starting position: (\d+)$`,
@@ -25,6 +25,10 @@ import (
 const inputRegexp = `^(on|off) x=(-?\d+)\.\.(-?\d+),y=(-?\d+)\.\.(-?\d+),z=(-?\d+)\.\.(-?\d+)`
 
 func init() {
+	glue.RegisterPuzzle(2021, 22, glue.Puzzle{
+		Title: "Reactor Reboot",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2021, 22, glue.RegexpSolver{
 		Solver: solve,
 		Regexp: inputRegexp,
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 23, glue.Puzzle{
+		Title: "Amphipod",
+		Tags:  []string{"pathfinding"},
+	})
 	glue.RegisterSolver(2021, 23, glue.LineSolver(solve))
 }
 
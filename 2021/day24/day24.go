@@ -16,20 +16,40 @@
 package day24
 
 import (
-	"strconv"
-	"strings"
+	"errors"
+	"fmt"
 
 	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util/fn"
+	"github.com/fis/aoc/util/regvm"
+	"github.com/fis/aoc/util/symex"
 )
 
 func init() {
+	glue.RegisterPuzzle(2021, 24, glue.Puzzle{
+		Title:         "Arithmetic Logic Unit",
+		Tags:          []string{"vm"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2021, 24, glue.LineSolver(solve))
 }
 
 func solve(lines []string) ([]string, error) {
-	coeffs := parseCoeffs(lines)
+	prog, err := isa.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := analyzeCoeffs(prog)
+	if err != nil {
+		return nil, err
+	}
 	p1 := coeffs.findValid(true)
 	p2 := coeffs.findValid(false)
+	for _, num := range []string{p1, p2} {
+		if z := runMonad(prog, num); z != 0 {
+			return nil, fmt.Errorf("model number %s rejected: z = %d", num, z)
+		}
+	}
 	return []string{p1, p2}, nil
 }
 
@@ -82,16 +102,156 @@ type monadCoeff struct {
 
 type monadCoeffs [ndigits]monadCoeff
 
-func parseCoeffs(lines []string) (coeffs monadCoeffs) {
-	for i := 0; i < ndigits; i++ {
-		base := i * 18
-		if words := strings.Split(lines[base+4], " "); words[2] == "26" {
-			coeffs[i].pop = true
+// analyzeCoeffs extracts the coefficients of the MONAD program by executing each of its 14 blocks
+// (one per input digit) symbolically, and matching the result against the expected forms: either
+// z' = 26z + w + b (push), or z' = (z%26 + a == w) ? z/26 : 26(z/26) + w + b (pop).
+func analyzeCoeffs(prog *regvm.Prog[[]int]) (coeffs monadCoeffs, err error) {
+	var starts []int
+	for ip, in := range prog.Code {
+		if in.Op == opInp {
+			starts = append(starts, ip)
+		}
+	}
+	if len(starts) != ndigits || starts[0] != 0 {
+		return monadCoeffs{}, fmt.Errorf("expected %d blocks, got %d", ndigits, len(starts))
+	}
+	p := &symex.Program{Code: prog.Code, Sem: sem, NumRegs: prog.NumRegs()}
+	for i, start := range starts {
+		end := len(prog.Code)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		w := prog.Code[start].Args[0].Val
+		s, err := p.Straight(p.Entry(start+1), start+1, end)
+		if err != nil {
+			return monadCoeffs{}, fmt.Errorf("block %d: %w", i, err)
+		}
+		if coeffs[i], err = analyzeBlock(s.Regs[regZ], w); err != nil {
+			return monadCoeffs{}, fmt.Errorf("block %d: %w", i, err)
+		}
+	}
+	return coeffs, nil
+}
+
+// analyzeBlock matches the symbolic result of a single block against the expected forms. The w
+// register holds the input digit.
+func analyzeBlock(z *symex.Expr, w int) (c monadCoeff, err error) {
+	ranges := func(id int) symex.Interval {
+		switch id {
+		case w:
+			return symex.Interval{Lo: 1, Hi: 9}
+		case regZ:
+			return symex.Interval{Lo: 0, Hi: 1 << 40}
 		}
-		words := strings.Split(lines[base+5], " ")
-		coeffs[i].a, _ = strconv.Atoi(words[2])
-		words = strings.Split(lines[base+15], " ")
-		coeffs[i].b, _ = strconv.Atoi(words[2])
+		return symex.Full
+	}
+	z = symex.Simplify(z, ranges)
+	W, Z := symex.V(w), symex.V(regZ)
+	cond := findCond(z, w)
+	if cond == nil {
+		// push: z' = 26z + w + b
+		c.b, err = matchPush(z, W, Z)
+		return c, err
+	}
+	// pop: cond is (z%26 - w == -a), possibly moved around
+	k, rest, ok := symex.Linear(symex.Minus(cond.Args[0], cond.Args[1]), W)
+	if !ok || !symex.Equal(k, symex.C(-1)) {
+		return c, fmt.Errorf("unexpected condition: %v", cond)
 	}
-	return coeffs
+	mod := symex.Rem(Z, symex.C(base))
+	k, a, ok := symex.Linear(rest, mod)
+	if !ok || !symex.Equal(k, symex.C(1)) {
+		return c, fmt.Errorf("unexpected condition: %v", cond)
+	}
+	if c.a, ok = a.IsConst(); !ok {
+		return c, fmt.Errorf("unexpected condition: %v", cond)
+	}
+	zMatch := symex.Simplify(symex.Replace(z, cond, symex.C(1)), ranges)
+	zMiss := symex.Simplify(symex.Replace(z, cond, symex.C(0)), ranges)
+	q := symex.Quo(Z, symex.C(base))
+	if !symex.Equal(zMatch, q) {
+		return c, fmt.Errorf("unexpected result on match: %v", zMatch)
+	}
+	c.pop = true
+	c.b, err = matchPush(zMiss, W, q)
+	return c, err
+}
+
+// base is the base of the numbers stored in the z register, which the program uses as a stack.
+const base = 26
+
+// findCond returns the innermost comparison involving the w register.
+func findCond(e *symex.Expr, w int) *symex.Expr {
+	for _, arg := range e.Args {
+		if c := findCond(arg, w); c != nil {
+			return c
+		}
+	}
+	if e.Kind == symex.Eq && e.Uses(w) {
+		return e
+	}
+	return nil
+}
+
+// matchPush matches the expression against 26z + w + b, and returns b.
+func matchPush(e, w, z *symex.Expr) (int, error) {
+	k, rest, ok := symex.Linear(e, w)
+	if !ok || !symex.Equal(k, symex.C(1)) {
+		return 0, fmt.Errorf("unexpected push: %v", e)
+	}
+	k, b, ok := symex.Linear(rest, z)
+	if !ok || !symex.Equal(k, symex.C(base)) {
+		return 0, fmt.Errorf("unexpected push: %v", e)
+	}
+	if bv, ok := b.IsConst(); ok {
+		return bv, nil
+	}
+	return 0, errors.New("unexpected push: " + e.String())
+}
+
+// runMonad executes the MONAD program on the digits of a model number, and returns the final value
+// of the z register.
+func runMonad(prog *regvm.Prog[[]int], num string) int {
+	digits := make([]int, len(num))
+	for i, d := range num {
+		digits[i] = int(d - '0')
+	}
+	m := regvm.NewMachine(prog, digits)
+	m.Run()
+	return m.Regs[regZ]
+}
+
+type alu = regvm.Machine[[]int]
+
+const regZ = 3
+
+const opInp = 0
+
+// isa is the instruction set of the ALU. The environment of the machine holds the remaining input.
+var isa = &regvm.ISA[[]int]{
+	Regs: regvm.NamedRegs("w", "x", "y", "z"),
+	Ops: []regvm.Op[[]int]{
+		{Name: "inp", Args: "r", Exec: func(m *alu, a []regvm.Operand) {
+			m.Set(a[0], m.Env[0])
+			m.Env = m.Env[1:]
+		}},
+		{Name: "add", Args: "rv", Exec: func(m *alu, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])+m.Get(a[1])) }},
+		{Name: "mul", Args: "rv", Exec: func(m *alu, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])*m.Get(a[1])) }},
+		{Name: "div", Args: "rv", Exec: func(m *alu, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])/m.Get(a[1])) }},
+		{Name: "mod", Args: "rv", Exec: func(m *alu, a []regvm.Operand) { m.Set(a[0], m.Get(a[0])%m.Get(a[1])) }},
+		{Name: "eql", Args: "rv", Exec: func(m *alu, a []regvm.Operand) {
+			m.Set(a[0], fn.If(m.Get(a[0]) == m.Get(a[1]), 1, 0))
+		}},
+	},
+}
+
+// sem describes the ALU instructions symbolically. The input instruction is not supported, as it's
+// handled by splitting the program to blocks.
+var sem = symex.Semantics{
+	nil,
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Plus(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Times(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Quo(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Rem(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *symex.State, a []regvm.Operand) { s.Set(a[0], symex.Equals(s.Get(a[0]), s.Get(a[1]))) },
 }
@@ -0,0 +1,71 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package day24
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/util/symex"
+)
+
+const blockTemplate = `inp w
+mul x 0
+add x z
+mod x 26
+div z %d
+add x %d
+eql x w
+eql x 0
+mul y 0
+add y 25
+mul y x
+add y 1
+mul z y
+mul y 0
+add y w
+add y %d
+mul y x
+add z y`
+
+func TestAnalyzeBlock(t *testing.T) {
+	tests := []struct {
+		div, a, b int
+		want      monadCoeff
+	}{
+		{div: 1, a: 12, b: 4, want: monadCoeff{pop: false, b: 4}},
+		{div: 1, a: 15, b: 11, want: monadCoeff{pop: false, b: 11}},
+		{div: 26, a: -7, b: 13, want: monadCoeff{pop: true, a: -7, b: 13}},
+		{div: 26, a: 0, b: 2, want: monadCoeff{pop: true, a: 0, b: 2}},
+	}
+	for _, test := range tests {
+		prog, err := isa.Parse(strings.Split(fmt.Sprintf(blockTemplate, test.div, test.a, test.b), "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &symex.Program{Code: prog.Code, Sem: sem, NumRegs: prog.NumRegs()}
+		s, err := p.Straight(p.Entry(1), 1, len(prog.Code))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := analyzeBlock(s.Regs[regZ], 0)
+		if err != nil {
+			t.Errorf("analyzeBlock(%d, %d, %d): %v", test.div, test.a, test.b, err)
+		} else if got != test.want {
+			t.Errorf("analyzeBlock(%d, %d, %d) = %+v, want %+v", test.div, test.a, test.b, got, test.want)
+		}
+	}
+}
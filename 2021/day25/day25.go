@@ -20,13 +20,21 @@ import (
 )
 
 func init() {
-	glue.RegisterSolver(2021, 25, glue.LineSolver(solve))
+	glue.RegisterPuzzle(2021, 25, glue.Puzzle{
+		Title: "Sea Cucumber",
+		Tags:  []string{"grid", "automaton"},
+	})
+	glue.RegisterSolver(2021, 25, solver(func(lines []string) int { return simulateBits(parseInputBits(lines)) }))
+	glue.RegisterVariant(2021, 25, "byteCopying", solver(func(lines []string) int { return simulateCopying(parseInput(lines)) }))
+	glue.RegisterVariant(2021, 25, "byteInplace", solver(func(lines []string) int { return simulateInplace(parseInput(lines)) }))
+	glue.RegisterVariant(2021, 25, "bits", solver(func(lines []string) int { return simulateBits(parseInputBits(lines)) }))
 }
 
-func solve(lines []string) ([]string, error) {
-	sf := parseInputBits(lines)
-	p1 := simulateBits(sf)
-	return glue.Ints(p1), nil
+func solver(simulate func(lines []string) int) glue.Solver {
+	return glue.LineSolver(func(lines []string) ([]string, error) {
+		p1 := simulate(lines)
+		return glue.Ints(p1), nil
+	})
 }
 
 type seaCumber byte
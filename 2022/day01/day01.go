@@ -24,6 +24,9 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 1, glue.Puzzle{
+		Title: "Calorie Counting",
+	})
 	glue.RegisterSolver(2022, 1, glue.ChunkSolver(solve))
 }
 
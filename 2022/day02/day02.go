@@ -21,6 +21,9 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 2, glue.Puzzle{
+		Title: "Rock Paper Scissors",
+	})
 	glue.RegisterSolver(2022, 2, glue.LineSolver(solve))
 }
 
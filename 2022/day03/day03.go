@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 3, glue.Puzzle{
+		Title: "Rucksack Reorganization",
+		Tags:  []string{"strings", "bits"},
+	})
 	glue.RegisterSolver(2022, 3, glue.LineSolver(glue.WithParser(pack, solve)))
 }
 
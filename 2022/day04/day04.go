@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 4, glue.Puzzle{
+		Title: "Camp Cleanup",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2022, 4, glue.LineSolver(glue.WithParser(parsePair, solve)))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 5, glue.Puzzle{
+		Title: "Supply Stacks",
+		Tags:  []string{"simulation", "parsing"},
+	})
 	glue.RegisterSolver(2022, 5, glue.LineSolver(solve))
 }
 
@@ -17,23 +17,34 @@ package day06
 
 import (
 	"fmt"
+	"io"
 	"math/bits"
+	"math/rand"
 
 	"github.com/fis/aoc/glue"
 )
 
 func init() {
-	glue.RegisterSolver(2022, 6, glue.LineSolver(solve))
+	glue.RegisterPuzzle(2022, 6, glue.Puzzle{
+		Title: "Tuning Trouble",
+		Tags:  []string{"strings"},
+	})
+	glue.RegisterSolver(2022, 6, solver(findMarkerWindowed))
+	glue.RegisterVariant(2022, 6, "bitset", solver(findMarkerBitset))
+	glue.RegisterVariant(2022, 6, "windowed", solver(findMarkerWindowed))
+	glue.RegisterGenerator(2022, 6, glue.GeneratorFunc(generate))
 }
 
-func solve(lines []string) ([]string, error) {
-	if n := len(lines); n != 1 {
-		return nil, fmt.Errorf("expected 1 line, got %d", n)
-	}
-	sig := lines[0]
-	packet := findMarkerWindowed(sig, 4)
-	msg := packet - 4 + findMarkerWindowed(sig[packet-4:], 14)
-	return glue.Ints(packet, msg), nil
+func solver(findMarker func(sig string, size int) int) glue.Solver {
+	return glue.LineSolver(func(lines []string) ([]string, error) {
+		if n := len(lines); n != 1 {
+			return nil, fmt.Errorf("expected 1 line, got %d", n)
+		}
+		sig := lines[0]
+		packet := findMarker(sig, 4)
+		msg := packet - 4 + findMarker(sig[packet-4:], 14)
+		return glue.Ints(packet, msg), nil
+	})
 }
 
 func findMarkerBitset(sig string, size int) int {
@@ -80,3 +91,26 @@ func findMarkerWindowed(sig string, size int) int {
 	}
 	return -1
 }
+
+// input generator
+
+// generate writes a random signal of about 30*size characters. It starts with a stretch drawn from
+// only 3 letters, so there's no early start-of-packet marker; then a stretch from 13 letters, so
+// there's no start-of-message marker; and then a run of all 14 distinct letters to guarantee one.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	letters := rng.Perm(26)
+	var sig []byte
+	for _, part := range []struct{ n, alphabet int }{{10 * size, 3}, {10 * size, 13}} {
+		for i := 0; i < part.n; i++ {
+			sig = append(sig, 'a'+byte(letters[rng.Intn(part.alphabet)]))
+		}
+	}
+	for _, i := range rng.Perm(14) {
+		sig = append(sig, 'a'+byte(letters[i]))
+	}
+	for i := 0; i < 10*size; i++ {
+		sig = append(sig, 'a'+byte(rng.Intn(26)))
+	}
+	_, err := fmt.Fprintf(w, "%s\n", sig)
+	return err
+}
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 7, glue.Puzzle{
+		Title: "No Space Left On Device",
+		Tags:  []string{"parsing", "graph"},
+	})
 	glue.RegisterSolver(2022, 7, glue.LineSolver(solve))
 }
 
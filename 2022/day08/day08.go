@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 8, glue.Puzzle{
+		Title: "Treetop Tree House",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2022, 8, glue.LineSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 9, glue.Puzzle{
+		Title: "Rope Bridge",
+		Tags:  []string{"geometry", "simulation"},
+	})
 	glue.RegisterSolver(2022, 9, glue.LineSolver(glue.WithParser(parseMove, solve)))
 }
 
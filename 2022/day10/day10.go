@@ -16,72 +16,78 @@
 package day10
 
 import (
-	"fmt"
-	"strconv"
-	"strings"
-
 	"github.com/fis/aoc/glue"
 	"github.com/fis/aoc/util/fn"
+	"github.com/fis/aoc/util/regvm"
 )
 
 func init() {
-	glue.RegisterSolver(2022, 10, glue.LineSolver(glue.WithParser(parseInstruction, solve)))
+	glue.RegisterPuzzle(2022, 10, glue.Puzzle{
+		Title: "Cathode-Ray Tube",
+		Tags:  []string{"vm"},
+	})
+	glue.RegisterSolver(2022, 10, glue.LineSolver(solve))
 }
 
-func solve(prog []instruction) ([]string, error) {
+func solve(lines []string) ([]string, error) {
+	prog, err := isa.Parse(lines)
+	if err != nil {
+		return nil, err
+	}
 	p1 := sigStrength(prog)
 	img := render(prog)
 	return append(glue.Ints(p1), img...), nil
 }
 
-func sigStrength(prog []instruction) (strength int) {
+func sigStrength(prog *regvm.Prog[struct{}]) (strength int) {
 	const (
-		sampleCycle = 19
+		sampleCycle = 20
 		sampleMod   = 40
 	)
-	cycle, x := 0, 1
-	for _, inst := range prog {
-		c := cycle % sampleMod
-		if c <= sampleCycle && c+inst.cycles > sampleCycle {
-			strength += (cycle - c + sampleCycle + 1) * x
+	eachCycle(prog, func(cycle, x int) {
+		if cycle%sampleMod == sampleCycle {
+			strength += cycle * x
 		}
-		cycle, x = cycle+inst.cycles, x+inst.addend
-	}
+	})
 	return strength
 }
 
-func render(prog []instruction) []string {
+func render(prog *regvm.Prog[struct{}]) []string {
 	const (
 		W = 40
 		H = 6
 	)
 	var screen [H][W]byte
-	cycle, x := 0, 1
-	for _, inst := range prog {
-		for c := 0; c < inst.cycles; c++ {
-			px, py := (cycle+c)%W, (cycle+c)/W
+	eachCycle(prog, func(cycle, x int) {
+		px, py := (cycle-1)%W, (cycle-1)/W
+		if py < H {
 			screen[py][px] = fn.If[byte](px >= x-1 && px <= x+1, '#', ' ')
 		}
-		cycle, x = cycle+inst.cycles, x+inst.addend
-	}
+	})
 	return fn.Map(screen[:], func(row [W]byte) string { return string(row[:]) })
 }
 
-type instruction struct {
-	cycles int
-	addend int
-}
-
-func parseInstruction(line string) (instruction, error) {
-	if line == "noop" {
-		return instruction{cycles: 1, addend: 0}, nil
-	}
-	if strings.HasPrefix(line, "addx ") {
-		addend, err := strconv.Atoi(line[5:])
-		if err != nil {
-			return instruction{}, fmt.Errorf("invalid addend: %q: %w", line[5:], err)
+// eachCycle runs the program, and calls f with the (1-based) number of each cycle, and the value of
+// the X register during it.
+func eachCycle(prog *regvm.Prog[struct{}], f func(cycle, x int)) {
+	m := regvm.NewMachine(prog, struct{}{})
+	m.Regs[regX] = 1
+	m.OnStep = func(m *machine, in *regvm.Inst) {
+		for c := 0; c < isa.Ops[in.Op].Cycles; c++ {
+			f(m.Cycles+c+1, m.Regs[regX])
 		}
-		return instruction{cycles: 2, addend: addend}, nil
 	}
-	return instruction{}, fmt.Errorf("invalid instruction: %q", line)
+	m.Run()
+}
+
+type machine = regvm.Machine[struct{}]
+
+const regX = 0
+
+var isa = &regvm.ISA[struct{}]{
+	Regs: regvm.NamedRegs("x"),
+	Ops: []regvm.Op[struct{}]{
+		{Name: "noop", Cycles: 1, Exec: func(m *machine, a []regvm.Operand) {}},
+		{Name: "addx", Args: "i", Cycles: 2, Exec: func(m *machine, a []regvm.Operand) { m.Regs[regX] += a[0].Val }},
+	},
 }
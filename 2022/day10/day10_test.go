@@ -17,7 +17,6 @@ package day10
 import (
 	"testing"
 
-	"github.com/fis/aoc/util/fn"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -41,7 +40,7 @@ var ex = []string{
 }
 
 func TestSigStrength(t *testing.T) {
-	prog, err := fn.MapE(ex, parseInstruction)
+	prog, err := isa.Parse(ex)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,7 +51,7 @@ func TestSigStrength(t *testing.T) {
 }
 
 func TestRender(t *testing.T) {
-	prog, err := fn.MapE(ex, parseInstruction)
+	prog, err := isa.Parse(ex)
 	if err != nil {
 		t.Fatal(err)
 	}
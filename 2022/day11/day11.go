@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 11, glue.Puzzle{
+		Title: "Monkey in the Middle",
+		Tags:  []string{"simulation", "number-theory"},
+	})
 	glue.RegisterSolver(2022, 11, glue.ChunkSolver(glue.WithParser(parseMonkey, solve)))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 12, glue.Puzzle{
+		Title: "Hill Climbing Algorithm",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2022, 12, glue.LineSolver(solve))
 }
 
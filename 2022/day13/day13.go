@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 13, glue.Puzzle{
+		Title: "Distress Signal",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2022, 13, glue.LineSolver(solve))
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 14, glue.Puzzle{
+		Title: "Regolith Reservoir",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2022, 14, glue.LineSolver(glue.WithParser(parsePath, solve)))
 }
 
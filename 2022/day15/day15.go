@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 15, glue.Puzzle{
+		Title: "Beacon Exclusion Zone",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2022, 15, glue.LineSolver(glue.WithParser(parseReading, solve)))
 }
 
@@ -27,6 +27,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 16, glue.Puzzle{
+		Title:         "Proboscidea Volcanium",
+		Tags:          []string{"graph", "dp"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2022, 16, glue.LineSolver(glue.WithParser(ParseValveScan, solve)))
 	glue.RegisterPlotter(2022, 16, "", glue.LinePlotter(plot), map[string]string{"ex": strings.Join(ExampleScan, "\n")})
 }
@@ -218,26 +223,21 @@ func (t *stateTracker) keep(time int, st state) bool {
 }
 
 func ParseValveScan(line string) (vs ValveScan, err error) {
-	tail, ok := util.CheckPrefix(line, "Valve ")
-	if !ok {
-		return ValveScan{}, fmt.Errorf("expected `Valve ` in %q", line)
+	p := util.NewLineParser(line)
+	if err := p.Expect("Valve "); err != nil {
+		return ValveScan{}, err
 	}
-	vs.name, tail = util.NextWord(tail)
-	tail, ok = util.CheckPrefix(tail, " has flow rate=")
-	if !ok {
-		return ValveScan{}, fmt.Errorf("expected ` has flow rate=` in %q", line)
+	vs.name = p.Word()
+	if err := p.Expect(" has flow rate="); err != nil {
+		return ValveScan{}, err
 	}
-	vs.flowRate, ok, tail = util.NextInt(tail)
-	if !ok {
-		return ValveScan{}, fmt.Errorf("expected an integer in %q", line)
+	if vs.flowRate, err = p.Int(); err != nil {
+		return ValveScan{}, err
 	}
-	if tail, ok = util.CheckPrefix(tail, "; tunnel leads to valve "); !ok {
-		tail, ok = util.CheckPrefix(tail, "; tunnels lead to valves ")
+	if !p.Consume("; tunnel leads to valve ") && !p.Consume("; tunnels lead to valves ") {
+		return ValveScan{}, p.Errorf("expected `; tunnels? leads? to valves?`")
 	}
-	if !ok {
-		return ValveScan{}, fmt.Errorf("expected `; tunnels? leads? to valves? in %q", line)
-	}
-	vs.tunnels = strings.Split(tail, ", ")
+	vs.tunnels = strings.Split(p.Rest(), ", ")
 	return vs, nil
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 17, glue.Puzzle{
+		Title: "Pyroclastic Flow",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2022, 17, glue.LineSolver(solve))
 }
 
@@ -16,26 +16,37 @@
 package day18
 
 import (
-	"fmt"
-
 	"github.com/fis/aoc/glue"
 	"github.com/fis/aoc/util"
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 18, glue.Puzzle{
+		Title: "Boiling Boulders",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2022, 18, glue.LineSolver(glue.WithParser(parseCube, solve)))
 }
 
 func parseCube(line string) (p3, error) {
-	x, okx, tail := util.NextInt(line)
-	tail, okc1 := util.CheckPrefix(tail, ",")
-	y, oky, tail := util.NextInt(tail)
-	tail, okc2 := util.CheckPrefix(tail, ",")
-	z, okz, _ := util.NextInt(tail)
-	if okx && okc1 && oky && okc2 && okz {
-		return p3{x + 1, y + 1, z + 1}, nil
+	var c [3]int
+	p := util.NewLineParser(line)
+	for i := range c {
+		if i > 0 {
+			if err := p.Expect(","); err != nil {
+				return p3{}, err
+			}
+		}
+		n, err := p.Int()
+		if err != nil {
+			return p3{}, err
+		}
+		c[i] = n + 1
+	}
+	if !p.Done() {
+		return p3{}, p.Errorf("trailing input after point: %q", p.Rest())
 	}
-	return p3{}, fmt.Errorf("bad point: expecting \"X,Y,Z\", got %q", line)
+	return p3{c[0], c[1], c[2]}, nil
 }
 
 func solve(cubes []p3) ([]string, error) {
@@ -17,6 +17,8 @@ package day19
 
 import (
 	"fmt"
+	"io"
+	"math/rand"
 
 	"github.com/fis/aoc/glue"
 	"github.com/fis/aoc/util"
@@ -25,7 +27,12 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 19, glue.Puzzle{
+		Title: "Not Enough Minerals",
+		Tags:  []string{"combinatorics"},
+	})
 	glue.RegisterSolver(2022, 19, glue.LineSolver(glue.WithParser(parseBlueprint, solve)))
+	glue.RegisterGenerator(2022, 19, glue.GeneratorFunc(generate))
 }
 
 func parseBlueprint(line string) (bp blueprint, err error) {
@@ -187,3 +194,24 @@ type blueprint struct {
 	geoCostOre  int
 	geoCostObs  int
 }
+
+// input generator
+
+// generate writes the given number of random blueprints (but at least three, which part 2 needs),
+// with costs in the same ranges as in the real inputs.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	between := func(lo, hi int) int { return lo + rng.Intn(hi-lo+1) }
+	for i := 1; i <= max(size, 3); i++ {
+		_, err := fmt.Fprintf(w,
+			"Blueprint %d:"+
+				" Each ore robot costs %d ore."+
+				" Each clay robot costs %d ore."+
+				" Each obsidian robot costs %d ore and %d clay."+
+				" Each geode robot costs %d ore and %d obsidian.\n",
+			i, between(2, 4), between(2, 4), between(2, 4), between(5, 20), between(2, 4), between(5, 20))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ package day19
 
 import (
 	"testing"
+
+	"github.com/fis/aoc/glue"
 )
 
 var (
@@ -40,3 +42,7 @@ func TestMaxGeodes(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkScale(b *testing.B) {
+	glue.RunScaleBenchmarks(b, 2022, 19, 3, 10, 30, 100)
+}
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 20, glue.Puzzle{
+		Title: "Grove Positioning System",
+		Tags:  []string{"simulation"},
+	})
 	glue.RegisterSolver(2022, 20, glue.IntSolver(solve))
 }
 
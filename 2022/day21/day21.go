@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 21, glue.Puzzle{
+		Title: "Monkey Math",
+		Tags:  []string{"parsing", "graph"},
+	})
 	glue.RegisterSolver(2022, 21, glue.LineSolver(solve))
 }
 
@@ -139,7 +143,7 @@ func parseJobs(lines []string) (root *mathJob, human *humanJob) {
 	for _, line := range lines {
 		sep := strings.Index(line, ": ")
 		name := line[:sep]
-		if c, ok, _ := util.NextInt(line[sep+2:]); ok {
+		if c, _, err := util.NextInt(line[sep+2:]); err == nil {
 			if name == "humn" {
 				*human = humanJob(c)
 			} else {
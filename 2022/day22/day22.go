@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 22, glue.Puzzle{
+		Title: "Monkey Map",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2022, 22, glue.LineSolver(solve))
 }
 
@@ -319,25 +323,22 @@ func parseInput(lines []string) (lvl *level, path []move, err error) {
 	}
 	lvl = &level{data: lines[:h], w: w, h: h, wrapX: wrapX, wrapY: wrapY}
 
-	pathSpec := lines[len(lines)-1]
-	for len(pathSpec) > 0 {
-		steps, ok, tail := util.NextInt(pathSpec)
-		if !ok {
-			return nil, nil, fmt.Errorf("expected an integer")
+	pathSpec := util.NewLineParser(lines[len(lines)-1])
+	pathSpec.Line = len(lines)
+	for !pathSpec.Done() {
+		steps, err := pathSpec.Int()
+		if err != nil {
+			return nil, nil, err
 		}
 		m := move{steps: steps}
-		pathSpec = tail
-		if len(pathSpec) > 0 {
-			turn := pathSpec[0]
-			switch turn {
-			case 'L':
-				m.turn = -1
-			case 'R':
-				m.turn = +1
-			default:
-				return nil, nil, fmt.Errorf("expected L or R")
-			}
-			pathSpec = pathSpec[1:]
+		switch {
+		case pathSpec.Done():
+		case pathSpec.Consume("L"):
+			m.turn = -1
+		case pathSpec.Consume("R"):
+			m.turn = +1
+		default:
+			return nil, nil, pathSpec.Errorf("expected L or R")
 		}
 		path = append(path, m)
 	}
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 23, glue.Puzzle{
+		Title: "Unstable Diffusion",
+		Tags:  []string{"grid", "automaton"},
+	})
 	glue.RegisterSolver(2022, 23, glue.LineSolver(solve))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 24, glue.Puzzle{
+		Title: "Blizzard Basin",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2022, 24, glue.LineSolver(solve))
 }
 
@@ -20,6 +20,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2022, 25, glue.Puzzle{
+		Title: "Full of Hot Air",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2022, 25, glue.LineSolver(glue.WithParser(parseSNAFU, solve)))
 }
 
@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 1, glue.Puzzle{
+		Title: "Trebuchet?!",
+		Tags:  []string{"strings"},
+	})
 	glue.RegisterSolver(2023, 1, glue.LineSolver(solve))
 }
 
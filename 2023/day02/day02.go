@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 2, glue.Puzzle{
+		Title: "Cube Conundrum",
+		Tags:  []string{"parsing"},
+	})
 	glue.RegisterSolver(2023, 2, glue.LineSolver(glue.WithParser(parseGame, solve)))
 }
 
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 3, glue.Puzzle{
+		Title: "Gear Ratios",
+		Tags:  []string{"grid"},
+	})
 	glue.RegisterSolver(2023, 3, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 4, glue.Puzzle{
+		Title: "Scratchcards",
+		Tags:  []string{"dp"},
+	})
 	glue.RegisterSolver(2023, 4, glue.LineSolver(glue.WithParser(parseCardFast, solve)))
 }
 
@@ -26,6 +26,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 5, glue.Puzzle{
+		Title: "If You Give A Seed A Fertilizer",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2023, 5, glue.ChunkSolver(solve))
 }
 
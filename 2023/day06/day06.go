@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 6, glue.Puzzle{
+		Title: "Wait For It",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2023, 6, glue.LineSolver(solve))
 }
 
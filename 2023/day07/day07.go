@@ -26,6 +26,9 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 7, glue.Puzzle{
+		Title: "Camel Cards",
+	})
 	glue.RegisterSolver(2023, 7, glue.LineSolver(glue.WithParser(parseBid, solve)))
 }
 
@@ -28,6 +28,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 8, glue.Puzzle{
+		Title:         "Haunted Wasteland",
+		Tags:          []string{"graph", "number-theory"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2023, 8, glue.ChunkSolver(solve))
 	glue.RegisterPlotter(2023, 8, "", glue.ChunkPlotter(plot), map[string]string{"ex1": ex1, "ex2": ex2, "ex3": ex3})
 }
@@ -96,6 +101,9 @@ func findCycle(g *graph, from int, dirs []direction) cycle {
 	}
 }
 
+// mergeCycles finds the combined cycle of two ghosts. Only the last end node of each cycle is
+// tracked, so this relies on each ghost passing a single end node once per cycle, as in the actual
+// inputs.
 func mergeCycles(c1, c2 cycle) cycle {
 	if c1.size < c2.size {
 		c1, c2 = c2, c1
@@ -22,6 +22,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 9, glue.Puzzle{
+		Title: "Mirage Maintenance",
+		Tags:  []string{"number-theory"},
+	})
 	glue.RegisterSolver(2023, 9, glue.LineSolver(glue.WithParser(func(line string) ([]int, error) { return util.Ints(line), nil }, solve)))
 }
 
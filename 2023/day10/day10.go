@@ -23,6 +23,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 10, glue.Puzzle{
+		Title: "Pipe Maze",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2023, 10, glue.LevelSolver{Solver: solve, Empty: '.'})
 }
 
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 11, glue.Puzzle{
+		Title: "Cosmic Expansion",
+		Tags:  []string{"grid", "geometry"},
+	})
 	glue.RegisterSolver(2023, 11, glue.LineSolver(solve))
 }
 
@@ -17,6 +17,8 @@ package day12
 
 import (
 	"fmt"
+	"io"
+	"math/rand"
 	"strings"
 
 	"github.com/fis/aoc/glue"
@@ -25,7 +27,12 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 12, glue.Puzzle{
+		Title: "Hot Springs",
+		Tags:  []string{"dp"},
+	})
 	glue.RegisterSolver(2023, 12, glue.LineSolver(solve))
+	glue.RegisterGenerator(2023, 12, glue.GeneratorFunc(generate))
 }
 
 func solve(lines []string) ([]string, error) {
@@ -139,3 +146,39 @@ func parseLine(line string) (record, error) {
 	}
 	return record{row: []byte(row), groups: util.Ints(groups)}, nil
 }
+
+// input generator
+
+// generate writes 20 random records with rows of the given length. Each row is derived from a
+// random arrangement of damaged springs (with at least one), so there's always a way to solve it.
+func generate(w io.Writer, rng *rand.Rand, size int) error {
+	for i := 0; i < 20; i++ {
+		row := make([]byte, size)
+		for j := range row {
+			row[j] = ".#"[rng.Intn(2)]
+		}
+		row[rng.Intn(size)] = '#'
+		var groups []string
+		for j := 0; j < size; {
+			k := j
+			for k < size && row[k] == '#' {
+				k++
+			}
+			if k > j {
+				groups = append(groups, fmt.Sprint(k-j))
+				j = k
+			} else {
+				j++
+			}
+		}
+		for j := range row {
+			if rng.Intn(5) < 2 {
+				row[j] = '?'
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", row, strings.Join(groups, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
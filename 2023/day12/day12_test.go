@@ -14,7 +14,13 @@
 
 package day12
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
 
 var tests = []struct {
 	row          string
@@ -46,3 +52,61 @@ func TestCountWaysUnfolded(t *testing.T) {
 		}
 	}
 }
+
+func TestCountWaysGenerated(t *testing.T) {
+	for size := 1; size <= 12; size++ {
+		for seed := int64(1); seed <= 5; seed++ {
+			input, err := glue.Generate(2023, 12, seed, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range util.Lines(string(input)) {
+				r, err := parseLine(line)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := bruteForceWays(r.row, r.groups)
+				if got := countWays(r.row, r.groups); got != want {
+					t.Errorf("countWays(%q, %v) = %d, want %d", r.row, r.groups, got, want)
+				}
+			}
+		}
+	}
+}
+
+// bruteForceWays is the obviously correct reference for countWays: it tries every way to replace
+// the unknown springs, and counts the ones with the right groups.
+func bruteForceWays(row []byte, groups []int) (ways int) {
+	var unknown []int
+	for i, b := range row {
+		if b == '?' {
+			unknown = append(unknown, i)
+		}
+	}
+	try := slices.Clone(row)
+	for bits := 0; bits < 1<<len(unknown); bits++ {
+		for j, i := range unknown {
+			try[i] = ".#"[bits>>j&1]
+		}
+		var got []int
+		run := 0
+		for _, b := range try {
+			if b == '#' {
+				run++
+			} else if run > 0 {
+				got, run = append(got, run), 0
+			}
+		}
+		if run > 0 {
+			got = append(got, run)
+		}
+		if slices.Equal(got, groups) {
+			ways++
+		}
+	}
+	return ways
+}
+
+func BenchmarkScale(b *testing.B) {
+	glue.RunScaleBenchmarks(b, 2023, 12, 20, 40, 80, 160)
+}
@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 13, glue.Puzzle{
+		Title: "Point of Incidence",
+		Tags:  []string{"grid", "bits"},
+	})
 	glue.RegisterSolver(2023, 13, glue.ChunkSolver(solve))
 }
 
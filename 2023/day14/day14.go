@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 14, glue.Puzzle{
+		Title: "Parabolic Reflector Dish",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2023, 14, glue.GenericSolver(solve))
 }
 
@@ -25,6 +25,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 15, glue.Puzzle{
+		Title: "Lens Library",
+		Tags:  []string{"hashing"},
+	})
 	glue.RegisterSolver(2023, 15, glue.LineSolver(solve))
 }
 
@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 16, glue.Puzzle{
+		Title: "The Floor Will Be Lava",
+		Tags:  []string{"grid", "simulation"},
+	})
 	glue.RegisterSolver(2023, 16, glue.FixedLevelSolver(solve))
 }
 
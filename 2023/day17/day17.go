@@ -21,6 +21,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 17, glue.Puzzle{
+		Title: "Clumsy Crucible",
+		Tags:  []string{"grid", "pathfinding"},
+	})
 	glue.RegisterSolver(2023, 17, glue.FixedLevelSolver(solve))
 }
 
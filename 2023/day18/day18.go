@@ -24,6 +24,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 18, glue.Puzzle{
+		Title: "Lavaduct Lagoon",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2023, 18, glue.RegexpSolver{
 		Solver: glue.WithParser(parseDigPlan, solve),
 		Regexp: `([UDLR]) (\d+) \(#([0-9a-f]{6})\)`,
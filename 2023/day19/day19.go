@@ -28,6 +28,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 19, glue.Puzzle{
+		Title: "Aplenty",
+		Tags:  []string{"parsing", "graph"},
+	})
 	glue.RegisterSolver(2023, 19, glue.LineSolver(solve))
 	glue.RegisterPlotter(2023, 19, "", glue.LinePlotter(plot), map[string]string{"ex": ex}) // TODO: ex
 }
@@ -26,6 +26,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 20, glue.Puzzle{
+		Title:         "Pulse Propagation",
+		Tags:          []string{"graph", "simulation"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2023, 20, glue.LineSolver(solve))
 	glue.RegisterPlotter(2023, 20, "", glue.LinePlotter(plot), nil)
 }
@@ -32,6 +32,11 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 21, glue.Puzzle{
+		Title:         "Step Counter",
+		Tags:          []string{"grid", "pathfinding"},
+		InputSpecific: true,
+	})
 	glue.RegisterSolver(2023, 21, glue.FixedLevelSolver(solve))
 	glue.RegisterPlotter(2023, 21, "", plotter{}, nil)
 }
@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 22, glue.Puzzle{
+		Title: "Sand Slabs",
+		Tags:  []string{"geometry", "graph"},
+	})
 	glue.RegisterSolver(2023, 22, glue.LineSolver(glue.WithParser(parseBrick, solve)))
 }
 
@@ -29,16 +29,24 @@ import (
 )
 
 func init() {
-	glue.RegisterSolver(2023, 23, glue.FixedLevelSolver(solve))
+	glue.RegisterPuzzle(2023, 23, glue.Puzzle{
+		Title: "A Long Walk",
+		Tags:  []string{"grid", "graph"},
+	})
+	glue.RegisterSolver(2023, 23, solver(unsafeLongestPath))
+	glue.RegisterVariant(2023, 23, "native", solver(unsafeLongestPath))
+	glue.RegisterVariant(2023, 23, "go", solver(safeLongestPath))
 	glue.RegisterPlotter(2023, 23, "a", plotter{tr: tracerA{}}, map[string]string{"ex": ex})
 	glue.RegisterPlotter(2023, 23, "b", plotter{tr: tracerB{}, undir: true}, map[string]string{"ex": ex})
 }
 
-func solve(l *util.FixedLevel) ([]string, error) {
-	g, startV, endV := deconstruct(l)
-	p1 := longestPath(g)
-	p2 := unsafeLongestPath(g, startV, endV)
-	return glue.Ints(p1, p2), nil
+func solver(longestUndirPath func(g *graph.SparseW, startV, endV int) int) glue.Solver {
+	return glue.FixedLevelSolver(func(l *util.FixedLevel) ([]string, error) {
+		g, startV, endV := deconstruct(l)
+		p1 := longestPath(g)
+		p2 := longestUndirPath(g, startV, endV)
+		return glue.Ints(p1, p2), nil
+	})
 }
 
 func longestPath(g *graph.SparseW) (longest int) {
@@ -59,12 +67,58 @@ func longestPath(g *graph.SparseW) (longest int) {
 	return -fn.Min(d)
 }
 
+// safeLongestPath is the pure Go equivalent of unsafeLongestPath, for comparison.
+func safeLongestPath(g *graph.SparseW, startV, endV int) (longest int) {
+	sg, firstV, lastV := undirSubgraph(g, startV, endV)
+	wS, wE := g.W(startV, firstV), g.W(lastV, endV)
+	var maxD uint32
+	bruteForce(sg, uint32(firstV), 0, uint32(lastV), &maxD)
+	return wS + int(maxD) + wE
+}
+
+func bruteForce(sg []vertex, atV, d, toV uint32, maxD *uint32) {
+	if atV == toV {
+		if d > *maxD {
+			*maxD = d
+		}
+		return
+	}
+	sg[atV].seen = true
+	for _, next := range sg[atV].next[:sg[atV].degree] {
+		if !sg[next.v].seen {
+			bruteForce(sg, next.v, d+next.w, toV, maxD)
+		}
+	}
+	sg[atV].seen = false
+}
+
 type vertex struct {
 	degree uint32
 	next   [4]struct{ v, w uint32 }
 	seen   bool
 }
 
+// undirSubgraph converts the graph to the undirected form used by the brute force searches. The
+// start and end vertices are left out, as they only have a single neighbour each: the returned
+// firstV and lastV are those neighbours.
+func undirSubgraph(g *graph.SparseW, startV, endV int) (sg []vertex, firstV, lastV int) {
+	sg = make([]vertex, g.Len())
+	for u := range sg {
+		for it := g.Succ(u); it.Valid(); it = g.Next(it) {
+			v, w := it.Head(), it.W()
+			if u != startV && u != endV && v != startV && v != endV {
+				d := sg[u].degree
+				sg[u].next[d].v, sg[u].next[d].w = uint32(v), uint32(w)
+				sg[u].degree = d + 1
+				d = sg[v].degree
+				sg[v].next[d].v, sg[v].next[d].w = uint32(u), uint32(w)
+				sg[v].degree = d + 1
+			}
+		}
+	}
+	return sg, g.SuccI(startV, 0), g.PredI(endV, 0)
+}
+
 var gateExits = []struct {
 	d util.P
 	c byte
@@ -247,21 +301,7 @@ type backRef struct {
 }
 
 func (tr tracerB) trace(g *graph.SparseW, startV, endV int) (longestV map[int]struct{}, longestE map[[2]int]struct{}) {
-	sg := make([]vertex, g.Len())
-	for u := range sg {
-		for it := g.Succ(u); it.Valid(); it = g.Next(it) {
-			v, w := it.Head(), it.W()
-			if u != startV && u != endV && v != startV && v != endV {
-				d := sg[u].degree
-				sg[u].next[d].v, sg[u].next[d].w = uint32(v), uint32(w)
-				sg[u].degree = d + 1
-				d = sg[v].degree
-				sg[v].next[d].v, sg[v].next[d].w = uint32(u), uint32(w)
-				sg[v].degree = d + 1
-			}
-		}
-	}
-	firstV, lastV := g.SuccI(startV, 0), g.PredI(endV, 0)
+	sg, firstV, lastV := undirSubgraph(g, startV, endV)
 	tr.bruteForce(sg, uint32(firstV), 0, uint32(lastV), nil)
 	longestV = map[int]struct{}{startV: {}, endV: {}}
 	longestE = map[[2]int]struct{}{{startV, firstV}: {}, {lastV, endV}: {}}
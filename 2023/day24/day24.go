@@ -29,6 +29,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 24, glue.Puzzle{
+		Title: "Never Tell Me The Odds",
+		Tags:  []string{"geometry"},
+	})
 	glue.RegisterSolver(2023, 24, glue.LineSolver(glue.WithParser(parseHailstone, solve)))
 }
 
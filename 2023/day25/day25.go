@@ -27,6 +27,10 @@ import (
 )
 
 func init() {
+	glue.RegisterPuzzle(2023, 25, glue.Puzzle{
+		Title: "Snowverload",
+		Tags:  []string{"graph"},
+	})
 	glue.RegisterSolver(2023, 25, glue.LineSolver(solve))
 	glue.RegisterPlotter(2023, 25, "a", glue.LinePlotter(plotCut), map[string]string{"ex": ex})
 	glue.RegisterPlotter(2023, 25, "b", glue.LinePlotter(plotEdges), map[string]string{"ex": ex})
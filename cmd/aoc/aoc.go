@@ -18,6 +18,7 @@ package main
 import (
 	"github.com/fis/aoc/glue"
 
+	_ "github.com/fis/aoc/2015" // solvers
 	_ "github.com/fis/aoc/2016" // solvers
 	_ "github.com/fis/aoc/2017" // solvers
 	_ "github.com/fis/aoc/2018" // solvers
@@ -26,6 +27,9 @@ import (
 	_ "github.com/fis/aoc/2021" // solvers
 	_ "github.com/fis/aoc/2022" // solvers
 	_ "github.com/fis/aoc/2023" // solvers
+
+	_ "github.com/fis/aoc/z80/2022" // Z80 solutions
+	_ "github.com/fis/aoc/z80/2023" // Z80 solutions
 )
 
 func main() {
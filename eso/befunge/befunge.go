@@ -0,0 +1,564 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package befunge implements a Funge-98 interpreter, restricted to two
+// dimensions (Befunge-98).
+//
+// The interpreter follows the Funge-98 specification, with an unbounded
+// Funge-space, the stack stack, and the `y` system information instruction.
+// Concurrency (`t`), file I/O (`i`, `o`) and system execution (`=`) are not
+// implemented, and like all unimplemented instructions, they reflect. A few
+// simple fingerprints are available; see Fingerprints.
+//
+// Where the specification leaves room for interpretation, the interpreter
+// behaves like cfunge, which the solutions were written against. In
+// particular, `&` leaves the character following the number unread, unless it
+// is a newline.
+package befunge
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Handprint is the handprint of the interpreter, as reported by `y`.
+const Handprint = 0x474f4642 // "GOFB"
+
+// ErrLimit is returned when a program runs for longer than the step limit.
+var ErrLimit = errors.New("step limit exceeded")
+
+var (
+	east  = Vec{1, 0}
+	west  = Vec{-1, 0}
+	north = Vec{0, -1}
+	south = Vec{0, 1}
+)
+
+// Interp is the state of a running Befunge-98 program.
+type Interp struct {
+	// Limit is the maximum number of instructions to execute, or 0 for no
+	// limit.
+	Limit int64
+	// Args are the command line arguments reported by `y`, starting with the
+	// program name.
+	Args []string
+
+	space *space
+	ip    ip
+	in    *bufio.Reader
+	out   *bufio.Writer
+	steps int64
+	done  bool
+	exit  int
+}
+
+// ip is the state of the (single) instruction pointer.
+type ip struct {
+	pos, delta Vec
+	offset     Vec     // storage offset
+	stacks     [][]int // the stack stack; the last one is the TOSS
+	stringMode bool
+	semantics  [26][]func(*Interp) // fingerprint semantics of A-Z
+}
+
+// New returns an interpreter ready to run a program. The program reads its
+// input with `&` and `~` from in, and writes its output to out.
+func New(src []byte, in io.Reader, out io.Writer) *Interp {
+	it := &Interp{
+		Args:  []string{"prog.b98"},
+		space: newSpace(),
+		ip:    ip{delta: east, stacks: [][]int{nil}},
+		in:    bufio.NewReader(in),
+		out:   bufio.NewWriter(out),
+	}
+	it.space.load(src)
+	return it
+}
+
+// Run runs a program to completion, and returns its exit code.
+func Run(src []byte, in io.Reader, out io.Writer) (int, error) {
+	return New(src, in, out).Run()
+}
+
+// Run executes the program until it ends with `@` or `q`, and returns its exit
+// code.
+func (it *Interp) Run() (int, error) {
+	defer it.out.Flush()
+	// The IP starts at the origin, which may hold spaces or comments.
+	if c := it.space.get(it.ip.pos); c == ' ' || c == ';' {
+		it.ip.pos = it.ip.pos.sub(it.ip.delta)
+		it.skip()
+	}
+	for !it.done {
+		if it.Limit > 0 && it.steps >= it.Limit {
+			return 0, ErrLimit
+		}
+		it.steps++
+		it.exec(it.space.get(it.ip.pos))
+		if it.done {
+			break
+		}
+		it.skip()
+	}
+	return it.exit, it.out.Flush()
+}
+
+// skip moves the IP to the next instruction, passing over spaces and ;-comments,
+// which take no time. In string mode, only the first of a run of spaces is
+// passed over.
+func (it *Interp) skip() {
+	ip, s := &it.ip, it.space
+	ip.pos = s.next(ip.pos, ip.delta)
+	if ip.stringMode {
+		return
+	}
+	for {
+		switch s.get(ip.pos) {
+		case ' ':
+			ip.pos = s.next(ip.pos, ip.delta)
+		case ';':
+			ip.pos = s.next(ip.pos, ip.delta)
+			for s.get(ip.pos) != ';' {
+				ip.pos = s.next(ip.pos, ip.delta)
+			}
+			ip.pos = s.next(ip.pos, ip.delta)
+		default:
+			return
+		}
+	}
+}
+
+// nextInstr returns the position of the next instruction after the IP, as
+// skip would find it, without moving the IP.
+func (it *Interp) nextInstr() Vec {
+	saved := it.ip.pos
+	it.skip()
+	p := it.ip.pos
+	it.ip.pos = saved
+	return p
+}
+
+func (it *Interp) toss() *[]int { return &it.ip.stacks[len(it.ip.stacks)-1] }
+
+func (it *Interp) push(v int) {
+	s := it.toss()
+	*s = append(*s, v)
+}
+
+// pop removes a value from the top of the TOSS. An empty stack pops zeros.
+func (it *Interp) pop() int {
+	s := it.toss()
+	if len(*s) == 0 {
+		return 0
+	}
+	v := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return v
+}
+
+func (it *Interp) pushVec(v Vec) {
+	it.push(v.X)
+	it.push(v.Y)
+}
+
+func (it *Interp) popVec() Vec {
+	y := it.pop()
+	return Vec{it.pop(), y}
+}
+
+// pushString pushes a 0gnirts: a string in reverse, so that its first
+// character ends up on top, preceded by a terminating zero.
+func (it *Interp) pushString(s string) {
+	it.push(0)
+	for i := len(s) - 1; i >= 0; i-- {
+		it.push(int(s[i]))
+	}
+}
+
+func (it *Interp) reflect() {
+	it.ip.delta = Vec{-it.ip.delta.X, -it.ip.delta.Y}
+}
+
+// exec executes a single instruction.
+func (it *Interp) exec(c int) {
+	ip := &it.ip
+	if ip.stringMode {
+		if c == '"' {
+			ip.stringMode = false
+		} else {
+			it.push(c)
+		}
+		return
+	}
+
+	switch {
+	case c >= '0' && c <= '9':
+		it.push(c - '0')
+		return
+	case c >= 'a' && c <= 'f':
+		it.push(c - 'a' + 10)
+		return
+	case c >= 'A' && c <= 'Z':
+		if sem := ip.semantics[c-'A']; len(sem) > 0 {
+			sem[len(sem)-1](it)
+		} else {
+			it.reflect()
+		}
+		return
+	}
+
+	switch c {
+	// Flow control.
+	case '>':
+		ip.delta = east
+	case '<':
+		ip.delta = west
+	case '^':
+		ip.delta = north
+	case 'v':
+		ip.delta = south
+	case '?':
+		ip.delta = []Vec{east, west, north, south}[rand.Intn(4)]
+	case '[':
+		ip.delta = Vec{ip.delta.Y, -ip.delta.X}
+	case ']':
+		ip.delta = Vec{-ip.delta.Y, ip.delta.X}
+	case 'r':
+		it.reflect()
+	case 'x':
+		ip.delta = it.popVec()
+	case '_':
+		if it.pop() == 0 {
+			ip.delta = east
+		} else {
+			ip.delta = west
+		}
+	case '|':
+		if it.pop() == 0 {
+			ip.delta = south
+		} else {
+			ip.delta = north
+		}
+	case 'w':
+		b, a := it.pop(), it.pop()
+		if a < b {
+			ip.delta = Vec{ip.delta.Y, -ip.delta.X}
+		} else if a > b {
+			ip.delta = Vec{-ip.delta.Y, ip.delta.X}
+		}
+	case '#':
+		ip.pos = it.space.next(ip.pos, ip.delta)
+	case 'j':
+		n := it.pop()
+		delta := ip.delta
+		if n < 0 {
+			delta, n = Vec{-delta.X, -delta.Y}, -n
+		}
+		for ; n > 0; n-- {
+			ip.pos = it.space.next(ip.pos, delta)
+		}
+	case 'k':
+		it.iterate()
+	case '@':
+		it.done = true
+	case 'q':
+		it.exit = it.pop()
+		it.done = true
+	case 'z':
+
+	// Arithmetic and logic.
+	case '+':
+		b, a := it.pop(), it.pop()
+		it.push(a + b)
+	case '-':
+		b, a := it.pop(), it.pop()
+		it.push(a - b)
+	case '*':
+		b, a := it.pop(), it.pop()
+		it.push(a * b)
+	case '/':
+		b, a := it.pop(), it.pop()
+		if b == 0 {
+			it.push(0)
+		} else {
+			it.push(a / b)
+		}
+	case '%':
+		b, a := it.pop(), it.pop()
+		if b == 0 {
+			it.push(0)
+		} else {
+			it.push(a % b)
+		}
+	case '!':
+		it.push(b2i(it.pop() == 0))
+	case '`':
+		b, a := it.pop(), it.pop()
+		it.push(b2i(a > b))
+
+	// Stack manipulation.
+	case '$':
+		it.pop()
+	case ':':
+		v := it.pop()
+		it.push(v)
+		it.push(v)
+	case '\\':
+		b, a := it.pop(), it.pop()
+		it.push(b)
+		it.push(a)
+	case 'n':
+		*it.toss() = (*it.toss())[:0]
+	case '{':
+		it.beginBlock()
+	case '}':
+		it.endBlock()
+	case 'u':
+		it.stackUnderStack()
+
+	// Funge-space access.
+	case '"':
+		ip.stringMode = true
+	case '\'':
+		ip.pos = it.space.next(ip.pos, ip.delta)
+		it.push(it.space.get(ip.pos))
+	case 's':
+		ip.pos = it.space.next(ip.pos, ip.delta)
+		it.space.put(ip.pos, it.pop())
+	case 'g':
+		it.push(it.space.get(it.popVec().add(ip.offset)))
+	case 'p':
+		pos := it.popVec().add(ip.offset)
+		it.space.put(pos, it.pop())
+
+	// Input and output.
+	case '.':
+		fmt.Fprintf(it.out, "%d ", it.pop())
+	case ',':
+		it.out.WriteByte(byte(it.pop()))
+	case '&':
+		if v, ok := it.readNumber(); ok {
+			it.push(v)
+		} else {
+			it.reflect()
+		}
+	case '~':
+		it.out.Flush()
+		if b, err := it.in.ReadByte(); err == nil {
+			it.push(int(b))
+		} else {
+			it.reflect()
+		}
+
+	// Fingerprints and system information.
+	case '(':
+		it.loadFingerprint()
+	case ')':
+		it.unloadFingerprint()
+	case 'y':
+		it.sysInfo()
+
+	default:
+		it.reflect()
+	}
+}
+
+// iterate implements `k`, which executes the next instruction n times.
+func (it *Interp) iterate() {
+	ip := &it.ip
+	n := it.pop()
+	target := it.nextInstr()
+	if n <= 0 {
+		// 0k skips over the instruction.
+		ip.pos = target
+		return
+	}
+	c := it.space.get(target)
+	pos, delta := ip.pos, ip.delta
+	for ; n > 0 && !it.done; n-- {
+		it.exec(c)
+	}
+	if ip.pos == pos && ip.delta == delta {
+		// The instruction did not move the IP, so it continues past it.
+		ip.pos = target
+	}
+}
+
+// readNumber reads a decimal number for `&`, skipping any preceding
+// non-digits. It reports false at end of input.
+func (it *Interp) readNumber() (int, bool) {
+	it.out.Flush()
+	var (
+		v      int
+		digits bool
+	)
+	for {
+		b, err := it.in.ReadByte()
+		if err != nil {
+			return v, digits
+		}
+		if b >= '0' && b <= '9' {
+			v, digits = 10*v+int(b-'0'), true
+			continue
+		}
+		if !digits {
+			continue
+		}
+		if b != '\n' {
+			it.in.UnreadByte()
+		}
+		return v, true
+	}
+}
+
+// beginBlock implements `{`, which pushes a new stack onto the stack stack.
+func (it *Interp) beginBlock() {
+	ip := &it.ip
+	n := it.pop()
+	soss := it.toss()
+	var toss []int
+	if n > 0 {
+		if k := len(*soss); n > k {
+			toss = append(make([]int, n-k), (*soss)...)
+			*soss = (*soss)[:0]
+		} else {
+			toss = append(toss, (*soss)[k-n:]...)
+			*soss = (*soss)[:k-n]
+		}
+	} else {
+		for ; n < 0; n++ {
+			*soss = append(*soss, 0)
+		}
+	}
+	it.pushVec(ip.offset)
+	ip.stacks = append(ip.stacks, toss)
+	ip.offset = ip.pos.add(ip.delta)
+}
+
+// endBlock implements `}`, which pops the TOSS from the stack stack.
+func (it *Interp) endBlock() {
+	ip := &it.ip
+	if len(ip.stacks) < 2 {
+		it.reflect()
+		return
+	}
+	n := it.pop()
+	toss := *it.toss()
+	ip.stacks = ip.stacks[:len(ip.stacks)-1]
+	ip.offset = it.popVec()
+	if n > 0 {
+		if k := len(toss); n > k {
+			*it.toss() = append(*it.toss(), make([]int, n-k)...)
+			*it.toss() = append(*it.toss(), toss...)
+		} else {
+			*it.toss() = append(*it.toss(), toss[k-n:]...)
+		}
+	} else {
+		for ; n < 0; n++ {
+			it.pop()
+		}
+	}
+}
+
+// stackUnderStack implements `u`, which moves values between the TOSS and the
+// SOSS.
+func (it *Interp) stackUnderStack() {
+	ip := &it.ip
+	if len(ip.stacks) < 2 {
+		it.reflect()
+		return
+	}
+	n := it.pop()
+	toss, soss := len(ip.stacks)-1, len(ip.stacks)-2
+	from, to := soss, toss
+	if n < 0 {
+		from, to, n = toss, soss, -n
+	}
+	for ; n > 0; n-- {
+		s := ip.stacks[from]
+		v := 0
+		if len(s) > 0 {
+			v, ip.stacks[from] = s[len(s)-1], s[:len(s)-1]
+		}
+		ip.stacks[to] = append(ip.stacks[to], v)
+	}
+}
+
+// sysInfo implements `y`, which pushes information about the interpreter and
+// the program's environment, or picks a single item of it.
+func (it *Interp) sysInfo() {
+	n := it.pop()
+	ip := &it.ip
+	base := len(*it.toss())
+
+	// The items are pushed in reverse order, the last one first.
+	env := os.Environ()
+	it.push(0)
+	for i := len(env) - 1; i >= 0; i-- {
+		it.pushString(env[i])
+	}
+	it.push(0)
+	it.push(0)
+	for i := len(it.Args) - 1; i >= 0; i-- {
+		it.pushString(it.Args[i])
+	}
+	for i := 0; i < len(ip.stacks); i++ {
+		size := len(ip.stacks[i])
+		if i == len(ip.stacks)-1 {
+			size = base
+		}
+		it.push(size)
+	}
+	it.push(len(ip.stacks))
+	now := time.Now()
+	it.push(now.Hour()<<16 | now.Minute()<<8 | now.Second())
+	it.push((now.Year()-1900)<<16 | int(now.Month())<<8 | now.Day())
+	it.pushVec(it.space.max.sub(it.space.min))
+	it.pushVec(it.space.min)
+	it.pushVec(ip.offset)
+	it.pushVec(ip.delta)
+	it.pushVec(ip.pos)
+	it.push(0)         // team number
+	it.push(0)         // IP ID
+	it.push(2)         // dimensions
+	it.push('/')       // path separator
+	it.push(0)         // operating paradigm: none
+	it.push(1)         // version
+	it.push(Handprint) // handprint
+	it.push(8)         // bytes per cell
+	it.push(0)         // flags: no t, i, o or =, buffered I/O
+
+	if n <= 0 {
+		return
+	}
+	s := it.toss()
+	v := 0
+	if i := len(*s) - n; i >= 0 {
+		v = (*s)[i]
+	}
+	*s = (*s)[:base]
+	it.push(v)
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package befunge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name, src, input, want string
+		exit                   int
+	}{
+		{name: "string", src: `"olleh",,,,,@`, want: "hello"},
+		{name: "arithmetic", src: `93-.93/.93%.25*.93` + "`.@", want: "6 3 0 10 1 "},
+		{name: "zero", src: `50/.50%.@`, want: "0 0 "},
+		{name: "wrap", src: `<@.2`, want: "2 "},
+		{name: "trampoline", src: `#@1.@`, want: "1 "},
+		{name: "comment", src: `;@;2.@`, want: "2 "},
+		{name: "lines", src: "v\n>2.@", want: "2 "},
+		{name: "turns", src: "1]@\n .\n @", want: "1 "},
+		{name: "fetch", src: `'A,@`, want: "A"},
+		{name: "store", src: `'A'Bs.,@`, want: "A"},
+		{name: "put", src: `"!"00p00g,@`, want: "!"},
+		{name: "iterate", src: `3k1...@`, want: "1 1 1 "},
+		{name: "jump", src: `2j@@1.@`, want: "1 "},
+		{name: "input", src: `&&+.~,@`, input: "12 30\nx", want: "42 x"},
+		{name: "eof", src: `~.@`, input: "A", want: "65 "},
+		{name: "stacks", src: `1232{..0}.@`, want: "3 2 1 "},
+		{name: "under", src: `120{4u....@`, want: "1 2 0 0 "},
+		{name: "sysinfo", src: `1y.2y.@`, want: "0 8 "},
+		{name: "fingerprint", src: `"AMOR"4(MX+.@`, want: "1010 "},
+		{name: "quit", src: `7q`, exit: 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out strings.Builder
+			it := New([]byte(test.src), strings.NewReader(test.input), &out)
+			it.Limit = 1000
+			exit, err := it.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != test.want || exit != test.exit {
+				t.Errorf("got %q, exit %d; want %q, exit %d", got, exit, test.want, test.exit)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	it := New([]byte(">"), strings.NewReader(""), &strings.Builder{})
+	it.Limit = 100
+	if _, err := it.Run(); !errors.Is(err, ErrLimit) {
+		t.Errorf("Run = %v, want ErrLimit", err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package befunge
+
+import "sort"
+
+// Fingerprint is a Funge-98 extension, loaded with the `(` instruction. It
+// binds some of the instructions A-Z to new semantics.
+type Fingerprint struct {
+	Name string
+	Ops  map[byte]func(*Interp)
+}
+
+// ID returns the fingerprint ID of the extension, as computed by `(` from the
+// characters of its name.
+func (f Fingerprint) ID() int {
+	id := 0
+	for _, c := range []byte(f.Name) {
+		id = id*256 + int(c)
+	}
+	return id
+}
+
+// Fingerprints lists the available fingerprints.
+var Fingerprints = []Fingerprint{
+	{Name: "NULL", Ops: nullOps()},
+	{Name: "BOOL", Ops: map[byte]func(*Interp){
+		'A': binOp(func(a, b int) int { return a & b }),
+		'N': func(it *Interp) { it.push(^it.pop()) },
+		'O': binOp(func(a, b int) int { return a | b }),
+		'X': binOp(func(a, b int) int { return a ^ b }),
+	}},
+	{Name: "MODU", Ops: map[byte]func(*Interp){
+		'M': binOp(func(a, b int) int {
+			if b == 0 {
+				return 0
+			}
+			m := a % b
+			if m != 0 && (m < 0) != (b < 0) {
+				m += b
+			}
+			return m
+		}),
+		'U': binOp(func(a, b int) int {
+			if b == 0 {
+				return 0
+			}
+			m := a % b
+			if m < 0 {
+				m = -m
+			}
+			return m
+		}),
+		'R': binOp(func(a, b int) int {
+			if b == 0 {
+				return 0
+			}
+			return a % b
+		}),
+	}},
+	{Name: "ROMA", Ops: map[byte]func(*Interp){
+		'C': pushConst(100),
+		'D': pushConst(500),
+		'I': pushConst(1),
+		'L': pushConst(50),
+		'M': pushConst(1000),
+		'V': pushConst(5),
+		'X': pushConst(10),
+	}},
+}
+
+func nullOps() map[byte]func(*Interp) {
+	ops := make(map[byte]func(*Interp))
+	for c := byte('A'); c <= 'Z'; c++ {
+		ops[c] = (*Interp).reflect
+	}
+	return ops
+}
+
+func binOp(f func(a, b int) int) func(*Interp) {
+	return func(it *Interp) {
+		b, a := it.pop(), it.pop()
+		it.push(f(a, b))
+	}
+}
+
+func pushConst(v int) func(*Interp) {
+	return func(it *Interp) { it.push(v) }
+}
+
+// popFingerprint pops a fingerprint ID, as used by `(` and `)`, and looks it
+// up.
+func (it *Interp) popFingerprint() (Fingerprint, bool) {
+	n, id := it.pop(), 0
+	for ; n > 0; n-- {
+		id = id*256 + it.pop()
+	}
+	for _, f := range Fingerprints {
+		if f.ID() == id {
+			return f, true
+		}
+	}
+	return Fingerprint{}, false
+}
+
+// loadFingerprint implements `(`, which pushes the semantics of a fingerprint
+// onto the semantics stacks of the instructions it defines.
+func (it *Interp) loadFingerprint() {
+	f, ok := it.popFingerprint()
+	if !ok {
+		it.reflect()
+		return
+	}
+	for _, c := range sortedOps(f) {
+		it.ip.semantics[c-'A'] = append(it.ip.semantics[c-'A'], f.Ops[c])
+	}
+	it.push(f.ID())
+	it.push(1)
+}
+
+// unloadFingerprint implements `)`, which pops the semantics of the
+// instructions a fingerprint defines, whether or not they came from it.
+func (it *Interp) unloadFingerprint() {
+	f, ok := it.popFingerprint()
+	if !ok {
+		it.reflect()
+		return
+	}
+	for _, c := range sortedOps(f) {
+		if sem := it.ip.semantics[c-'A']; len(sem) > 0 {
+			it.ip.semantics[c-'A'] = sem[:len(sem)-1]
+		}
+	}
+}
+
+func sortedOps(f Fingerprint) []byte {
+	ops := make([]byte, 0, len(f.Ops))
+	for c := range f.Ops {
+		ops = append(ops, c)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	return ops
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package befunge
+
+// Vec is a vector in Funge-space: a position, or a delta between positions.
+// The y axis grows downwards, as in the source code.
+type Vec struct {
+	X, Y int
+}
+
+func (v Vec) add(w Vec) Vec { return Vec{v.X + w.X, v.Y + w.Y} }
+func (v Vec) sub(w Vec) Vec { return Vec{v.X - w.X, v.Y - w.Y} }
+
+// chunkBits is the log2 of the side length of a chunk of Funge-space.
+const chunkBits = 6
+
+const (
+	chunkSize = 1 << chunkBits
+	chunkMask = chunkSize - 1
+)
+
+type chunk [chunkSize * chunkSize]int
+
+// space is an unbounded two-dimensional Funge-space. It is stored as square
+// chunks, allocated on demand; cells that have never been written hold spaces.
+type space struct {
+	chunks   map[Vec]*chunk
+	min, max Vec // bounds of the cells written so far, inclusive
+	empty    bool
+}
+
+func newSpace() *space {
+	return &space{chunks: make(map[Vec]*chunk), empty: true}
+}
+
+func chunkOf(p Vec) (key Vec, idx int) {
+	return Vec{p.X >> chunkBits, p.Y >> chunkBits}, (p.Y&chunkMask)<<chunkBits | p.X&chunkMask
+}
+
+func (s *space) get(p Vec) int {
+	key, idx := chunkOf(p)
+	if c, ok := s.chunks[key]; ok {
+		return c[idx]
+	}
+	return ' '
+}
+
+// put stores a value in a cell. The bounds of the space are only ever grown,
+// which the specification permits, and keeps them cheap to maintain.
+func (s *space) put(p Vec, v int) {
+	key, idx := chunkOf(p)
+	c, ok := s.chunks[key]
+	if !ok {
+		if v == ' ' {
+			return
+		}
+		c = new(chunk)
+		for i := range c {
+			c[i] = ' '
+		}
+		s.chunks[key] = c
+	}
+	c[idx] = v
+	if v == ' ' {
+		return
+	}
+	if s.empty {
+		s.min, s.max, s.empty = p, p, false
+		return
+	}
+	s.min = Vec{min(s.min.X, p.X), min(s.min.Y, p.Y)}
+	s.max = Vec{max(s.max.X, p.X), max(s.max.Y, p.Y)}
+}
+
+// load stores source code into the space, with its first character at the
+// origin. Form feeds are ignored, as is a final line terminator.
+func (s *space) load(src []byte) {
+	var p Vec
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '\r':
+			if i+1 < len(src) && src[i+1] == '\n' {
+				i++
+			}
+			fallthrough
+		case '\n':
+			p = Vec{0, p.Y + 1}
+		case '\f':
+		default:
+			s.put(p, int(c))
+			p.X++
+		}
+	}
+}
+
+func (s *space) contains(p Vec) bool {
+	return !s.empty && p.X >= s.min.X && p.X <= s.max.X && p.Y >= s.min.Y && p.Y <= s.max.Y
+}
+
+// next returns the position following p when moving by delta, wrapping around
+// as in Lahey-space: a move that would leave the bounds of the space continues
+// from the opposite side, along the same line.
+func (s *space) next(p, delta Vec) Vec {
+	q := p.add(delta)
+	if s.contains(q) || delta == (Vec{}) {
+		return q
+	}
+	for {
+		r := p.sub(delta)
+		if !s.contains(r) {
+			return p
+		}
+		p = r
+	}
+}
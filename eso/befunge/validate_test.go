@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package befunge_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/eso/befunge"
+	"github.com/fis/aoc/util"
+)
+
+// maxTicks is the number of instructions after which a solution is considered to be stuck.
+const maxTicks = 10_000_000_000
+
+var reProgName = regexp.MustCompile(`^(\d{4})-(\d\d)-(\d)\.b98$`)
+
+func TestSolutions(t *testing.T) {
+	progs, err := filepath.Glob("../20*/*.b98")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prog := range progs {
+		m := reProgName.FindStringSubmatch(filepath.Base(prog))
+		if m == nil {
+			continue
+		}
+		prog, year, day := prog, m[1], m[2]
+		part, _ := strconv.Atoi(m[3])
+		t.Run(strings.TrimSuffix(filepath.Base(prog), ".b98"), func(t *testing.T) {
+			src, err := os.ReadFile(prog)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input, err := os.ReadFile(filepath.Join("../../testdata", year, "day"+day+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := util.ReadLines(filepath.Join("../../testdata", year, "day"+day+".out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if part > len(want) {
+				t.Fatalf("no expected output for part %d", part)
+			}
+			var out strings.Builder
+			it := befunge.New(src, bytes.NewReader(input), &out)
+			it.Limit = maxTicks
+			if _, err := it.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(out.String()); got != want[part-1] {
+				t.Errorf("got %q, want %q", got, want[part-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// arith applies an arithmetic operation to two numbers. Integers use the
+// integer operation, and any doubles make it a floating point one.
+func arith(a, b Value, ints func(a, b Value) Value, floats func(x, y float64) float64) (Value, bool) {
+	if isInt(a) && isInt(b) {
+		return ints(a, b), true
+	}
+	if isNumber(a) && isNumber(b) {
+		return Double(floats(toFloat(a), toFloat(b))), true
+	}
+	return nil, false
+}
+
+func isNumber(v Value) bool {
+	_, ok := v.(Double)
+	return ok || isInt(v)
+}
+
+// vectorize lifts a binary operation over blocks: element-wise for two
+// blocks, and against each element for a block and a scalar.
+func vectorize(name Ident, f func(a, b Value) (Value, bool)) func(a, b Value) Value {
+	var g func(a, b Value) Value
+	g = func(a, b Value) Value {
+		ab, aBlock := a.(Block)
+		bb, bBlock := b.(Block)
+		as, aStream := a.(Stream)
+		bs, bStream := b.(Stream)
+		switch {
+		case aStream && !bStream && !bBlock:
+			return as.mapItems(func(x Value) Value { return g(x, b) })
+		case bStream && !aStream && !aBlock:
+			return bs.mapItems(func(y Value) Value { return g(a, y) })
+		case aBlock && bBlock:
+			out := make(Block, min(len(ab), len(bb)))
+			for i := range out {
+				out[i] = g(ab[i], bb[i])
+			}
+			return out
+		case aBlock:
+			out := make(Block, len(ab))
+			for i, x := range ab {
+				out[i] = g(x, b)
+			}
+			return out
+		case bBlock:
+			out := make(Block, len(bb))
+			for i, y := range bb {
+				out[i] = g(a, y)
+			}
+			return out
+		}
+		v, ok := f(a, b)
+		if !ok {
+			unsupported(name, a, b)
+		}
+		return v
+	}
+	return g
+}
+
+func addValues(a, b Value) (Value, bool) {
+	return arith(a, b, addInt, func(x, y float64) float64 { return x + y })
+}
+
+func subValues(a, b Value) (Value, bool) {
+	return arith(a, b, subInt, func(x, y float64) float64 { return x - y })
+}
+
+func mulValues(a, b Value) (Value, bool) {
+	return arith(a, b, mulInt, func(x, y float64) float64 { return x * y })
+}
+
+func divValues(a, b Value) (Value, bool) {
+	if isInt(b) && toBig(b).Sign() == 0 {
+		fail("division by zero")
+	}
+	return arith(a, b, divInt, func(x, y float64) float64 { return x / y })
+}
+
+func modValues(a, b Value) (Value, bool) {
+	if isInt(b) && toBig(b).Sign() == 0 {
+		fail("division by zero")
+	}
+	return arith(a, b, modInt, math.Mod)
+}
+
+// binary returns a built-in that pops two values and pushes the result of f,
+// or fails as unsupported if f does not handle the types.
+func binary(f func(a, b Value) (Value, bool)) builtin {
+	return func(it *Interp, name Ident) {
+		a, b := it.pop2()
+		v, ok := f(a, b)
+		if !ok {
+			unsupported(name, a, b)
+		}
+		it.push(v)
+	}
+}
+
+// unary is the one-argument counterpart of binary.
+func unary(f func(a Value) (Value, bool)) builtin {
+	return func(it *Interp, name Ident) {
+		a := it.pop()
+		v, ok := f(a)
+		if !ok {
+			unsupported(name, a)
+		}
+		it.push(v)
+	}
+}
+
+// vectorized returns a built-in like binary, but lifted over blocks.
+func vectorized(f func(a, b Value) (Value, bool)) builtin {
+	return func(it *Interp, name Ident) {
+		a, b := it.pop2()
+		it.push(vectorize(name, f)(a, b))
+	}
+}
+
+func negate(a Value) (Value, bool) {
+	switch a := a.(type) {
+	case Int:
+		if a != math.MinInt64 {
+			return -a, true
+		}
+	case Double:
+		return -a, true
+	}
+	if isInt(a) {
+		return normInt(new(big.Int).Neg(toBig(a))), true
+	}
+	return nil, false
+}
+
+func compareOp(f func(c int) bool) builtin {
+	return func(it *Interp, _ Ident) {
+		a, b := it.pop2()
+		it.push(boolInt(f(compare(a, b))))
+	}
+}
+
+func bitOp(ints func(x, y int64) int64, bigs func(z, x, y *big.Int) *big.Int) func(a, b Value) (Value, bool) {
+	return func(a, b Value) (Value, bool) {
+		if !isInt(a) || !isInt(b) {
+			return nil, false
+		}
+		return intOp(a, b, func(x, y int64) (int64, bool) { return ints(x, y), true }, bigs), true
+	}
+}
+
+// reduceWith returns a built-in that folds a nonempty list with a binary
+// operation.
+func reduceWith(f func(a, b Value) (Value, bool)) builtin {
+	return func(it *Interp, name Ident) {
+		_, vs := it.popList(name)
+		if len(vs) == 0 {
+			fail("%s: empty list", name)
+		}
+		acc := vs[0]
+		for _, v := range vs[1:] {
+			var ok bool
+			if acc, ok = f(acc, v); !ok {
+				unsupported(name, acc, v)
+			}
+		}
+		it.push(acc)
+	}
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		".+": binary(func(a, b Value) (Value, bool) {
+			if v, ok := addValues(a, b); ok {
+				return v, true
+			}
+			switch a := a.(type) {
+			case Str:
+				switch b := b.(type) {
+				case Block:
+					as, _ := items(a)
+					return rebuild(a, concat(as, b)), true
+				case Str:
+					return a + b, true
+				case Char:
+					return a + Str(b), true
+				case Int:
+					return Str(take([]byte(a), int(b))), true
+				}
+			case Block:
+				switch b := b.(type) {
+				case Block:
+					return concat(a, b), true
+				case Int:
+					return Block(take(a, int(b))), true
+				case Str:
+					bs, _ := items(b)
+					return concat(a, bs), true
+				}
+			case Stream:
+				if b, ok := b.(Int); ok {
+					return Block(a.take(max(0, int(b)))), true
+				}
+			case Char:
+				if b, ok := b.(Char); ok {
+					return Str([]byte{byte(a), byte(b)}), true
+				}
+			}
+			return nil, false
+		}),
+		".-": binary(func(a, b Value) (Value, bool) {
+			if v, ok := subValues(a, b); ok {
+				return v, true
+			}
+			if n, ok := a.(Int); ok {
+				a, b = b, n
+			}
+			if n, ok := b.(Int); ok {
+				switch a := a.(type) {
+				case Stream:
+					return a.drop(max(0, int(n))), true
+				case Str:
+					return Str(drop([]byte(a), int(n))), true
+				case Block:
+					return Block(drop(a, int(n))), true
+				}
+			}
+			return nil, false
+		}),
+		".*": binary(func(a, b Value) (Value, bool) {
+			if v, ok := mulValues(a, b); ok {
+				return v, true
+			}
+			if n, ok := b.(Int); ok && n >= 0 {
+				switch a := a.(type) {
+				case Char:
+					return Str(strings.Repeat(string([]byte{byte(a)}), int(n))), true
+				case Str, Block:
+					out := make(Block, n)
+					for i := range out {
+						out[i] = a
+					}
+					return out, true
+				}
+			}
+			return nil, false
+		}),
+		"./": binary(func(a, b Value) (Value, bool) {
+			if a, ok := a.(Str); ok {
+				if b, ok := b.(Str); ok {
+					return Str(strings.TrimPrefix(string(a), string(b))), true
+				}
+			}
+			return divValues(a, b)
+		}),
+		".%": binary(modValues),
+		"+.": unary(func(a Value) (Value, bool) {
+			if c, ok := a.(Char); ok {
+				return c + 1, true
+			}
+			return addValues(a, Int(1))
+		}),
+		"-.": unary(func(a Value) (Value, bool) {
+			// On lists, -. prepends the first item.
+			if vs, ok := items(a); ok && len(vs) > 0 {
+				return rebuild(a, append([]Value{vs[0]}, vs...)), true
+			}
+			if c, ok := a.(Char); ok {
+				return c - 1, true
+			}
+			return subValues(a, Int(1))
+		}),
+		"ng": func(it *Interp, name Ident) {
+			it.push(vectorize(name, func(a, _ Value) (Value, bool) { return negate(a) })(it.pop(), Int(0)))
+		},
+		"ab": unary(func(a Value) (Value, bool) {
+			if isNumber(a) && compare(a, Int(0)) < 0 {
+				return negate(a)
+			}
+			return a, isNumber(a)
+		}),
+		"sn": unary(func(a Value) (Value, bool) {
+			if !isNumber(a) {
+				return nil, false
+			}
+			return Int(compare(a, Int(0))), true
+		}),
+
+		// Vectorized arithmetic.
+		"?+": vectorized(addValues),
+		"?-": vectorized(subValues),
+		"?*": vectorized(mulValues),
+		"?/": vectorized(divValues),
+		"?i": func(it *Interp, name Ident) { it.push(vectorize(name, addValues)(it.pop(), Int(1))) },
+		"?d": func(it *Interp, name Ident) { it.push(vectorize(name, subValues)(it.pop(), Int(1))) },
+
+		// Comparison and logic.
+		"=s": func(it *Interp, name Ident) {
+			a, b := it.pop2()
+			as, ok1 := items(a)
+			bs, ok2 := items(b)
+			if !ok1 || !ok2 {
+				unsupported(name, a, b)
+			}
+			it.push(boolInt(compare(Block(sortValues(as, false)), Block(sortValues(bs, false))) == 0))
+		},
+		"==": compareOp(func(c int) bool { return c == 0 }),
+		"!=": compareOp(func(c int) bool { return c != 0 }),
+		".<": compareOp(func(c int) bool { return c < 0 }),
+		".>": compareOp(func(c int) bool { return c > 0 }),
+		"<=": compareOp(func(c int) bool { return c <= 0 }),
+		">=": compareOp(func(c int) bool { return c >= 0 }),
+		"cm": func(it *Interp, _ Ident) {
+			a, b := it.pop2()
+			it.push(Int(compare(a, b)))
+		},
+		">.": func(it *Interp, _ Ident) {
+			a, b := it.pop2()
+			if compare(a, b) >= 0 {
+				it.push(a)
+			} else {
+				it.push(b)
+			}
+		},
+		"<.": func(it *Interp, _ Ident) {
+			a, b := it.pop2()
+			if compare(a, b) <= 0 {
+				it.push(a)
+			} else {
+				it.push(b)
+			}
+		},
+		"&&": binary(bitOp(func(x, y int64) int64 { return x & y }, (*big.Int).And)),
+		"||": binary(bitOp(func(x, y int64) int64 { return x | y }, (*big.Int).Or)),
+		"r&": reduceWith(bitOp(func(x, y int64) int64 { return x & y }, (*big.Int).And)),
+		"r|": reduceWith(bitOp(func(x, y int64) int64 { return x | y }, (*big.Int).Or)),
+		"n!": func(it *Interp, _ Ident) {
+			// On a list, n! is the most common item.
+			if vs, ok := items(it.top()); ok && len(vs) > 0 {
+				it.pop()
+				fs := byFrequency(vs)
+				it.push(fs[len(fs)-1])
+				return
+			}
+			it.push(boolInt(!truthy(it.pop())))
+		},
+	})
+}
+
+func power(a, b Value) (Value, bool) {
+	if isInt(a) && isInt(b) {
+		if toBig(b).Sign() < 0 {
+			return nil, false
+		}
+		return normInt(new(big.Int).Exp(toBig(a), toBig(b), nil)), true
+	}
+	if isNumber(a) && isNumber(b) {
+		return Double(math.Pow(toFloat(a), toFloat(b))), true
+	}
+	return nil, false
+}
+
+// rounding returns a built-in that rounds a double to an integer-valued
+// double, and leaves integers as they are.
+func rounding(f func(float64) float64) builtin {
+	return unary(func(a Value) (Value, bool) {
+		if d, ok := a.(Double); ok {
+			return Double(f(float64(d))), true
+		}
+		return a, isInt(a)
+	})
+}
+
+func primeFactors(n int64) Block {
+	var out Block
+	for p := int64(2); p*p <= n; p++ {
+		for n%p == 0 {
+			out = append(out, Int(p))
+			n /= p
+		}
+	}
+	if n > 1 {
+		out = append(out, Int(n))
+	}
+	return out
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"**": func(it *Interp, name Ident) {
+			if c, ok := it.top().(Char); ok {
+				it.pop()
+				it.push(Int(c))
+				return
+			}
+			binary(power)(it, name)
+		},
+		"|+": binary(func(a, b Value) (Value, bool) {
+			// Unlike .+ and .-, |+ and |- read numbers from strings.
+			return addValues(readNumber(a), readNumber(b))
+		}),
+		"|-": binary(func(a, b Value) (Value, bool) {
+			return subValues(readNumber(a), readNumber(b))
+		}),
+		"z?": func(it *Interp, _ Ident) { it.push(boolInt(!truthy(it.pop()))) },
+		"ck": func(it *Interp, _ Ident) { it.push(boolInt(truthy(it.pop()))) },
+		"S[": func(it *Interp, name Ident) {
+			// Below a list, S[ strips the value from its start; alone, it
+			// squares a number.
+			if len(it.stack) >= 2 {
+				if vs, ok := items(it.stack[len(it.stack)-2]); ok {
+					x := it.pop()
+					l := it.pop()
+					i := 0
+					for i < len(vs) && equal(vs[i], x) {
+						i++
+					}
+					it.push(rebuild(l, vs[i:]))
+					return
+				}
+			}
+			unary(func(a Value) (Value, bool) { return mulValues(a, a) })(it, name)
+		},
+		"nz": func(it *Interp, _ Ident) { it.push(boolInt(truthy(it.pop()))) },
+		"fC": func(it *Interp, name Ident) { it.push(primeFactors(int64(it.popInt(name)))) },
+		"av": rounding(math.Floor),
+		"cl": rounding(math.Ceil),
+		"ti": unary(func(a Value) (Value, bool) {
+			if d, ok := a.(Double); ok {
+				n, _ := big.NewFloat(float64(d)).Int(nil)
+				return normInt(n), true
+			}
+			return a, isInt(a)
+		}),
+	})
+}
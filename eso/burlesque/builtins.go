@@ -0,0 +1,230 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import "slices"
+
+// builtin is the implementation of a built-in command. The name is passed in
+// for error messages, so that one function can serve several names.
+type builtin func(it *Interp, name Ident)
+
+// builtins maps the names of built-in commands to their implementations.
+var builtins = map[Ident]builtin{}
+
+func addBuiltins(m map[Ident]builtin) {
+	for name, f := range m {
+		if _, ok := builtins[name]; ok {
+			panic("duplicate built-in: " + name)
+		}
+		builtins[name] = f
+	}
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		// Stack manipulation.
+		"J":  func(it *Interp, _ Ident) { it.push(it.top()) },
+		"vv": func(it *Interp, _ Ident) { it.pop() },
+		"j": func(it *Interp, _ Ident) {
+			a, b := it.pop2()
+			it.push(b, a)
+		},
+		"x/": func(it *Interp, _ Ident) {
+			a, b, c := it.pop3()
+			it.push(b, c, a)
+		},
+		"#r": func(it *Interp, _ Ident) {
+			if n := len(it.stack); n > 0 {
+				it.stack = append([]Value{it.stack[n-1]}, it.stack[:n-1]...)
+			}
+		},
+		"#R": func(it *Interp, _ Ident) {
+			if n := len(it.stack); n > 0 {
+				it.stack = append(it.stack[1:n:n], it.stack[0])
+			}
+		},
+		"MV": func(it *Interp, name Ident) {
+			n := it.popInt(name)
+			if n < 0 || n >= len(it.stack) {
+				fail("%s: stack too shallow", name)
+			}
+			i := len(it.stack) - 1 - n
+			v := it.stack[i]
+			it.stack = append(slices.Delete(it.stack, i, i+1), v)
+		},
+		"it": func(it *Interp, _ Ident) { it.stack = []Value{it.pop()} },
+		"/v": func(it *Interp, _ Ident) {
+			a := it.pop()
+			it.pop()
+			it.push(a)
+		},
+		"CL": func(it *Interp, _ Ident) {
+			// The collected block lists the stack top first.
+			b := slices.Clone(it.stack)
+			slices.Reverse(b)
+			it.stack = []Value{Block(b)}
+		},
+
+		// The global stack.
+		"Pp": func(it *Interp, _ Ident) { it.pocket = append(it.pocket, it.pop()) },
+		"PP": func(it *Interp, name Ident) {
+			it.push(it.pocketTop(name))
+			it.pocket = it.pocket[:len(it.pocket)-1]
+		},
+		"pP":  func(it *Interp, name Ident) { it.push(it.pocketTop(name)) },
+		"p\\": func(it *Interp, _ Ident) { it.stack, it.pocket = it.pocket, it.stack },
+
+		// The hidden stack: hd hides a value, #a and #b load the top two
+		// hidden values, `a and `b replace them, and !a and !b evaluate them.
+		"hd": func(it *Interp, _ Ident) { it.hidden = append(it.hidden, it.pop()) },
+		"#a": func(it *Interp, name Ident) { it.push(*it.hiddenAt(name, 0)) },
+		"#b": func(it *Interp, name Ident) { it.push(*it.hiddenAt(name, 1)) },
+		"`a": func(it *Interp, name Ident) { *it.hiddenAt(name, 0) = it.pop() },
+		"`b": func(it *Interp, name Ident) { *it.hiddenAt(name, 1) = it.pop() },
+		"!a": func(it *Interp, name Ident) { it.evalValue(*it.hiddenAt(name, 0)) },
+		"!b": func(it *Interp, name Ident) { it.evalValue(*it.hiddenAt(name, 1)) },
+
+		// Control flow.
+		"e!": func(it *Interp, _ Ident) { it.evalValue(it.pop()) },
+		"pe": func(it *Interp, name Ident) {
+			src := it.popStr(name)
+			b, err := Parse(string(src))
+			if err != nil {
+				fail("%s: %v", name, err)
+			}
+			it.eval(b)
+		},
+		"E!": func(it *Interp, name Ident) {
+			b, n := it.pop2()
+			body, ok1 := b.(Block)
+			count, ok2 := n.(Int)
+			if !ok1 || !ok2 {
+				unsupported(name, b, n)
+			}
+			for i := Int(0); i < count; i++ {
+				it.eval(body)
+			}
+		},
+		"if": func(it *Interp, name Ident) {
+			c, b := it.pop2()
+			body, ok := b.(Block)
+			if !ok {
+				unsupported(name, c, b)
+			}
+			if truthy(c) {
+				it.eval(body)
+			}
+		},
+		"ch": func(it *Interp, name Ident) {
+			c, b := it.pop2()
+			bs, ok := b.(Block)
+			if !ok || len(bs) != 2 {
+				unsupported(name, c, b)
+			}
+			if truthy(c) {
+				it.push(bs[0])
+			} else {
+				it.push(bs[1])
+			}
+		},
+		"ie": func(it *Interp, name Ident) {
+			t, f, c := it.pop3()
+			tb, ok1 := t.(Block)
+			fb, ok2 := f.(Block)
+			if !ok1 || !ok2 {
+				unsupported(name, t, f, c)
+			}
+			if truthy(c) {
+				it.eval(tb)
+			} else {
+				it.eval(fb)
+			}
+		},
+		"w!": func(it *Interp, name Ident) {
+			b, c := it.pop2()
+			body, ok1 := b.(Block)
+			cond, ok2 := c.(Block)
+			if !ok1 || !ok2 {
+				unsupported(name, b, c)
+			}
+			for truthy(it.evalTop(cond, it.stack...)) {
+				it.eval(body)
+			}
+		},
+	})
+
+	// Variables: sN stores, SN stores without popping, gN loads. An unset
+	// variable reads as an empty block.
+	for d := byte('0'); d <= '9'; d++ {
+		i := int(d - '0')
+		addBuiltins(map[Ident]builtin{
+			Ident([]byte{'s', d}): func(it *Interp, _ Ident) { it.vars[i] = it.pop() },
+			Ident([]byte{'S', d}): func(it *Interp, _ Ident) { it.vars[i] = it.top() },
+			Ident([]byte{'g', d}): func(it *Interp, name Ident) {
+				if it.vars[i] == nil {
+					it.push(Block{})
+					return
+				}
+				it.push(it.vars[i])
+			},
+		})
+	}
+
+	// Variables named by values: sv stores a value under the key on top of
+	// it, and gv loads one, or an empty block for a key that is not set.
+	addBuiltins(map[Ident]builtin{
+		"sv": func(it *Interp, _ Ident) {
+			k, v := it.pop(), it.pop()
+			if it.named == nil {
+				it.named = make(map[string]Value)
+			}
+			it.named[key(k)] = v
+		},
+		"gv": func(it *Interp, _ Ident) {
+			v, ok := it.named[key(it.pop())]
+			if !ok {
+				v = Block{}
+			}
+			it.push(v)
+		},
+	})
+}
+
+func (it *Interp) pocketTop(name Ident) Value {
+	if len(it.pocket) == 0 {
+		fail("%s: empty global stack", name)
+	}
+	return it.pocket[len(it.pocket)-1]
+}
+
+func (it *Interp) hiddenAt(name Ident, depth int) *Value {
+	if depth >= len(it.hidden) {
+		fail("%s: not enough hidden values", name)
+	}
+	return &it.hidden[len(it.hidden)-1-depth]
+}
+
+// evalValue evaluates a value as code: blocks and identifiers are run, and
+// anything else is pushed.
+func (it *Interp) evalValue(v Value) {
+	switch v := v.(type) {
+	case Block:
+		it.eval(v)
+	case Ident:
+		it.call(v)
+	default:
+		it.push(v)
+	}
+}
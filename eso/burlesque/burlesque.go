@@ -0,0 +1,233 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package burlesque implements an interpreter for a subset of Burlesque, the
+// stack-based esoteric language by Roman Müntener.
+//
+// A program starts with its input on the stack, as a single string, and when
+// it ends, the stack is printed, top first, one value per line. The
+// interpreter covers the built-ins used by the solutions under eso/; for
+// others, and for argument types a built-in does not handle, Run returns an
+// *UnsupportedError.
+package burlesque
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimit is returned when a program runs for longer than the step limit.
+var ErrLimit = errors.New("step limit exceeded")
+
+// UnsupportedError is returned for built-ins the interpreter does not know,
+// or does not know for the types of its arguments.
+type UnsupportedError struct {
+	Name  Ident
+	Types []string // argument types, if the built-in itself exists
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Types == nil {
+		return fmt.Sprintf("unsupported built-in: %s", e.Name)
+	}
+	return fmt.Sprintf("unsupported built-in: %s for (%s)", e.Name, strings.Join(e.Types, ", "))
+}
+
+// Interp is the state of a running Burlesque program.
+type Interp struct {
+	// Limit is the maximum number of steps to take, or 0 for no limit. A step
+	// is evaluating a value, or building an item of a list.
+	Limit int64
+
+	prog   Block
+	stack  []Value
+	pocket []Value // the global stack of Pp and PP
+	hidden []Value // the hidden values of hd
+	vars   [10]Value
+	named  map[string]Value // the variables of sv and gv, by key
+	defs   map[Ident]Block
+	steps  int64
+	depth  int // nesting of user-defined commands
+}
+
+// maxDepth bounds the recursion of user-defined commands, so that a runaway
+// program fails instead of exhausting the Go stack.
+const maxDepth = 10000
+
+// runtimeError is used to unwind the interpreter when a built-in fails.
+type runtimeError struct{ err error }
+
+// New parses a program, and returns an interpreter ready to run it with the
+// given input.
+func New(src, input string) (*Interp, error) {
+	prog, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Interp{prog: prog, stack: []Value{Str(input)}, defs: make(map[Ident]Block)}, nil
+}
+
+// Run runs a program to completion, and returns what it prints.
+func Run(src, input string) (string, error) {
+	it, err := New(src, input)
+	if err != nil {
+		return "", err
+	}
+	if err := it.Run(); err != nil {
+		return "", err
+	}
+	return it.Output(), nil
+}
+
+// Run runs the program.
+func (it *Interp) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			re, ok := r.(runtimeError)
+			if !ok {
+				panic(r)
+			}
+			err = re.err
+		}
+	}()
+	it.eval(it.prog)
+	return nil
+}
+
+// Stack returns the contents of the stack, top last.
+func (it *Interp) Stack() []Value { return it.stack }
+
+// Output formats the stack the way the program prints it when it ends.
+func (it *Interp) Output() string {
+	var sb strings.Builder
+	for i := len(it.stack) - 1; i >= 0; i-- {
+		sb.WriteString(Display(it.stack[i]))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func fail(format string, args ...any) {
+	panic(runtimeError{fmt.Errorf(format, args...)})
+}
+
+func unsupported(name Ident, args ...Value) {
+	types := make([]string, len(args))
+	for i, a := range args {
+		types[i] = typeName(a)
+	}
+	panic(runtimeError{&UnsupportedError{Name: name, Types: types}})
+}
+
+// eval evaluates the values of a block, in order, on the current stack.
+func (it *Interp) eval(b Block) {
+	for _, v := range b {
+		it.steps++
+		if it.Limit > 0 && it.steps > it.Limit {
+			panic(runtimeError{ErrLimit})
+		}
+		switch v := v.(type) {
+		case Ident:
+			it.call(v)
+		case Quote:
+			it.push(Ident(v))
+		case def:
+			it.defs[v.name] = v.body
+		default:
+			it.push(v)
+		}
+	}
+}
+
+func (it *Interp) call(name Ident) {
+	if body, ok := it.defs[name]; ok {
+		if it.depth++; it.depth > maxDepth {
+			fail("%s: recursion too deep", name)
+		}
+		it.eval(body)
+		it.depth--
+		return
+	}
+	f, ok := builtins[name]
+	if !ok {
+		panic(runtimeError{&UnsupportedError{Name: name}})
+	}
+	f(it, name)
+	// Built-ins that build lists cost about as much as evaluating their items.
+	if len(it.stack) > 0 {
+		switch v := it.stack[len(it.stack)-1].(type) {
+		case Block:
+			it.steps += int64(len(v))
+		case Str:
+			it.steps += int64(len(v))
+		}
+	}
+}
+
+// evalOn evaluates a block on a fresh stack holding the given values, and
+// returns the resulting stack.
+func (it *Interp) evalOn(b Block, args ...Value) []Value {
+	saved := it.stack
+	it.stack = append([]Value(nil), args...)
+	it.eval(b)
+	result := it.stack
+	it.stack = saved
+	return result
+}
+
+// evalTop is like evalOn, but only returns the top of the resulting stack.
+func (it *Interp) evalTop(b Block, args ...Value) Value {
+	st := it.evalOn(b, args...)
+	if len(st) == 0 {
+		fail("block left an empty stack")
+	}
+	return st[len(st)-1]
+}
+
+// test evaluates a block as a predicate on a value.
+func (it *Interp) test(b Block, v Value) bool {
+	return truthy(it.evalTop(b, v))
+}
+
+func (it *Interp) push(vs ...Value) { it.stack = append(it.stack, vs...) }
+
+func (it *Interp) pop() Value {
+	if len(it.stack) == 0 {
+		fail("stack underflow")
+	}
+	v := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	return v
+}
+
+func (it *Interp) pop2() (a, b Value) {
+	b = it.pop()
+	a = it.pop()
+	return a, b
+}
+
+func (it *Interp) pop3() (a, b, c Value) {
+	c = it.pop()
+	b = it.pop()
+	a = it.pop()
+	return a, b, c
+}
+
+func (it *Interp) top() Value {
+	if len(it.stack) == 0 {
+		fail("stack underflow")
+	}
+	return it.stack[len(it.stack)-1]
+}
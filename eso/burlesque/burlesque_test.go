@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name, src, input, want string
+	}{
+		{name: "input", src: `ln{ri}m[++`, input: "1\n2\n3\n", want: "6\n"},
+		{name: "arithmetic", src: `vv2 3.+`, want: "5\n"},
+		{name: "reverse", src: `vv"hello"<-`, want: "olleh\n"},
+		{name: "sort", src: `vv{3 1 2}><`, want: "{1 2 3}\n"},
+		{name: "map", src: `vv{1 2 3}{2.*}m[`, want: "{2 4 6}\n"},
+		{name: "filter", src: `vv{1 2 3}{2.>}f[`, want: "{3}\n"},
+		{name: "range", src: `vv5ro++`, want: "15\n"},
+		{name: "collect", src: `vv1 2 3CL`, want: "{3 2 1}\n"},
+		{name: "split", src: `vv"a,b"",";;`, want: "{a b}\n"},
+		{name: "repeat", src: `vv"x"4.*`, want: "{x x x x}\n"},
+		{name: "while", src: `vv0{+.}{J10.<}w!`, want: "10\n"},
+		{name: "define", src: `vv%dB={2.*}21dB`, want: "42\n"},
+		{name: "stream", src: `vv{1 2}cy4.+`, want: "{1 2 1 2}\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Run(test.src, test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	it, err := New(`vv{1}{1}w!`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it.Limit = 1000
+	if err := it.Run(); !errors.Is(err, ErrLimit) {
+		t.Errorf("Run = %v, want ErrLimit", err)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	_, err := Run(`vvxx`, "")
+	var ue *UnsupportedError
+	if !errors.As(err, &ue) || ue.Name != "xx" {
+		t.Errorf("Run = %v, want unsupported xx", err)
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import "slices"
+
+// mapList applies a block to each item of a list, each on a fresh stack, and
+// collects everything the block leaves on it, top first like CL. The explode
+// function, if not nil, determines how an item is put on the stack.
+func (it *Interp) mapList(f Block, vs []Value, explode func(v Value) []Value) Block {
+	var out Block
+	for _, v := range vs {
+		args := []Value{v}
+		if explode != nil {
+			args = explode(v)
+		}
+		out = appendResults(out, it.evalOn(f, args...))
+	}
+	return out
+}
+
+// appendResults appends the stack left by a block to a list of results, top
+// first.
+func appendResults(out Block, st []Value) Block {
+	for i := len(st) - 1; i >= 0; i-- {
+		out = append(out, st[i])
+	}
+	return out
+}
+
+// pushAll is the explode function of ^p: the items go on the stack in order.
+func pushAll(v Value) []Value {
+	vs, ok := items(v)
+	if !ok {
+		return []Value{v}
+	}
+	return vs
+}
+
+// pushAllReversed is the explode function of p^, which leaves the first item
+// on top.
+func pushAllReversed(v Value) []Value {
+	vs := pushAll(v)
+	out := make([]Value, len(vs))
+	for i, x := range vs {
+		out[len(vs)-1-i] = x
+	}
+	return out
+}
+
+func mapper(explode func(v Value) []Value, post func(it *Interp, name Ident, b Block) Value) builtin {
+	return func(it *Interp, name Ident) {
+		_, vs, f := it.popListFunc(name)
+		res := it.mapList(f, vs, explode)
+		if post != nil {
+			it.push(post(it, name, res))
+		} else {
+			it.push(res)
+		}
+	}
+}
+
+// pushTwice is the explode function of [m, which duplicates each item.
+func pushTwice(v Value) []Value { return []Value{v, v} }
+
+// extremeBy returns a built-in that picks the first item of a list with the
+// smallest (sign -1) or largest (sign +1) key computed by a block.
+func extremeBy(sign int) builtin {
+	return func(it *Interp, name Ident) {
+		_, vs, f := it.popListFunc(name)
+		if len(vs) == 0 {
+			fail("%s: empty list", name)
+		}
+		best, bestKey := vs[0], it.evalTop(f, vs[0])
+		for _, v := range vs[1:] {
+			if k := it.evalTop(f, v); compare(k, bestKey)*sign > 0 {
+				best, bestKey = v, k
+			}
+		}
+		it.push(best)
+	}
+}
+
+// popFuncList is like popListFunc, but for the built-ins that take the
+// block and the list in either order.
+func (it *Interp) popFuncList(name Ident) (Value, []Value, Block) {
+	if _, ok := it.top().(Block); ok && len(it.stack) >= 2 {
+		if _, ok := it.stack[len(it.stack)-2].(Block); !ok {
+			return it.popListFunc(name)
+		}
+	}
+	a, b := it.pop2()
+	f, ok1 := a.(Block)
+	vs, ok2 := items(b)
+	if !ok1 || !ok2 {
+		unsupported(name, a, b)
+	}
+	return b, vs, f
+}
+
+func (it *Interp) findIndex(f Block, vs []Value) int {
+	for i, v := range vs {
+		if it.test(f, v) {
+			return i
+		}
+	}
+	return -1
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"m[":  mapper(nil, nil),
+		"[m":  mapper(pushTwice, nil),
+		"m^":  mapper(pushAllReversed, nil),
+		"^m":  mapper(pushAll, nil),
+		"\\m": mapper(nil, func(_ *Interp, _ Ident, b Block) Value { return concatAll(b) }),
+		"ms":  mapper(nil, func(_ *Interp, name Ident, b Block) Value { return sum(name, b) }),
+		"mp":  mapper(nil, func(_ *Interp, name Ident, b Block) Value { return product(name, b) }),
+		"mu":  mapper(nil, func(_ *Interp, _ Ident, b Block) Value { return unlines(b) }),
+		"f[": func(it *Interp, name Ident) {
+			l, vs, f := it.popListFunc(name)
+			var out []Value
+			for _, v := range vs {
+				if it.test(f, v) {
+					out = append(out, v)
+				}
+			}
+			it.push(rebuild(l, out))
+		},
+		"r[": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			if len(vs) == 0 {
+				fail("%s: empty list", name)
+			}
+			acc := vs[0]
+			for _, v := range vs[1:] {
+				acc = it.evalTop(f, acc, v)
+			}
+			it.push(acc)
+		},
+		"fe": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			i := it.findIndex(f, vs)
+			if i < 0 {
+				fail("%s: no element found", name)
+			}
+			it.push(vs[i])
+		},
+		"fi": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			it.push(Int(it.findIndex(f, vs)))
+		},
+		"fI": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			var out Block
+			for i, v := range vs {
+				if it.test(f, v) {
+					out = append(out, Int(i))
+				}
+			}
+			it.push(out)
+		},
+		"Fi": func(it *Interp, name Ident) {
+			x := it.pop()
+			_, vs := it.popList(name)
+			idx := -1
+			for i, v := range vs {
+				if equal(v, x) {
+					idx = i
+					break
+				}
+			}
+			it.push(Int(idx))
+		},
+		"al": func(it *Interp, name Ident) {
+			// Unlike the usual convention, al is false for an empty list.
+			_, vs, f := it.popListFunc(name)
+			all := len(vs) > 0
+			for _, v := range vs {
+				if !it.test(f, v) {
+					all = false
+					break
+				}
+			}
+			it.push(boolInt(all))
+		},
+		"ay": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			it.push(boolInt(it.findIndex(f, vs) >= 0))
+		},
+		"Z]": func(it *Interp, name Ident) {
+			a, b, f := it.pop3()
+			fb, ok := f.(Block)
+			if !ok {
+				unsupported(name, a, b, f)
+			}
+			as, bs := zipArgs(name, a, b)
+			var out Block
+			for i := range as {
+				out = appendResults(out, it.evalOn(fb, as[i], bs[i]))
+			}
+			it.push(out)
+		},
+		"Z[": func(it *Interp, name Ident) {
+			a, b, f := it.pop3()
+			fb, ok := f.(Block)
+			if !ok {
+				unsupported(name, a, b, f)
+			}
+			as, bs := zipArgs(name, a, b)
+			var out Block
+			for i := range as {
+				out = appendResults(out, it.evalOn(fb, Block{as[i], bs[i]}))
+			}
+			it.push(out)
+		},
+	})
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"MP": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			it.push(it.mapList(f, vs, nil)...)
+		},
+		"FM": func(it *Interp, name Ident) {
+			l, p, f := it.pop3()
+			vs, ok1 := items(l)
+			pb, ok2 := p.(Block)
+			fb, ok3 := f.(Block)
+			if !ok1 || !ok2 || !ok3 {
+				unsupported(name, l, p, f)
+			}
+			var keep []Value
+			for _, v := range vs {
+				if it.test(pb, v) {
+					keep = append(keep, v)
+				}
+			}
+			it.push(it.mapList(fb, keep, nil))
+		},
+		"pt": func(it *Interp, name Ident) {
+			l, vs, f := it.popListFunc(name)
+			var yes, no []Value
+			for _, v := range vs {
+				if it.test(f, v) {
+					yes = append(yes, v)
+				} else {
+					no = append(no, v)
+				}
+			}
+			it.push(Block{rebuild(l, yes), rebuild(l, no)})
+		},
+		"cn": func(it *Interp, name Ident) {
+			// The block lists pairs of a condition and a result: cn picks
+			// the result of the first condition that holds for the value.
+			cases := it.popBlock(name)
+			v := it.pop()
+			for i := 0; i+1 < len(cases); i += 2 {
+				c, ok := cases[i].(Block)
+				if ok && it.test(c, v) || !ok && truthy(cases[i]) {
+					it.push(cases[i+1])
+					return
+				}
+			}
+			fail("%s: no condition holds", name)
+		},
+		"ap": func(it *Interp, name Ident) {
+			// The index and the block can come in either order.
+			a, b := it.pop2()
+			i, ok1 := a.(Int)
+			f, ok2 := b.(Block)
+			if !ok1 || !ok2 {
+				i, ok1 = b.(Int)
+				f, ok2 = a.(Block)
+			}
+			if !ok1 || !ok2 {
+				unsupported(name, a, b)
+			}
+			l, vs := it.popList(name)
+			if i < 0 || int(i) >= len(vs) {
+				fail("%s: index %d out of range [0, %d)", name, i, len(vs))
+			}
+			out := slices.Clone(vs)
+			out[i] = it.evalTop(f, vs[i])
+			it.push(rebuild(l, out))
+		},
+		"<m": extremeBy(-1),
+		">m": extremeBy(+1),
+		"dw": func(it *Interp, name Ident) {
+			l, vs, f := it.popFuncList(name)
+			i := 0
+			for i < len(vs) && it.test(f, vs[i]) {
+				i++
+			}
+			it.push(rebuild(l, vs[i:]))
+		},
+		"tw": func(it *Interp, name Ident) {
+			l, vs, f := it.popFuncList(name)
+			i := 0
+			for i < len(vs) && it.test(f, vs[i]) {
+				i++
+			}
+			it.push(rebuild(l, vs[:i]))
+		},
+		"fl": func(it *Interp, name Ident) {
+			_, vs, f := it.popListFunc(name)
+			n := 0
+			for _, v := range vs {
+				if it.test(f, v) {
+					n++
+				}
+			}
+			it.push(Int(n))
+		},
+		"gB": func(it *Interp, name Ident) {
+			l, vs, f := it.popListFunc(name)
+			keys := make(map[int]Value)
+			key := func(i int) Value {
+				if k, ok := keys[i]; ok {
+					return k
+				}
+				keys[i] = it.evalTop(f, vs[i])
+				return keys[i]
+			}
+			var groups [][]Value
+			start := 0
+			for i := 1; i <= len(vs); i++ {
+				if i == len(vs) || !equal(key(start), key(i)) {
+					groups = append(groups, vs[start:i])
+					start = i
+				}
+			}
+			it.push(rebuildAll(l, groups))
+		},
+		"gb": func(it *Interp, name Ident) {
+			l, vs, f := it.popListFunc(name)
+			it.push(rebuildAll(l, groupBy(vs, func(first, v Value) bool {
+				return truthy(it.evalTop(f, first, v))
+			})))
+		},
+		"sb": func(it *Interp, name Ident) {
+			l, vs, f := it.popListFunc(name)
+			out := slices.Clone(vs)
+			slices.SortStableFunc(out, func(a, b Value) int {
+				c, ok := it.evalTop(f, a, b).(Int)
+				if !ok {
+					fail("%s: comparison did not return an integer", name)
+				}
+				return int(c)
+			})
+			it.push(rebuild(l, out))
+		},
+	})
+}
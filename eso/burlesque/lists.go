@@ -0,0 +1,1112 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"cmp"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// items returns the elements of a list: the values of a block, or the
+// characters of a string.
+func items(v Value) ([]Value, bool) {
+	switch v := v.(type) {
+	case Block:
+		return v, true
+	case Str:
+		out := make([]Value, len(v))
+		for i := range out {
+			out[i] = Char(v[i])
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// rebuild returns a list of the same kind as orig: a string, if orig is one
+// and all the items are characters, or a block otherwise.
+func rebuild(orig Value, vs []Value) Value {
+	if _, ok := orig.(Str); ok {
+		if s, ok := charsToStr(vs); ok {
+			return s
+		}
+	}
+	return Block(vs)
+}
+
+func charsToStr(vs []Value) (Str, bool) {
+	buf := make([]byte, len(vs))
+	for i, v := range vs {
+		c, ok := v.(Char)
+		if !ok {
+			return "", false
+		}
+		buf[i] = byte(c)
+	}
+	return Str(buf), true
+}
+
+// digits returns the decimal digits of an integer, ignoring its sign.
+func digits(v Value) []Value { return digitsIn(v, 10) }
+
+// digitsIn returns the digits of an integer in the given base, most
+// significant first, ignoring its sign.
+func digitsIn(v Value, base int) []Value {
+	n := new(big.Int).Abs(toBig(v))
+	if n.Sign() == 0 {
+		return []Value{Int(0)}
+	}
+	var out []Value
+	b, d := big.NewInt(int64(base)), new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, b, d)
+		out = append(out, Int(d.Int64()))
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// undigits is the inverse of digits.
+func undigits(ds []Value) Value { return undigitsIn(ds, 10) }
+
+// undigitsIn is the inverse of digitsIn.
+func undigitsIn(ds []Value, base int) Value {
+	n := new(big.Int)
+	b := big.NewInt(int64(base))
+	for _, d := range ds {
+		n.Mul(n, b)
+		n.Add(n, toBig(d))
+	}
+	return normInt(n)
+}
+
+// byFrequency returns the distinct items of a list, from the least common to
+// the most common. Items that are equally common are in sorted order.
+func byFrequency(vs []Value) []Value {
+	groups := groupBy(sortValues(vs, false), equal)
+	slices.SortStableFunc(groups, func(a, b []Value) int { return cmp.Compare(len(a), len(b)) })
+	out := make([]Value, len(groups))
+	for i, g := range groups {
+		out[i] = g[0]
+	}
+	return out
+}
+
+func take[T any](s []T, n int) []T {
+	return s[:max(0, min(n, len(s)))]
+}
+
+func drop[T any](s []T, n int) []T {
+	return s[max(0, min(n, len(s))):]
+}
+
+func concat(a, b Block) Block {
+	return append(append(make(Block, 0, len(a)+len(b)), a...), b...)
+}
+
+// listOp returns a built-in that pops a list, and pushes f applied to its
+// items. The result is rebuilt into a string if the argument is one. Integers
+// are treated as the lists of their digits.
+func listOp(f func(vs []Value) []Value) builtin {
+	return func(it *Interp, name Ident) {
+		v := it.pop()
+		if isInt(v) {
+			it.push(undigits(f(digits(v))))
+			return
+		}
+		vs, ok := items(v)
+		if !ok {
+			unsupported(name, v)
+		}
+		it.push(rebuild(v, f(vs)))
+	}
+}
+
+// elemOp is like listOp, but for functions that pick a single element.
+func elemOp(f func(vs []Value) Value) builtin {
+	return func(it *Interp, name Ident) {
+		v := it.pop()
+		var vs []Value
+		if s, ok := v.(Stream); ok {
+			vs = s.take(1)
+		} else if isInt(v) {
+			vs = digits(v)
+		} else if l, ok := items(v); ok {
+			vs = l
+		} else {
+			unsupported(name, v)
+		}
+		if len(vs) == 0 {
+			fail("%s: empty list", name)
+		}
+		it.push(f(vs))
+	}
+}
+
+func sortValues(vs []Value, desc bool) []Value {
+	out := slices.Clone(vs)
+	slices.SortStableFunc(out, func(a, b Value) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return out
+}
+
+func sum(name Ident, vs []Value) Value {
+	var acc Value = Int(0)
+	for _, v := range vs {
+		s, ok := addValues(acc, v)
+		if !ok {
+			unsupported(name, acc, v)
+		}
+		acc = s
+	}
+	return acc
+}
+
+func product(name Ident, vs []Value) Value {
+	var acc Value = Int(1)
+	for _, v := range vs {
+		p, ok := mulValues(acc, v)
+		if !ok {
+			unsupported(name, acc, v)
+		}
+		acc = p
+	}
+	return acc
+}
+
+func (it *Interp) popList(name Ident) (Value, []Value) {
+	v := it.pop()
+	vs, ok := items(v)
+	if !ok {
+		unsupported(name, v)
+	}
+	return v, vs
+}
+
+func (it *Interp) popBlock(name Ident) Block {
+	v := it.pop()
+	b, ok := v.(Block)
+	if !ok {
+		unsupported(name, v)
+	}
+	return b
+}
+
+func (it *Interp) popInt(name Ident) int {
+	v := it.pop()
+	n, ok := v.(Int)
+	if !ok {
+		unsupported(name, v)
+	}
+	return int(n)
+}
+
+// popListFunc pops a list and a block to apply to its items.
+func (it *Interp) popListFunc(name Ident) (Value, []Value, Block) {
+	l, f := it.pop2()
+	vs, ok1 := items(l)
+	fb, ok2 := f.(Block)
+	if !ok1 || !ok2 {
+		unsupported(name, l, f)
+	}
+	return l, vs, fb
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"-]": elemOp(func(vs []Value) Value { return vs[0] }),
+		"[~": elemOp(func(vs []Value) Value { return vs[len(vs)-1] }),
+		">]": elemOp(func(vs []Value) Value { return slices.MaxFunc(vs, compare) }),
+		"<]": elemOp(func(vs []Value) Value { return slices.MinFunc(vs, compare) }),
+		"[-": listOp(func(vs []Value) []Value { return drop(vs, 1) }),
+		"~]": listOp(func(vs []Value) []Value { return take(vs, len(vs)-1) }),
+		"~-": listOp(func(vs []Value) []Value { return take(drop(vs, 1), len(vs)-2) }),
+		"<-": listOp(func(vs []Value) []Value {
+			out := slices.Clone(vs)
+			slices.Reverse(out)
+			return out
+		}),
+		"<>": listOp(func(vs []Value) []Value { return sortValues(vs, true) }),
+		"><": func(it *Interp, name Ident) {
+			if c, ok := it.top().(Char); ok {
+				it.pop()
+				it.push(boolInt(isDigit(byte(c))))
+				return
+			}
+			listOp(func(vs []Value) []Value { return sortValues(vs, false) })(it, name)
+		},
+		"L[": func(it *Interp, name Ident) {
+			if n, ok := it.top().(Int); ok {
+				// On an integer, L[ is the character with that code.
+				it.pop()
+				it.push(Char(n))
+				return
+			}
+			_, vs := it.popList(name)
+			it.push(Int(len(vs)))
+		},
+		"sa": func(it *Interp, name Ident) {
+			if _, ok := it.top().(Int); ok {
+				// With an index on top, sa sets an item.
+				i := it.popInt(name)
+				x := it.pop()
+				l, vs := it.popList(name)
+				if i < 0 || i >= len(vs) {
+					fail("%s: index %d out of range [0, %d)", name, i, len(vs))
+				}
+				out := slices.Clone(vs)
+				out[i] = x
+				it.push(rebuild(l, out))
+				return
+			}
+			v := it.top()
+			vs, ok := items(v)
+			if !ok {
+				unsupported(name, v)
+			}
+			it.push(Int(len(vs)))
+		},
+		"!!": func(it *Interp, name Ident) {
+			l, i := it.pop2()
+			vs, ok1 := items(l)
+			n, ok2 := i.(Int)
+			if !ok1 || !ok2 {
+				unsupported(name, l, i)
+			}
+			if n < 0 || int(n) >= len(vs) {
+				fail("%s: index %d out of range [0, %d)", name, n, len(vs))
+			}
+			it.push(vs[n])
+		},
+		"+]": func(it *Interp, name Ident) {
+			l, x := it.pop2()
+			switch l := l.(type) {
+			case Stream:
+				it.push(Stream{func(i int) Value {
+					if i == 0 {
+						return x
+					}
+					return l.at(i - 1)
+				}})
+				return
+			case Block:
+				it.push(append(Block{x}, l...))
+				return
+			case Str:
+				switch x := x.(type) {
+				case Char:
+					it.push(Str(x) + l)
+					return
+				case Str:
+					it.push(x + l)
+					return
+				}
+			}
+			unsupported(name, l, x)
+		},
+		"[+": func(it *Interp, name Ident) {
+			l, x := it.pop2()
+			switch l := l.(type) {
+			case Block:
+				it.push(append(slices.Clip(l), x))
+				return
+			case Str:
+				switch x := x.(type) {
+				case Char:
+					it.push(l + Str(x))
+					return
+				case Str:
+					it.push(l + x)
+					return
+				}
+			}
+			unsupported(name, l, x)
+		},
+		"_+": func(it *Interp, _ Ident) {
+			a, b := it.pop2()
+			switch a := a.(type) {
+			case Block:
+				if bb, ok := b.(Block); ok {
+					it.push(concat(a, bb))
+				} else {
+					it.push(append(slices.Clip(a), b))
+				}
+				return
+			case Str:
+				switch b := b.(type) {
+				case Str:
+					it.push(a + b)
+					return
+				case Char:
+					it.push(a + Str(b))
+					return
+				}
+			case Char:
+				switch b := b.(type) {
+				case Str:
+					it.push(Str(a) + b)
+					return
+				case Char:
+					it.push(Str([]byte{byte(a), byte(b)}))
+					return
+				}
+			}
+			if b, ok := b.(Block); ok {
+				it.push(append(Block{a}, b...))
+				return
+			}
+			it.push(Block{a, b})
+		},
+		"++": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(sum(name, vs))
+		},
+		"pd": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(product(name, vs))
+		},
+		"PD": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			out := make(Block, len(vs))
+			for i, v := range vs {
+				ws, ok := items(v)
+				if !ok {
+					unsupported(name, v)
+				}
+				out[i] = product(name, ws)
+			}
+			it.push(out)
+		},
+		"bx": func(it *Interp, _ Ident) { it.push(Block{it.pop()}) },
+		"XX": func(it *Interp, name Ident) {
+			v := it.pop()
+			if isInt(v) {
+				it.push(Block(digits(v)))
+				return
+			}
+			vs, ok := items(v)
+			if !ok {
+				unsupported(name, v)
+			}
+			it.push(Block(vs))
+		},
+		"\\[": func(it *Interp, name Ident) {
+			b := it.popBlock(name)
+			it.push(concatAll(b))
+		},
+		"FL": func(it *Interp, name Ident) {
+			if s, ok := it.top().(Stream); ok {
+				it.pop()
+				it.push(flattenStream(s))
+				return
+			}
+			b := it.popBlock(name)
+			it.push(flatten(b))
+		},
+		"CN": func(it *Interp, name Ident) {
+			l, x := it.pop2()
+			if s, ok := l.(Str); ok {
+				if sub, ok := x.(Str); ok {
+					n := 0
+					for i := 0; i+len(sub) <= len(s); i++ {
+						if s[i:i+len(sub)] == sub {
+							n++
+						}
+					}
+					it.push(Int(n))
+					return
+				}
+			}
+			vs, ok := items(l)
+			if !ok {
+				unsupported(name, l, x)
+			}
+			n := 0
+			for _, v := range vs {
+				if equal(v, x) {
+					n++
+				}
+			}
+			it.push(Int(n))
+		},
+		"ro": func(it *Interp, name Ident) { it.push(intRange(1, it.popInt(name))) },
+		"rz": func(it *Interp, name Ident) { it.push(intRange(0, it.popInt(name))) },
+		"r@": func(it *Interp, name Ident) {
+			if d, ok := it.top().(Double); ok {
+				it.pop()
+				it.push(Double(math.Sqrt(float64(d))))
+				return
+			}
+			if l, ok := it.top().(Block); ok {
+				// On a block, r@ lists its permutations.
+				it.pop()
+				var out Block
+				for _, p := range permutations(l) {
+					out = append(out, Block(p))
+				}
+				it.push(out)
+				return
+			}
+			if l, ok := it.top().(Str); ok {
+				it.pop()
+				vs, _ := items(l)
+				var out Block
+				for _, p := range permutations(vs) {
+					out = append(out, rebuild(l, p))
+				}
+				it.push(out)
+				return
+			}
+			a, b := it.pop2()
+			x, ok1 := a.(Int)
+			y, ok2 := b.(Int)
+			if !ok1 || !ok2 {
+				unsupported(name, a, b)
+			}
+			it.push(intRange(int(x), int(y)))
+		},
+		"cp": func(it *Interp, name Ident) {
+			a, b := it.pop2()
+			as, ok1 := items(a)
+			bs, ok2 := items(b)
+			if !ok1 || !ok2 {
+				unsupported(name, a, b)
+			}
+			out := make(Block, 0, len(as)*len(bs))
+			for _, x := range as {
+				for _, y := range bs {
+					out = append(out, Block{x, y})
+				}
+			}
+			it.push(out)
+		},
+		"CB": func(it *Interp, name Ident) {
+			n := it.popInt(name)
+			_, vs := it.popList(name)
+			var out Block
+			var rec func(acc Block)
+			rec = func(acc Block) {
+				if len(acc) == n {
+					out = append(out, slices.Clone(acc))
+					return
+				}
+				for _, v := range vs {
+					rec(append(acc, v))
+				}
+			}
+			rec(nil)
+			it.push(out)
+		},
+		"co": func(it *Interp, name Ident) {
+			n := it.popInt(name)
+			if s, ok := it.top().(Stream); ok && n > 0 {
+				it.pop()
+				it.push(Stream{func(i int) Value { return Block(s.drop(i * n).take(n)) }})
+				return
+			}
+			l, vs := it.popList(name)
+			if n <= 0 {
+				fail("%s: nonpositive chunk size", name)
+			}
+			var out Block
+			for i := 0; i < len(vs); i += n {
+				out = append(out, rebuild(l, vs[i:min(i+n, len(vs))]))
+			}
+			it.push(out)
+		},
+		"CO": func(it *Interp, name Ident) {
+			n := it.popInt(name)
+			l, vs := it.popList(name)
+			var out Block
+			for i := 0; i+n <= len(vs); i++ {
+				out = append(out, rebuild(l, vs[i:i+n]))
+			}
+			it.push(out)
+		},
+		"tp": func(it *Interp, name Ident) {
+			_, rows := it.popList(name)
+			var out []Block
+			var kinds []Value
+			for _, r := range rows {
+				vs, ok := items(r)
+				if !ok {
+					unsupported(name, r)
+				}
+				for i, v := range vs {
+					if i == len(out) {
+						out = append(out, nil)
+						kinds = append(kinds, r)
+					}
+					out[i] = append(out[i], v)
+				}
+			}
+			res := make(Block, len(out))
+			for i, col := range out {
+				res[i] = rebuild(kinds[i], col)
+			}
+			it.push(res)
+		},
+		"zi": func(it *Interp, name Ident) {
+			if s, ok := it.top().(Stream); ok {
+				it.pop()
+				it.push(Stream{func(i int) Value { return Block{Int(i), s.at(i)} }})
+				return
+			}
+			_, vs := it.popList(name)
+			out := make(Block, len(vs))
+			for i, v := range vs {
+				out[i] = Block{Int(i), v}
+			}
+			it.push(out)
+		},
+		"z[": func(it *Interp, name Ident) {
+			a, b := it.pop2()
+			as, bs := zipArgs(name, a, b)
+			out := make(Block, len(as))
+			for i := range out {
+				out[i] = Block{as[i], bs[i]}
+			}
+			it.push(out)
+		},
+		"NB": listOp(func(vs []Value) []Value {
+			var out []Value
+			seen := make(map[string]bool)
+			for _, v := range vs {
+				if k := key(v); !seen[k] {
+					seen[k] = true
+					out = append(out, v)
+				}
+			}
+			return out
+		}),
+	})
+}
+
+func intersperse(vs []Value, x Value) []Value {
+	var out []Value
+	for i, v := range vs {
+		if i > 0 {
+			out = append(out, x)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// matrix returns the rows of a block of blocks.
+func matrix(name Ident, v Value) [][]Value {
+	rows, ok := items(v)
+	if !ok {
+		unsupported(name, v)
+	}
+	out := make([][]Value, len(rows))
+	for i, r := range rows {
+		if out[i], ok = items(r); !ok {
+			unsupported(name, v)
+		}
+	}
+	return out
+}
+
+// permutations lists the orderings of a list.
+func permutations(vs []Value) [][]Value {
+	if len(vs) <= 1 {
+		return [][]Value{slices.Clone(vs)}
+	}
+	var out [][]Value
+	for i, v := range vs {
+		rest := append(slices.Clone(vs[:i]), vs[i+1:]...)
+		for _, p := range permutations(rest) {
+			out = append(out, append([]Value{v}, p...))
+		}
+	}
+	return out
+}
+
+func intRange(from, to int) Block {
+	out := make(Block, 0, max(0, to-from+1))
+	for i := from; i <= to; i++ {
+		out = append(out, Int(i))
+	}
+	return out
+}
+
+// concatAll concatenates a block of lists, and keeps any other values as
+// they are. A block of strings and characters makes a string.
+func concatAll(b Block) Value {
+	var out []Value
+	for _, v := range b {
+		if vs, ok := items(v); ok {
+			out = append(out, vs...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	if s, ok := charsToStr(out); ok && len(out) > 0 {
+		return s
+	}
+	return Block(out)
+}
+
+// flatten removes all nesting from a block.
+func flatten(b Block) Block {
+	var out Block
+	for _, v := range b {
+		if inner, ok := v.(Block); ok {
+			out = append(out, flatten(inner)...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// flattenStream is flatten for streams, done lazily as far as needed.
+func flattenStream(s Stream) Stream {
+	var done []Value
+	next := 0
+	return Stream{func(i int) Value {
+		for len(done) <= i {
+			if inner, ok := s.at(next).(Block); ok {
+				done = append(done, flatten(inner)...)
+			} else {
+				done = append(done, s.at(next))
+			}
+			next++
+		}
+		return done[i]
+	}}
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"^p": func(it *Interp, _ Ident) { it.push(pushAll(it.pop())...) },
+		"p^": func(it *Interp, _ Ident) { it.push(pushAllReversed(it.pop())...) },
+	})
+}
+
+// zipArgs returns the items of two lists to zip together, cut to the same
+// length. Either of them can be a stream.
+func zipArgs(name Ident, a, b Value) (as, bs []Value) {
+	sa, aStream := a.(Stream)
+	sb, bStream := b.(Stream)
+	var ok1, ok2 bool
+	switch {
+	case aStream && !bStream:
+		bs, ok2 = items(b)
+		as, ok1 = sa.take(len(bs)), true
+	case bStream && !aStream:
+		as, ok1 = items(a)
+		bs, ok2 = sb.take(len(as)), true
+	default:
+		as, ok1 = items(a)
+		bs, ok2 = items(b)
+	}
+	if !ok1 || !ok2 {
+		unsupported(name, a, b)
+	}
+	n := min(len(as), len(bs))
+	return as[:n], bs[:n]
+}
+
+func contains(vs []Value, x Value) bool {
+	return slices.ContainsFunc(vs, func(v Value) bool { return equal(v, x) })
+}
+
+// groupBy splits a list into runs of items for which same returns true
+// against the first item of the run.
+func groupBy(vs []Value, same func(first, v Value) bool) [][]Value {
+	var out [][]Value
+	for _, v := range vs {
+		if n := len(out); n > 0 && same(out[n-1][0], v) {
+			out[n-1] = append(out[n-1], v)
+		} else {
+			out = append(out, []Value{v})
+		}
+	}
+	return out
+}
+
+func rebuildAll(orig Value, groups [][]Value) Block {
+	out := make(Block, len(groups))
+	for i, g := range groups {
+		out[i] = rebuild(orig, g)
+	}
+	return out
+}
+
+// index looks up the item at a (possibly nested) index.
+func index(name Ident, l Value, path []Value) Value {
+	for _, p := range path {
+		vs, ok1 := items(l)
+		i, ok2 := p.(Int)
+		if !ok1 || !ok2 {
+			unsupported(name, l, p)
+		}
+		if i < 0 || int(i) >= len(vs) {
+			fail("%s: index %d out of range [0, %d)", name, i, len(vs))
+		}
+		l = vs[i]
+	}
+	return l
+}
+
+// update returns a copy of a list with the item at a nested index replaced.
+func update(name Ident, l Value, path []Value, x Value) Value {
+	if len(path) == 0 {
+		return x
+	}
+	vs, ok1 := items(l)
+	i, ok2 := path[0].(Int)
+	if !ok1 || !ok2 {
+		unsupported(name, l, path[0])
+	}
+	if i < 0 || int(i) >= len(vs) {
+		fail("%s: index %d out of range [0, %d)", name, i, len(vs))
+	}
+	out := slices.Clone(vs)
+	out[i] = update(name, vs[i], path[1:], x)
+	return rebuild(l, out)
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		// Splitting off ends: g_ leaves the head on top of the tail, while
+		// l_ leaves the rest of the list on top of its last item.
+		"g_": func(it *Interp, name Ident) {
+			if s, ok := it.top().(Stream); ok {
+				it.pop()
+				it.push(s.drop(1), s.at(0))
+				return
+			}
+			l, vs := it.popList(name)
+			if len(vs) == 0 {
+				fail("%s: empty list", name)
+			}
+			it.push(rebuild(l, vs[1:]), vs[0])
+		},
+		"l_": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			if len(vs) == 0 {
+				fail("%s: empty list", name)
+			}
+			it.push(vs[len(vs)-1], rebuild(l, vs[:len(vs)-1]))
+		},
+		"si": func(it *Interp, name Ident) {
+			_, is := it.popList(name)
+			l, vs := it.popList(name)
+			out := make([]Value, len(is))
+			for j, i := range is {
+				out[j] = index(name, Block(vs), []Value{i})
+			}
+			it.push(rebuild(l, out))
+		},
+		"dg": func(it *Interp, name Ident) {
+			base := it.popInt(name)
+			v := it.pop()
+			if !isInt(v) || base < 2 {
+				unsupported(name, v, Int(base))
+			}
+			it.push(Block(digitsIn(v, base)))
+		},
+		"ug": func(it *Interp, name Ident) {
+			base := it.popInt(name)
+			_, vs := it.popList(name)
+			it.push(undigitsIn(vs, base))
+		},
+		"nu": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(boolInt(len(vs) == 0))
+		},
+		"f:": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			groups := groupBy(sortValues(vs, false), equal)
+			slices.SortStableFunc(groups, func(a, b []Value) int { return cmp.Compare(len(b), len(a)) })
+			out := make(Block, len(groups))
+			for i, g := range groups {
+				out[i] = Block{Int(len(g)), g[0]}
+			}
+			it.push(out)
+		},
+		"IC": func(it *Interp, name Ident) {
+			sep := it.pop()
+			_, vs := it.popList(name)
+			var out []Value
+			for i, v := range vs {
+				if i > 0 {
+					out = append(out, sep)
+				}
+				ws, ok := items(v)
+				if !ok {
+					unsupported(name, v, sep)
+				}
+				out = append(out, ws...)
+			}
+			it.push(Block(out))
+		},
+		"=[": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			var out Block
+			for _, g := range groupBy(vs, equal) {
+				out = append(out, rebuild(l, g))
+			}
+			it.push(out)
+		},
+		"fc": elemOp(func(vs []Value) Value { return byFrequency(vs)[0] }),
+		"-~": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			if len(vs) == 0 {
+				fail("%s: empty list", name)
+			}
+			ws, ok := items(vs[0])
+			if !ok || len(ws) == 0 {
+				unsupported(name, vs[0])
+			}
+			it.push(rebuild(vs[0], ws[1:]))
+		},
+		"[]": func(it *Interp, name Ident) {
+			x := it.pop()
+			l, vs := it.popList(name)
+			it.push(rebuild(l, intersperse(vs, x)))
+		},
+		"[P": func(it *Interp, name Ident) {
+			x := it.pop()
+			n := it.popInt(name)
+			l, vs := it.popList(name)
+			pad := make([]Value, max(0, n-len(vs)))
+			for i := range pad {
+				pad[i] = x
+			}
+			it.push(rebuild(l, append(pad, vs...)))
+		},
+		"[[": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			x := it.pop()
+			it.push(rebuild(l, intersperse(vs, x)))
+		},
+		"en": func(it *Interp, name Ident) {
+			n := it.popInt(name)
+			l, vs := it.popList(name)
+			if n <= 0 {
+				fail("%s: nonpositive step", name)
+			}
+			var out []Value
+			for i := n - 1; i < len(vs); i += n {
+				out = append(out, vs[i])
+			}
+			it.push(rebuild(l, out))
+		},
+		"RT": listOp(func(vs []Value) []Value {
+			if len(vs) == 0 {
+				return vs
+			}
+			return append(slices.Clone(vs[1:]), vs[0])
+		}),
+		"rt": listOp(func(vs []Value) []Value {
+			if len(vs) == 0 {
+				return vs
+			}
+			return append([]Value{vs[len(vs)-1]}, vs[:len(vs)-1]...)
+		}),
+		"IN": setOp(func(a, b []Value) []Value {
+			in := keySet(b)
+			var out []Value
+			for _, v := range a {
+				if in[key(v)] > 0 {
+					out = append(out, v)
+				}
+			}
+			return out
+		}),
+		"UN": setOp(func(a, b []Value) []Value {
+			seen := keySet(a)
+			out := slices.Clone(a)
+			for _, v := range b {
+				if k := key(v); seen[k] == 0 {
+					seen[k] = 1
+					out = append(out, v)
+				}
+			}
+			return out
+		}),
+		"\\\\": setOp(func(a, b []Value) []Value {
+			drop := keySet(b)
+			var out []Value
+			for _, v := range a {
+				if k := key(v); drop[k] > 0 {
+					drop[k]--
+				} else {
+					out = append(out, v)
+				}
+			}
+			return out
+		}),
+		"RA": func(it *Interp, name Ident) {
+			i := it.popInt(name)
+			l, vs := it.popList(name)
+			if i < 0 || i >= len(vs) {
+				fail("%s: index %d out of range [0, %d)", name, i, len(vs))
+			}
+			it.push(rebuild(l, slices.Delete(slices.Clone(vs), i, i+1)))
+		},
+		"ia": func(it *Interp, name Ident) {
+			i := it.popInt(name)
+			x := it.pop()
+			l, vs := it.popList(name)
+			if i < 0 || i > len(vs) {
+				fail("%s: index %d out of range [0, %d]", name, i, len(vs))
+			}
+			it.push(rebuild(l, slices.Insert(slices.Clone(vs), i, x)))
+		},
+		"iS": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			out := make(Block, len(vs)+1)
+			for i := range out {
+				out[i] = rebuild(l, vs[i:])
+			}
+			it.push(out)
+		},
+		"iT": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			out := make(Block, len(vs)+1)
+			for i := range out {
+				out[i] = rebuild(l, vs[:i])
+			}
+			it.push(out)
+		},
+		"sm": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			same := true
+			for _, v := range vs {
+				same = same && equal(v, vs[0])
+			}
+			it.push(boolInt(same))
+		},
+		"so": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(boolInt(slices.IsSortedFunc(vs, compare)))
+		},
+		"SO": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(boolInt(slices.IsSortedFunc(vs, func(a, b Value) int { return compare(b, a) })))
+		},
+		"iR": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			out := make(Block, len(vs))
+			for i := range vs {
+				out[i] = rebuild(l, append(slices.Clone(vs[i:]), vs[:i]...))
+			}
+			it.push(out)
+		},
+		"mm": func(it *Interp, name Ident) {
+			a, b := it.pop2()
+			as, bs := matrix(name, a), matrix(name, b)
+			out := make(Block, len(as))
+			mul, add := vectorize(name, mulValues), vectorize(name, addValues)
+			for i, row := range as {
+				prod := make(Block, len(bs[0]))
+				for j := range prod {
+					var acc Value = Int(0)
+					for k, x := range row {
+						acc = add(acc, mul(x, bs[k][j]))
+					}
+					prod[j] = acc
+				}
+				out[i] = prod
+			}
+			it.push(out)
+		},
+		"U_": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			unique := true
+			for i, v := range vs {
+				unique = unique && !contains(vs[:i], v)
+			}
+			it.push(boolInt(unique))
+		},
+		"sg": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			it.push(rebuildAll(l, groupBy(sortValues(vs, false), equal)))
+		},
+		"gl": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			it.push(Int(len(groupBy(vs, equal))))
+		},
+		"gn": func(it *Interp, name Ident) {
+			l, vs := it.popList(name)
+			var out []Value
+			for _, g := range groupBy(vs, equal) {
+				out = append(out, g[0])
+			}
+			it.push(rebuild(l, out))
+		},
+		"d!": func(it *Interp, name Ident) {
+			l, p := it.pop2()
+			path, ok := p.(Block)
+			if !ok {
+				unsupported(name, l, p)
+			}
+			it.push(index(name, l, path))
+		},
+		"D!": func(it *Interp, name Ident) {
+			l, p, x := it.pop3()
+			path, ok := p.(Block)
+			if !ok {
+				unsupported(name, l, p, x)
+			}
+			it.push(update(name, l, path, x))
+		},
+
+		// Infinite lists.
+		"bc": func(it *Interp, _ Ident) { it.push(cycle(Block{it.pop()})) },
+		"cy": func(it *Interp, name Ident) {
+			_, vs := it.popList(name)
+			if len(vs) == 0 {
+				fail("%s: empty list", name)
+			}
+			it.push(cycle(vs))
+		},
+		"r0": func(it *Interp, _ Ident) { it.push(counter(0)) },
+		"r1": func(it *Interp, _ Ident) { it.push(counter(1)) },
+	})
+}
+
+// setOp returns a built-in that combines two lists.
+// keySet counts the items of a list by their key.
+func keySet(vs []Value) map[string]int {
+	set := make(map[string]int, len(vs))
+	for _, v := range vs {
+		set[key(v)]++
+	}
+	return set
+}
+
+func setOp(f func(a, b []Value) []Value) builtin {
+	return func(it *Interp, name Ident) {
+		a, b := it.pop2()
+		as, ok1 := items(a)
+		bs, ok2 := items(b)
+		if !ok1 || !ok2 {
+			unsupported(name, a, b)
+		}
+		it.push(rebuild(a, f(as, bs)))
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a Burlesque program into the block of its top-level values.
+func Parse(src string) (Block, error) {
+	p := parser{src: src}
+	prog, err := p.block(false)
+	if err != nil {
+		return nil, fmt.Errorf("offset %d: %w", p.pos, err)
+	}
+	return prog, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) block(nested bool) (Block, error) {
+	var b Block
+	for {
+		p.space()
+		if p.pos == len(p.src) {
+			if nested {
+				return nil, fmt.Errorf("unterminated block")
+			}
+			return b, nil
+		}
+		switch c := p.src[p.pos]; {
+		case c == '}':
+			if !nested {
+				return nil, fmt.Errorf("unbalanced }")
+			}
+			p.pos++
+			return b, nil
+		case c == '{':
+			p.pos++
+			inner, err := p.block(true)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, inner)
+		case c == '"':
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, s)
+		case c == '\'':
+			if p.pos+1 >= len(p.src) {
+				return nil, fmt.Errorf("unterminated character")
+			}
+			b = append(b, Char(p.src[p.pos+1]))
+			p.pos += 2
+		case isDigit(c) || c == '-' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]):
+			b = append(b, p.number())
+		case c == '@':
+			if p.pos+2 >= len(p.src) {
+				return nil, fmt.Errorf("truncated @ pair")
+			}
+			b = append(b, Char(p.src[p.pos+1]), Char(p.src[p.pos+2]))
+			p.pos += 3
+		case c == ')' || c == ':':
+			p.pos++
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			op := Ident("m[")
+			if c == ':' {
+				op = "f["
+			}
+			b = append(b, Block{name}, op)
+		case c == '(':
+			p.pos++
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if p.pos == len(p.src) || p.src[p.pos] != ')' {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			p.pos++
+			b = append(b, Quote(name))
+		case c == '%':
+			if p.pos+4 >= len(p.src) || p.src[p.pos+3] != '=' || p.src[p.pos+4] != '{' {
+				return nil, fmt.Errorf("malformed definition")
+			}
+			name := Ident(p.src[p.pos+1 : p.pos+3])
+			p.pos += 5
+			body, err := p.block(true)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, def{name: name, body: body})
+		default:
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, name)
+		}
+	}
+}
+
+func (p *parser) space() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// ident parses a built-in name: two characters, except for the few single
+// character ones.
+func (p *parser) ident() (Ident, error) {
+	if p.pos < len(p.src) && (p.src[p.pos] == 'J' || p.src[p.pos] == 'j' || p.src[p.pos] == ',') {
+		p.pos++
+		return Ident(p.src[p.pos-1 : p.pos]), nil
+	}
+	if p.pos+2 > len(p.src) {
+		return "", fmt.Errorf("truncated identifier")
+	}
+	p.pos += 2
+	return Ident(p.src[p.pos-2 : p.pos]), nil
+}
+
+// str parses a string literal. The escapes \n, \t, \" and \\ are recognized;
+// any other backslash is kept as is, which is convenient for regular
+// expressions.
+func (p *parser) str() (Str, error) {
+	var sb strings.Builder
+	for i := p.pos + 1; i < len(p.src); i++ {
+		c := p.src[i]
+		switch {
+		case c == '"':
+			p.pos = i + 1
+			return Str(sb.String()), nil
+		case c == '\\' && i+1 < len(p.src):
+			i++
+			switch p.src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\':
+				sb.WriteByte(p.src[i])
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(p.src[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// number parses an integer or a double. Integers may have a decimal exponent,
+// as in 1e6.
+func (p *parser) number() Value {
+	start := p.pos
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	p.digits()
+	if p.pos+1 < len(p.src) && p.src[p.pos] == '.' && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		p.digits()
+		d, _ := strconv.ParseFloat(p.src[start:p.pos], 64)
+		return Double(d)
+	}
+	n, _ := new(big.Int).SetString(p.src[start:p.pos], 10)
+	if p.pos+1 < len(p.src) && p.src[p.pos] == 'e' && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		e := p.pos
+		p.digits()
+		exp, _ := strconv.Atoi(p.src[e:p.pos])
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	}
+	return normInt(n)
+}
+
+func (p *parser) digits() {
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
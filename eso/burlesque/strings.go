@@ -0,0 +1,416 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func (it *Interp) popStr(name Ident) Str {
+	v := it.pop()
+	s, ok := v.(Str)
+	if !ok {
+		unsupported(name, v)
+	}
+	return s
+}
+
+func strBlock(ss []string) Block {
+	out := make(Block, len(ss))
+	for i, s := range ss {
+		out[i] = Str(s)
+	}
+	return out
+}
+
+// lines splits a string into lines like Haskell's lines: a final newline
+// does not start a new, empty line.
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// readInt parses an integer, allowing surrounding whitespace and a sign.
+func readInt(s string) (Value, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "+")
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Int(n), true
+	}
+	if n, ok := toBigString(s, 10); ok {
+		return n, true
+	}
+	return nil, false
+}
+
+// joinLines joins a list into lines of text.
+func joinLines(it *Interp, name Ident) {
+	_, vs := it.popList(name)
+	it.push(unlines(vs))
+}
+
+func unlines(vs []Value) Str {
+	ls := make([]string, len(vs))
+	for i, v := range vs {
+		ls[i] = Display(v)
+	}
+	return Str(strings.Join(ls, "\n"))
+}
+
+// readNumber is readInt for strings that hold one, and the identity for
+// other values.
+func readNumber(v Value) Value {
+	if s, ok := v.(Str); ok {
+		if n, ok := readInt(string(s)); ok {
+			return n
+		}
+	}
+	return v
+}
+
+func toBigString(s string, base int) (Value, bool) {
+	if s == "" {
+		return nil, false
+	}
+	n, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, false
+	}
+	return normInt(n), true
+}
+
+// textArg converts a string or a character to a string.
+func textArg(v Value) (string, bool) {
+	switch v := v.(type) {
+	case Str:
+		return string(v), true
+	case Char:
+		return string([]byte{byte(v)}), true
+	}
+	return "", false
+}
+
+var regexps = map[string]*regexp.Regexp{}
+
+func compileRegexp(name Ident, expr Str) *regexp.Regexp {
+	if re, ok := regexps[string(expr)]; ok {
+		return re
+	}
+	re, err := regexp.Compile(string(expr))
+	if err != nil {
+		fail("%s: %v", name, err)
+	}
+	regexps[string(expr)] = re
+	return re
+}
+
+// split splits a list on a separator. Strings are split on a string or a
+// character, blocks on a subsequence.
+func split(name Ident, l, sep Value) Block {
+	if s, ok := l.(Str); ok {
+		if t, ok := textArg(sep); ok {
+			return strBlock(strings.Split(string(s), t))
+		}
+	}
+	vs, ok1 := items(l)
+	seps, ok2 := sep.(Block)
+	if !ok1 || !ok2 || len(seps) == 0 {
+		unsupported(name, l, sep)
+	}
+	var out Block
+	start := 0
+	for i := 0; i+len(seps) <= len(vs); {
+		if isPrefix(vs[i:], seps) {
+			out = append(out, rebuild(l, vs[start:i]))
+			i += len(seps)
+			start = i
+		} else {
+			i++
+		}
+	}
+	return append(out, rebuild(l, vs[start:]))
+}
+
+func isPrefix(vs, prefix []Value) bool {
+	if len(prefix) > len(vs) {
+		return false
+	}
+	for i, p := range prefix {
+		if !equal(vs[i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+func isInfix(vs, sub []Value) bool {
+	for i := 0; i+len(sub) <= len(vs); i++ {
+		if isPrefix(vs[i:], sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"ln": func(it *Interp, name Ident) { it.push(strBlock(lines(string(it.popStr(name))))) },
+		"wd": func(it *Interp, name Ident) { it.push(strBlock(strings.Fields(string(it.popStr(name))))) },
+		"WD": func(it *Interp, name Ident) { it.push(strBlock(strings.Fields(string(it.popStr(name))))) },
+		"uN": joinLines,
+		"sh": func(it *Interp, _ Ident) { it.push(Str(Display(it.pop()))) },
+		";;": func(it *Interp, name Ident) {
+			l, sep := it.pop2()
+			it.push(split(name, l, sep))
+		},
+		"im": func(it *Interp, name Ident) {
+			// im implodes a list of numbers by writing them one after another.
+			_, vs := it.popList(name)
+			var sb strings.Builder
+			for _, v := range vs {
+				sb.WriteString(Display(v))
+			}
+			n, ok := readInt(sb.String())
+			if !ok {
+				fail("%s: not an integer: %q", name, sb.String())
+			}
+			it.push(n)
+		},
+		"ri": func(it *Interp, name Ident) {
+			var ri func(v Value) Value
+			ri = func(v Value) Value {
+				switch v := v.(type) {
+				case Str:
+					if n, ok := readInt(string(v)); ok {
+						return n
+					}
+					fail("%s: not an integer: %q", name, v)
+				case Char:
+					if isDigit(byte(v)) {
+						return Int(v - '0')
+					}
+				case Block:
+					out := make(Block, len(v))
+					for i, e := range v {
+						out[i] = ri(e)
+					}
+					return out
+				}
+				if isInt(v) {
+					return v
+				}
+				unsupported(name, v)
+				return nil
+			}
+			it.push(ri(it.pop()))
+		},
+		"ps": func(it *Interp, name Ident) {
+			var ps func(v Value) Value
+			ps = func(v Value) Value {
+				switch v := v.(type) {
+				case Str:
+					prog, err := Parse(string(v))
+					if err != nil {
+						fail("%s: %v", name, err)
+					}
+					return prog
+				case Block:
+					out := make(Block, len(v))
+					for i, e := range v {
+						out[i] = ps(e)
+					}
+					return out
+				}
+				unsupported(name, v)
+				return nil
+			}
+			it.push(ps(it.pop()))
+		},
+		"r~": func(it *Interp, name Ident) {
+			l, old, repl := it.pop3()
+			if s, ok := l.(Str); ok {
+				o, ok1 := textArg(old)
+				r, ok2 := textArg(repl)
+				if !ok1 || !ok2 {
+					unsupported(name, l, old, repl)
+				}
+				it.push(Str(strings.ReplaceAll(string(s), o, r)))
+				return
+			}
+			b, ok := l.(Block)
+			if !ok {
+				unsupported(name, l, old, repl)
+			}
+			out := make(Block, len(b))
+			for i, v := range b {
+				if equal(v, old) {
+					v = repl
+				}
+				out[i] = v
+			}
+			it.push(out)
+		},
+		"~?": func(it *Interp, name Ident) {
+			expr := it.popStr(name)
+			s := it.popStr(name)
+			it.push(strBlock(compileRegexp(name, expr).FindAllString(string(s), -1)))
+		},
+		"=~": func(it *Interp, name Ident) {
+			expr := it.popStr(name)
+			s := it.popStr(name)
+			m := compileRegexp(name, expr).FindStringSubmatch(string(s))
+			if m == nil {
+				it.push(Block{})
+				return
+			}
+			it.push(strBlock(m[1:]))
+		},
+		"~!": func(it *Interp, name Ident) {
+			l, p := it.pop2()
+			if s, ok := l.(Str); ok {
+				if t, ok := textArg(p); ok {
+					it.push(boolInt(strings.HasPrefix(string(s), t)))
+					return
+				}
+			}
+			vs, ok1 := items(l)
+			ps, ok2 := items(p)
+			if !ok1 || !ok2 {
+				unsupported(name, l, p)
+			}
+			it.push(boolInt(isPrefix(vs, ps)))
+		},
+		"~~": func(it *Interp, name Ident) {
+			l, sub := it.pop2()
+			vs, ok1 := items(l)
+			ss, ok2 := items(sub)
+			if !ok1 || !ok2 {
+				unsupported(name, l, sub)
+			}
+			it.push(boolInt(isInfix(vs, ss)))
+		},
+		"~[": func(it *Interp, name Ident) {
+			l, x := it.pop2()
+			if s, ok := l.(Str); ok {
+				if t, ok := textArg(x); ok {
+					it.push(boolInt(strings.Contains(string(s), t)))
+					return
+				}
+			}
+			vs, ok := items(l)
+			if !ok {
+				unsupported(name, l, x)
+			}
+			found := false
+			for _, v := range vs {
+				if equal(v, x) {
+					found = true
+					break
+				}
+			}
+			it.push(boolInt(found))
+		},
+		"b2": baseConv(2),
+		"b6": baseConv(16),
+	})
+}
+
+// baseConv returns a built-in that converts a string of digits in the given
+// base to an integer.
+func baseConv(base int) builtin {
+	return func(it *Interp, name Ident) {
+		s := Str(strings.TrimSpace(string(it.popStr(name))))
+		if s == "" {
+			it.push(Int(0))
+			return
+		}
+		n, ok := toBigString(string(s), base)
+		if !ok {
+			fail("%s: invalid number: %q", name, s)
+		}
+		it.push(n)
+	}
+}
+
+// textOp returns a built-in that transforms a string, or a character as a
+// string of one.
+func textOp(f func(s string) string) builtin {
+	return func(it *Interp, name Ident) {
+		switch v := it.pop().(type) {
+		case Str:
+			it.push(Str(f(string(v))))
+		case Char:
+			it.push(Char(f(string([]byte{byte(v)}))[0]))
+		default:
+			unsupported(name, v)
+		}
+	}
+}
+
+// readArray parses a nested array literal, like [1,[2,3]], into blocks.
+func readArray(name Ident, s string) Value {
+	prog, err := Parse(strings.NewReplacer("[", "{", "]", "}", ",", " ").Replace(s))
+	if err != nil || len(prog) != 1 {
+		fail("%s: malformed array: %q", name, s)
+	}
+	return prog[0]
+}
+
+func isAlpha(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func init() {
+	addBuiltins(map[Ident]builtin{
+		"zz": textOp(strings.ToLower),
+		"ZZ": textOp(strings.ToUpper),
+		"t[": textOp(func(s string) string { return strings.TrimLeft(s, " \t\r\n") }),
+		"t]": textOp(func(s string) string { return strings.TrimRight(s, " \t\r\n") }),
+		"tt": textOp(strings.TrimSpace),
+		"sr": func(it *Interp, name Ident) {
+			s := it.popStr(name)
+			expr := it.popStr(name)
+			it.push(strBlock(compileRegexp(name, expr).Split(string(s), -1)))
+		},
+		"ra": func(it *Interp, name Ident) {
+			if c, ok := it.top().(Char); ok {
+				it.pop()
+				it.push(boolInt(unicode.IsPunct(rune(c))))
+				return
+			}
+			it.push(readArray(name, string(it.popStr(name))))
+		},
+		"b0": baseConv(10),
+		"to": func(it *Interp, _ Ident) { it.push(Str(typeName(it.pop()))) },
+		"rd": func(it *Interp, name Ident) {
+			switch v := it.pop().(type) {
+			case Char:
+				it.push(boolInt(isAlpha(byte(v))))
+			case Str:
+				d, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+				if err != nil {
+					fail("%s: not a number: %q", name, v)
+				}
+				it.push(Double(d))
+			default:
+				unsupported(name, v)
+			}
+		},
+	})
+}
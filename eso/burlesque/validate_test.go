@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/eso/burlesque"
+	"github.com/fis/aoc/util"
+)
+
+var long = flag.Bool("burlesque.long", false, "run the solutions with the full step limit")
+
+// maxSteps is the number of values evaluated after which a solution is
+// considered too slow to check. Only the quick solutions fit in the default
+// limit; the -burlesque.long flag raises it to cover nearly all of them.
+const (
+	maxSteps     = 2_000_000
+	maxStepsLong = 50_000_000
+)
+
+// knownBad lists the solutions whose results still differ from the reference
+// interpreter, due to semantics of some built-ins not yet matched here. They
+// are still run, and counted as expected failures until they start passing.
+var knownBad = map[string]string{
+	"2021-19-1": "fe finds no matching scanner orientation",
+	"2021-19-2": "fe finds no matching scanner orientation",
+	"2021-22-2": "stack underflow",
+	"2022-17-1": "wrong tower height",
+	"2022-22-1": "walks off the map",
+}
+
+var reProgName = regexp.MustCompile(`^(\d{4})-(\d\d)-(\d)\.blsq$`)
+
+// TestSolutions runs the Burlesque solutions against the test data. Solutions
+// that need built-ins the interpreter does not support, or that run for too
+// long, are skipped.
+func TestSolutions(t *testing.T) {
+	progs, err := filepath.Glob("../20*/*.blsq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := int64(maxSteps)
+	if *long {
+		limit = maxStepsLong
+	}
+	var passed, failed, expectedFailed, skipped int
+	for _, prog := range progs {
+		m := reProgName.FindStringSubmatch(filepath.Base(prog))
+		if m == nil {
+			continue
+		}
+		prog, year, day := prog, m[1], m[2]
+		part, _ := strconv.Atoi(m[3])
+		var result *int
+		name := strings.TrimSuffix(filepath.Base(prog), ".blsq")
+		t.Run(name, func(t *testing.T) {
+			result = &failed
+			err := runSolution(prog, year, day, part, limit)
+			var ue *burlesque.UnsupportedError
+			if errors.As(err, &ue) || errors.Is(err, burlesque.ErrLimit) {
+				result = &skipped
+				t.Skip(err)
+			}
+			if reason, ok := knownBad[name]; ok {
+				if err == nil {
+					t.Fatalf("known bad solution (%s) now passes; remove it from knownBad", reason)
+				}
+				result = &expectedFailed
+				t.Logf("expected failure (%s): %v", reason, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = &passed
+		})
+		if result != nil {
+			*result++
+		}
+	}
+	t.Logf("%d passed, %d failed, %d expected failures, %d skipped", passed, failed, expectedFailed, skipped)
+}
+
+// runSolution runs a single solution with the given step limit, and checks its
+// output against the test data.
+func runSolution(prog, year, day string, part int, limit int64) error {
+	src, err := os.ReadFile(prog)
+	if err != nil {
+		return err
+	}
+	input, err := os.ReadFile(filepath.Join("../../testdata", year, "day"+day+".txt"))
+	if err != nil {
+		return err
+	}
+	want, err := util.ReadLines(filepath.Join("../../testdata", year, "day"+day+".out"))
+	if err != nil {
+		return err
+	}
+	it, err := burlesque.New(string(src), string(input))
+	if err != nil {
+		return err
+	}
+	it.Limit = limit
+	if err := it.Run(); err != nil {
+		return err
+	}
+	got, exp := it.Output(), strings.Join(expected(want, part), "\n")
+	if strings.Contains(exp, "\n") {
+		// Pictures may use '.' for the blank pixels.
+		got = strings.ReplaceAll(got, ".", " ")
+	}
+	got, exp = trimLines(got), trimLines(exp)
+	if got != exp {
+		return fmt.Errorf("got:\n%s\nwant:\n%s", got, exp)
+	}
+	return nil
+}
+
+// expected returns the expected output of a part: the first line for part 1,
+// and the rest, which may be several lines long, for part 2.
+func expected(want []string, part int) []string {
+	if part == 1 {
+		return want[:min(1, len(want))]
+	}
+	return want[min(1, len(want)):]
+}
+
+func trimLines(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,392 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burlesque
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Value is a Burlesque value. It is one of the types below; integers that do
+// not fit in an Int are represented as *big.Int.
+type Value any
+
+type (
+	// Int is an integer.
+	Int int64
+	// Double is a floating point number.
+	Double float64
+	// Char is a single character.
+	Char byte
+	// Str is a string.
+	Str string
+	// Block is a list of values, which doubles as code.
+	Block []Value
+	// Ident is a built-in (or user-defined) command. Evaluating it runs the
+	// command.
+	Ident string
+	// Quote is a quoted identifier, as in (J). Evaluating it pushes the
+	// identifier.
+	Quote string
+)
+
+// Stream is a lazy infinite list, such as a block cycled forever, or the
+// integers counting up from a starting point. Only a few built-ins accept
+// streams, most notably the zips, which cut them to the length of the other
+// list.
+type Stream struct {
+	item func(i int) Value
+}
+
+func cycle(b Block) Stream { return Stream{func(i int) Value { return b[i%len(b)] }} }
+
+func counter(from Int) Stream { return Stream{func(i int) Value { return from + Int(i) }} }
+
+func (s Stream) at(i int) Value { return s.item(i) }
+
+func (s Stream) take(n int) []Value {
+	out := make([]Value, n)
+	for i := range out {
+		out[i] = s.at(i)
+	}
+	return out
+}
+
+func (s Stream) drop(n int) Stream {
+	return Stream{func(i int) Value { return s.at(i + n) }}
+}
+
+func (s Stream) mapItems(f func(v Value) Value) Stream {
+	return Stream{func(i int) Value { return f(s.at(i)) }}
+}
+
+// def is a definition of a new command, as in %xx={...}.
+type def struct {
+	name Ident
+	body Block
+}
+
+func normInt(n *big.Int) Value {
+	if n.IsInt64() {
+		return Int(n.Int64())
+	}
+	return n
+}
+
+func toBig(v Value) *big.Int {
+	switch v := v.(type) {
+	case Int:
+		return big.NewInt(int64(v))
+	case *big.Int:
+		return v
+	}
+	panic(fmt.Sprintf("not an integer: %v", v))
+}
+
+func isInt(v Value) bool {
+	switch v.(type) {
+	case Int, *big.Int:
+		return true
+	}
+	return false
+}
+
+// intOp applies an integer operation, with a fast path for small values that
+// falls back to big.Int if the result overflows.
+func intOp(a, b Value, small func(x, y int64) (int64, bool), large func(z, x, y *big.Int) *big.Int) Value {
+	if x, ok := a.(Int); ok {
+		if y, ok := b.(Int); ok {
+			if z, ok := small(int64(x), int64(y)); ok {
+				return Int(z)
+			}
+		}
+	}
+	return normInt(large(new(big.Int), toBig(a), toBig(b)))
+}
+
+func addInt(a, b Value) Value {
+	return intOp(a, b, func(x, y int64) (int64, bool) {
+		z := x + y
+		return z, (z > x) == (y > 0)
+	}, (*big.Int).Add)
+}
+
+func subInt(a, b Value) Value {
+	return intOp(a, b, func(x, y int64) (int64, bool) {
+		z := x - y
+		return z, (z < x) == (y > 0)
+	}, (*big.Int).Sub)
+}
+
+func mulInt(a, b Value) Value {
+	return intOp(a, b, func(x, y int64) (int64, bool) {
+		if x == 0 || y == 0 {
+			return 0, true
+		}
+		z := x * y
+		return z, z/y == x && !(x == -1 && y == math.MinInt64) && !(y == -1 && x == math.MinInt64)
+	}, (*big.Int).Mul)
+}
+
+// divInt and modInt round towards negative infinity, like Haskell's div and
+// mod.
+func divInt(a, b Value) Value {
+	return intOp(a, b, func(x, y int64) (int64, bool) {
+		if y == 0 || x == math.MinInt64 && y == -1 {
+			return 0, false
+		}
+		q := x / y
+		if (x%y != 0) && ((x < 0) != (y < 0)) {
+			q--
+		}
+		return q, true
+	}, func(z, x, y *big.Int) *big.Int {
+		m := new(big.Int)
+		z.DivMod(x, y, m)
+		if m.Sign() != 0 && y.Sign() < 0 {
+			z.Add(z, big.NewInt(1))
+		}
+		return z
+	})
+}
+
+func modInt(a, b Value) Value {
+	return intOp(a, b, func(x, y int64) (int64, bool) {
+		if y == 0 {
+			return 0, false
+		}
+		m := x % y
+		if m != 0 && (m < 0) != (y < 0) {
+			m += y
+		}
+		return m, true
+	}, func(z, x, y *big.Int) *big.Int {
+		z.Mod(x, y)
+		if z.Sign() != 0 && y.Sign() < 0 {
+			z.Add(z, y)
+		}
+		return z
+	})
+}
+
+func typeRank(v Value) int {
+	switch v.(type) {
+	case Int, *big.Int, Double:
+		return 0
+	case Char:
+		return 1
+	case Str:
+		return 2
+	case Block:
+		return 3
+	}
+	return 4
+}
+
+// compare orders values: numbers numerically, strings and blocks
+// lexicographically, and values of different types by their type.
+func compare(a, b Value) int {
+	if ra, rb := typeRank(a), typeRank(b); ra != rb {
+		return cmp.Compare(ra, rb)
+	}
+	switch a := a.(type) {
+	case Int:
+		if b, ok := b.(Int); ok {
+			return cmp.Compare(a, b)
+		}
+		if isInt(b) {
+			return toBig(a).Cmp(toBig(b))
+		}
+		return cmp.Compare(float64(a), float64(b.(Double)))
+	case *big.Int:
+		if isInt(b) {
+			return a.Cmp(toBig(b))
+		}
+		f, _ := a.Float64()
+		return cmp.Compare(f, float64(b.(Double)))
+	case Double:
+		return cmp.Compare(float64(a), toFloat(b))
+	case Char:
+		return cmp.Compare(a, b.(Char))
+	case Str:
+		return strings.Compare(string(a), string(b.(Str)))
+	case Block:
+		b := b.(Block)
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(len(a), len(b))
+	}
+	return strings.Compare(Display(a), Display(b))
+}
+
+func equal(a, b Value) bool { return compare(a, b) == 0 }
+
+func toFloat(v Value) float64 {
+	switch v := v.(type) {
+	case Int:
+		return float64(v)
+	case *big.Int:
+		f, _ := v.Float64()
+		return f
+	case Double:
+		return float64(v)
+	}
+	panic(fmt.Sprintf("not a number: %v", v))
+}
+
+// truthy is the truth value of a condition: nonzero numbers and nonempty
+// lists are true.
+func truthy(v Value) bool {
+	switch v := v.(type) {
+	case Int:
+		return v != 0
+	case *big.Int:
+		return v.Sign() != 0
+	case Double:
+		return v != 0
+	case Char:
+		return v != 0
+	case Str:
+		return v != ""
+	case Block:
+		return len(v) > 0
+	}
+	return true
+}
+
+func boolInt(b bool) Value {
+	if b {
+		return Int(1)
+	}
+	return Int(0)
+}
+
+// Display formats a value the way the interpreter prints it.
+func Display(v Value) string {
+	var sb strings.Builder
+	display(&sb, v)
+	return sb.String()
+}
+
+func display(sb *strings.Builder, v Value) {
+	switch v := v.(type) {
+	case Int:
+		sb.WriteString(strconv.FormatInt(int64(v), 10))
+	case *big.Int:
+		sb.WriteString(v.String())
+	case Double:
+		s := strconv.FormatFloat(float64(v), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eIN") {
+			s += ".0"
+		}
+		sb.WriteString(s)
+	case Char:
+		sb.WriteByte(byte(v))
+	case Str:
+		sb.WriteString(string(v))
+	case Block:
+		sb.WriteByte('{')
+		for i, e := range v {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			display(sb, e)
+		}
+		sb.WriteByte('}')
+	case Stream:
+		sb.WriteString("{")
+		for i := 0; i < 3; i++ {
+			display(sb, v.at(i))
+			sb.WriteString(" ")
+		}
+		sb.WriteString("...}")
+	case Ident:
+		sb.WriteString(string(v))
+	case Quote:
+		sb.WriteString("(" + string(v) + ")")
+	case def:
+		sb.WriteString("%" + string(v.name) + "=")
+		display(sb, v.body)
+	default:
+		fmt.Fprint(sb, v)
+	}
+}
+
+// typeName is the name of the type of a value, for error messages.
+func typeName(v Value) string {
+	switch v.(type) {
+	case Int, *big.Int:
+		return "Int"
+	case Double:
+		return "Double"
+	case Char:
+		return "Char"
+	case Str:
+		return "Str"
+	case Block:
+		return "Block"
+	case Stream:
+		return "Stream"
+	case Ident, Quote:
+		return "Ident"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// key returns a string that is the same for two values exactly when they
+// compare equal, for use as a map key.
+func key(v Value) string {
+	var sb strings.Builder
+	writeKey(&sb, v)
+	return sb.String()
+}
+
+func writeKey(sb *strings.Builder, v Value) {
+	switch v := v.(type) {
+	case Int, *big.Int:
+		sb.WriteByte('n')
+		display(sb, v)
+	case Double:
+		sb.WriteByte('n')
+		if f := float64(v); f == math.Trunc(f) && !math.IsInf(f, 0) {
+			bf, _ := big.NewFloat(f).Int(nil)
+			sb.WriteString(bf.String())
+		} else {
+			display(sb, v)
+		}
+	case Char:
+		sb.WriteByte('c')
+		sb.WriteByte(byte(v))
+	case Str:
+		fmt.Fprintf(sb, "s%d:%s", len(v), v)
+	case Block:
+		sb.WriteByte('{')
+		for _, e := range v {
+			writeKey(sb, e)
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('}')
+	default:
+		fmt.Fprintf(sb, "%T:", v)
+		display(sb, v)
+	}
+}
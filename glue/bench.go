@@ -0,0 +1,448 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/subcommands"
+)
+
+// benchAlpha is the significance level for flagging a change against the baseline.
+const benchAlpha = 0.05
+
+type benchCmd struct {
+	impl       string
+	variant    string
+	count      int
+	benchTime  time.Duration
+	baseline   string
+	save       string
+	threshold  float64
+	cpuProfile string
+	memProfile string
+}
+
+func (*benchCmd) Name() string {
+	return "bench"
+}
+
+func (*benchCmd) Synopsis() string {
+	return "Benchmark the AoC solvers against their test inputs."
+}
+
+func (*benchCmd) Usage() string {
+	return `bench [flags] [year [day]]:
+
+  Benchmark the solvers of all the days that have test data under
+  "testdata/YYYY/dayDD.{txt,out}" in the current directory, optionally
+  restricted to a single year or day. Named inputs (see 'aoc help solve')
+  are benchmarked separately, and shown as YYYY.DD@NAME. With -variant,
+  only the days that have a variant of that name are benchmarked, using
+  the variant as the solver.
+
+  Each solver is run once as a warm-up, checking the answers against the
+  expected output, and then repeatedly until either -count runs have been
+  made, or at least 3 runs have been made and -benchtime has been spent.
+  The median, 90th percentile and minimum wall clock times are reported,
+  along with the average number of heap allocations and bytes allocated
+  per run.
+
+  The raw results can be saved in a JSON file with -save, and compared
+  against a previously saved file with -baseline. Changes are tested for
+  significance with the Mann-Whitney U test; a significant change in the
+  median of more than -threshold percent is reported as a regression or an
+  improvement. If any regressions are found, the command exits with a
+  failure status.
+
+  The -cpuprofile and -memprofile flags name a directory where a CPU and
+  heap profile (respectively) are written for each day, as
+  YYYY-DD.cpu.pprof and YYYY-DD.mem.pprof, for analysis with 'go tool
+  pprof'. For a named input, the name follows the day, as in
+  YYYY-DD@NAME.cpu.pprof.
+
+  The allocation counts of a heap profile are cumulative over the whole
+  process, so they include the days benchmarked earlier. To see the
+  allocations of just the one day, use the snapshot taken before it as the
+  base: 'go tool pprof -sample_index=alloc_space -base
+  YYYY-DD.mem.base.pprof YYYY-DD.mem.pprof'. The in-use figures are after
+  a garbage collection, once the day is done.
+
+`
+}
+
+func (c *benchCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.impl, "impl", DefaultImpl, "implementation language of the solvers to benchmark")
+	f.StringVar(&c.variant, "variant", "", "name of the alternative algorithm to benchmark")
+	f.IntVar(&c.count, "count", 20, "maximum number of timed runs per day")
+	f.DurationVar(&c.benchTime, "benchtime", time.Second, "time to spend on the timed runs of a day")
+	f.StringVar(&c.baseline, "baseline", "", "compare the results against this saved baseline file")
+	f.StringVar(&c.save, "save", "", "save the results as a baseline in this file")
+	f.Float64Var(&c.threshold, "threshold", 5, "smallest change in percent that's flagged against the baseline")
+	f.StringVar(&c.cpuProfile, "cpuprofile", "", "write a CPU profile of each day into this directory")
+	f.StringVar(&c.memProfile, "memprofile", "", "write a heap profile of each day into this directory")
+}
+
+func (c *benchCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if f.NArg() > 2 {
+		fmt.Fprintf(os.Stderr, "usage: bench [flags] [year [day]]\n")
+		return subcommands.ExitFailure
+	}
+	if c.variant != "" && c.impl != DefaultImpl {
+		fmt.Fprintf(os.Stderr, "variants are only available for the %s implementation\n", DefaultImpl)
+		return subcommands.ExitFailure
+	}
+	tests, err := c.findTests(f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+
+	var base map[benchKey]*BenchResult
+	if c.baseline != "" {
+		if base, err = readBaseline(c.baseline); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	fmt.Printf("%-7s %9s %9s %9s %10s %12s", "day", "median", "p90", "min", "allocs/op", "B/op")
+	if base != nil {
+		fmt.Printf(" %8s %6s", "delta", "p")
+	}
+	fmt.Println()
+
+	saved := BenchBaseline{GoVersion: runtime.Version()}
+	status := subcommands.ExitSuccess
+	for _, test := range tests {
+		r, err := c.run(test)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", benchLabel(test), err)
+			status = subcommands.ExitFailure
+			continue
+		}
+		saved.Results = append(saved.Results, r)
+		st := r.stats()
+		fmt.Printf("%-7s %9s %9s %9s %10d %12d", benchLabel(test), fmtDuration(st.median), fmtDuration(st.p90), fmtDuration(st.min), r.Allocs, r.Bytes)
+		if b, ok := base[r.key()]; ok {
+			delta := 100 * (float64(st.median)/float64(b.stats().median) - 1)
+			p := mannWhitney(r.Samples, b.Samples)
+			fmt.Printf(" %+7.1f%% %6.3f", delta, p)
+			if p < benchAlpha && delta > c.threshold {
+				fmt.Print(" REGRESSION")
+				status = subcommands.ExitFailure
+			} else if p < benchAlpha && delta < -c.threshold {
+				fmt.Print(" improvement")
+			}
+		} else if base != nil {
+			fmt.Printf(" %8s %6s", "-", "-")
+		}
+		fmt.Println()
+	}
+
+	if c.save != "" {
+		if err := writeBaseline(c.save, &saved); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+	}
+	return status
+}
+
+func (c *benchCmd) findTests(args []string) ([]TestCase, error) {
+	const testRoot = "testdata"
+	var (
+		tests []TestCase
+		err   error
+	)
+	switch len(args) {
+	case 0:
+		tests, err = FindAllTests(testRoot)
+	case 1:
+		year, yerr := strconv.Atoi(args[0])
+		if yerr != nil {
+			return nil, fmt.Errorf("not a year: %s", args[0])
+		}
+		tests, err = FindTests(testRoot, year)
+	case 2:
+		year, day, suffix, derr := parseDay(args[0], args[1])
+		if derr == nil && suffix != "" {
+			derr = fmt.Errorf("unexpected suffix after day number")
+		}
+		if derr != nil {
+			return nil, derr
+		}
+		tests, err = FindTests(testRoot, year)
+		tests = slices.DeleteFunc(tests, func(t TestCase) bool { return t.Day != day })
+	}
+	if err != nil {
+		return nil, err
+	}
+	tests = slices.DeleteFunc(tests, func(t TestCase) bool {
+		yd := YearDay{t.Year, t.Day}
+		if c.variant != "" {
+			_, ok := variants[yd][c.variant]
+			return !ok
+		}
+		_, ok := impls[c.impl][yd]
+		return !ok
+	})
+	if len(tests) == 0 && c.variant != "" {
+		return nil, fmt.Errorf("no %s variants with test data found", c.variant)
+	} else if len(tests) == 0 {
+		return nil, fmt.Errorf("no %s solvers with test data found", c.impl)
+	}
+	return tests, nil
+}
+
+// run benchmarks the solver of a single day.
+func (c *benchCmd) run(test TestCase) (*BenchResult, error) {
+	input, err := os.ReadFile(test.InputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	got, err := c.solve(test, input)
+	if err != nil {
+		return nil, err
+	}
+	want := test.Want
+	if c.impl != DefaultImpl && len(got) > 0 && len(got) < len(want) {
+		want = want[:len(got)] // alternative implementations may only solve the first part
+	}
+	if !slices.Equal(got, want) {
+		return nil, fmt.Errorf("got %q, want %q", got, want)
+	}
+
+	name := fmt.Sprintf("%04d-%02d", test.Year, test.Day)
+	if test.Name != "" {
+		name += "@" + test.Name
+	}
+	if c.memProfile != "" {
+		if err := writeHeapProfile(filepath.Join(c.memProfile, name+".mem.base.pprof")); err != nil {
+			return nil, err
+		}
+	}
+	if c.cpuProfile != "" {
+		f, err := os.Create(filepath.Join(c.cpuProfile, name+".cpu.pprof"))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &BenchResult{Impl: c.impl, Variant: c.variant, Year: test.Year, Day: test.Day, Input: test.Name}
+	var (
+		ms         runtime.MemStats
+		allocs, by uint64
+	)
+	start := time.Now()
+	for len(r.Samples) < c.count && (len(r.Samples) < 3 || time.Since(start) < c.benchTime) {
+		runtime.ReadMemStats(&ms)
+		allocs0, by0 := ms.Mallocs, ms.TotalAlloc
+		t0 := time.Now()
+		_, err := c.solve(test, input)
+		elapsed := time.Since(t0)
+		runtime.ReadMemStats(&ms)
+		if err != nil {
+			pprof.StopCPUProfile()
+			return nil, err
+		}
+		r.Samples = append(r.Samples, elapsed)
+		allocs += ms.Mallocs - allocs0
+		by += ms.TotalAlloc - by0
+	}
+	r.Allocs = allocs / uint64(len(r.Samples))
+	r.Bytes = by / uint64(len(r.Samples))
+
+	if c.cpuProfile != "" {
+		pprof.StopCPUProfile()
+	}
+	if c.memProfile != "" {
+		if err := writeHeapProfile(filepath.Join(c.memProfile, name+".mem.pprof")); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// writeHeapProfile writes a heap profile to the named file, after a garbage collection to bring the
+// in-use statistics up to date.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
+
+func (c *benchCmd) solve(test TestCase, input []byte) ([]string, error) {
+	out, _, err := solveSelected(c.impl, c.variant, test.Year, test.Day, bytes.NewReader(input))
+	return out, err
+}
+
+// BenchBaseline is the format of the saved benchmark results.
+type BenchBaseline struct {
+	GoVersion string         `json:"go_version"`
+	Results   []*BenchResult `json:"results"`
+}
+
+// BenchResult holds the benchmark results of a single solver.
+type BenchResult struct {
+	Impl    string          `json:"impl"`
+	Variant string          `json:"variant,omitempty"`
+	Input   string          `json:"input,omitempty"`
+	Year    int             `json:"year"`
+	Day     int             `json:"day"`
+	Samples []time.Duration `json:"samples_ns"`
+	Allocs  uint64          `json:"allocs_per_op"`
+	Bytes   uint64          `json:"bytes_per_op"`
+}
+
+type benchKey struct {
+	impl, variant, input string
+	YearDay
+}
+
+func (r *BenchResult) key() benchKey {
+	return benchKey{impl: r.Impl, variant: r.Variant, input: r.Input, YearDay: YearDay{r.Year, r.Day}}
+}
+
+// benchLabel returns the name of a test case in the results table.
+func benchLabel(test TestCase) string {
+	if test.Name == "" {
+		return fmt.Sprintf("%d.%02d", test.Year, test.Day)
+	}
+	return fmt.Sprintf("%d.%02d@%s", test.Year, test.Day, test.Name)
+}
+
+type benchStats struct {
+	median, p90, min time.Duration
+}
+
+func (r *BenchResult) stats() benchStats {
+	s := slices.Clone(r.Samples)
+	slices.Sort(s)
+	return benchStats{median: percentile(s, 50), p90: percentile(s, 90), min: s[0]}
+}
+
+// fmtDuration formats a duration rounded to three significant digits, to keep the columns narrow.
+func fmtDuration(d time.Duration) string {
+	for r := time.Duration(1); r < time.Hour; r *= 10 {
+		if d < 1000*r {
+			return d.Round(r).String()
+		}
+	}
+	return d.Round(time.Second).String()
+}
+
+// percentile returns the p'th percentile of the sorted samples, interpolating between the closest
+// ranks.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	pos := p / 100 * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i] + time.Duration(frac*float64(sorted[i+1]-sorted[i]))
+}
+
+// mannWhitney returns the two-sided p-value of the Mann-Whitney U test of the null hypothesis that
+// the two samples come from the same distribution. It uses the normal approximation of the U
+// statistic, with a correction for ties, which is good enough for flagging regressions.
+func mannWhitney(x, y []time.Duration) float64 {
+	type obs struct {
+		v     time.Duration
+		first bool
+	}
+	all := make([]obs, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range y {
+		all = append(all, obs{v, false})
+	}
+	slices.SortFunc(all, func(a, b obs) int { return cmp.Compare(a.v, b.v) })
+
+	n1, n2, n := float64(len(x)), float64(len(y)), float64(len(all))
+	rankSum, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank, t := float64(i+j+1)/2, float64(j-i)
+		for _, o := range all[i:j] {
+			if o.first {
+				rankSum += rank
+			}
+		}
+		ties += t*t*t - t
+		i = j
+	}
+
+	u := rankSum - n1*(n1+1)/2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 || math.IsNaN(sigma) {
+		return 1
+	}
+	z := (u - n1*n2/2) / sigma
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+func readBaseline(path string) (map[benchKey]*BenchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b BenchBaseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	results := make(map[benchKey]*BenchResult)
+	for _, r := range b.Results {
+		if len(r.Samples) > 0 {
+			results[r.key()] = r
+		}
+	}
+	return results, nil
+}
+
+func writeBaseline(path string, b *BenchBaseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
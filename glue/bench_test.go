@@ -0,0 +1,97 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{10, 20, 30, 40, 50}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 0, want: 10},
+		{p: 50, want: 30},
+		{p: 90, want: 46},
+		{p: 100, want: 50},
+	}
+	for _, test := range tests {
+		if got := percentile(samples, test.p); got != test.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", samples, test.p, got, test.want)
+		}
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	tests := []struct {
+		x, y []time.Duration
+		want float64
+	}{
+		{x: []time.Duration{1, 2, 3, 4, 5}, y: []time.Duration{6, 7, 8, 9, 10}, want: 0.00902},
+		{x: []time.Duration{6, 7, 8, 9, 10}, y: []time.Duration{1, 2, 3, 4, 5}, want: 0.00902},
+		{x: []time.Duration{1, 3, 5, 7, 9}, y: []time.Duration{2, 4, 6, 8, 10}, want: 0.60151},
+		{x: []time.Duration{5, 5, 5}, y: []time.Duration{5, 5, 5}, want: 1},
+	}
+	for _, test := range tests {
+		if got := mannWhitney(test.x, test.y); math.Abs(got-test.want) > 1e-5 {
+			t.Errorf("mannWhitney(%v, %v) = %.5f, want %.5f", test.x, test.y, got, test.want)
+		}
+	}
+}
+
+func TestFmtDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 999, want: "999ns"},
+		{d: 25144, want: "25.1µs"},
+		{d: 3830259, want: "3.83ms"},
+		{d: 726588445, want: "727ms"},
+		{d: 153368123456, want: "2m33s"},
+	}
+	for _, test := range tests {
+		if got := fmtDuration(test.d); got != test.want {
+			t.Errorf("fmtDuration(%d) = %q, want %q", test.d, got, test.want)
+		}
+	}
+}
+
+func TestFmtWrong(t *testing.T) {
+	pad := strings.Repeat(" ", 19)
+	tests := []struct {
+		out, want []string
+		expected  string
+	}{
+		{
+			out: []string{"1", "2"}, want: []string{"1", "3"},
+			expected: "1 2  WRONG, want: 1 3",
+		},
+		{
+			out: []string{"1", "#.", ".#"}, want: []string{"1", "##", ".#"},
+			expected: "1\n" + pad + "#.\n" + pad + ".#  WRONG, want:\n" + pad + "1\n" + pad + "##\n" + pad + ".#",
+		},
+	}
+	for _, test := range tests {
+		if got := fmtWrong(test.out, test.want); got != test.expected {
+			t.Errorf("fmtWrong(%q, %q) = %q, want %q", test.out, test.want, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Puzzle is the catalogue entry of an AoC puzzle and its solution, listed by `aoc list`.
+type Puzzle struct {
+	// Title is the title of the puzzle, without the "Day N:" prefix.
+	Title string
+	// Tags classify the puzzle and the solution. They must be among the names in PuzzleTags.
+	Tags []string
+	// InputSpecific is set if the solution relies on properties of the actual puzzle inputs beyond
+	// what the puzzle text promises, such as a particular structure of the program in the input.
+	InputSpecific bool
+}
+
+// PuzzleTags maps the names of the known puzzle tags to their descriptions.
+var PuzzleTags = map[string]string{
+	"automaton":     "cellular automaton (Game of Life and friends)",
+	"bits":          "bit manipulation",
+	"combinatorics": "search over combinations, permutations or assignments",
+	"dp":            "dynamic programming or memoization",
+	"geometry":      "coordinates, distances, intervals or shapes",
+	"graph":         "graph or tree structure algorithms",
+	"grid":          "2D map in the input or the solution",
+	"hashing":       "MD5 or another hash function",
+	"intcode":       "Intcode computer of 2019",
+	"number-theory": "modular arithmetic, divisors, primes and such",
+	"parsing":       "nontrivial input language or data format",
+	"pathfinding":   "shortest or longest paths, BFS, Dijkstra or A*",
+	"simulation":    "step by step simulation of a process",
+	"strings":       "string manipulation",
+	"vm":            "interpreter for an assembly-like language",
+}
+
+var puzzles = make(map[YearDay]Puzzle)
+
+// RegisterPuzzle adds the catalogue entry of the given day. This function is expected to be called
+// from an `init` func, next to the RegisterSolver call.
+func RegisterPuzzle(year, day int, p Puzzle) {
+	yd := YearDay{year, day}
+	if _, ok := puzzles[yd]; ok {
+		panic(fmt.Sprintf("duplicate puzzles: %d %d", year, day))
+	}
+	for _, tag := range p.Tags {
+		if _, ok := PuzzleTags[tag]; !ok {
+			panic(fmt.Sprintf("unknown tag for puzzle %d %d: %s", year, day, tag))
+		}
+	}
+	puzzles[yd] = p
+}
+
+// LookupPuzzle returns the catalogue entry of the given day, if there is one.
+func LookupPuzzle(year, day int) (Puzzle, bool) {
+	p, ok := puzzles[YearDay{year, day}]
+	return p, ok
+}
+
+// HasTag tests if the puzzle has the named tag.
+func (p Puzzle) HasTag(tag string) bool {
+	return slices.Contains(p.Tags, tag)
+}
+
+// Catalogue returns the days with a catalogue entry, in order.
+func Catalogue() []YearDay {
+	days := make([]YearDay, 0, len(puzzles))
+	for yd := range puzzles {
+		days = append(days, yd)
+	}
+	slices.SortFunc(days, func(a, b YearDay) int {
+		if a.Year != b.Year {
+			return cmp.Compare(a.Year, b.Year)
+		}
+		return cmp.Compare(a.Day, b.Day)
+	})
+	return days
+}
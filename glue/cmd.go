@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
@@ -37,6 +38,9 @@ func Main() {
 	subcommands.Register(subcommands.CommandsCommand(), "")
 	subcommands.Register(&solveCmd{}, "")
 	subcommands.Register(&plotCmd{}, "")
+	subcommands.Register(&benchCmd{}, "")
+	subcommands.Register(&listCmd{}, "")
+	subcommands.Register(&newCmd{}, "")
 
 	flag.Parse()
 	os.Exit(int(subcommands.Execute(context.Background())))
@@ -44,7 +48,15 @@ func Main() {
 
 // "aoc solve"
 
-type solveCmd struct{}
+type solveCmd struct {
+	impl     string
+	variant  string
+	jobs     int
+	slowest  int
+	diag     string
+	watch    bool
+	examples string
+}
 
 func (*solveCmd) Name() string {
 	return "solve"
@@ -56,9 +68,10 @@ func (*solveCmd) Synopsis() string {
 
 func (*solveCmd) Usage() string {
 	out := strings.Builder{}
-	out.WriteString(`solve <year> <day> [input]:
+	out.WriteString(`solve [-impl=lang | -variant=name] [-v=scopes] [-watch [-examples=dir]] <year> <day> [input]:
+solve [-impl=lang | -variant=name] [-v=scopes] [-j=N] [-slowest=N] all | <year>:
 
-  Solve one of the AoC puzzles.
+  Solve one of the AoC puzzles, or all of them.
 
   If an input file is not provided, but the file "testdata/YYYY/dayDD.txt"
   exists under the current directory, that file is used instead; this
@@ -66,34 +79,109 @@ func (*solveCmd) Usage() string {
   input is read from standard input, which you can explicitly request by
   passing "-" as the input file.
 
+  An input file of the form "@NAME" selects one of the additional named
+  inputs of the day from the test data, which are kept as either
+  "testdata/YYYY/dayDD.NAME.txt" or "testdata/YYYY/dayDD/NAME.txt".
+
+  The -impl flag selects an alternative implementation of the solution, such
+  as "z80". Any metrics the implementation reports (like emulated T-states)
+  are printed on standard error after the answers.
+
+  The -variant flag selects one of the alternative algorithms some of the
+  days have, listed below the available days. Variants always produce the
+  same answers as the default solver, but may have very different running
+  times.
+
+  The -v flag turns on diagnostic output, which is printed on standard error.
+  Its value is a comma-separated list of the scopes to enable: a day, like
+  "2019/18", a whole year, like "2019", a subsystem, like "intcode", or "all"
+  for everything.
+
+  The -watch flag keeps the command running after solving the input, and
+  solves it again every time the input file changes. The results are
+  redrawn each time with timing, and answers that differ from the previous
+  run are marked. With -examples, all the files in the given directory are
+  also solved, and watched for changes, on each run. An example (or the
+  input) with a matching ".out" file is checked against it.
+
+  Given just "all" or a year, all the matching days that have an input in
+  the test data are solved, using -j solvers in parallel. The results are
+  printed in order, with the time each day took, and checked against the
+  expected answers where those are known. The total of the times, and the
+  -slowest days, are printed at the end.
+
   Available days:
 `)
-	years, days := []int(nil), make(map[int][]int)
-	for yd := range solvers {
-		if _, ok := days[yd.Year]; !ok {
-			years = append(years, yd.Year)
+	for _, impl := range Impls() {
+		fmt.Fprintf(&out, "  %s:\n", impl)
+		years, days := []int(nil), make(map[int][]int)
+		for yd := range impls[impl] {
+			if _, ok := days[yd.Year]; !ok {
+				years = append(years, yd.Year)
+			}
+			days[yd.Year] = append(days[yd.Year], yd.Day)
 		}
-		days[yd.Year] = append(days[yd.Year], yd.Day)
-	}
-	slices.Sort(years)
-	for _, y := range years {
-		fmt.Fprintf(&out, "    %d:", y)
-		slices.Sort(days[y])
-		for _, d := range days[y] {
-			fmt.Fprintf(&out, " %d", d)
+		slices.Sort(years)
+		for _, y := range years {
+			fmt.Fprintf(&out, "    %d:", y)
+			slices.Sort(days[y])
+			for _, d := range days[y] {
+				fmt.Fprintf(&out, " %d", d)
+			}
+			out.WriteRune('\n')
 		}
-		out.WriteRune('\n')
+	}
+	out.WriteString("\n  Available variants:\n")
+	vdays := make([]YearDay, 0, len(variants))
+	for yd := range variants {
+		vdays = append(vdays, yd)
+	}
+	slices.SortFunc(vdays, func(a, b YearDay) int {
+		if a.Year != b.Year {
+			return cmp.Compare(a.Year, b.Year)
+		}
+		return cmp.Compare(a.Day, b.Day)
+	})
+	for _, yd := range vdays {
+		fmt.Fprintf(&out, "    %d %d: %s\n", yd.Year, yd.Day, strings.Join(Variants(yd.Year, yd.Day), " "))
 	}
 	return out.String()
 }
 
-func (*solveCmd) SetFlags(_ *flag.FlagSet) {}
+func (c *solveCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.impl, "impl", DefaultImpl, "implementation language of the solver to use")
+	f.StringVar(&c.variant, "variant", "", "name of an alternative algorithm to use")
+	f.IntVar(&c.jobs, "j", runtime.NumCPU(), "number of days to solve in parallel, when solving many")
+	f.IntVar(&c.slowest, "slowest", 5, "number of slowest days to list, when solving many")
+	f.StringVar(&c.diag, "v", "", "comma-separated `scopes` to print diagnostics for")
+	f.BoolVar(&c.watch, "watch", false, "solve again whenever the input changes")
+	f.StringVar(&c.examples, "examples", "", "`dir`ectory of example inputs to also solve, with -watch")
+}
 
-func (*solveCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
-	if f.NArg() < 2 || f.NArg() > 3 {
-		fmt.Fprintf(os.Stderr, "usage: solve <year> <day> [input]\n")
+func (c *solveCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if f.NArg() < 1 || f.NArg() > 3 {
+		fmt.Fprintf(os.Stderr, "usage: solve [-impl=lang | -variant=name] [-v=scopes] [-watch [-examples=dir]] <year> <day> [input]\n")
+		return subcommands.ExitFailure
+	}
+	if err := util.SetDiagFilter(c.diag); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+	if c.variant != "" && c.impl != DefaultImpl {
+		fmt.Fprintf(os.Stderr, "variants are only available for the %s implementation\n", DefaultImpl)
+		return subcommands.ExitFailure
+	}
+	if c.examples != "" && !c.watch {
+		fmt.Fprintln(os.Stderr, "-examples can only be used with -watch")
 		return subcommands.ExitFailure
 	}
+	if f.NArg() == 1 {
+		if c.watch {
+			fmt.Fprintln(os.Stderr, "-watch can only be used when solving a single day")
+			return subcommands.ExitFailure
+		}
+		return c.solveAll(f.Arg(0))
+	}
 	year, day, suffix, err := parseDay(f.Arg(0), f.Arg(1))
 	if err != nil || suffix != "" {
 		if err == nil {
@@ -103,6 +191,15 @@ func (*solveCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) s
 		return subcommands.ExitFailure
 	}
 
+	if c.watch {
+		path, err := watchPath(f.Arg(2), year, day)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+		return c.solveWatch(year, day, path)
+	}
+
 	in, inName, close, err := parseInput(f.Arg(2), year, day)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -110,7 +207,7 @@ func (*solveCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) s
 	}
 	defer close()
 
-	out, err := Solve(year, day, in)
+	out, metrics, err := solveSelected(c.impl, c.variant, year, day, in)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s: %v\n", inName, err)
 		return subcommands.ExitFailure
@@ -118,6 +215,9 @@ func (*solveCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) s
 	for _, line := range out {
 		fmt.Println(line)
 	}
+	for _, m := range metrics {
+		fmt.Fprintf(os.Stderr, "# %s = %d\n", m.Name, m.Value)
+	}
 
 	return subcommands.ExitSuccess
 }
@@ -225,9 +325,8 @@ func parseDay(ya, da string) (y, d int, suffix string, err error) {
 	if y, err = strconv.Atoi(ya); err != nil {
 		return 0, 0, "", fmt.Errorf("not a year: %s", ya)
 	}
-	var ok bool
-	d, ok, suffix = util.NextInt(da)
-	if !ok {
+	d, suffix, err = util.NextInt(da)
+	if err != nil {
 		return 0, 0, "", fmt.Errorf("not a day with suffix: %s", da)
 	}
 	return y, d, suffix, nil
@@ -235,6 +334,11 @@ func parseDay(ya, da string) (y, d int, suffix string, err error) {
 
 func parseInput(arg string, year, day int) (file *os.File, name string, close func(), err error) {
 	// file, name, closable = os.Stdin, "<stdin>", false
+	if name, ok := strings.CutPrefix(arg, "@"); ok {
+		if arg, err = FindInput("testdata", year, day, name); err != nil {
+			return nil, "", nil, err
+		}
+	}
 	if arg != "" && arg != "-" {
 		if file, err = os.Open(arg); err != nil {
 			return nil, "", nil, err
@@ -248,3 +352,18 @@ func parseInput(arg string, year, day int) (file *os.File, name string, close fu
 	}
 	return os.Stdin, "<stdin>", func() {}, nil
 }
+
+// watchPath resolves the input argument like parseInput does, except that the input must be a file,
+// and the default test data file is used even if it does not exist yet.
+func watchPath(arg string, year, day int) (string, error) {
+	if name, ok := strings.CutPrefix(arg, "@"); ok {
+		return FindInput("testdata", year, day, name)
+	}
+	switch arg {
+	case "-":
+		return "", errors.New("can't watch standard input")
+	case "":
+		return fmt.Sprintf("testdata/%04d/day%02d.txt", year, day), nil
+	}
+	return arg, nil
+}
@@ -22,9 +22,13 @@
 package glue
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"runtime/debug"
+	"slices"
 )
 
 // Solver represents a function capable of solving one of the AoC puzzles.
@@ -33,11 +37,41 @@ type Solver interface {
 	Solve(r io.Reader) ([]string, error)
 }
 
+// Metric is a named measurement about a solver run, such as the number of T-states taken by an
+// emulated CPU.
+type Metric struct {
+	Name  string
+	Value int64
+}
+
+// MeasuredSolver is implemented by solvers that can report metrics about a run in addition to the
+// output lines.
+type MeasuredSolver interface {
+	Solver
+	// SolveMeasured is like Solve, but also returns the metrics collected during the run.
+	SolveMeasured(r io.Reader) ([]string, []Metric, error)
+}
+
 // Plotter represents a function capable of turning an AoC puzzle input to a GraphViz dot graph.
 type Plotter interface {
 	Plot(r io.Reader, w io.Writer) error
 }
 
+// Generator produces random inputs for a puzzle, for testing the solvers beyond the real inputs.
+type Generator interface {
+	// Generate writes a valid random input to w, drawing all randomness from rng. The size (at
+	// least 1) scales the input in a way that depends on the puzzle, such as the number of lines.
+	Generate(w io.Writer, rng *rand.Rand, size int) error
+}
+
+// GeneratorFunc adapts a plain function to the Generator interface.
+type GeneratorFunc func(w io.Writer, rng *rand.Rand, size int) error
+
+// Generate implements the Generator interface.
+func (g GeneratorFunc) Generate(w io.Writer, rng *rand.Rand, size int) error {
+	return g(w, rng, size)
+}
+
 type plotterRecord struct {
 	plotter  Plotter
 	examples map[string]string
@@ -52,13 +86,23 @@ type YearDaySuffix struct {
 	Suffix string
 }
 
+// DefaultImpl is the name of the implementation language of the solvers registered with
+// RegisterSolver. Alternative implementations are registered with RegisterImpl.
+const DefaultImpl = "go"
+
 var (
-	solvers  map[YearDay]Solver
-	plotters map[YearDaySuffix]plotterRecord
+	solvers    map[YearDay]Solver
+	impls      map[string]map[YearDay]Solver
+	variants   map[YearDay]map[string]Solver
+	generators map[YearDay]Generator
+	plotters   map[YearDaySuffix]plotterRecord
 )
 
 func init() {
 	solvers = make(map[YearDay]Solver)
+	variants = make(map[YearDay]map[string]Solver)
+	generators = make(map[YearDay]Generator)
+	impls = map[string]map[YearDay]Solver{DefaultImpl: solvers}
 	plotters = make(map[YearDaySuffix]plotterRecord)
 }
 
@@ -72,6 +116,87 @@ func RegisterSolver(year, day int, s Solver) {
 	solvers[yd] = s
 }
 
+// RegisterImpl makes a solver known to the glue code as an alternative implementation of the given
+// day, written in the named language (for example, "z80"). This function is expected to be called
+// from an `init` func.
+func RegisterImpl(impl string, year, day int, s Solver) {
+	if impl == DefaultImpl {
+		RegisterSolver(year, day, s)
+		return
+	}
+	days, ok := impls[impl]
+	if !ok {
+		days = make(map[YearDay]Solver)
+		impls[impl] = days
+	}
+	yd := YearDay{year, day}
+	if _, ok := days[yd]; ok {
+		panic(fmt.Sprintf("duplicate %s solvers: %d %d", impl, year, day))
+	}
+	days[yd] = s
+}
+
+// Impls returns the names of all implementation languages with registered solvers, in sorted
+// order.
+func Impls() []string {
+	names := make([]string, 0, len(impls))
+	for impl := range impls {
+		names = append(names, impl)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RegisterVariant registers a named alternative algorithm for solving a day. Variants are
+// expected to produce exactly the same answers as the default solver; they exist so that the
+// different approaches can be benchmarked and cross-checked against each other.
+func RegisterVariant(year, day int, name string, s Solver) {
+	yd := YearDay{year, day}
+	days, ok := variants[yd]
+	if !ok {
+		days = make(map[string]Solver)
+		variants[yd] = days
+	}
+	if _, ok := days[name]; ok {
+		panic(fmt.Sprintf("duplicate variants: %d %d %s", year, day, name))
+	}
+	days[name] = s
+}
+
+// Variants returns the sorted names of the variants registered for a day.
+func Variants(year, day int) []string {
+	names := make([]string, 0, len(variants[YearDay{year, day}]))
+	for name := range variants[YearDay{year, day}] {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RegisterGenerator makes a random input generator known to the glue code for the given day.
+// This function is expected to be called from an `init` func.
+func RegisterGenerator(year, day int, g Generator) {
+	yd := YearDay{year, day}
+	if _, ok := generators[yd]; ok {
+		panic(fmt.Sprintf("duplicate generators: %d %d", year, day))
+	}
+	generators[yd] = g
+}
+
+// Generate produces a random input of the given size for a day. The input is fully determined by
+// the seed.
+func Generate(year, day int, seed int64, size int) ([]byte, error) {
+	g, ok := generators[YearDay{year, day}]
+	if !ok {
+		return nil, fmt.Errorf("no generator for day: %d %d", year, day)
+	}
+	var buf bytes.Buffer
+	if err := g.Generate(&buf, rand.New(rand.NewSource(seed)), max(size, 1)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // RegisterPlotter makes a plotter known to the glue code as the nominated plotter of the given day.
 func RegisterPlotter(year, day int, suffix string, p Plotter, examples map[string]string) {
 	yd := YearDaySuffix{YearDay{year, day}, suffix}
@@ -81,13 +206,70 @@ func RegisterPlotter(year, day int, suffix string, p Plotter, examples map[strin
 	plotters[yd] = plotterRecord{plotter: p, examples: examples}
 }
 
+// PanicError is the error returned by the Solve functions when a solver panics.
+type PanicError struct {
+	YearDay
+	Value any    // value passed to panic
+	Stack []byte // stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%d day %d: panic: %v\n\n%s", e.Year, e.Day, e.Value, e.Stack)
+}
+
+// solve calls a solver, converting a panic into a PanicError, so that one broken day will not
+// crash the whole program or test run.
+func solve(yd YearDay, s Solver, input io.Reader) (out []string, metrics []Metric, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, metrics, err = nil, nil, &PanicError{YearDay: yd, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	if ms, ok := s.(MeasuredSolver); ok {
+		return ms.SolveMeasured(input)
+	}
+	out, err = s.Solve(input)
+	return out, nil, err
+}
+
 // Solve solves the given AoC puzzle using the provided input.
 func Solve(year, day int, input io.Reader) ([]string, error) {
 	s, ok := solvers[YearDay{year, day}]
 	if !ok {
 		return nil, fmt.Errorf("unknown day: %d %d", year, day)
 	}
-	return s.Solve(input)
+	out, _, err := solve(YearDay{year, day}, s, input)
+	return out, err
+}
+
+// SolveImpl solves the given AoC puzzle using the named implementation. If the solver is a
+// MeasuredSolver, its metrics are also returned.
+func SolveImpl(impl string, year, day int, input io.Reader) ([]string, []Metric, error) {
+	s, ok := impls[impl][YearDay{year, day}]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown %s day: %d %d", impl, year, day)
+	}
+	return solve(YearDay{year, day}, s, input)
+}
+
+// SolveVariant solves the given AoC puzzle using the named variant of the solver.
+func SolveVariant(year, day int, variant string, input io.Reader) ([]string, error) {
+	s, ok := variants[YearDay{year, day}][variant]
+	if !ok {
+		return nil, fmt.Errorf("unknown variant of day %d %d: %s", year, day, variant)
+	}
+	out, _, err := solve(YearDay{year, day}, s, input)
+	return out, err
+}
+
+// solveSelected solves the given AoC puzzle using the named variant of the solver if one is
+// specified, and otherwise the named implementation.
+func solveSelected(impl, variant string, year, day int, input io.Reader) ([]string, []Metric, error) {
+	if variant != "" {
+		out, err := SolveVariant(year, day, variant, input)
+		return out, nil, err
+	}
+	return SolveImpl(impl, year, day, input)
 }
 
 // SolveFile calls Solve on an input file.
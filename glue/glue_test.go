@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSolvePanic(t *testing.T) {
+	const year, day = 1, 1
+	RegisterSolver(year, day, GenericSolver(func(io.Reader) ([]string, error) {
+		panic("access denied")
+	}))
+	defer delete(solvers, YearDay{year, day})
+
+	_, err := Solve(year, day, strings.NewReader(""))
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Solve: got error %v, want a PanicError", err)
+	}
+	if pe.Year != year || pe.Day != day || pe.Value != "access denied" {
+		t.Errorf("Solve: got PanicError{%d, %d, %v}, want {%d, %d, access denied}", pe.Year, pe.Day, pe.Value, year, day)
+	}
+	if !bytes.Contains(pe.Stack, []byte("TestSolvePanic")) {
+		t.Errorf("Solve: stack trace does not mention the panicking function:\n%s", pe.Stack)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+)
+
+type listCmd struct {
+	tags     string
+	uses     string
+	specific bool
+	general  bool
+	long     bool
+	year     int
+	day      int
+	imports  map[YearDay][]string // packages used by each solution, if -uses or -l is set
+}
+
+func (*listCmd) Name() string {
+	return "list"
+}
+
+func (*listCmd) Synopsis() string {
+	return "List the AoC puzzles in the catalogue."
+}
+
+func (*listCmd) Usage() string {
+	out := strings.Builder{}
+	out.WriteString(`list [-tag=t1,t2,...] [-uses=pkg] [-input-specific | -general] [-l] [year [day]]:
+
+  List the puzzles in the catalogue, with their titles and tags. The list can
+  be narrowed down to a year or a single day, and filtered by the flags; all
+  of the given filters must match.
+
+  The -tag flag selects the puzzles that have all the listed tags, and -uses
+  the ones whose solution imports the given package of this module, such as
+  "util/regvm". The -input-specific flag selects the solutions that rely on
+  properties of the actual puzzle inputs, and -general the rest. The -l flag
+  adds the packages each solution uses to the list.
+
+  The packages are found with "go list", so -uses and -l only work within
+  the source tree of the module, with the go command in the path.
+
+  For example, "aoc list -tag=intcode" lists all the Intcode days, and
+  "aoc list -input-specific" all the solutions that may not work on inputs
+  other than the real ones.
+
+  Available tags:
+`)
+	tags := make([]string, 0, len(PuzzleTags))
+	for tag := range PuzzleTags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	for _, tag := range tags {
+		fmt.Fprintf(&out, "    %-14s %s\n", tag, PuzzleTags[tag])
+	}
+	return out.String()
+}
+
+func (c *listCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.tags, "tag", "", "comma-separated `tags` the puzzles must all have")
+	f.StringVar(&c.uses, "uses", "", "`package` the solutions must use, relative to the module")
+	f.BoolVar(&c.specific, "input-specific", false, "only list solutions specific to the actual inputs")
+	f.BoolVar(&c.general, "general", false, "only list solutions not specific to the actual inputs")
+	f.BoolVar(&c.long, "l", false, "also list the packages the solutions use")
+}
+
+func (c *listCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if f.NArg() > 2 || (c.specific && c.general) {
+		fmt.Fprintf(os.Stderr, "usage: list [-tag=t1,t2,...] [-uses=pkg] [-input-specific | -general] [-l] [year [day]]\n")
+		return subcommands.ExitFailure
+	}
+	if f.NArg() > 0 {
+		dayArg := "0"
+		if f.NArg() > 1 {
+			dayArg = f.Arg(1)
+		}
+		var (
+			suffix string
+			err    error
+		)
+		c.year, c.day, suffix, err = parseDay(f.Arg(0), dayArg)
+		if err == nil && suffix != "" {
+			err = fmt.Errorf("not a day: %s", dayArg)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+	}
+	for _, tag := range c.tagList() {
+		if _, ok := PuzzleTags[tag]; !ok {
+			fmt.Fprintf(os.Stderr, "unknown tag: %s (see 'aoc help list')\n", tag)
+			return subcommands.ExitFailure
+		}
+	}
+	if c.uses != "" || c.long {
+		imports, err := solutionImports()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "finding the packages used: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		c.imports = imports
+	}
+
+	if c.list(os.Stdout) == 0 {
+		fmt.Fprintln(os.Stderr, "no matching puzzles")
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+func (c *listCmd) tagList() []string {
+	if c.tags == "" {
+		return nil
+	}
+	return strings.Split(c.tags, ",")
+}
+
+// list writes the matching puzzles to w, and returns their count. Every row has the same cells,
+// even if some are empty, so that the columns line up across the whole list.
+func (c *listCmd) list(w io.Writer) (count int) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	for _, yd := range Catalogue() {
+		p := puzzles[yd]
+		if !c.match(yd, p) {
+			continue
+		}
+		count++
+		specific := ""
+		if p.InputSpecific {
+			specific = "input-specific"
+		}
+		fields := []string{fmt.Sprintf("%d.%02d", yd.Year, yd.Day), p.Title, strings.Join(p.Tags, " "), specific}
+		if c.long {
+			fields = append(fields, strings.Join(c.imports[yd], " "))
+		}
+		fmt.Fprintln(tw, strings.Join(fields, "\t"))
+	}
+	tw.Flush()
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line != "" {
+			fmt.Fprintln(w, strings.TrimRight(line, " \n"))
+		}
+	}
+	return count
+}
+
+// match tests if the puzzle of the given day is selected by the command line.
+func (c *listCmd) match(yd YearDay, p Puzzle) bool {
+	if (c.year != 0 && yd.Year != c.year) || (c.day != 0 && yd.Day != c.day) {
+		return false
+	}
+	for _, tag := range c.tagList() {
+		if !p.HasTag(tag) {
+			return false
+		}
+	}
+	if c.uses != "" && !slices.Contains(c.imports[yd], c.uses) {
+		return false
+	}
+	return !(c.specific && !p.InputSpecific) && !(c.general && p.InputSpecific)
+}
+
+// solutionImports finds the packages of this module (other than glue) imported directly by the
+// package of each day, as paths relative to the module, like "util/fn" or "2019/intcode". The
+// packages are listed with `go list`, which must be run within the module.
+func solutionImports() (map[YearDay][]string, error) {
+	module := strings.TrimSuffix(reflect.TypeOf(YearDay{}).PkgPath(), "/glue")
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}{{range .Imports}} {{.}}{{end}}", module+"/...")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	imports := make(map[YearDay][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		pkgs := strings.Fields(line)
+		if len(pkgs) == 0 {
+			continue
+		}
+		m := reDayPackage.FindStringSubmatch(strings.TrimPrefix(pkgs[0], module+"/"))
+		if m == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		var uses []string
+		for _, pkg := range pkgs[1:] {
+			if rel, ok := strings.CutPrefix(pkg, module+"/"); ok && rel != "glue" {
+				uses = append(uses, rel)
+			}
+		}
+		imports[YearDay{year, day}] = uses
+	}
+	return imports, nil
+}
+
+var reDayPackage = regexp.MustCompile(`^(\d{4})/day(\d\d)$`)
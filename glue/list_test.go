@@ -0,0 +1,92 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestList(t *testing.T) {
+	entries := map[YearDay]Puzzle{
+		{1, 1}: {Title: "First", Tags: []string{"grid", "simulation"}},
+		{1, 2}: {Title: "Second Puzzle", Tags: []string{"intcode"}, InputSpecific: true},
+		{2, 1}: {Title: "Third", Tags: []string{"grid"}},
+		{2, 2}: {Title: "Untagged"},
+	}
+	imports := map[YearDay][]string{{1, 1}: {"util"}, {1, 2}: {"2019/intcode"}}
+	for yd, p := range entries {
+		RegisterPuzzle(yd.Year, yd.Day, p)
+		defer delete(puzzles, yd)
+	}
+
+	tests := []struct {
+		cmd  listCmd
+		want []string
+	}{
+		{cmd: listCmd{}, want: []string{
+			"1.01  First          grid simulation",
+			"1.02  Second Puzzle  intcode          input-specific",
+			"2.01  Third          grid",
+			"2.02  Untagged",
+		}},
+		{cmd: listCmd{year: 2}, want: []string{"2.01  Third     grid", "2.02  Untagged"}},
+		{cmd: listCmd{tags: "grid"}, want: []string{"1.01  First  grid simulation", "2.01  Third  grid"}},
+		{cmd: listCmd{tags: "grid,simulation"}, want: []string{"1.01  First  grid simulation"}},
+		{cmd: listCmd{uses: "2019/intcode", imports: imports}, want: []string{"1.02  Second Puzzle  intcode  input-specific"}},
+		{cmd: listCmd{specific: true}, want: []string{"1.02  Second Puzzle  intcode  input-specific"}},
+		{cmd: listCmd{general: true, year: 1}, want: []string{"1.01  First  grid simulation"}},
+		{cmd: listCmd{year: 1, day: 2, long: true, imports: imports}, want: []string{"1.02  Second Puzzle  intcode  input-specific  2019/intcode"}},
+		{cmd: listCmd{tags: "dp"}, want: nil},
+	}
+	for _, test := range tests {
+		var out strings.Builder
+		count := test.cmd.list(&out)
+		got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if count == 0 {
+			got = nil
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" || count != len(test.want) {
+			t.Errorf("list(%+v) = %d puzzles, mismatch (-want +got):\n%s", test.cmd, count, diff)
+		}
+	}
+}
+
+func TestRegisterPuzzleUnknownTag(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterPuzzle with an unknown tag did not panic")
+		}
+	}()
+	RegisterPuzzle(1, 3, Puzzle{Title: "Bad", Tags: []string{"no-such-tag"}})
+	delete(puzzles, YearDay{1, 3})
+}
+
+func TestSolutionImports(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	imports, err := solutionImports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := imports[YearDay{2019, 25}]; !slices.Contains(got, "2019/intcode") || slices.Contains(got, "glue") {
+		t.Errorf("solutionImports()[2019.25] = %q, want it to contain 2019/intcode but not glue", got)
+	}
+}
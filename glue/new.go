@@ -0,0 +1,492 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/subcommands"
+)
+
+type newCmd struct {
+	kind  string
+	title string
+	root  string
+}
+
+func (*newCmd) Name() string {
+	return "new"
+}
+
+func (*newCmd) Synopsis() string {
+	return "Create the skeleton of a new AoC day."
+}
+
+func (*newCmd) Usage() string {
+	kinds := make([]string, 0, len(dayKinds))
+	for k := range dayKinds {
+		kinds = append(kinds, k)
+	}
+	slices.Sort(kinds)
+	return `new [-kind=name] [-title=text] [-root=dir] <year> <day>:
+
+  Create the files for a new AoC day: "YYYY/dayDD/dayDD.go", with a solve
+  function registered using the solver wrapper selected by -kind, and a test
+  for it in "YYYY/dayDD/dayDD_test.go". The catalogue entry of the day (see
+  'aoc help list') gets the -title (by default just "Day N"), and the tags
+  and packages implied by the kind; the rest is up to you.
+
+  The command also brings the aggregators up to date: every dayDD directory
+  of the year is imported in "YYYY/days.go" (created along with its tests if
+  the year is new), and every year is imported in "cmd/aoc/aoc.go".
+
+  Existing files are never overwritten, and the aggregators only get the
+  missing imports added, so it is safe to run the command again, or on a day
+  that already exists, just to fix up the imports.
+
+  Available kinds: ` + strings.Join(kinds, " ") + `
+
+`
+}
+
+func (c *newCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.kind, "kind", "lines", "type of solver wrapper to use")
+	f.StringVar(&c.title, "title", "", "title of the puzzle, for the catalogue")
+	f.StringVar(&c.root, "root", ".", "root directory of the repository")
+}
+
+func (c *newCmd) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if f.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: new [-kind=name] [-title=text] [-root=dir] <year> <day>\n")
+		return subcommands.ExitFailure
+	}
+	year, day, suffix, err := parseDay(f.Arg(0), f.Arg(1))
+	if err == nil && (suffix != "" || day < 1 || day > 25) {
+		err = fmt.Errorf("not a puzzle day: %s", f.Arg(1))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+
+	changed, err := newDay(c.root, year, day, c.kind, c.title, time.Now().Year())
+	for _, path := range changed {
+		fmt.Println(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+	if len(changed) == 0 {
+		fmt.Fprintln(os.Stderr, "all files already up to date")
+	}
+	return subcommands.ExitSuccess
+}
+
+// dayKind describes how the generated solver of a day is wrapped for the glue.
+type dayKind struct {
+	imports []string // packages in addition to glue, relative to the module
+	tags    []string // catalogue tags implied by the kind
+	wrapper string   // expression to register, with %s for the solve function
+	decls   string   // declarations to add before the solve function
+	solve   string   // the solve function
+}
+
+var dayKinds = map[string]dayKind{
+	"lines": {
+		wrapper: "glue.LineSolver(%s)",
+		solve: `func solve(lines []string) ([]string, error) {
+	p1, p2 := 0, 0
+	return glue.Ints(p1, p2), nil
+}`,
+	},
+	"chunks": {
+		wrapper: "glue.ChunkSolver(%s)",
+		solve: `func solve(chunks []string) ([]string, error) {
+	p1, p2 := 0, 0
+	return glue.Ints(p1, p2), nil
+}`,
+	},
+	"ints": {
+		wrapper: "glue.IntSolver(%s)",
+		solve: `func solve(input []int) ([]string, error) {
+	p1, p2 := 0, 0
+	return glue.Ints(p1, p2), nil
+}`,
+	},
+	"level": {
+		imports: []string{"util"},
+		tags:    []string{"grid"},
+		wrapper: "glue.LevelSolver{Solver: %s, Empty: '.'}",
+		solve: `func solve(l *util.Level) ([]string, error) {
+	p1, p2 := 0, 0
+	return glue.Ints(p1, p2), nil
+}`,
+	},
+	"regexp": {
+		wrapper: "glue.RegexpSolver{Solver: %s, Regexp: inputRegexp}",
+		decls:   "const inputRegexp = `^(.*)$`",
+		solve: `func solve(lines [][]string) ([]string, error) {
+	p1, p2 := 0, 0
+	return glue.Ints(p1, p2), nil
+}`,
+	},
+	"intcode": {
+		imports: []string{"2019/intcode"},
+		tags:    []string{"intcode"},
+		wrapper: "intcode.Solver(%s)",
+		solve: `func solve(prog []int64) ([]int64, error) {
+	p1, p2 := int64(0), int64(0)
+	return []int64{p1, p2}, nil
+}`,
+	},
+}
+
+// newData is the data available to the templates of the generated files.
+type newData struct {
+	Copyright int
+	Module    string
+	Year, Day int
+	Imports   []string
+	Puzzle    string
+	Register  string
+	Decls     string
+	Solve     string
+}
+
+var newTemplates = template.Must(template.New("").Parse(`
+{{- define "header" -}}
+// Copyright {{.Copyright}} Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+{{end}}
+
+{{- define "day" -}}
+{{template "header" .}}
+// Package day{{printf "%02d" .Day}} solves AoC {{.Year}} day {{.Day}}.
+package day{{printf "%02d" .Day}}
+
+{{if eq (len .Imports) 1 -}}
+import "{{.Module}}/{{index .Imports 0}}"
+{{- else -}}
+import (
+{{- range .Imports}}
+	"{{$.Module}}/{{.}}"
+{{- end}}
+)
+{{- end}}
+
+func init() {
+	glue.RegisterPuzzle({{.Year}}, {{.Day}}, {{.Puzzle}})
+	glue.RegisterSolver({{.Year}}, {{.Day}}, {{.Register}})
+}
+{{if .Decls}}
+{{.Decls}}
+{{end}}
+{{.Solve}}
+{{end}}
+
+{{- define "dayTest" -}}
+{{template "header" .}}
+package day{{printf "%02d" .Day}}
+
+import (
+	"strings"
+	"testing"
+
+	"{{.Module}}/glue"
+	"github.com/google/go-cmp/cmp"
+)
+
+const ex = ` + "``" + `
+
+func TestSolve(t *testing.T) {
+	if ex == "" {
+		t.Skip("no example yet")
+	}
+	want := []string{"0", "0"}
+	got, err := glue.Solve({{.Year}}, {{.Day}}, strings.NewReader(ex))
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Solve(ex) mismatch (-want +got):\n%s", diff)
+	}
+}
+{{end}}
+
+{{- define "days" -}}
+{{template "header" .}}
+// Package y{{.Year}} contains the glue and tests for all AoC {{.Year}} days.
+package y{{.Year}}
+
+import (
+{{- range .Imports}}
+	_ "{{$.Module}}/{{.}}" // solvers
+{{- end}}
+)
+{{end}}
+
+{{- define "daysTest" -}}
+{{template "header" .}}
+package y{{.Year}}
+
+import (
+	"testing"
+
+	"{{.Module}}/glue"
+)
+
+func TestAllDays(t *testing.T) {
+	glue.RunTests(t, "../testdata", {{.Year}})
+}
+
+func TestVariants(t *testing.T) {
+	glue.RunVariantTests(t, "../testdata", {{.Year}})
+}
+
+func TestGenerated(t *testing.T) {
+	glue.RunGeneratedTests(t, {{.Year}})
+}
+
+func TestCatalogue(t *testing.T) {
+	glue.RunCatalogueTests(t, {{.Year}})
+}
+
+func BenchmarkAllDays(b *testing.B) {
+	glue.RunBenchmarks(b, "../testdata", {{.Year}})
+}
+{{end}}
+`))
+
+var (
+	reModule       = regexp.MustCompile(`(?m)^module\s+(\S+)\s*$`)
+	reSolverImport = regexp.MustCompile(`^\t_ "([^"]+)" // solvers$`)
+	reDayDir       = regexp.MustCompile(`^day\d\d$`)
+)
+
+// newDay creates the files of a new day under the repository root, if they don't exist yet, and
+// adds any missing imports to the aggregators. It returns the paths of all files it changed.
+func newDay(root string, year, day int, kind, title string, copyright int) (changed []string, err error) {
+	k, ok := dayKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind: %s", kind)
+	}
+	gomod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("not at the root of the repository: %w", err)
+	}
+	m := reModule.FindSubmatch(gomod)
+	if m == nil {
+		return nil, errors.New("go.mod: module path not found")
+	}
+	module := string(m[1])
+	if title == "" {
+		title = fmt.Sprintf("Day %d", day)
+	}
+
+	data := newData{
+		Copyright: copyright,
+		Module:    module,
+		Year:      year,
+		Day:       day,
+		Imports:   append([]string{"glue"}, k.imports...),
+		Puzzle:    puzzleLiteral(title, k.tags),
+		Register:  fmt.Sprintf(k.wrapper, "solve"),
+		Decls:     k.decls,
+		Solve:     k.solve,
+	}
+	yearDir := filepath.Join(root, strconv.Itoa(year))
+	dayDir := filepath.Join(yearDir, fmt.Sprintf("day%02d", day))
+	if err := os.MkdirAll(dayDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	days, err := findDirs(yearDir, reDayDir, "")
+	if err != nil {
+		return nil, err
+	}
+	yearData := data
+	yearData.Imports = nil
+	for _, d := range days {
+		yearData.Imports = append(yearData.Imports, fmt.Sprintf("%d/%s", year, d))
+	}
+
+	files := []struct {
+		path, tmpl string
+		data       newData
+	}{
+		{filepath.Join(dayDir, fmt.Sprintf("day%02d.go", day)), "day", data},
+		{filepath.Join(dayDir, fmt.Sprintf("day%02d_test.go", day)), "dayTest", data},
+		{filepath.Join(yearDir, "days.go"), "days", yearData},
+		{filepath.Join(yearDir, "days_test.go"), "daysTest", yearData},
+	}
+	for _, f := range files {
+		created, err := createFile(f.path, f.tmpl, f.data)
+		if err != nil {
+			return changed, err
+		}
+		if created {
+			changed = append(changed, f.path)
+		}
+	}
+
+	if err := syncImports(filepath.Join(yearDir, "days.go"), module+"/"+strconv.Itoa(year)+"/", days, &changed); err != nil {
+		return changed, err
+	}
+	years, err := findDirs(root, reYearDir, "days.go")
+	if err != nil {
+		return changed, err
+	}
+	if err := syncImports(filepath.Join(root, "cmd", "aoc", "aoc.go"), module+"/", years, &changed); err != nil {
+		return changed, err
+	}
+	return changed, nil
+}
+
+// puzzleLiteral formats a glue.Puzzle composite literal for the generated code.
+func puzzleLiteral(title string, tags []string) string {
+	quote := func(list []string) string {
+		q := make([]string, len(list))
+		for i, s := range list {
+			q[i] = strconv.Quote(s)
+		}
+		return strings.Join(q, ", ")
+	}
+	lit := fmt.Sprintf("glue.Puzzle{\n\t\tTitle: %q,\n", title)
+	if len(tags) > 0 {
+		lit += fmt.Sprintf("\t\tTags: []string{%s},\n", quote(tags))
+	}
+	return lit + "\t}"
+}
+
+// createFile writes the named template to path, formatted as Go source, unless the file already
+// exists. It reports whether the file was created.
+func createFile(path, tmpl string, data newData) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := newTemplates.ExecuteTemplate(&buf, tmpl, data); err != nil {
+		return false, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// findDirs returns the sorted names of the subdirectories of dir that match re, and (if not empty)
+// contain the file named marker.
+func findDirs(dir string, re *regexp.Regexp, marker string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || !re.MatchString(e.Name()) {
+			continue
+		}
+		if marker != "" {
+			if _, err := os.Stat(filepath.Join(dir, e.Name(), marker)); err != nil {
+				continue
+			}
+		}
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// syncImports makes sure the Go source file at path has a blank solver import of prefix+name for
+// each of the listed names. The solver imports must form a contiguous block in the file, which is
+// kept sorted. If the file was modified, its path is appended to changed.
+func syncImports(path, prefix string, names []string, changed *[]string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(src), "\n")
+	first, last := -1, -1
+	imports := make(map[string]struct{})
+	for i, line := range lines {
+		m := reSolverImport.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if first >= 0 && last != i-1 {
+			return fmt.Errorf("%s: solver imports are not in a single block", path)
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		imports[m[1]] = struct{}{}
+	}
+	if first < 0 {
+		return fmt.Errorf("%s: no solver imports found", path)
+	}
+	added := false
+	for _, name := range names {
+		if _, ok := imports[prefix+name]; !ok {
+			imports[prefix+name] = struct{}{}
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+	block := make([]string, 0, len(imports))
+	for imp := range imports {
+		block = append(block, fmt.Sprintf("\t_ %q // solvers", imp))
+	}
+	slices.Sort(block)
+	lines = slices.Replace(lines, first, last+1, block...)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return err
+	}
+	*changed = append(*changed, path)
+	return nil
+}
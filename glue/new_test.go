@@ -0,0 +1,123 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testAocGo = `package main
+
+import (
+	"github.com/fis/aoc/glue"
+
+	_ "github.com/fis/aoc/2020" // solvers
+
+	_ "github.com/fis/aoc/z80/2022" // Z80 solutions
+)
+
+func main() {
+	glue.Main()
+}
+`
+
+func TestNewDay(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module github.com/fis/aoc\n",
+		"cmd/aoc/aoc.go": testAocGo,
+		"2020/days.go":   "package y2020\n\nimport (\n\t_ \"github.com/fis/aoc/2020/day01\" // solvers\n)\n",
+	}
+	for file, data := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a day that exists but was never added to the aggregator
+	if err := os.MkdirAll(filepath.Join(root, "2020", "day03"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		year, day   int
+		kind, title string
+		want        []string
+	}{
+		{2021, 5, "regexp", "Test", []string{"2021/day05/day05.go", "2021/day05/day05_test.go", "2021/days.go", "2021/days_test.go", "cmd/aoc/aoc.go"}},
+		{2021, 5, "regexp", "Test", nil},
+		{2021, 6, "intcode", "", []string{"2021/day06/day06.go", "2021/day06/day06_test.go", "2021/days.go"}},
+		{2020, 2, "lines", "Test", []string{"2020/day02/day02.go", "2020/day02/day02_test.go", "2020/days_test.go", "2020/days.go"}},
+		{2020, 2, "level", "Test", nil},
+	}
+	for _, test := range tests {
+		changed, err := newDay(root, test.year, test.day, test.kind, test.title, 2024)
+		if err != nil {
+			t.Fatalf("newDay(%d, %d, %s): %v", test.year, test.day, test.kind, err)
+		}
+		for i, path := range changed {
+			changed[i] = filepath.ToSlash(strings.TrimPrefix(path, root+string(filepath.Separator)))
+		}
+		if diff := cmp.Diff(test.want, changed); diff != "" {
+			t.Errorf("newDay(%d, %d, %s) changes mismatch (-want +got):\n%s", test.year, test.day, test.kind, diff)
+		}
+	}
+
+	for _, file := range []string{"2020/day02/day02.go", "2020/day02/day02_test.go", "2021/day05/day05.go", "2021/day06/day06.go", "2021/days.go", "2021/days_test.go"} {
+		if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(root, file), nil, parser.AllErrors); err != nil {
+			t.Errorf("generated file does not parse: %v", err)
+		}
+	}
+
+	for file, want := range map[string]string{"2021/day05/day05.go": `Title: "Test"`, "2021/day06/day06.go": `Title: "Day 6"`} {
+		if data, err := os.ReadFile(filepath.Join(root, file)); err != nil {
+			t.Error(err)
+		} else if !strings.Contains(string(data), want) {
+			t.Errorf("%s: missing %s:\n%s", file, want, data)
+		}
+	}
+
+	wantImports := map[string][]string{
+		"2020/days.go":   {"github.com/fis/aoc/2020/day01", "github.com/fis/aoc/2020/day02", "github.com/fis/aoc/2020/day03"},
+		"2021/days.go":   {"github.com/fis/aoc/2021/day05", "github.com/fis/aoc/2021/day06"},
+		"cmd/aoc/aoc.go": {"github.com/fis/aoc/2020", "github.com/fis/aoc/2021", "github.com/fis/aoc/glue", "github.com/fis/aoc/z80/2022"},
+	}
+	for file, want := range wantImports {
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(root, file), nil, parser.ImportsOnly)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		var got []string
+		for _, imp := range f.Imports {
+			got = append(got, strings.Trim(imp.Path.Value, `"`))
+		}
+		slices.Sort(got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%s imports mismatch (-want +got):\n%s", file, diff)
+		}
+	}
+}
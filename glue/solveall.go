@@ -0,0 +1,221 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fis/aoc/util"
+	"github.com/google/subcommands"
+)
+
+type corpusResult struct {
+	out     []string
+	err     error
+	elapsed time.Duration
+}
+
+// solveAll implements the `aoc solve all` and `aoc solve <year>` forms of the command.
+func (c *solveCmd) solveAll(arg string) subcommands.ExitStatus {
+	year := 0
+	if arg != "all" {
+		var err error
+		if year, err = strconv.Atoi(arg); err != nil {
+			fmt.Fprintf(os.Stderr, "not a year or \"all\": %s\n", arg)
+			return subcommands.ExitFailure
+		}
+	}
+	jobs, skipped, err := c.corpusJobs(year)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+	if len(jobs) == 0 {
+		fmt.Fprintf(os.Stderr, "no days with inputs found for: %s\n", arg)
+		return subcommands.ExitFailure
+	}
+
+	// The results are printed as soon as all the earlier ones are done, to keep the order fixed.
+	results, ready := make([]*corpusResult, len(jobs)), make([]bool, len(jobs))
+	done := make(chan int)
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(c.jobs, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = c.solveJob(jobs[i])
+				done <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range jobs {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+		close(done)
+	}()
+
+	status := subcommands.ExitSuccess
+	var failed []error
+	start, next := time.Now(), 0
+	fmt.Printf("%-7s %9s  %s\n", "day", "time", "answers")
+	for i := range done {
+		ready[i] = true
+		for next < len(jobs) && ready[next] {
+			job, r := jobs[next], results[next]
+			want := job.Want
+			if c.impl != DefaultImpl && len(r.out) > 0 && len(r.out) < len(want) {
+				want = want[:len(r.out)] // alternative implementations may only solve the first part
+			}
+			fmt.Printf("%-7s %9s  ", benchLabel(job), fmtDuration(r.elapsed))
+			switch {
+			case r.err != nil:
+				first, _, _ := strings.Cut(r.err.Error(), "\n")
+				fmt.Printf("ERROR: %s\n", first)
+				failed = append(failed, fmt.Errorf("%s: %w", benchLabel(job), r.err))
+				status = subcommands.ExitFailure
+			case want != nil && !slices.Equal(r.out, want):
+				fmt.Println(fmtWrong(r.out, want))
+				status = subcommands.ExitFailure
+			default:
+				fmt.Println(fmtAnswers(answerLines(r.out)))
+			}
+			next++
+		}
+	}
+	wall := time.Since(start)
+
+	var total time.Duration
+	for _, r := range results {
+		total += r.elapsed
+	}
+	fmt.Printf("\ntotal: %v for %d inputs (wall clock %v with -j=%d)\n", fmtDuration(total), len(jobs), fmtDuration(wall), c.jobs)
+	if skipped > 0 {
+		fmt.Printf("skipped %d days without an input\n", skipped)
+	}
+	if c.slowest > 0 {
+		order := make([]int, len(jobs))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(results[b].elapsed, results[a].elapsed) })
+		fmt.Println("slowest:")
+		for _, i := range order[:min(c.slowest, len(order))] {
+			fmt.Printf("  %-7s %9s  %4.1f%%\n", benchLabel(jobs[i]), fmtDuration(results[i].elapsed), 100*float64(results[i].elapsed)/float64(total))
+		}
+	}
+	for _, err := range failed {
+		fmt.Fprintf(os.Stderr, "\n%v\n", err)
+	}
+	return status
+}
+
+// corpusJobs lists the inputs of all the days selected by the flags in the given year (or all years
+// if zero). The expected answers are left empty if they are not known. The number of selected days
+// that have no inputs is also returned.
+func (c *solveCmd) corpusJobs(year int) (jobs []TestCase, skipped int, err error) {
+	var days []YearDay
+	if c.variant != "" {
+		for yd := range variants {
+			if _, ok := variants[yd][c.variant]; ok {
+				days = append(days, yd)
+			}
+		}
+	} else {
+		for yd := range impls[c.impl] {
+			days = append(days, yd)
+		}
+	}
+	days = slices.DeleteFunc(days, func(yd YearDay) bool { return year != 0 && yd.Year != year })
+	slices.SortFunc(days, func(a, b YearDay) int {
+		if a.Year != b.Year {
+			return cmp.Compare(a.Year, b.Year)
+		}
+		return cmp.Compare(a.Day, b.Day)
+	})
+
+	for _, yd := range days {
+		basePath := fmt.Sprintf("testdata/%04d/day%02d", yd.Year, yd.Day)
+		inputs, err := findInputs(basePath, ".txt")
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(inputs) == 0 {
+			skipped++
+		}
+		for _, in := range inputs {
+			want, err := util.ReadLines(in.path + ".out")
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, 0, err
+			}
+			jobs = append(jobs, TestCase{Year: yd.Year, Day: yd.Day, Name: in.name, InputFile: in.path + ".txt", Want: want})
+		}
+	}
+	return jobs, skipped, nil
+}
+
+func (c *solveCmd) solveJob(job TestCase) *corpusResult {
+	input, err := os.ReadFile(job.InputFile)
+	if err != nil {
+		return &corpusResult{err: err}
+	}
+	start := time.Now()
+	out, _, err := solveSelected(c.impl, c.variant, job.Year, job.Day, bytes.NewReader(input))
+	return &corpusResult{out: out, err: err, elapsed: time.Since(start)}
+}
+
+// answersIndent lines up the continuation lines of multi-line answers with the answers column.
+var answersIndent = strings.Repeat(" ", len(fmt.Sprintf("%-7s %9s  ", "", "")))
+
+// answerLines returns the lines to print for the answers to a day. The usual one or two answers
+// share a single line, but longer outputs (such as the pictures some puzzles draw) are kept on
+// their own lines.
+func answerLines(out []string) []string {
+	if len(out) <= 2 {
+		return []string{strings.Join(out, " ")}
+	}
+	return out
+}
+
+// fmtAnswers joins the lines of the answers, indenting all but the first to the answers column.
+func fmtAnswers(lines []string) string {
+	return strings.Join(lines, "\n"+answersIndent)
+}
+
+// fmtWrong formats a wrong set of answers followed by the expected ones.
+func fmtWrong(out, want []string) string {
+	got, exp := slices.Clone(answerLines(out)), answerLines(want)
+	got[len(got)-1] += "  WRONG, want:"
+	if len(exp) == 1 {
+		got[len(got)-1] += " " + exp[0]
+	} else {
+		got = append(got, exp...)
+	}
+	return fmtAnswers(got)
+}
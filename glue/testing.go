@@ -15,26 +15,50 @@
 package glue
 
 import (
+	"bytes"
 	"errors"
+	"flag"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/fis/aoc/util"
 	"github.com/google/go-cmp/cmp"
 )
 
+func init() {
+	if testing.Testing() {
+		flag.Func("diag", "enable diagnostics for the comma-separated `scopes`, like -diag=2019/18,intcode", util.SetDiagFilter)
+	}
+}
+
+// captureDiag redirects all diagnostic output to the log of a test, until the returned function is
+// called. Diagnostics are only enabled in tests by the -diag flag.
+func captureDiag(t *testing.T) (restore func()) {
+	return util.SetDiagSink(func(scope, line string) {
+		t.Logf("[%s] %s", scope, line)
+	})
+}
+
+// RunTests checks that all the days of a year produce the expected answers for the test inputs.
+// Diagnostic output of each input is captured in the log of its subtest.
 func RunTests(t *testing.T, testRoot string, year int) {
 	tests, err := FindTests(testRoot, year)
 	if err != nil {
 		t.Fatal(err)
 	}
 	for _, test := range tests {
-		t.Run(fmt.Sprintf("day=%04d.%02d", year, test.Day), func(t *testing.T) {
+		t.Run(test.name(), func(t *testing.T) {
+			defer captureDiag(t)()
 			if got, err := SolveFile(year, test.Day, test.InputFile); err != nil {
 				t.Errorf("Solve: %v", err)
 			} else if diff := cmp.Diff(test.Want, got); diff != "" {
@@ -44,13 +68,197 @@ func RunTests(t *testing.T, testRoot string, year int) {
 	}
 }
 
+// RunVariantTests checks that all the registered variants of the days of a year produce the
+// expected answers for the test inputs, and therefore agree with each other.
+func RunVariantTests(t *testing.T, testRoot string, year int) {
+	tests, err := FindTests(testRoot, year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		for _, variant := range Variants(year, test.Day) {
+			test, variant := test, variant
+			t.Run(test.name()+"/variant="+variant, func(t *testing.T) {
+				defer captureDiag(t)()
+				f, err := os.Open(test.InputFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				if got, err := SolveVariant(year, test.Day, variant, f); err != nil {
+					t.Errorf("Solve: %v", err)
+				} else if diff := cmp.Diff(test.Want, got); diff != "" {
+					t.Errorf("Solve mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+// RunGeneratedTests solves random inputs for all the days of a year that have a generator. The
+// default solver must succeed on each, and every variant of the day must agree with it.
+func RunGeneratedTests(t *testing.T, year int) {
+	var days []int
+	for yd := range generators {
+		if yd.Year == year {
+			days = append(days, yd.Day)
+		}
+	}
+	slices.Sort(days)
+	for _, day := range days {
+		for _, size := range generatedTestSizes {
+			for seed := int64(1); seed <= generatedTestSeeds; seed++ {
+				t.Run(fmt.Sprintf("day=%04d.%02d/size=%d/seed=%d", year, day, size, seed), func(t *testing.T) {
+					input, err := Generate(year, day, seed, size)
+					if err != nil {
+						t.Fatalf("Generate: %v", err)
+					}
+					want, err := Solve(year, day, bytes.NewReader(input))
+					if err != nil {
+						t.Fatalf("Solve: %v\ninput:\n%s", err, input)
+					}
+					for _, variant := range Variants(year, day) {
+						if got, err := SolveVariant(year, day, variant, bytes.NewReader(input)); err != nil {
+							t.Errorf("Solve[%s]: %v\ninput:\n%s", variant, err, input)
+						} else if diff := cmp.Diff(want, got); diff != "" {
+							t.Errorf("Solve[%s] mismatch (-default +variant):\n%s\ninput:\n%s", variant, diff, input)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+var (
+	generatedTestSizes = []int{1, 2, 5, 10, 20}
+	generatedTestSeeds = int64(5)
+)
+
+// RunCatalogueTests checks the catalogue entries of the days of a year. Every day with a solver must
+// have one, and the intcode tag must match whether the package of the day imports the Intcode
+// package. The package is expected to be in the "dayDD" directory under the current directory.
+func RunCatalogueTests(t *testing.T, year int) {
+	module := strings.TrimSuffix(reflect.TypeOf(YearDay{}).PkgPath(), "/glue")
+	for day := 1; day <= 25; day++ {
+		_, solved := solvers[YearDay{year, day}]
+		p, listed := LookupPuzzle(year, day)
+		if !solved && !listed {
+			continue
+		}
+		t.Run(fmt.Sprintf("day=%04d.%02d", year, day), func(t *testing.T) {
+			if !solved {
+				t.Fatal("catalogue entry for a day without a solver")
+			} else if !listed {
+				t.Fatal("no catalogue entry")
+			}
+			if p.Title == "" {
+				t.Error("no title")
+			}
+			imports, err := packageImports(fmt.Sprintf("day%02d", day), module)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if intcode := slices.Contains(imports, "2019/intcode"); p.HasTag("intcode") != intcode {
+				t.Errorf("intcode tag is %t, but using the Intcode package is %t", p.HasTag("intcode"), intcode)
+			}
+		})
+	}
+}
+
+// packageImports returns the sorted list of the packages of the module imported by the non-test Go
+// files in dir, relative to the module path. The glue package is not included.
+func packageImports(dir, module string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var imports []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			if rel, ok := strings.CutPrefix(path, module+"/"); ok && rel != "glue" && !slices.Contains(imports, rel) {
+				imports = append(imports, rel)
+			}
+		}
+	}
+	slices.Sort(imports)
+	return imports, nil
+}
+
+// RunScaleBenchmarks benchmarks the default solver of a day on random inputs of each of the given
+// sizes, to see how it scales.
+func RunScaleBenchmarks(b *testing.B, year, day int, sizes ...int) {
+	for _, size := range sizes {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			input, err := Generate(year, day, 1, size)
+			if err != nil {
+				b.Fatalf("Generate: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := Solve(year, day, bytes.NewReader(input)); err != nil {
+					b.Fatalf("Solve: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// RunImplTests runs the tests of all days with a solver in the named alternative implementation,
+// across all years with test data. If an implementation only solves the first part of a puzzle,
+// output matching the beginning of the expected output is accepted.
+func RunImplTests(t *testing.T, testRoot, impl string) {
+	tests, err := FindAllTests(testRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		if _, ok := impls[impl][YearDay{test.Year, test.Day}]; !ok {
+			continue
+		}
+		test := test
+		t.Run(test.name(), func(t *testing.T) {
+			f, err := os.Open(test.InputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, metrics, err := SolveImpl(impl, test.Year, test.Day, f)
+			if err != nil {
+				t.Fatalf("Solve: %v", err)
+			}
+			for _, m := range metrics {
+				t.Logf("%s = %d", m.Name, m.Value)
+			}
+			want := test.Want
+			if len(got) > 0 && len(got) < len(want) {
+				want = want[:len(got)]
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Solve mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func RunBenchmarks(b *testing.B, testRoot string, year int) {
 	tests, err := FindTests(testRoot, year)
 	if err != nil {
 		b.Fatal(err)
 	}
 	for _, test := range tests {
-		b.Run(fmt.Sprintf("day=%04d.%02d", year, test.Day), func(b *testing.B) {
+		b.Run(test.name(), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				if got, err := SolveFile(year, test.Day, test.InputFile); err != nil {
 					b.Errorf("Solve: %v", err)
@@ -62,13 +270,25 @@ func RunBenchmarks(b *testing.B, testRoot string, year int) {
 	}
 }
 
+// TestCase is a puzzle input with known answers. Each day can have any number of inputs: the
+// default one is in the files "YYYY/dayDD.txt" and "YYYY/dayDD.out" under the test data root, and
+// the ones named NAME either in "YYYY/dayDD.NAME.{txt,out}" or "YYYY/dayDD/NAME.{txt,out}".
 type TestCase struct {
 	Year      int
 	Day       int
+	Name      string // name of the input; empty for the default one
 	InputFile string
 	Want      []string
 }
 
+// name returns the name for the subtest of the test case.
+func (tc TestCase) name() string {
+	if tc.Name == "" {
+		return fmt.Sprintf("day=%04d.%02d", tc.Year, tc.Day)
+	}
+	return fmt.Sprintf("day=%04d.%02d/input=%s", tc.Year, tc.Day, tc.Name)
+}
+
 var reYearDir = regexp.MustCompile(`^\d{4}$`)
 
 func FindAllTests(testRoot string) (tests []TestCase, err error) {
@@ -97,19 +317,84 @@ func FindAllTests(testRoot string) (tests []TestCase, err error) {
 func FindTests(testRoot string, year int) (tests []TestCase, err error) {
 	for day := 1; day <= 25; day++ {
 		basePath := fmt.Sprintf("%s/%04d/day%02d", testRoot, year, day)
-		want, err := util.ReadLines(basePath + ".out")
+		inputs, err := findInputs(basePath, ".out")
 		if err != nil {
-			if errors.Is(err, fs.ErrNotExist) {
-				continue
+			return nil, err
+		}
+		for _, in := range inputs {
+			want, err := util.ReadLines(in.path + ".out")
+			if err != nil {
+				return nil, fmt.Errorf("failed to read test output: %w", err)
 			}
-			return nil, fmt.Errorf("failed to read test output: %w", err)
+			tests = append(tests, TestCase{
+				Year:      year,
+				Day:       day,
+				Name:      in.name,
+				InputFile: in.path + ".txt",
+				Want:      want,
+			})
 		}
-		tests = append(tests, TestCase{
-			Year:      year,
-			Day:       day,
-			InputFile: basePath + ".txt",
-			Want:      want,
-		})
 	}
 	return tests, nil
 }
+
+// FindInput returns the path of the named input file of a day, in any of the layouts described
+// for TestCase. Unlike FindTests, it does not require the expected output to exist.
+func FindInput(testRoot string, year, day int, name string) (string, error) {
+	basePath := fmt.Sprintf("%s/%04d/day%02d", testRoot, year, day)
+	inputs, err := findInputs(basePath, ".txt")
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, in := range inputs {
+		if in.name == name {
+			return in.path + ".txt", nil
+		}
+		names = append(names, fmt.Sprintf("%q", in.name))
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no inputs for day %d %d", year, day)
+	}
+	return "", fmt.Errorf("no input named %q for day %d %d (have: %s)", name, year, day, strings.Join(names, ", "))
+}
+
+type namedInput struct {
+	name string
+	path string // without the extension
+}
+
+// findInputs lists all the inputs of a day that have a file with the given extension, sorted by
+// name. The default input, if it exists, comes first.
+func findInputs(basePath, ext string) (inputs []namedInput, err error) {
+	if _, err := os.Stat(basePath + ext); err == nil {
+		inputs = append(inputs, namedInput{name: "", path: basePath})
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var named []namedInput
+	files, err := filepath.Glob(basePath + ".*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		path := strings.TrimSuffix(file, ext)
+		named = append(named, namedInput{name: strings.TrimPrefix(path, basePath+"."), path: path})
+	}
+	items, err := os.ReadDir(basePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, item := range items {
+		if name, ok := strings.CutSuffix(item.Name(), ext); ok && !item.IsDir() {
+			named = append(named, namedInput{name: name, path: filepath.Join(basePath, name)})
+		}
+	}
+	slices.SortFunc(named, func(a, b namedInput) int { return strings.Compare(a.name, b.name) })
+	for i := 1; i < len(named); i++ {
+		if named[i].name == named[i-1].name {
+			return nil, fmt.Errorf("%s: input %q is defined twice", basePath, named[i].name)
+		}
+	}
+	return append(inputs, named...), nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindTests(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"2020/day01.txt", "2020/day01.out",
+		"2020/day01.alice.txt", "2020/day01.alice.out",
+		"2020/day01/bob.txt", "2020/day01/bob.out",
+		"2020/day01/carol.txt", // no expected output
+		"2020/day02.edge.txt", "2020/day02.edge.out",
+	}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests, err := FindTests(root, 2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, test := range tests {
+		rel, _ := filepath.Rel(root, test.InputFile)
+		got = append(got, test.name()+" "+rel+" "+test.Want[0])
+	}
+	want := []string{
+		"day=2020.01 2020/day01.txt 2020/day01.out",
+		"day=2020.01/input=alice 2020/day01.alice.txt 2020/day01.alice.out",
+		"day=2020.01/input=bob 2020/day01/bob.txt 2020/day01/bob.out",
+		"day=2020.02/input=edge 2020/day02.edge.txt 2020/day02.edge.out",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FindTests mismatch (-want +got):\n%s", diff)
+	}
+
+	if got, err := FindInput(root, 2020, 1, "carol"); err != nil || got != filepath.Join(root, "2020/day01/carol.txt") {
+		t.Errorf("FindInput(carol) = (%q, %v), want %q", got, err, "2020/day01/carol.txt")
+	}
+	if _, err := FindInput(root, 2020, 1, "dave"); err == nil {
+		t.Errorf("FindInput(dave) succeeded, want an error")
+	}
+}
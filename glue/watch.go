@@ -0,0 +1,249 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fis/aoc/util"
+	"github.com/google/subcommands"
+)
+
+const (
+	// watchDebounce is how long a burst of changes (like an editor saving a file) gets to settle.
+	watchDebounce = 100 * time.Millisecond
+	// watchPollInterval is how often the polling watcher checks for changes.
+	watchPollInterval = 500 * time.Millisecond
+)
+
+// watchResult is the outcome of solving one of the watched inputs.
+type watchResult struct {
+	name    string
+	out     []string
+	want    []string
+	metrics []Metric
+	err     error
+	elapsed time.Duration
+}
+
+// solveWatch implements the `aoc solve -watch` form of the command: it solves the input, and all the
+// examples, every time any of them change.
+func (c *solveCmd) solveWatch(year, day int, input string) subcommands.ExitStatus {
+	paths := []string{input}
+	if c.examples != "" {
+		paths = append(paths, c.examples)
+	}
+	w, err := newWatcher(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return subcommands.ExitFailure
+	}
+	defer w.close()
+
+	clear := isTerminal(os.Stdout)
+	prev := make(map[string][]string)
+	for {
+		inputs, err := c.watchInputs(input)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+		results := make([]watchResult, len(inputs))
+		for i, in := range inputs {
+			results[i] = c.watchSolve(year, day, in)
+		}
+		if clear {
+			fmt.Print("\x1b[H\x1b[2J")
+		} else {
+			fmt.Println()
+		}
+		fmt.Printf("%d day %d, last run at %s; watching for changes\n", year, day, time.Now().Format(time.TimeOnly))
+		renderWatch(os.Stdout, results, prev)
+		for _, r := range results {
+			if r.err == nil {
+				prev[r.name] = r.out
+			}
+		}
+		if err := w.wait(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return subcommands.ExitFailure
+		}
+	}
+}
+
+// watchInputs lists the files to solve: the input itself, and the examples (if any) in name order.
+// Expected answers (".out" files) in the examples directory are not inputs.
+func (c *solveCmd) watchInputs(input string) ([]string, error) {
+	inputs := []string{input}
+	if c.examples == "" {
+		return inputs, nil
+	}
+	entries, err := os.ReadDir(c.examples)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasSuffix(e.Name(), ".out") && !strings.HasPrefix(e.Name(), ".") {
+			inputs = append(inputs, filepath.Join(c.examples, e.Name()))
+		}
+	}
+	return inputs, nil
+}
+
+// watchSolve solves one of the watched inputs, along with the expected answers if the input has
+// a matching ".out" file.
+func (c *solveCmd) watchSolve(year, day int, path string) watchResult {
+	r := watchResult{name: path}
+	input, err := os.ReadFile(path)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.want, err = util.ReadLines(strings.TrimSuffix(path, filepath.Ext(path)) + ".out")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		r.err = err
+		return r
+	}
+	start := time.Now()
+	r.out, r.metrics, r.err = solveSelected(c.impl, c.variant, year, day, bytes.NewReader(input))
+	r.elapsed = time.Since(start)
+	return r
+}
+
+// renderWatch prints the results of one round of solving. Answers that differ from the previous
+// successful run of the same input (if any) are marked with the old value.
+func renderWatch(w io.Writer, results []watchResult, prev map[string][]string) {
+	for _, r := range results {
+		fmt.Fprintf(w, "\n%s  %s\n", r.name, fmtDuration(r.elapsed))
+		if r.err != nil {
+			fmt.Fprintf(w, "  ERROR: %v\n", r.err)
+			continue
+		}
+		old, seen := prev[r.name]
+		for i, line := range r.out {
+			var notes []string
+			switch {
+			case !seen:
+			case i >= len(old):
+				notes = append(notes, "new")
+			case line != old[i]:
+				notes = append(notes, "was: "+old[i])
+			}
+			if i < len(r.want) && line != r.want[i] {
+				notes = append(notes, "WRONG, want: "+r.want[i])
+			}
+			if len(notes) > 0 {
+				fmt.Fprintf(w, "  %s  (%s)\n", line, strings.Join(notes, "; "))
+			} else {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+		if seen && len(old) > len(r.out) {
+			fmt.Fprintf(w, "  (%d answers less than before)\n", len(old)-len(r.out))
+		}
+		for _, m := range r.metrics {
+			fmt.Fprintf(w, "  # %s = %d\n", m.Name, m.Value)
+		}
+	}
+}
+
+// A watcher waits for changes in a set of files and directories. For a file, any change to the
+// file counts, including creating it if it does not exist. For a directory, a change to any file
+// directly inside it counts.
+type watcher interface {
+	// wait blocks until there has been a change since the previous call (or since the watcher was
+	// created), and the changes have settled.
+	wait() error
+	close() error
+}
+
+// newWatcher returns a watcher for the given paths. It uses the native change notifications of the
+// platform where possible, and falls back to polling.
+func newWatcher(paths []string) (watcher, error) {
+	if w, err := newNativeWatcher(paths); err == nil {
+		return w, nil
+	}
+	return newPollWatcher(paths, watchPollInterval), nil
+}
+
+// pollWatcher is a watcher that periodically compares the size and modification time of the
+// watched files.
+type pollWatcher struct {
+	paths    []string
+	interval time.Duration
+	last     string
+}
+
+func newPollWatcher(paths []string, interval time.Duration) *pollWatcher {
+	w := &pollWatcher{paths: slices.Clone(paths), interval: interval}
+	w.last = w.snapshot()
+	return w
+}
+
+func (w *pollWatcher) wait() error {
+	for {
+		time.Sleep(w.interval)
+		if s := w.snapshot(); s != w.last {
+			for s != w.last {
+				w.last = s
+				time.Sleep(watchDebounce)
+				s = w.snapshot()
+			}
+			return nil
+		}
+	}
+}
+
+func (*pollWatcher) close() error {
+	return nil
+}
+
+// snapshot summarizes the state of the watched paths; any change will result in a different string.
+func (w *pollWatcher) snapshot() string {
+	var s strings.Builder
+	stat := func(path string, fi fs.FileInfo) {
+		fmt.Fprintf(&s, "%q %d %d\n", path, fi.Size(), fi.ModTime().UnixNano())
+	}
+	for _, path := range w.paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&s, "%q missing\n", path)
+			continue
+		}
+		stat(path, fi)
+		if !fi.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if fi, err := e.Info(); err == nil {
+				stat(filepath.Join(path, e.Name()), fi)
+			}
+		}
+	}
+	return s.String()
+}
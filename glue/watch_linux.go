@@ -0,0 +1,134 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package glue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// inotifyWatcher is a watcher using the Linux inotify API. Files are watched through their parent
+// directories, so that editors replacing the file with a new one are handled.
+type inotifyWatcher struct {
+	fd    int
+	dirs  map[int]string  // watched directories by watch descriptor
+	whole map[int]bool    // watch descriptors of directories watched as a whole
+	files map[string]bool // watched files
+	buf   []byte
+}
+
+func newNativeWatcher(paths []string) (watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	w := &inotifyWatcher{
+		fd:    fd,
+		dirs:  make(map[int]string),
+		whole: make(map[int]bool),
+		files: make(map[string]bool),
+		buf:   make([]byte, 64*1024),
+	}
+	for _, path := range paths {
+		dir, isDir := filepath.Dir(path), false
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			dir, isDir = path, true
+		} else {
+			w.files[filepath.Clean(path)] = true
+		}
+		wd, err := unix.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			unix.Close(fd)
+			return nil, &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+		}
+		w.dirs[wd] = filepath.Clean(dir)
+		if isDir {
+			w.whole[wd] = true
+		}
+	}
+	return w, nil
+}
+
+func (w *inotifyWatcher) wait() error {
+	for {
+		_, relevant, err := w.read(-1)
+		if err != nil {
+			return err
+		}
+		if relevant {
+			break
+		}
+	}
+	for {
+		got, _, err := w.read(int(watchDebounce.Milliseconds()))
+		if err != nil {
+			return err
+		}
+		if !got {
+			return nil
+		}
+	}
+}
+
+// read waits up to timeout milliseconds (or forever, if negative) for events, and consumes the
+// available ones. It reports whether there were any events, and if any of them were for the
+// watched paths.
+func (w *inotifyWatcher) read(timeout int) (got, relevant bool, err error) {
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, timeout)
+	if errors.Is(err, unix.EINTR) {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, os.NewSyscallError("poll", err)
+	} else if n == 0 {
+		return false, false, nil
+	}
+	n, err = unix.Read(w.fd, w.buf)
+	if errors.Is(err, unix.EAGAIN) {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, os.NewSyscallError("read", err)
+	}
+	for data := w.buf[:n]; len(data) >= unix.SizeofInotifyEvent; {
+		wd := int(int32(binary.NativeEndian.Uint32(data[0:])))
+		mask := binary.NativeEndian.Uint32(data[4:])
+		size := unix.SizeofInotifyEvent + int(binary.NativeEndian.Uint32(data[12:]))
+		name := string(bytes.TrimRight(data[unix.SizeofInotifyEvent:size], "\x00"))
+		data = data[size:]
+		if mask&unix.IN_Q_OVERFLOW != 0 || w.whole[wd] || w.files[filepath.Join(w.dirs[wd], name)] {
+			relevant = true
+		}
+	}
+	return true, relevant, nil
+}
+
+func (w *inotifyWatcher) close() error {
+	return unix.Close(w.fd)
+}
+
+// isTerminal tests if the file is a terminal, and can therefore be cleared for redrawing.
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRenderWatch(t *testing.T) {
+	results := []watchResult{
+		{name: "in.txt", out: []string{"1", "22", "3"}, elapsed: 1500 * time.Microsecond},
+		{name: "ex1.txt", out: []string{"4"}, want: []string{"5"}, elapsed: 20 * time.Microsecond},
+		{name: "ex2.txt", err: errors.New("bad input"), elapsed: 0},
+	}
+	prev := map[string][]string{"in.txt": {"1", "2"}, "ex2.txt": {"7"}}
+	want := strings.Join([]string{
+		"",
+		"in.txt  1.5ms",
+		"  1",
+		"  22  (was: 2)",
+		"  3  (new)",
+		"",
+		"ex1.txt  20µs",
+		"  4  (WRONG, want: 5)",
+		"",
+		"ex2.txt  0s",
+		"  ERROR: bad input",
+		"",
+	}, "\n")
+	var got strings.Builder
+	renderWatch(&got, results, prev)
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("renderWatch mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchers(t *testing.T) {
+	watchers := []struct {
+		name string
+		new  func(paths []string) (watcher, error)
+	}{
+		{name: "poll", new: func(paths []string) (watcher, error) { return newPollWatcher(paths, 10*time.Millisecond), nil }},
+		{name: "native", new: newNativeWatcher},
+	}
+	for _, w := range watchers {
+		t.Run(w.name, func(t *testing.T) {
+			root := t.TempDir()
+			input, examples := filepath.Join(root, "in.txt"), filepath.Join(root, "ex")
+			if err := os.Mkdir(examples, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			wr, err := w.new([]string{input, examples})
+			if err != nil {
+				t.Skipf("watcher not available: %v", err)
+			}
+			defer wr.close()
+			changes := make(chan error)
+			go func() {
+				for {
+					err := wr.wait()
+					changes <- err
+					if err != nil {
+						return
+					}
+				}
+			}()
+
+			steps := []struct {
+				desc   string
+				change func() error
+				want   bool
+			}{
+				{"create input", func() error { return os.WriteFile(input, []byte("1\n"), 0o644) }, true},
+				{"modify input", func() error { return os.WriteFile(input, []byte("12\n"), 0o644) }, true},
+				{"unrelated file", func() error { return os.WriteFile(filepath.Join(root, "other.txt"), nil, 0o644) }, false},
+				{"add example", func() error { return os.WriteFile(filepath.Join(examples, "a.txt"), []byte("x"), 0o644) }, true},
+				{"rename example", func() error { return os.Rename(filepath.Join(examples, "a.txt"), filepath.Join(examples, "b.txt")) }, true},
+			}
+			for _, step := range steps {
+				if err := step.change(); err != nil {
+					t.Fatalf("%s: %v", step.desc, err)
+				}
+				timeout := 5 * time.Second
+				if !step.want {
+					timeout = 300 * time.Millisecond
+				}
+				select {
+				case err := <-changes:
+					if err != nil {
+						t.Fatalf("%s: wait: %v", step.desc, err)
+					}
+					if !step.want {
+						t.Errorf("%s: unexpected change", step.desc)
+					}
+				case <-time.After(timeout):
+					if step.want {
+						t.Fatalf("%s: no change seen", step.desc)
+					}
+				}
+			}
+		})
+	}
+}
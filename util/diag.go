@@ -15,37 +15,209 @@
 package util
 
 import (
-	"flag"
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-var verbose = flag.Bool("verbose", false, "turn on diagnostic outputs")
+// Diag is a named scope of diagnostic output, such as "2019/18" for a puzzle day, or "intcode" for
+// a subsystem. Diagnostics are off by default, and can be turned on for a set of scopes with
+// SetDiagFilter. Enabled output goes to standard error one line at a time, each line prefixed with
+// the name of the scope, unless redirected with SetDiagSink.
+//
+// Scopes are meant to be created once, in a package-level variable.
+type Diag struct {
+	name   string
+	mu     sync.Mutex
+	line   []byte
+	logger *slog.Logger
+}
+
+// NewDiag returns a diagnostic scope with the given name. Numeric components of the name (separated
+// by '/') are normalized to not have leading zeros, so "2019/05" is the same as "2019/5".
+func NewDiag(name string) *Diag {
+	d := &Diag{name: normalizeDiagName(name)}
+	d.logger = slog.New(diagHandler{d: d, h: slog.NewTextHandler(d, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})})
+	return d
+}
+
+// Name returns the name of the scope.
+func (d *Diag) Name() string {
+	return d.name
+}
+
+// Enabled tests if diagnostic output of this scope has been requested. It can be used to avoid
+// doing expensive work only needed for diagnostics.
+func (d *Diag) Enabled() bool {
+	f := diagFilter.Load()
+	return f != nil && f.match(d.name)
+}
+
+// Print prints its arguments to the diagnostic output of the scope.
+func (d *Diag) Print(a ...interface{}) {
+	if d.Enabled() {
+		fmt.Fprint(d, a...)
+	}
+}
+
+// Println prints its arguments, followed by a newline, to the diagnostic output of the scope.
+func (d *Diag) Println(a ...interface{}) {
+	if d.Enabled() {
+		fmt.Fprintln(d, a...)
+	}
+}
+
+// Printf formats data to the diagnostic output of the scope.
+func (d *Diag) Printf(format string, a ...interface{}) {
+	if d.Enabled() {
+		fmt.Fprintf(d, format, a...)
+	}
+}
+
+// Logger returns a structured logger that writes to the diagnostic output of the scope. All levels
+// are logged whenever the scope is enabled.
+func (d *Diag) Logger() *slog.Logger {
+	return d.logger
+}
+
+// Write implements io.Writer for the diagnostic output of the scope. Data is buffered until a full
+// line has been written. Unlike the other methods, Write does not check if the scope is enabled.
+func (d *Diag) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.line = append(d.line, p...)
+	for {
+		i := bytes.IndexByte(d.line, '\n')
+		if i < 0 {
+			break
+		}
+		emitDiag(d.name, string(d.line[:i]))
+		d.line = d.line[i+1:]
+	}
+	if len(d.line) == 0 {
+		d.line = nil
+	}
+	return len(p), nil
+}
+
+// diagHandler is a slog.Handler that only handles records when its scope is enabled.
+type diagHandler struct {
+	d *Diag
+	h slog.Handler
+}
+
+func (dh diagHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return dh.d.Enabled() && dh.h.Enabled(ctx, level)
+}
+
+func (dh diagHandler) Handle(ctx context.Context, r slog.Record) error {
+	return dh.h.Handle(ctx, r)
+}
+
+func (dh diagHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return diagHandler{d: dh.d, h: dh.h.WithAttrs(attrs)}
+}
+
+func (dh diagHandler) WithGroup(name string) slog.Handler {
+	return diagHandler{d: dh.d, h: dh.h.WithGroup(name)}
+}
 
-// Diag prints its arguments to the diagnostic output stream.
-func Diag(a ...interface{}) {
-	if !*verbose {
-		return
+// diagPatterns is a parsed filter of enabled diagnostic scopes.
+type diagPatterns struct {
+	all      bool
+	patterns []string
+}
+
+func (f *diagPatterns) match(name string) bool {
+	if f.all {
+		return true
+	}
+	for _, p := range f.patterns {
+		if name == p || (strings.HasPrefix(name, p) && name[len(p)] == '/') {
+			return true
+		}
 	}
-	fmt.Print(a...)
+	return false
 }
 
-// Diagln prints its arguments, followed by a newline, to the diagnostic output stream.
-func Diagln(a ...interface{}) {
-	if !*verbose {
-		return
+var diagFilter atomic.Pointer[diagPatterns]
+
+// SetDiagFilter enables the diagnostic output of the scopes listed in spec, and disables all
+// others. The spec is a comma-separated list of scope names, each of which also enables all the
+// scopes nested under it: "2019" enables the scopes of all 2019 days, and "2019/18,intcode" the
+// scope of one day and the Intcode interpreter. The special name "all" enables everything, and an
+// empty spec disables all diagnostics.
+func SetDiagFilter(spec string) error {
+	if spec == "" {
+		diagFilter.Store(nil)
+		return nil
+	}
+	f := &diagPatterns{}
+	for _, p := range strings.Split(spec, ",") {
+		p = strings.Trim(strings.TrimSpace(p), "/")
+		if p == "" {
+			return fmt.Errorf("empty diagnostic scope in: %q", spec)
+		}
+		if p == "all" {
+			f.all = true
+			continue
+		}
+		f.patterns = append(f.patterns, normalizeDiagName(p))
 	}
-	fmt.Println(a...)
+	diagFilter.Store(f)
+	return nil
 }
 
-// Diagf formats data to the diagnostic output stream.
-func Diagf(format string, a ...interface{}) {
-	if !*verbose {
-		return
+func normalizeDiagName(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		if n, err := strconv.Atoi(part); err == nil && n >= 0 {
+			parts[i] = strconv.Itoa(n)
+		}
 	}
-	fmt.Printf(format, a...)
+	return strings.Join(parts, "/")
 }
 
-// IsDiag tests if verbose output has been requested.
-func IsDiag() bool {
-	return *verbose
+var (
+	diagMu   sync.Mutex
+	diagSink func(scope, line string)
+)
+
+func emitDiag(scope, line string) {
+	diagMu.Lock()
+	defer diagMu.Unlock()
+	if diagSink != nil {
+		diagSink(scope, line)
+	} else {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", scope, line)
+	}
+}
+
+// SetDiagSink redirects all diagnostic output, one line at a time, to the given function instead of
+// standard error. Calls to the sink are serialized. The returned function restores the previous
+// sink.
+func SetDiagSink(sink func(scope, line string)) (restore func()) {
+	diagMu.Lock()
+	defer diagMu.Unlock()
+	old := diagSink
+	diagSink = sink
+	return func() {
+		diagMu.Lock()
+		defer diagMu.Unlock()
+		diagSink = old
+	}
 }
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiagFilter(t *testing.T) {
+	defer SetDiagFilter("")
+	scopes := []*Diag{NewDiag("2019/05"), NewDiag("2019/18"), NewDiag("2020/18"), NewDiag("intcode"), NewDiag("intcodex")}
+	tests := []struct {
+		spec string
+		want []bool
+	}{
+		{spec: "", want: []bool{false, false, false, false, false}},
+		{spec: "2019/5", want: []bool{true, false, false, false, false}},
+		{spec: "2019/18,intcode", want: []bool{false, true, false, true, false}},
+		{spec: "2019", want: []bool{true, true, false, false, false}},
+		{spec: "2019/", want: []bool{true, true, false, false, false}},
+		{spec: "201", want: []bool{false, false, false, false, false}},
+		{spec: "all", want: []bool{true, true, true, true, true}},
+	}
+	for _, test := range tests {
+		if err := SetDiagFilter(test.spec); err != nil {
+			t.Errorf("SetDiagFilter(%q): %v", test.spec, err)
+			continue
+		}
+		for i, d := range scopes {
+			if got := d.Enabled(); got != test.want[i] {
+				t.Errorf("SetDiagFilter(%q): %s enabled = %t, want %t", test.spec, d.Name(), got, test.want[i])
+			}
+		}
+	}
+	if err := SetDiagFilter("2019,,intcode"); err == nil {
+		t.Error("SetDiagFilter with an empty scope: no error")
+	}
+}
+
+func TestDiagOutput(t *testing.T) {
+	defer SetDiagFilter("")
+	var got []string
+	defer SetDiagSink(func(scope, line string) { got = append(got, scope+": "+line) })()
+
+	on, off := NewDiag("on"), NewDiag("off")
+	if err := SetDiagFilter("on"); err != nil {
+		t.Fatal(err)
+	}
+	on.Print("a", "b")
+	off.Println("hidden")
+	on.Printf("%d\nc\n", 1)
+	on.Logger().Info("msg", "x", 2)
+	off.Logger().Info("hidden")
+	on.Println()
+
+	want := []string{"on: ab1", "on: c", "on: level=INFO msg=msg x=2", "on: "}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diagnostic output mismatch (-want +got):\n%s", diff)
+	}
+}
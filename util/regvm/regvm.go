@@ -0,0 +1,429 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package regvm is a small framework for the assembly-like register machine languages that keep
+// cropping up in AoC puzzles.
+//
+// A language is described by an ISA: a table of operations, each with a mnemonic, the kinds of its
+// operands and an implementation, plus a way of naming the registers. The ISA parses the text of a
+// program into a Prog, which can then be executed on a Machine. The machine takes care of the
+// register file, the instruction pointer, cycle counting, step hooks (for tracing, or for puzzles
+// that want to observe the execution) and loop detection, so that each language only needs to
+// define what its instructions do.
+//
+// Any language-specific state (an accumulator, output channels, a display...) lives in the
+// environment of the machine, the type parameter E.
+package regvm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxArgs is the maximum number of operands an instruction can have.
+const MaxArgs = 4
+
+// ArgKind is the type of one operand in an operation's signature.
+type ArgKind byte
+
+const (
+	// ArgReg is an operand that must name a register.
+	ArgReg ArgKind = 'r'
+	// ArgImm is an operand that must be an integer literal.
+	ArgImm ArgKind = 'i'
+	// ArgVal is an operand that can be either a register or an integer literal.
+	ArgVal ArgKind = 'v'
+)
+
+// Operand is a single parsed operand: either a register (by index) or an immediate value.
+type Operand struct {
+	Reg bool
+	Val int
+}
+
+// R returns a register operand.
+func R(r int) Operand { return Operand{Reg: true, Val: r} }
+
+// I returns an immediate operand.
+func I(v int) Operand { return Operand{Val: v} }
+
+// Inst is a single parsed instruction: an index into the ISA operation table, and its operands.
+type Inst struct {
+	Op   int
+	N    int
+	Args [MaxArgs]Operand
+}
+
+// Op describes one operation of an instruction set.
+type Op[E any] struct {
+	// Name is the mnemonic of the operation.
+	Name string
+	// Args is the signature of the operation: one ArgKind character per operand.
+	Args string
+	// Cycles is the number of cycles the operation takes. Zero is treated as 1.
+	Cycles int
+	// Exec implements the operation. Operations that transfer control should do so with the
+	// Jump, JumpRel or Halt methods of the machine; otherwise execution continues with the next
+	// instruction.
+	Exec func(m *Machine[E], args []Operand)
+}
+
+// ISA is the definition of an instruction set.
+type ISA[E any] struct {
+	// Ops is the table of operations. Each instruction refers to its operation by index.
+	Ops []Op[E]
+	// Regs defines the register names. If nil, registers are allocated dynamically: each distinct
+	// identifier gets the next free index, in order of appearance in the program.
+	Regs *RegFile
+	// Syntax, if set, splits a line of the program to the mnemonic and operands. The default is to
+	// split on whitespace, and treat the first field as the mnemonic.
+	Syntax func(line string) (name string, args []string, err error)
+
+	once   sync.Once
+	byName map[string]int
+}
+
+// RegFile describes a fixed set of named registers.
+type RegFile struct {
+	// N is the number of registers.
+	N int
+	// Names maps the register names to indices.
+	Names map[string]int
+	// Labels holds the printable name of each register.
+	Labels []string
+}
+
+// NamedRegs returns a register file of registers with the given names, in order.
+func NamedRegs(names ...string) *RegFile {
+	rf := &RegFile{N: len(names), Names: make(map[string]int, len(names)), Labels: names}
+	for r, name := range names {
+		rf.Names[name] = r
+	}
+	return rf
+}
+
+// LetterRegs returns a register file of n single-letter registers starting from 'a'.
+func LetterRegs(n int) *RegFile {
+	rf := &RegFile{N: n, Names: make(map[string]int, n)}
+	for r := 0; r < n; r++ {
+		name := string(rune('a' + r))
+		rf.Names[name] = r
+		rf.Labels = append(rf.Labels, name)
+	}
+	return rf
+}
+
+// NumberedRegs returns a register file of n registers named by their index.
+func NumberedRegs(n int) *RegFile {
+	rf := &RegFile{N: n, Names: make(map[string]int, n)}
+	for r := 0; r < n; r++ {
+		name := strconv.Itoa(r)
+		rf.Names[name] = r
+		rf.Labels = append(rf.Labels, name)
+	}
+	return rf
+}
+
+// Lookup returns the index of the operation with the given mnemonic.
+func (isa *ISA[E]) Lookup(name string) (op int, ok bool) {
+	isa.once.Do(func() {
+		isa.byName = make(map[string]int, len(isa.Ops))
+		for i, op := range isa.Ops {
+			isa.byName[op.Name] = i
+		}
+	})
+	op, ok = isa.byName[name]
+	return op, ok
+}
+
+// Parse parses the text of a program, one instruction per line.
+func (isa *ISA[E]) Parse(lines []string) (*Prog[E], error) {
+	p := &Prog[E]{ISA: isa, Code: make([]Inst, 0, len(lines))}
+	for i, line := range lines {
+		in, err := p.parseInst(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		p.Code = append(p.Code, in)
+	}
+	return p, nil
+}
+
+// Prog is a parsed program.
+type Prog[E any] struct {
+	// ISA is the instruction set the program was written in.
+	ISA *ISA[E]
+	// Code contains the instructions of the program.
+	Code []Inst
+	// RegNames holds the names of the registers, if they were dynamically allocated.
+	RegNames []string
+}
+
+func (p *Prog[E]) parseInst(line string) (Inst, error) {
+	var (
+		name string
+		args []string
+	)
+	if p.ISA.Syntax != nil {
+		var err error
+		if name, args, err = p.ISA.Syntax(line); err != nil {
+			return Inst{}, err
+		}
+	} else if fields := strings.Fields(line); len(fields) > 0 {
+		name, args = fields[0], fields[1:]
+	}
+	op, ok := p.ISA.Lookup(name)
+	if !ok {
+		return Inst{}, fmt.Errorf("invalid operation: %q", name)
+	}
+	sig := p.ISA.Ops[op].Args
+	if len(args) != len(sig) {
+		return Inst{}, fmt.Errorf("%s: got %d operands, want %d", name, len(args), len(sig))
+	}
+	in := Inst{Op: op, N: len(args)}
+	for i, arg := range args {
+		var err error
+		if in.Args[i], err = p.parseArg(ArgKind(sig[i]), arg); err != nil {
+			return Inst{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return in, nil
+}
+
+func (p *Prog[E]) parseArg(kind ArgKind, arg string) (Operand, error) {
+	if kind != ArgReg {
+		if v, err := strconv.Atoi(arg); err == nil {
+			return I(v), nil
+		} else if kind == ArgImm {
+			return Operand{}, fmt.Errorf("not an integer: %q", arg)
+		}
+	}
+	if r, ok := p.reg(arg); ok {
+		return R(r), nil
+	}
+	return Operand{}, fmt.Errorf("not a register: %q", arg)
+}
+
+func (p *Prog[E]) reg(name string) (int, bool) {
+	if p.ISA.Regs != nil {
+		r, ok := p.ISA.Regs.Names[name]
+		return r, ok
+	}
+	if name == "" {
+		return 0, false
+	}
+	for r, rn := range p.RegNames {
+		if rn == name {
+			return r, true
+		}
+	}
+	p.RegNames = append(p.RegNames, name)
+	return len(p.RegNames) - 1, true
+}
+
+// NumRegs returns the number of registers a machine needs to run the program.
+func (p *Prog[E]) NumRegs() int {
+	if p.ISA.Regs != nil {
+		return p.ISA.Regs.N
+	}
+	return len(p.RegNames)
+}
+
+// RegName returns a printable name for a register.
+func (p *Prog[E]) RegName(r int) string {
+	if p.ISA.Regs != nil && r < len(p.ISA.Regs.Labels) {
+		return p.ISA.Regs.Labels[r]
+	} else if p.ISA.Regs == nil && r < len(p.RegNames) {
+		return p.RegNames[r]
+	}
+	return fmt.Sprintf("r%d", r)
+}
+
+// Format disassembles a single instruction to a string, using the default syntax.
+func (p *Prog[E]) Format(in Inst) string {
+	var s strings.Builder
+	s.WriteString(p.ISA.Ops[in.Op].Name)
+	for _, arg := range in.Args[:in.N] {
+		s.WriteByte(' ')
+		if arg.Reg {
+			s.WriteString(p.RegName(arg.Val))
+		} else {
+			s.WriteString(strconv.Itoa(arg.Val))
+		}
+	}
+	return s.String()
+}
+
+// Machine holds the execution state of a program.
+type Machine[E any] struct {
+	// Prog is the program being executed.
+	Prog *Prog[E]
+	// Code is the machine's own copy of the program code. Operations are free to modify it.
+	Code []Inst
+	// Regs is the register file.
+	Regs []int
+	// IP is the instruction pointer, as an index to Code.
+	IP int
+	// Cycles is the number of cycles executed so far.
+	Cycles int
+	// Steps is the number of instructions executed so far.
+	Steps int
+	// IPBound and IPReg hold the IP register binding: if IPBound is set, the IP is copied to the
+	// IPReg register before executing each instruction, and copied back afterwards.
+	IPBound bool
+	IPReg   int
+	// Env is the language-specific state of the machine.
+	Env E
+	// OnStep, if set, is called before each instruction is executed.
+	OnStep func(m *Machine[E], in *Inst)
+
+	jumped, halted bool
+}
+
+// NewMachine returns a machine ready to execute a program from the start, with all registers zero.
+func NewMachine[E any](p *Prog[E], env E) *Machine[E] {
+	m := &Machine[E]{Prog: p, Env: env}
+	m.Reset()
+	return m
+}
+
+// Reset restores the machine to its initial state, apart from the environment and the hooks.
+func (m *Machine[E]) Reset() {
+	m.Code = append(m.Code[:0], m.Prog.Code...)
+	if n := m.Prog.NumRegs(); len(m.Regs) != n {
+		m.Regs = make([]int, n)
+	} else {
+		clear(m.Regs)
+	}
+	m.IP, m.Cycles, m.Steps = 0, 0, 0
+	m.jumped, m.halted = false, false
+}
+
+// Get returns the value of an operand.
+func (m *Machine[E]) Get(o Operand) int {
+	if o.Reg {
+		return m.Regs[o.Val]
+	}
+	return o.Val
+}
+
+// Set stores a value to a register operand. Writes to immediate operands are silently ignored,
+// which is handy for languages (like assembunny) where self-modification can create nonsense.
+func (m *Machine[E]) Set(o Operand, v int) {
+	if o.Reg {
+		m.Regs[o.Val] = v
+	}
+}
+
+// Jump transfers control to an absolute address.
+func (m *Machine[E]) Jump(target int) {
+	m.IP, m.jumped = target, true
+}
+
+// JumpRel transfers control relative to the current instruction.
+func (m *Machine[E]) JumpRel(offset int) {
+	m.Jump(m.IP + offset)
+}
+
+// Halt stops the machine after the current instruction.
+func (m *Machine[E]) Halt() {
+	m.halted = true
+}
+
+// Halted returns true if the machine has stopped, either explicitly or by running out of code.
+func (m *Machine[E]) Halted() bool {
+	return m.halted || m.IP < 0 || m.IP >= len(m.Code)
+}
+
+// Step executes a single instruction. It returns false if the machine was already halted.
+func (m *Machine[E]) Step() bool {
+	if m.Halted() {
+		return false
+	}
+	in := &m.Code[m.IP]
+	if m.OnStep != nil {
+		m.OnStep(m, in)
+	}
+	op := &m.Prog.ISA.Ops[in.Op]
+	if m.IPBound {
+		m.Regs[m.IPReg] = m.IP
+	}
+	m.jumped = false
+	op.Exec(m, in.Args[:in.N])
+	if m.IPBound && !m.jumped {
+		m.IP = m.Regs[m.IPReg]
+	}
+	if !m.jumped {
+		m.IP++
+	}
+	m.Cycles += max(op.Cycles, 1)
+	m.Steps++
+	return true
+}
+
+// Run executes the program until the machine halts.
+func (m *Machine[E]) Run() {
+	if m.OnStep != nil || m.IPBound {
+		for m.Step() {
+		}
+		return
+	}
+	// fast path for the common case of no hooks or IP binding
+	ops := m.Prog.ISA.Ops
+	for !m.halted && uint(m.IP) < uint(len(m.Code)) {
+		in := &m.Code[m.IP]
+		op := &ops[in.Op]
+		m.jumped = false
+		op.Exec(m, in.Args[:in.N])
+		if !m.jumped {
+			m.IP++
+		}
+		m.Cycles += max(op.Cycles, 1)
+		m.Steps++
+	}
+}
+
+// RunUntil executes the program until the machine halts, or the stop condition (checked before
+// each instruction) returns true.
+func (m *Machine[E]) RunUntil(stop func(m *Machine[E]) bool) {
+	for !m.Halted() && !stop(m) {
+		m.Step()
+	}
+}
+
+// RunLoopCheck executes the program until the machine halts, or is about to execute some
+// instruction for the second time. It returns true if execution was stopped due to a loop. This is
+// only meaningful for languages where the control flow does not depend on the register state.
+func (m *Machine[E]) RunLoopCheck() (loop bool) {
+	seen := make([]bool, len(m.Code))
+	for !m.Halted() {
+		if seen[m.IP] {
+			return true
+		}
+		seen[m.IP] = true
+		m.Step()
+	}
+	return false
+}
+
+// Tracer returns a step hook that writes a disassembly of each executed instruction, along with the
+// register state before it, to w.
+func Tracer[E any](w io.Writer) func(m *Machine[E], in *Inst) {
+	return func(m *Machine[E], in *Inst) {
+		fmt.Fprintf(w, "%4d: %-20s %v\n", m.IP, m.Prog.Format(*in), m.Regs)
+	}
+}
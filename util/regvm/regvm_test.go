@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regvm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type counter = Machine[int]
+
+// testISA is a toy language with an output counter in its environment.
+var testISA = &ISA[int]{
+	Regs: LetterRegs(2),
+	Ops: []Op[int]{
+		{Name: "set", Args: "rv", Exec: func(m *counter, a []Operand) { m.Set(a[0], m.Get(a[1])) }},
+		{Name: "add", Args: "rv", Cycles: 2, Exec: func(m *counter, a []Operand) { m.Regs[a[0].Val] += m.Get(a[1]) }},
+		{Name: "out", Args: "v", Exec: func(m *counter, a []Operand) { m.Env += m.Get(a[0]) }},
+		{Name: "jnz", Args: "vi", Exec: func(m *counter, a []Operand) {
+			if m.Get(a[0]) != 0 {
+				m.JumpRel(a[1].Val)
+			}
+		}},
+		{Name: "hlt", Exec: func(m *counter, a []Operand) { m.Halt() }},
+	},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Inst
+		wantErr string
+	}{
+		{line: "set a 5", want: Inst{Op: 0, N: 2, Args: [MaxArgs]Operand{R(0), I(5)}}},
+		{line: "add b a", want: Inst{Op: 1, N: 2, Args: [MaxArgs]Operand{R(1), R(0)}}},
+		{line: "jnz 1 -2", want: Inst{Op: 3, N: 2, Args: [MaxArgs]Operand{I(1), I(-2)}}},
+		{line: "hlt", want: Inst{Op: 4}},
+		{line: "nop", wantErr: "invalid operation"},
+		{line: "set 1 2", wantErr: "not a register"},
+		{line: "set c 2", wantErr: "not a register"},
+		{line: "jnz a b", wantErr: "not an integer"},
+		{line: "out", wantErr: "got 0 operands, want 1"},
+	}
+	for _, test := range tests {
+		p, err := testISA.Parse([]string{test.line})
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Parse(%q) = %v, want error %q", test.line, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.line, err)
+		} else if diff := cmp.Diff(test.want, p.Code[0]); diff != "" {
+			t.Errorf("Parse(%q) mismatch (-want +got):\n%s", test.line, diff)
+		} else if got := p.Format(p.Code[0]); got != test.line {
+			t.Errorf("Format(Parse(%q)) = %q", test.line, got)
+		}
+	}
+}
+
+func TestDynamicRegs(t *testing.T) {
+	isa := &ISA[int]{Ops: testISA.Ops}
+	p, err := isa.Parse([]string{"set foo 3", "add bar foo", "add bar bar", "out bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"foo", "bar"}; !cmp.Equal(p.RegNames, want) {
+		t.Errorf("RegNames = %v, want %v", p.RegNames, want)
+	}
+	m := NewMachine(p, 0)
+	m.Run()
+	if m.Env != 6 {
+		t.Errorf("out = %d, want 6", m.Env)
+	}
+}
+
+func TestRun(t *testing.T) {
+	p, err := testISA.Parse([]string{
+		"set a 3",
+		"out a",
+		"add a -1",
+		"jnz a -2",
+		"hlt",
+		"out 100",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMachine(p, 0)
+	m.Run()
+	if m.Env != 6 || m.IP != 5 || m.Steps != 11 || m.Cycles != 14 {
+		t.Errorf("Run: out=%d IP=%d steps=%d cycles=%d, want 6, 5, 11, 14", m.Env, m.IP, m.Steps, m.Cycles)
+	}
+
+	m.Reset()
+	m.Env = 0
+	m.RunUntil(func(m *counter) bool { return m.Regs[0] == 1 })
+	if m.Env != 5 || m.IP != 3 {
+		t.Errorf("RunUntil: out=%d IP=%d, want 5, 3", m.Env, m.IP)
+	}
+
+	m.Reset()
+	if !m.RunLoopCheck() || m.IP != 1 {
+		t.Errorf("RunLoopCheck: IP=%d, want loop at 1", m.IP)
+	}
+}
+
+func TestIPBinding(t *testing.T) {
+	p, err := testISA.Parse([]string{
+		"add a 1", // skips the next instruction
+		"out 100",
+		"out 1",
+		"set b 0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMachine(p, 0)
+	m.IPBound, m.IPReg = true, 0
+	m.Run()
+	if m.Env != 1 {
+		t.Errorf("out = %d, want 1", m.Env)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	p, err := testISA.Parse([]string{"set b 7", "out b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace strings.Builder
+	m := NewMachine(p, 0)
+	m.OnStep = Tracer[int](&trace)
+	m.Run()
+	want := "   0: set b 7              [0 0]\n" +
+		"   1: out b                [0 7]\n"
+	if got := trace.String(); got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"math"
+)
+
+// Interval is a closed range of integers.
+type Interval struct {
+	Lo, Hi int
+}
+
+// Full is the interval of all integers.
+var Full = Interval{math.MinInt, math.MaxInt}
+
+// Point returns true if the interval contains a single value.
+func (r Interval) Point() bool { return r.Lo == r.Hi }
+
+// Size returns the number of values in the interval, saturated at math.MaxInt.
+func (r Interval) Size() int {
+	if r.Lo < 0 && r.Hi > math.MaxInt+r.Lo-1 {
+		return math.MaxInt
+	}
+	return r.Hi - r.Lo + 1
+}
+
+// Ranges maps variables to the intervals of their possible values.
+type Ranges func(id int) Interval
+
+func satAdd(a, b int) int {
+	s := a + b
+	if a > 0 && b > 0 && s < 0 {
+		return math.MaxInt
+	} else if a < 0 && b < 0 && s >= 0 {
+		return math.MinInt
+	}
+	return s
+}
+
+func satMul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if p/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	return p, true
+}
+
+// Range returns an interval known to contain all possible values of the expression, given the
+// ranges of the variables. A nil Ranges means the variables can take any value.
+func (e *Expr) Range(vr Ranges) Interval {
+	switch e.Kind {
+	case Const:
+		return Interval{e.Val, e.Val}
+	case Var:
+		if vr == nil {
+			return Full
+		}
+		return vr(e.Val)
+	case Add:
+		r := Interval{0, 0}
+		for _, arg := range e.Args {
+			a := arg.Range(vr)
+			r = Interval{satAdd(r.Lo, a.Lo), satAdd(r.Hi, a.Hi)}
+		}
+		return r
+	case Mul:
+		r := Interval{1, 1}
+		for _, arg := range e.Args {
+			a := arg.Range(vr)
+			lo, hi := math.MaxInt, math.MinInt
+			for _, x := range [2]int{r.Lo, r.Hi} {
+				for _, y := range [2]int{a.Lo, a.Hi} {
+					p, ok := satMul(x, y)
+					if !ok {
+						return Full
+					}
+					lo, hi = min(lo, p), max(hi, p)
+				}
+			}
+			r = Interval{lo, hi}
+		}
+		return r
+	case Div:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if b.Lo > 0 && a.Lo >= 0 {
+			return Interval{a.Lo / b.Hi, a.Hi / b.Lo}
+		}
+	case Mod:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if b.Lo > 0 && a.Lo >= 0 {
+			if a.Hi < b.Lo {
+				return a
+			}
+			return Interval{0, b.Hi - 1}
+		}
+	case BitAnd:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if a.Lo >= 0 || b.Lo >= 0 {
+			hi := math.MaxInt
+			if a.Lo >= 0 {
+				hi = a.Hi
+			}
+			if b.Lo >= 0 {
+				hi = min(hi, b.Hi)
+			}
+			return Interval{0, hi}
+		}
+	case Eq, Gt, And, Or:
+		return Interval{0, 1}
+	case Ite:
+		a, b := e.Args[1].Range(vr), e.Args[2].Range(vr)
+		return Interval{min(a.Lo, b.Lo), max(a.Hi, b.Hi)}
+	case First:
+		return Interval{0, math.MaxInt}
+	}
+	return Full
+}
+
+// Simplify rebuilds the expression, using the given variable ranges to decide comparisons and to
+// drop redundant operations.
+func Simplify(e *Expr, vr Ranges) *Expr {
+	if len(e.Args) == 0 {
+		return e
+	}
+	args := make([]*Expr, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = Simplify(arg, vr)
+	}
+	e = rebuild(e, args)
+	r := e.Range(vr)
+	if r.Point() {
+		return C(r.Lo)
+	}
+	switch e.Kind {
+	case Eq:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if a.Hi < b.Lo || b.Hi < a.Lo {
+			return zero
+		}
+	case Gt:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if a.Lo > b.Hi {
+			return one
+		} else if a.Hi <= b.Lo {
+			return zero
+		}
+	case Mod:
+		a, b := e.Args[0].Range(vr), e.Args[1].Range(vr)
+		if a.Lo >= 0 && b.Lo > 0 && a.Hi < b.Lo {
+			return e.Args[0]
+		}
+	case BitAnd:
+		for i, arg := range e.Args {
+			a := arg.Range(vr)
+			if m, ok := e.Args[1-i].IsConst(); ok && m&(m+1) == 0 && a.Lo >= 0 && a.Hi <= m {
+				return arg
+			}
+		}
+	}
+	return e
+}
+
+// rebuild returns an expression of the same kind as e, with new arguments, simplified by the
+// appropriate constructor.
+func rebuild(e *Expr, args []*Expr) *Expr {
+	switch e.Kind {
+	case Add:
+		return Plus(args...)
+	case Mul:
+		return Times(args...)
+	case Div:
+		return Quo(args[0], args[1])
+	case Mod:
+		return Rem(args[0], args[1])
+	case BitAnd:
+		return BitwiseAnd(args[0], args[1])
+	case BitOr:
+		return BitwiseOr(args[0], args[1])
+	case Eq:
+		return Equals(args[0], args[1])
+	case Gt:
+		return Greater(args[0], args[1])
+	case And:
+		return AllOf(args...)
+	case Or:
+		return AnyOf(args...)
+	case Ite:
+		return IfThenElse(args[0], args[1], args[2])
+	case Sum:
+		return Summation(e.Val, args[0], args[1], args[2], args[3])
+	case First:
+		return FirstIndex(e.Val, args[0], args[1], args[2])
+	}
+	return e
+}
+
+// Subst returns the expression with the variables in m replaced by the corresponding expressions.
+func Subst(e *Expr, m map[int]*Expr) *Expr {
+	switch e.Kind {
+	case Const:
+		return e
+	case Var:
+		if s, ok := m[e.Val]; ok {
+			return s
+		}
+		return e
+	}
+	args := make([]*Expr, len(e.Args))
+	changed := false
+	for i, arg := range e.Args {
+		args[i] = Subst(arg, m)
+		changed = changed || args[i] != arg
+	}
+	if !changed {
+		return e
+	}
+	return rebuild(e, args)
+}
+
+// Replace returns the expression with all occurrences of the subexpression old replaced by new.
+func Replace(e, old, new *Expr) *Expr {
+	if Equal(e, old) {
+		return new
+	}
+	if len(e.Args) == 0 {
+		return e
+	}
+	args := make([]*Expr, len(e.Args))
+	changed := false
+	for i, arg := range e.Args {
+		args[i] = Replace(arg, old, new)
+		changed = changed || args[i] != arg
+	}
+	if !changed {
+		return e
+	}
+	return rebuild(e, args)
+}
+
+// Linear splits the expression into coef*x + rest, where x is the given atom (typically a
+// variable), and neither coef nor rest contain it. It returns false if the expression isn't linear
+// in x.
+func Linear(e, x *Expr) (coef, rest *Expr, ok bool) {
+	if !e.Contains(x) {
+		return zero, e, true
+	}
+	if Equal(e, x) {
+		return one, zero, true
+	}
+	switch e.Kind {
+	case Add:
+		var coefs, rests []*Expr
+		for _, t := range e.Args {
+			c, r, ok := Linear(t, x)
+			if !ok {
+				return nil, nil, false
+			}
+			coefs, rests = append(coefs, c), append(rests, r)
+		}
+		return Plus(coefs...), Plus(rests...), true
+	case Mul:
+		var others []*Expr
+		found := false
+		for _, f := range e.Args {
+			switch {
+			case !f.Contains(x):
+				others = append(others, f)
+			case Equal(f, x) && !found:
+				found = true
+			default:
+				return nil, nil, false
+			}
+		}
+		return Times(others...), zero, true
+	}
+	return nil, nil, false
+}
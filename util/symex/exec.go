@@ -0,0 +1,222 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fis/aoc/util/regvm"
+)
+
+// State is the symbolic state of a machine along one execution path.
+type State struct {
+	// Regs holds the symbolic values of the registers.
+	Regs []*Expr
+	// IP is the (concrete) instruction pointer.
+	IP int
+	// Cond is the path condition, as a list of boolean expressions that must all hold.
+	Cond []*Expr
+
+	next   *Expr
+	halted bool
+	err    error
+}
+
+// Get returns the symbolic value of an operand.
+func (s *State) Get(o regvm.Operand) *Expr {
+	if o.Reg {
+		return s.Regs[o.Val]
+	}
+	return C(o.Val)
+}
+
+// Set assigns a symbolic value to an operand. Writes to immediates are ignored.
+func (s *State) Set(o regvm.Operand, e *Expr) {
+	if o.Reg {
+		s.Regs[o.Val] = e
+	}
+}
+
+// Jump sets the (possibly symbolic) address of the next instruction.
+func (s *State) Jump(target *Expr) {
+	s.next = target
+}
+
+// JumpRel sets the address of the next instruction, relative to the current one.
+func (s *State) JumpRel(offset *Expr) {
+	s.next = Plus(C(s.IP), offset)
+}
+
+// Halt stops execution along this path.
+func (s *State) Halt() {
+	s.halted = true
+}
+
+// Unsupported marks the current instruction as one that can't be executed symbolically, for
+// example because it does I/O.
+func (s *State) Unsupported() {
+	s.err = fmt.Errorf("unsupported instruction at %d", s.IP)
+}
+
+func (s *State) clone() *State {
+	return &State{
+		Regs: append([]*Expr(nil), s.Regs...),
+		IP:   s.IP,
+		Cond: append([]*Expr(nil), s.Cond...),
+	}
+}
+
+// Semantics describes the operations of an instruction set symbolically. It is indexed the same way
+// as the Ops of the corresponding regvm.ISA; a nil entry marks an operation that is not supported.
+type Semantics []func(s *State, args []regvm.Operand)
+
+// Program is a register machine program prepared for symbolic execution.
+type Program struct {
+	// Code is the program code.
+	Code []regvm.Inst
+	// Sem gives the meanings of the operations used in the code.
+	Sem Semantics
+	// NumRegs is the size of the register file.
+	NumRegs int
+	// IPBound and IPReg hold the IP register binding, as in regvm.Machine.
+	IPBound bool
+	IPReg   int
+}
+
+// NewProgram returns a program matching the code and configuration of a machine.
+func NewProgram[E any](m *regvm.Machine[E], sem Semantics) *Program {
+	return &Program{
+		Code:    append([]regvm.Inst(nil), m.Code...),
+		Sem:     sem,
+		NumRegs: len(m.Regs),
+		IPBound: m.IPBound,
+		IPReg:   m.IPReg,
+	}
+}
+
+// Entry returns a state at the given address, with each register r holding the variable r.
+func (p *Program) Entry(ip int) *State {
+	regs := make([]*Expr, p.NumRegs)
+	for r := range regs {
+		regs[r] = V(r)
+	}
+	return &State{Regs: regs, IP: ip}
+}
+
+// Halted returns true if the state is at the end of a path: either explicitly halted, or outside
+// the program code.
+func (p *Program) Halted(s *State) bool {
+	return s.halted || s.IP < 0 || s.IP >= len(p.Code)
+}
+
+// maxFork is the largest number of different possible targets a jump can have.
+const maxFork = 4
+
+// Step executes a single instruction of a (non-halted) state. It returns the possible successor
+// states, which will be more than one if the next instruction depends on the register values. The
+// original state may be reused as one of the successors.
+func (p *Program) Step(s *State) ([]*State, error) {
+	in := &p.Code[s.IP]
+	sem := p.Sem[in.Op]
+	if sem == nil {
+		return nil, fmt.Errorf("unsupported instruction at %d", s.IP)
+	}
+	if p.IPBound {
+		s.Regs[p.IPReg] = C(s.IP)
+	}
+	s.next, s.err = nil, nil
+	sem(s, in.Args[:in.N])
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.halted {
+		return []*State{s}, nil
+	}
+	next := s.next
+	switch {
+	case next != nil:
+	case p.IPBound:
+		next = Plus(s.Regs[p.IPReg], one)
+	default:
+		next = C(s.IP + 1)
+	}
+	if ip, ok := next.IsConst(); ok {
+		s.IP = ip
+		return []*State{s}, nil
+	}
+	targets, ok := targets(next)
+	if !ok {
+		return nil, fmt.Errorf("indirect jump at %d: %v", s.IP, next)
+	}
+	var out []*State
+	for _, ip := range targets {
+		c := Equals(next, C(ip))
+		if c.isConst(0) {
+			continue
+		}
+		t := s.clone()
+		t.IP = ip
+		if !c.isConst(1) {
+			t.Cond = append(t.Cond, c)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// targets returns the possible values of a jump target expression, if there's only a few of them.
+func targets(next *Expr) ([]int, bool) {
+	if next.Kind == Ite {
+		a, ok := targets(next.Args[1])
+		if !ok {
+			return nil, false
+		}
+		b, ok := targets(next.Args[2])
+		if !ok {
+			return nil, false
+		}
+		out := append(a, b...)
+		slices.Sort(out)
+		return slices.Compact(out), len(out) <= maxFork
+	}
+	r := next.Range(nil)
+	if r.Size() > maxFork {
+		return nil, false
+	}
+	out := make([]int, 0, r.Size())
+	for ip := r.Lo; ip <= r.Hi; ip++ {
+		out = append(out, ip)
+	}
+	return out, true
+}
+
+// Straight executes a program symbolically from the given state until it leaves the address range
+// [from, to), halts or reaches an instruction where the next address is not constant. It returns
+// the final state, which will not be the original state.
+func (p *Program) Straight(s *State, from, to int) (*State, error) {
+	s = s.clone()
+	for !p.Halted(s) && s.IP >= from && s.IP < to {
+		next, err := p.Step(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(next) != 1 {
+			return nil, fmt.Errorf("branch at %d", s.IP)
+		}
+		s = next[0]
+	}
+	return s, nil
+}
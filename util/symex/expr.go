@@ -0,0 +1,567 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package symex provides symbolic execution and loop summarization for register machine programs.
+//
+// Several AoC puzzles are built around a program that would take far too long to run as is, and
+// the intended solution is to figure out what it's computing by reading it. This package does a
+// (very) small part of that automatically: it builds symbolic expressions of what straight-line
+// code computes, and recognizes the shape of simple counting loops, so that a loop can be replaced
+// by a closed-form (or at least cheaply evaluable) summary of its effect.
+//
+// The package works on programs of the util/regvm framework. Each instruction set provides a
+// Semantics function that describes its operations symbolically.
+package symex
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kind is the type of an expression node.
+type Kind uint8
+
+const (
+	// Const is an integer constant, held in Val.
+	Const Kind = iota
+	// Var is a variable, identified by Val. Register r at the start of the analyzed code is
+	// variable r; higher numbers are used for bound variables.
+	Var
+	// Add is the sum of all the arguments.
+	Add
+	// Mul is the product of all the arguments.
+	Mul
+	// Div is the truncated quotient of the two arguments.
+	Div
+	// Mod is the remainder of the truncated division of the two arguments.
+	Mod
+	// BitAnd is the bitwise AND of the two arguments.
+	BitAnd
+	// BitOr is the bitwise OR of the two arguments.
+	BitOr
+	// Eq is 1 if the two arguments are equal, 0 otherwise.
+	Eq
+	// Gt is 1 if the first argument is greater than the second, 0 otherwise.
+	Gt
+	// And is 1 if all the (boolean) arguments are nonzero, 0 otherwise.
+	And
+	// Or is 1 if any of the (boolean) arguments are nonzero, 0 otherwise.
+	Or
+	// Ite (if-then-else) is the second argument if the first is nonzero, the third otherwise.
+	Ite
+	// Sum binds the variable Val to lo + j*step for each j in [0, n), where the arguments are
+	// lo, step, n and body, and is the sum of the body over all the values.
+	Sum
+	// First binds the variable Val to lo + j*step for j = 0, 1, ..., where the arguments are lo,
+	// step and body, and is the smallest j for which the body is nonzero.
+	First
+)
+
+var kindNames = [...]string{
+	Const: "const", Var: "var", Add: "+", Mul: "*", Div: "/", Mod: "%", BitAnd: "&", BitOr: "|",
+	Eq: "==", Gt: ">", And: "&&", Or: "||", Ite: "ite", Sum: "sum", First: "first",
+}
+
+// Expr is a node of an (immutable) expression tree. Expressions should only be built with the
+// constructor functions of this package, which keep them in a simplified canonical form.
+type Expr struct {
+	Kind Kind
+	Val  int
+	Args []*Expr
+}
+
+// C returns a constant expression.
+func C(v int) *Expr { return &Expr{Kind: Const, Val: v} }
+
+// V returns a variable expression.
+func V(id int) *Expr { return &Expr{Kind: Var, Val: id} }
+
+var (
+	zero = C(0)
+	one  = C(1)
+)
+
+// IsConst returns true if the expression is a constant, and its value.
+func (e *Expr) IsConst() (int, bool) {
+	if e.Kind == Const {
+		return e.Val, true
+	}
+	return 0, false
+}
+
+func (e *Expr) isConst(v int) bool {
+	return e.Kind == Const && e.Val == v
+}
+
+// isBool returns true if the expression is known to be either 0 or 1.
+func (e *Expr) isBool() bool {
+	switch e.Kind {
+	case Const:
+		return e.Val == 0 || e.Val == 1
+	case Eq, Gt, And, Or:
+		return true
+	case Ite:
+		return e.Args[1].isBool() && e.Args[2].isBool()
+	}
+	return false
+}
+
+// Compare defines a total order on expressions. It's used to keep the arguments of commutative
+// operations in a canonical order.
+func Compare(a, b *Expr) int {
+	if a == b {
+		return 0
+	}
+	if c := cmp.Compare(a.Kind, b.Kind); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Val, b.Val); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(a.Args), len(b.Args)); c != 0 {
+		return c
+	}
+	for i := range a.Args {
+		if c := Compare(a.Args[i], b.Args[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Equal returns true if the two expressions are structurally identical.
+func Equal(a, b *Expr) bool {
+	return Compare(a, b) == 0
+}
+
+// Uses returns true if the expression refers to the given variable.
+func (e *Expr) Uses(id int) bool {
+	if e.Kind == Var {
+		return e.Val == id
+	}
+	for _, arg := range e.Args {
+		if arg.Uses(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the expression contains the given subexpression.
+func (e *Expr) Contains(sub *Expr) bool {
+	if Equal(e, sub) {
+		return true
+	}
+	for _, arg := range e.Args {
+		if arg.Contains(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Expr) String() string {
+	switch e.Kind {
+	case Const:
+		return fmt.Sprint(e.Val)
+	case Var:
+		return fmt.Sprintf("v%d", e.Val)
+	case Sum, First:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.String()
+		}
+		return fmt.Sprintf("%s[v%d](%s)", kindNames[e.Kind], e.Val, strings.Join(args, ", "))
+	case Ite:
+		return fmt.Sprintf("(%v ? %v : %v)", e.Args[0], e.Args[1], e.Args[2])
+	}
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = arg.String()
+	}
+	return "(" + strings.Join(args, " "+kindNames[e.Kind]+" ") + ")"
+}
+
+// Plus returns the simplified sum of the arguments.
+func Plus(args ...*Expr) *Expr {
+	// collect terms as coefficient * base
+	type term struct {
+		base *Expr
+		coef int
+	}
+	var terms []term
+	c := 0
+	var add func(e *Expr, k int)
+	add = func(e *Expr, k int) {
+		switch {
+		case e.Kind == Const:
+			c += k * e.Val
+			return
+		case e.Kind == Add:
+			for _, arg := range e.Args {
+				add(arg, k)
+			}
+			return
+		case e.Kind == Mul && e.Args[len(e.Args)-1].Kind == Const:
+			n := len(e.Args) - 1
+			k *= e.Args[n].Val
+			if n == 1 {
+				e = e.Args[0]
+			} else {
+				e = &Expr{Kind: Mul, Args: e.Args[:n]}
+			}
+		}
+		for i := range terms {
+			if Equal(terms[i].base, e) {
+				terms[i].coef += k
+				return
+			}
+		}
+		terms = append(terms, term{e, k})
+	}
+	for _, arg := range args {
+		add(arg, 1)
+	}
+	var out []*Expr
+	for _, t := range terms {
+		if t.coef != 0 {
+			out = append(out, Times(t.base, C(t.coef)))
+		}
+	}
+	slices.SortFunc(out, Compare)
+	if c != 0 || len(out) == 0 {
+		out = append(out, C(c))
+	}
+	if len(out) == 1 {
+		return out[0]
+	}
+	return &Expr{Kind: Add, Args: out}
+}
+
+// Minus returns the simplified difference a - b.
+func Minus(a, b *Expr) *Expr {
+	return Plus(a, Times(b, C(-1)))
+}
+
+// Times returns the simplified product of the arguments. Constant factors are distributed over
+// sums, so that linear expressions stay as flat sums.
+func Times(args ...*Expr) *Expr {
+	var factors []*Expr
+	c := 1
+	var add func(e *Expr)
+	add = func(e *Expr) {
+		switch e.Kind {
+		case Const:
+			c *= e.Val
+		case Mul:
+			for _, arg := range e.Args {
+				add(arg)
+			}
+		default:
+			factors = append(factors, e)
+		}
+	}
+	for _, arg := range args {
+		add(arg)
+	}
+	if c == 0 {
+		return zero
+	}
+	if len(factors) == 0 {
+		return C(c)
+	}
+	if len(factors) == 1 && factors[0].Kind == Add && c != 1 {
+		terms := make([]*Expr, len(factors[0].Args))
+		for i, t := range factors[0].Args {
+			terms[i] = Times(t, C(c))
+		}
+		return Plus(terms...)
+	}
+	slices.SortFunc(factors, Compare)
+	if c != 1 {
+		factors = append(factors, C(c))
+	}
+	if len(factors) == 1 {
+		return factors[0]
+	}
+	return &Expr{Kind: Mul, Args: factors}
+}
+
+// Quo returns the simplified truncated quotient a / b.
+func Quo(a, b *Expr) *Expr {
+	if av, ok := a.IsConst(); ok {
+		if bv, ok := b.IsConst(); ok && bv != 0 {
+			return C(av / bv)
+		}
+	}
+	if b.isConst(1) {
+		return a
+	}
+	return &Expr{Kind: Div, Args: []*Expr{a, b}}
+}
+
+// Rem returns the simplified remainder a % b.
+func Rem(a, b *Expr) *Expr {
+	if av, ok := a.IsConst(); ok {
+		if bv, ok := b.IsConst(); ok && bv != 0 {
+			return C(av % bv)
+		}
+	}
+	if b.isConst(1) || b.isConst(-1) {
+		return zero
+	}
+	return &Expr{Kind: Mod, Args: []*Expr{a, b}}
+}
+
+// BitwiseAnd returns the simplified bitwise AND of a and b.
+func BitwiseAnd(a, b *Expr) *Expr {
+	if av, ok := a.IsConst(); ok {
+		if bv, ok := b.IsConst(); ok {
+			return C(av & bv)
+		}
+	}
+	if a.isConst(0) || b.isConst(0) {
+		return zero
+	}
+	if Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return &Expr{Kind: BitAnd, Args: []*Expr{a, b}}
+}
+
+// BitwiseOr returns the simplified bitwise OR of a and b.
+func BitwiseOr(a, b *Expr) *Expr {
+	if av, ok := a.IsConst(); ok {
+		if bv, ok := b.IsConst(); ok {
+			return C(av | bv)
+		}
+	}
+	if a.isConst(0) {
+		return b
+	} else if b.isConst(0) {
+		return a
+	}
+	if Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return &Expr{Kind: BitOr, Args: []*Expr{a, b}}
+}
+
+// Equals returns the simplified comparison a == b.
+func Equals(a, b *Expr) *Expr {
+	// move everything to the left side, unless one side is a constant
+	if b.Kind != Const {
+		if a.Kind == Const {
+			a, b = b, a
+		} else if a.isBool() && b.isBool() {
+			// keep boolean comparisons as they are
+		} else {
+			a, b = Minus(a, b), zero
+		}
+	}
+	if a.Kind == Const && b.Kind == Const {
+		return boolC(a.Val == b.Val)
+	}
+	if Equal(a, b) {
+		return one
+	}
+	if b.Kind == Const {
+		k := b.Val
+		switch {
+		case a.Kind == Add && a.Args[len(a.Args)-1].Kind == Const:
+			// x + c == k  =>  x == k - c
+			n := len(a.Args) - 1
+			return Equals(Plus(a.Args[:n]...), C(k-a.Args[n].Val))
+		case a.isBool() && k == 1:
+			return a
+		case a.isBool() && k != 0:
+			return zero
+		case a.Kind == Ite && a.Args[1].Kind == Const && a.Args[2].Kind == Const:
+			return IfThenElse(a.Args[0], boolC(a.Args[1].Val == k), boolC(a.Args[2].Val == k))
+		case a.Kind == Eq && a.Args[1].isConst(0) && a.Args[0].isBool() && k == 0:
+			// !!x == x for booleans
+			return a.Args[0]
+		case a.Kind == Mod && k == 0:
+			return divides(a.Args[1], a.Args[0])
+		}
+	}
+	return &Expr{Kind: Eq, Args: []*Expr{a, b}}
+}
+
+// divides returns the condition x % m == 0, dropping any terms of x that are multiples of m.
+func divides(m, x *Expr) *Expr {
+	if f, c := factors(m); c == -1 {
+		m = Times(f...) // divisibility doesn't care about the sign
+	}
+	if x.Kind == Add {
+		var keep []*Expr
+		for _, t := range x.Args {
+			if !isMultiple(t, m) {
+				keep = append(keep, t)
+			}
+		}
+		if len(keep) != len(x.Args) {
+			x = Plus(keep...)
+		}
+	} else if isMultiple(x, m) {
+		return one
+	}
+	if x.isConst(0) {
+		return one
+	}
+	if xv, ok := x.IsConst(); ok {
+		if mv, ok := m.IsConst(); ok && mv != 0 {
+			return boolC(xv%mv == 0)
+		}
+	}
+	return &Expr{Kind: Eq, Args: []*Expr{{Kind: Mod, Args: []*Expr{x, m}}, zero}}
+}
+
+// factors splits a product into its non-constant factors and the constant coefficient.
+func factors(e *Expr) ([]*Expr, int) {
+	switch e.Kind {
+	case Const:
+		return nil, e.Val
+	case Mul:
+		n := len(e.Args)
+		if c, ok := e.Args[n-1].IsConst(); ok {
+			return e.Args[:n-1], c
+		}
+		return e.Args, 1
+	}
+	return []*Expr{e}, 1
+}
+
+// isMultiple returns true if t is structurally a multiple of m: all the factors of m are also
+// factors of t.
+func isMultiple(t, m *Expr) bool {
+	tf, tc := factors(t)
+	mf, mc := factors(m)
+	if mc == 0 || tc%mc != 0 || len(mf) == 0 {
+		return false
+	}
+	used := make([]bool, len(tf))
+next:
+	for _, f := range mf {
+		for i, g := range tf {
+			if !used[i] && Equal(f, g) {
+				used[i] = true
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Greater returns the simplified comparison a > b.
+func Greater(a, b *Expr) *Expr {
+	if av, ok := a.IsConst(); ok {
+		if bv, ok := b.IsConst(); ok {
+			return boolC(av > bv)
+		}
+	}
+	if Equal(a, b) {
+		return zero
+	}
+	return &Expr{Kind: Gt, Args: []*Expr{a, b}}
+}
+
+// Not returns the simplified logical negation of a boolean expression.
+func Not(a *Expr) *Expr {
+	return Equals(a, zero)
+}
+
+// AllOf returns the simplified logical conjunction of the arguments.
+func AllOf(args ...*Expr) *Expr {
+	return logic(And, args)
+}
+
+// AnyOf returns the simplified logical disjunction of the arguments.
+func AnyOf(args ...*Expr) *Expr {
+	return logic(Or, args)
+}
+
+func logic(kind Kind, args []*Expr) *Expr {
+	absorb, neutral := 0, 1 // for And
+	if kind == Or {
+		absorb, neutral = 1, 0
+	}
+	var out []*Expr
+	for _, arg := range args {
+		if !arg.isBool() {
+			arg = Not(Not(arg))
+		}
+		switch {
+		case arg.isConst(absorb):
+			return C(absorb)
+		case arg.isConst(neutral):
+			continue
+		case arg.Kind == kind:
+			out = append(out, arg.Args...)
+		default:
+			out = append(out, arg)
+		}
+	}
+	slices.SortFunc(out, Compare)
+	out = slices.CompactFunc(out, Equal)
+	switch len(out) {
+	case 0:
+		return C(neutral)
+	case 1:
+		return out[0]
+	}
+	return &Expr{Kind: kind, Args: out}
+}
+
+// IfThenElse returns the simplified conditional expression.
+func IfThenElse(c, a, b *Expr) *Expr {
+	if cv, ok := c.IsConst(); ok {
+		if cv != 0 {
+			return a
+		}
+		return b
+	}
+	if Equal(a, b) {
+		return a
+	}
+	if !c.isBool() {
+		c = Not(Not(c))
+	}
+	if c.Kind == Eq && c.Args[1].isConst(0) && c.Args[0].isBool() {
+		// !c ? a : b  =>  c ? b : a
+		return IfThenElse(c.Args[0], b, a)
+	}
+	if c.isBool() {
+		switch {
+		case a.isConst(1) && b.isConst(0):
+			return c
+		case a.isConst(0) && b.isConst(1):
+			return Not(c)
+		case b.Kind == Ite && Equal(a, b.Args[1]):
+			// c ? a : (d ? a : x)  =>  (c || d) ? a : x
+			return IfThenElse(AnyOf(c, b.Args[0]), a, b.Args[2])
+		}
+	}
+	return &Expr{Kind: Ite, Args: []*Expr{c, a, b}}
+}
+
+func boolC(b bool) *Expr {
+	if b {
+		return one
+	}
+	return zero
+}
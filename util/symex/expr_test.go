@@ -0,0 +1,124 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	v0, v1 := V(0), V(1)
+	tests := []struct {
+		name string
+		e    *Expr
+		want string
+	}{
+		{name: "fold", e: Plus(C(1), Times(C(2), C(3))), want: "7"},
+		{name: "collect", e: Plus(v0, C(2), v0, C(-2)), want: "(v0 * 2)"},
+		{name: "cancel", e: Minus(Plus(v0, v1), v0), want: "v1"},
+		{name: "distribute", e: Times(Plus(v0, C(1)), C(3)), want: "((v0 * 3) + 3)"},
+		{name: "flatten", e: Times(v1, Times(v0, C(2)), C(2)), want: "(v0 * v1 * 4)"},
+		{name: "eqOffset", e: Equals(Plus(v0, C(3)), C(5)), want: "(v0 == 2)"},
+		{name: "eqBool", e: Equals(Greater(v0, v1), C(1)), want: "(v0 > v1)"},
+		{name: "eqBoolFalse", e: Equals(Greater(v0, v1), C(2)), want: "0"},
+		{name: "eqIte", e: Equals(IfThenElse(v0, C(3), C(4)), C(4)), want: "(v0 == 0)"},
+		{name: "notNot", e: Not(Not(Equals(v0, v1))), want: "((v0 + (v1 * -1)) == 0)"},
+		{name: "iteFlip", e: IfThenElse(Not(Equals(v0, C(1))), v1, C(0)), want: "((v0 == 1) ? 0 : v1)"},
+		{name: "iteBool", e: IfThenElse(Greater(v0, v1), C(1), C(0)), want: "(v0 > v1)"},
+		{name: "iteMerge", e: IfThenElse(Greater(v0, C(1)), C(0), IfThenElse(Greater(v1, C(1)), C(0), v0)), want: "(((v0 > 1) || (v1 > 1)) ? 0 : v0)"},
+		{name: "divides", e: Equals(Rem(Plus(v1, Times(v0, C(5))), v0), C(0)), want: "((v1 % v0) == 0)"},
+		{name: "and", e: AllOf(Greater(v0, v1), C(1), Greater(v0, v1)), want: "(v0 > v1)"},
+		{name: "or", e: AnyOf(Greater(v0, v1), C(1)), want: "1"},
+	}
+	for _, test := range tests {
+		if got := test.e.String(); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRanges(t *testing.T) {
+	ranges := func(id int) Interval {
+		if id == 0 {
+			return Interval{1, 9}
+		}
+		return Interval{0, 1 << 40}
+	}
+	w, z := V(0), V(1)
+	tests := []struct {
+		name string
+		e    *Expr
+		want string
+	}{
+		{name: "eqDisjoint", e: Equals(Plus(Rem(z, C(26)), C(12)), w), want: "0"},
+		{name: "eqOverlap", e: Equals(Plus(Rem(z, C(26)), C(-3)), w), want: "(((v0 * -1) + (v1 % 26)) == 3)"},
+		{name: "modSmall", e: Rem(w, C(10)), want: "v0"},
+		{name: "gt", e: Greater(w, C(0)), want: "1"},
+		{name: "mask", e: BitwiseAnd(Rem(z, C(16)), C(255)), want: "(v1 % 16)"},
+	}
+	for _, test := range tests {
+		if got := Simplify(test.e, ranges).String(); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	v0, v1, v2 := V(0), V(1), V(2)
+	tests := []struct {
+		e, x       *Expr
+		coef, rest string
+		ok         bool
+	}{
+		{e: Plus(Times(v0, C(3)), v1, C(2)), x: v0, coef: "3", rest: "(v1 + 2)", ok: true},
+		{e: Plus(Times(v0, v1), Times(v0, v2)), x: v0, coef: "(v1 + v2)", rest: "0", ok: true},
+		{e: Times(v0, v0), x: v0, ok: false},
+		{e: Plus(Rem(v1, C(26)), C(-7)), x: Rem(v1, C(26)), coef: "1", rest: "-7", ok: true},
+	}
+	for _, test := range tests {
+		coef, rest, ok := Linear(test.e, test.x)
+		if ok != test.ok {
+			t.Errorf("Linear(%v, %v): ok = %t, want %t", test.e, test.x, ok, test.ok)
+		} else if ok && (coef.String() != test.coef || rest.String() != test.rest) {
+			t.Errorf("Linear(%v, %v) = %v, %v; want %s, %s", test.e, test.x, coef, rest, test.coef, test.rest)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	n, k := V(0), 5
+	divSum := Summation(k, C(1), C(1), n, IfThenElse(Equals(Rem(n, V(k)), C(0)), V(k), C(0)))
+	linCount := Count(k, C(0), C(3), n, Equals(Times(V(k), C(2)), C(30)))
+	firstGt := FirstIndex(k, C(1), C(2), Greater(Times(V(k), V(k)), n))
+	firstLin := FirstIndex(k, C(10), C(-1), Greater(C(3), V(k)))
+	tests := []struct {
+		e    *Expr
+		n    int
+		want int
+	}{
+		{e: divSum, n: 12, want: 1 + 2 + 3 + 4 + 6 + 12},
+		{e: divSum, n: 10551339, want: 16137576},
+		{e: linCount, n: 5, want: 0},
+		{e: linCount, n: 6, want: 1},
+		{e: linCount, n: 100, want: 1},
+		{e: firstGt, n: 50, want: 4},
+		{e: firstLin, n: 0, want: 8},
+	}
+	for _, test := range tests {
+		if got := Eval(test.e, []int{test.n}); got != test.want {
+			t.Errorf("Eval(%v, n=%d) = %d, want %d", test.e, test.n, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fis/aoc/util/regvm"
+)
+
+// Analyzer finds and summarizes the loops of a program.
+//
+// A loop is identified by a backwards jump, from the end of the loop to its header. Loops are
+// assumed to occupy the contiguous range of code between the two. A loop can be summarized if
+// each of its registers, on each iteration that doesn't exit the loop, either
+//   - doesn't change (an invariant);
+//   - is incremented by a constant (the induction variable; one is required);
+//   - is overwritten before it's read (a temporary);
+//   - gets an invariant-dependent value added to it (an accumulator); or
+//   - is conditionally set to an invariant value (a flag);
+//
+// and the exit condition depends only on the invariants and the induction variable. Nested loops
+// are summarized from the inside out.
+type Analyzer struct {
+	// Prog is the analyzed program.
+	Prog *Program
+	// Barriers lists addresses that must not be skipped over by a loop summary.
+	Barriers []int
+	// MaxSteps limits the number of instructions executed on a single path of one loop iteration.
+	MaxSteps int
+	// MaxPaths limits the number of paths through a single loop iteration.
+	MaxPaths int
+
+	loopEnd   map[int]int
+	summaries map[int]*Summary
+	nextVar   int
+}
+
+// Summary describes the overall effect of executing a loop.
+type Summary struct {
+	// Header is the address of the loop header, where the summary applies.
+	Header int
+	// Exit is the address execution continues from after the loop.
+	Exit int
+	// Regs holds the values of the registers after the loop, in terms of their values at the
+	// header (the variables 0 .. NumRegs-1).
+	Regs []*Expr
+	// Err is set if the loop could not be summarized. Then Exit and Regs are not valid.
+	Err error
+}
+
+// NewAnalyzer returns an analyzer for the program. The static structure of the code (the loop
+// headers) is found immediately, but loops are only summarized when requested.
+func NewAnalyzer(p *Program) *Analyzer {
+	a := &Analyzer{
+		Prog:      p,
+		MaxSteps:  1000,
+		MaxPaths:  64,
+		loopEnd:   make(map[int]int),
+		summaries: make(map[int]*Summary),
+		nextVar:   p.NumRegs,
+	}
+	for ip := range p.Code {
+		next, err := p.Step(p.Entry(ip))
+		if err != nil {
+			continue
+		}
+		for _, s := range next {
+			if !p.Halted(s) && s.IP <= ip {
+				a.loopEnd[s.IP] = max(a.loopEnd[s.IP], ip)
+			}
+		}
+	}
+	return a
+}
+
+// Headers returns the addresses of all loop headers, mapped to the address of the last instruction
+// of the loop.
+func (a *Analyzer) Headers() map[int]int {
+	return a.loopEnd
+}
+
+// Summary returns the summary of the loop starting at the given header. It returns nil if there's
+// no loop there, or if it has a barrier in it.
+func (a *Analyzer) Summary(header int) *Summary {
+	end, ok := a.loopEnd[header]
+	if !ok {
+		return nil
+	}
+	for _, b := range a.Barriers {
+		if b >= header && b <= end {
+			return nil
+		}
+	}
+	if s, ok := a.summaries[header]; ok {
+		return s
+	}
+	// guard against (impossible) recursion while analyzing
+	a.summaries[header] = &Summary{Header: header, Err: errors.New("recursive loop")}
+	s := &Summary{Header: header}
+	s.Exit, s.Regs, s.Err = a.summarize(header, end)
+	a.summaries[header] = s
+	return s
+}
+
+// Apply returns the state after executing the summarized loop from the given state.
+func (s *Summary) Apply(st *State) *State {
+	m := make(map[int]*Expr, len(st.Regs))
+	for r, e := range st.Regs {
+		m[r] = e
+	}
+	out := &State{Regs: make([]*Expr, len(st.Regs)), IP: s.Exit, Cond: st.Cond}
+	for r, e := range s.Regs {
+		out.Regs[r] = Subst(e, m)
+	}
+	return out
+}
+
+func (a *Analyzer) fresh() int {
+	a.nextVar++
+	return a.nextVar - 1
+}
+
+type path struct {
+	st   *State
+	exit bool
+}
+
+// explore executes one iteration of a loop symbolically, and returns all the paths through it.
+func (a *Analyzer) explore(header, end int) ([]path, error) {
+	p := a.Prog
+	var paths []path
+	work := []*State{p.Entry(header)}
+	for len(work) > 0 {
+		s := work[len(work)-1]
+		work = work[:len(work)-1]
+		for steps := 0; ; steps++ {
+			if steps > a.MaxSteps {
+				return nil, fmt.Errorf("too many steps at %d", s.IP)
+			}
+			if p.Halted(s) || s.IP < header || s.IP > end {
+				paths = append(paths, path{st: s, exit: true})
+				break
+			}
+			if s.IP == header && steps > 0 {
+				paths = append(paths, path{st: s})
+				break
+			}
+			if s.IP != header {
+				if _, ok := a.loopEnd[s.IP]; ok {
+					inner := a.Summary(s.IP)
+					if inner == nil || inner.Err != nil {
+						return nil, fmt.Errorf("inner loop at %d not summarized", s.IP)
+					}
+					s = inner.Apply(s)
+					continue
+				}
+			}
+			next, err := p.Step(s)
+			if err != nil {
+				return nil, err
+			}
+			s = next[0]
+			work = append(work, next[1:]...)
+			if len(paths)+len(work) > a.MaxPaths {
+				return nil, errors.New("too many paths")
+			}
+		}
+	}
+	return paths, nil
+}
+
+// summarize attempts to summarize the loop with the given header.
+func (a *Analyzer) summarize(header, end int) (exit int, regs []*Expr, err error) {
+	paths, err := a.explore(header, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	var cont, exits []*State
+	exit = -1
+	for _, pt := range paths {
+		if !pt.exit {
+			cont = append(cont, pt.st)
+			continue
+		}
+		ip := pt.st.IP
+		if a.Prog.Halted(pt.st) {
+			ip = len(a.Prog.Code)
+		}
+		if exit >= 0 && ip != exit {
+			return 0, nil, fmt.Errorf("multiple exits: %d, %d", exit, ip)
+		}
+		exit = ip
+		exits = append(exits, pt.st)
+	}
+	if len(exits) == 0 {
+		return 0, nil, errors.New("infinite loop")
+	}
+	cond := exitCond(cont, exits)
+	exitRegs := merge(exits)
+	if len(cont) == 0 {
+		return exit, exitRegs, nil
+	}
+	next := merge(cont)
+	for i, e := range next {
+		if c, r, ok := Linear(e, V(i)); ok && c.isConst(1) && r.Kind == Const && r.Val != 0 {
+			if regs, ok := a.loop(i, r, next, exitRegs, cond); ok {
+				return exit, regs, nil
+			}
+		}
+	}
+	return 0, nil, errors.New("unrecognized loop")
+}
+
+// exitCond returns the condition for exiting the loop. If there's a single condition that tells
+// apart the exiting and continuing paths, it's removed from the path conditions.
+func exitCond(cont, exits []*State) *Expr {
+	for _, c := range exits[0].Cond {
+		nc := Not(c)
+		if !all(exits, c) || !all(cont, nc) {
+			continue
+		}
+		for _, s := range exits {
+			s.Cond = remove(s.Cond, c)
+		}
+		for _, s := range cont {
+			s.Cond = remove(s.Cond, nc)
+		}
+		return c
+	}
+	conds := make([]*Expr, len(exits))
+	for i, s := range exits {
+		conds[i] = AllOf(s.Cond...)
+	}
+	return AnyOf(conds...)
+}
+
+func all(states []*State, c *Expr) bool {
+	for _, s := range states {
+		if !containsExpr(s.Cond, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsExpr(list []*Expr, e *Expr) bool {
+	for _, x := range list {
+		if Equal(x, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []*Expr, e *Expr) []*Expr {
+	var out []*Expr
+	for _, x := range list {
+		if !Equal(x, e) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// merge combines the register values of several (mutually exclusive) paths.
+func merge(paths []*State) []*Expr {
+	last := paths[len(paths)-1]
+	regs := append([]*Expr(nil), last.Regs...)
+	for i := len(paths) - 2; i >= 0; i-- {
+		c := AllOf(paths[i].Cond...)
+		for r := range regs {
+			regs[r] = IfThenElse(c, paths[i].Regs[r], regs[r])
+		}
+	}
+	return regs
+}
+
+type regKind int
+
+const (
+	kindInvariant regKind = iota
+	kindInduction
+	kindTemp
+	kindAccum
+	kindFlag
+)
+
+// loop tries to summarize a loop with register i as the induction variable.
+func (a *Analyzer) loop(i int, step *Expr, next, exit []*Expr, cond *Expr) ([]*Expr, bool) {
+	n := len(next)
+	kinds := make([]regKind, n)
+	for r := range kinds {
+		switch {
+		case r == i:
+			kinds[r] = kindInduction
+		case Equal(next[r], V(r)):
+			kinds[r] = kindInvariant
+		default:
+			kinds[r] = -1
+		}
+	}
+	// free checks that the expression only uses invariants, the induction variable and bound
+	// variables
+	free := func(e *Expr) bool {
+		for r := 0; r < n; r++ {
+			if kinds[r] != kindInvariant && kinds[r] != kindInduction && e.Uses(r) {
+				return false
+			}
+		}
+		return true
+	}
+	dead := func(r int) bool {
+		if cond.Uses(r) {
+			return false
+		}
+		for x := range next {
+			if next[x].Uses(r) || (exit[x].Uses(r) && !(x == r && Equal(exit[r], V(r)))) {
+				return false
+			}
+		}
+		return true
+	}
+	deltas := make([]*Expr, n)
+	flagConds := make([]*Expr, n)
+	flagVals := make([]*Expr, n)
+	for r := range kinds {
+		if kinds[r] >= 0 {
+			continue
+		}
+		if dead(r) {
+			kinds[r] = kindTemp
+		} else if d, ok := splitAccum(next[r], r); ok {
+			kinds[r], deltas[r] = kindAccum, d
+		} else if e := next[r]; e.Kind == Ite && Equal(e.Args[2], V(r)) {
+			kinds[r], flagConds[r], flagVals[r] = kindFlag, e.Args[0], e.Args[1]
+		} else if e.Kind == Ite && Equal(e.Args[1], V(r)) {
+			kinds[r], flagConds[r], flagVals[r] = kindFlag, Not(e.Args[0]), e.Args[2]
+		} else {
+			return nil, false
+		}
+	}
+	if !free(cond) {
+		return nil, false
+	}
+	for r, k := range kinds {
+		switch k {
+		case kindAccum:
+			if !free(deltas[r]) {
+				return nil, false
+			}
+		case kindFlag:
+			if !free(flagConds[r]) || !free(flagVals[r]) || flagVals[r].Uses(i) {
+				return nil, false
+			}
+		case kindTemp:
+			if Equal(exit[r], V(r)) && !free(next[r]) {
+				return nil, false
+			}
+		}
+	}
+
+	// after returns the state after j iterations that continue the loop
+	after := func(j *Expr) map[int]*Expr {
+		st := make(map[int]*Expr, n)
+		st[i] = Plus(V(i), Times(j, step))
+		for r, kind := range kinds {
+			switch kind {
+			case kindAccum:
+				k := a.fresh()
+				st[r] = Plus(V(r), Summation(k, V(i), step, j, Subst(deltas[r], map[int]*Expr{i: V(k)})))
+			case kindFlag:
+				k := a.fresh()
+				set := Count(k, V(i), step, j, Subst(flagConds[r], map[int]*Expr{i: V(k)}))
+				st[r] = IfThenElse(Greater(set, zero), flagVals[r], V(r))
+			case kindTemp:
+				if Equal(exit[r], V(r)) {
+					prev := Subst(next[r], map[int]*Expr{i: Plus(V(i), Times(Minus(j, one), step))})
+					st[r] = IfThenElse(Equals(j, zero), V(r), prev)
+				}
+			}
+		}
+		return st
+	}
+
+	k := a.fresh()
+	iters := FirstIndex(k, V(i), step, Subst(cond, map[int]*Expr{i: V(k)}))
+	final := after(iters)
+	regs := make([]*Expr, n)
+	for r := range regs {
+		regs[r] = Subst(exit[r], final)
+	}
+	return regs, true
+}
+
+// splitAccum splits an expression of the form v + d, where v is the variable r, and returns d.
+func splitAccum(e *Expr, r int) (*Expr, bool) {
+	switch {
+	case Equal(e, V(r)):
+		return zero, true
+	case e.Kind == Add:
+		for t, arg := range e.Args {
+			if Equal(arg, V(r)) {
+				rest := append(append([]*Expr(nil), e.Args[:t]...), e.Args[t+1:]...)
+				d := Plus(rest...)
+				return d, !d.Uses(r)
+			}
+		}
+	case e.Kind == Ite && !e.Args[0].Uses(r):
+		a, ok := splitAccum(e.Args[1], r)
+		if !ok {
+			return nil, false
+		}
+		b, ok := splitAccum(e.Args[2], r)
+		if !ok {
+			return nil, false
+		}
+		return IfThenElse(e.Args[0], a, b), true
+	}
+	return nil, false
+}
+
+// Run executes a machine until it halts, replacing the execution of each loop that can be
+// summarized by its summary. The program of the analyzer must match the machine's.
+func Run[E any](m *regvm.Machine[E], a *Analyzer) {
+	RunUntil(m, a, func(*regvm.Machine[E]) bool { return false })
+}
+
+// RunUntil executes a machine like Run, until it halts or the stop function returns true. The stop
+// function is called before each instruction that is actually executed, like the OnStep hook.
+func RunUntil[E any](m *regvm.Machine[E], a *Analyzer, stop func(m *regvm.Machine[E]) bool) {
+	var env []int
+	for !m.Halted() && !stop(m) {
+		if s := a.Summary(m.IP); s != nil && s.Err == nil {
+			if len(env) != len(m.Regs) {
+				env = make([]int, len(m.Regs))
+			}
+			copy(env, m.Regs)
+			for r, e := range s.Regs {
+				m.Regs[r] = Eval(e, env)
+			}
+			m.IP = s.Exit
+			continue
+		}
+		m.Step()
+	}
+}
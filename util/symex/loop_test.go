@@ -0,0 +1,183 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/util/regvm"
+)
+
+type toy = regvm.Machine[struct{}]
+
+// toyISA is a small language in the style of the AoC assembly puzzles.
+var toyISA = &regvm.ISA[struct{}]{
+	Regs: regvm.LetterRegs(26),
+	Ops: []regvm.Op[struct{}]{
+		{Name: "set", Args: "rv", Exec: func(m *toy, a []regvm.Operand) { m.Set(a[0], m.Get(a[1])) }},
+		{Name: "add", Args: "rv", Exec: func(m *toy, a []regvm.Operand) { m.Regs[a[0].Val] += m.Get(a[1]) }},
+		{Name: "mul", Args: "rv", Exec: func(m *toy, a []regvm.Operand) { m.Regs[a[0].Val] *= m.Get(a[1]) }},
+		{Name: "mod", Args: "rv", Exec: func(m *toy, a []regvm.Operand) { m.Regs[a[0].Val] %= m.Get(a[1]) }},
+		{Name: "jnz", Args: "vv", Exec: func(m *toy, a []regvm.Operand) {
+			if m.Get(a[0]) != 0 {
+				m.JumpRel(m.Get(a[1]))
+			}
+		}},
+		{Name: "out", Args: "v", Exec: func(m *toy, a []regvm.Operand) {}},
+	},
+}
+
+var toySem = Semantics{
+	func(s *State, a []regvm.Operand) { s.Set(a[0], s.Get(a[1])) },
+	func(s *State, a []regvm.Operand) { s.Set(a[0], Plus(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *State, a []regvm.Operand) { s.Set(a[0], Times(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *State, a []regvm.Operand) { s.Set(a[0], Rem(s.Get(a[0]), s.Get(a[1]))) },
+	func(s *State, a []regvm.Operand) {
+		s.Jump(IfThenElse(s.Get(a[0]), Plus(C(s.IP), s.Get(a[1])), C(s.IP+1)))
+	},
+	func(s *State, a []regvm.Operand) { s.Unsupported() },
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		name    string
+		prog    string
+		headers []int
+		out     byte
+		inputs  []int
+	}{
+		{
+			name: "triangle",
+			prog: `
+set s 0
+set i 1
+add s i
+add i 1
+set t n
+mul t -1
+add t i
+add t -1
+jnz t -6`,
+			headers: []int{2},
+			out:     's',
+			inputs:  []int{1, 2, 10, 1000},
+		},
+		{
+			name: "countdown",
+			prog: `
+set c 0
+set t n
+mod t 3
+jnz t 2
+add c 1
+add n -1
+jnz n -5`,
+			headers: []int{1},
+			out:     'c',
+			inputs:  []int{1, 3, 10, 100000},
+		},
+		{
+			name: "prime",
+			prog: `
+set f 1
+set d 2
+set t n
+mod t d
+jnz t 2
+set f 0
+add d 1
+set t d
+mul t -1
+add t n
+jnz t -8`,
+			headers: []int{2},
+			out:     'f',
+			inputs:  []int{3, 4, 17, 91, 7919, 1000003},
+		},
+		{
+			name: "divisorSum",
+			prog: `
+set s 0
+set i 1
+set j 1
+set t i
+mul t j
+mul t -1
+add t n
+jnz t 2
+add s i
+add j 1
+set t n
+add t 1
+mul t -1
+add t j
+jnz t -11
+add i 1
+set t n
+add t 1
+mul t -1
+add t i
+jnz t -18`,
+			headers: []int{2, 3},
+			out:     's',
+			inputs:  []int{1, 12, 97, 360, 10551339},
+		},
+	}
+	for _, test := range tests {
+		prog, err := toyISA.Parse(strings.Split(strings.TrimPrefix(test.prog, "\n"), "\n"))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		m := regvm.NewMachine(prog, struct{}{})
+		a := NewAnalyzer(NewProgram(m, toySem))
+		for _, h := range test.headers {
+			if s := a.Summary(h); s == nil || s.Err != nil {
+				t.Errorf("%s: loop at %d not summarized: %+v", test.name, h, s)
+			}
+		}
+		for _, n := range test.inputs {
+			m.Reset()
+			m.Regs['n'-'a'] = n
+			Run(m, a)
+			got := m.Regs[test.out-'a']
+			if n <= 1000 {
+				m.Reset()
+				m.Regs['n'-'a'] = n
+				m.Run()
+				if want := m.Regs[test.out-'a']; got != want {
+					t.Errorf("%s(%d) = %d, want %d", test.name, n, got, want)
+				}
+			}
+			t.Logf("%s(%d) = %d", test.name, n, got)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	prog, err := toyISA.Parse(strings.Split("set i 5\nout i\nadd i -1\njnz i -2", "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := regvm.NewMachine(prog, struct{}{})
+	a := NewAnalyzer(NewProgram(m, toySem))
+	if s := a.Summary(1); s == nil || s.Err == nil {
+		t.Errorf("loop with output summarized: %+v", s)
+	}
+	Run(m, a)
+	if !m.Halted() || m.Regs['i'-'a'] != 0 {
+		t.Errorf("Run did not finish: %v", m.Regs)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symex
+
+import (
+	"fmt"
+	"math"
+)
+
+// Summation returns the simplified sum of body for the bound variable k taking the values
+// lo + j*step for j in [0, n). A closed form is used when the body doesn't depend on k, or when it
+// is a conditional whose condition holds for a single value of k.
+func Summation(k int, lo, step, n, body *Expr) *Expr {
+	if n.isConst(0) || body.isConst(0) {
+		return zero
+	}
+	if !body.Uses(k) {
+		return Times(body, n)
+	}
+	if s := closedSum(k, lo, step, n, body); s != nil {
+		return s
+	}
+	return &Expr{Kind: Sum, Val: k, Args: []*Expr{lo, step, n, body}}
+}
+
+// closedSum handles the case of summing `cond ? v : 0`, where cond contains a linear equation in
+// the bound variable. In that case there's at most one value of the variable that contributes to
+// the sum, unless the coefficient of the variable is zero.
+func closedSum(k int, lo, step, n, body *Expr) *Expr {
+	cond, v := body, one
+	if body.Kind == Ite && body.Args[2].isConst(0) {
+		cond, v = body.Args[0], body.Args[1]
+	} else if body.Kind == Ite && body.Args[1].isConst(0) {
+		cond, v = Not(body.Args[0]), body.Args[2]
+	} else if !body.isBool() {
+		return nil
+	}
+	conds := []*Expr{cond}
+	if cond.Kind == And {
+		conds = cond.Args
+	}
+	for i, eq := range conds {
+		if eq.Kind != Eq {
+			continue
+		}
+		a, b, ok := Linear(Minus(eq.Args[0], eq.Args[1]), V(k))
+		if !ok || a.isConst(0) {
+			continue
+		}
+		// a*(lo + j*step) + b == 0  =>  j = -(a*lo + b) / (a*step)
+		d, q := Times(Plus(Times(a, lo), b), C(-1)), Times(a, step)
+		j := Quo(d, q)
+		sol := Plus(lo, Times(j, step))
+		others := append([]*Expr{divides(q, d), Greater(j, C(-1)), Greater(n, j)}, conds[:i]...)
+		for _, c := range conds[i+1:] {
+			others = append(others, Subst(c, map[int]*Expr{k: sol}))
+		}
+		closed := IfThenElse(AllOf(others...), Subst(v, map[int]*Expr{k: sol}), zero)
+		if _, ok := a.IsConst(); ok {
+			return closed
+		}
+		rest := append([]*Expr{Equals(b, zero)}, conds[:i]...)
+		rest = append(rest, conds[i+1:]...)
+		degenerate := Summation(k, lo, step, n, IfThenElse(AllOf(rest...), v, zero))
+		return IfThenElse(Equals(a, zero), degenerate, closed)
+	}
+	return nil
+}
+
+// Count returns the number of values of the bound variable k, taking the values lo + j*step for j
+// in [0, n), for which cond is nonzero.
+func Count(k int, lo, step, n, cond *Expr) *Expr {
+	return Summation(k, lo, step, n, Not(Not(cond)))
+}
+
+// FirstIndex returns the simplified index j of the first value lo + j*step of the bound variable k
+// for which body is nonzero. A closed form is used for simple linear conditions.
+func FirstIndex(k int, lo, step, body *Expr) *Expr {
+	if bv, ok := body.IsConst(); ok && bv != 0 {
+		return zero
+	}
+	if body.Kind == Gt || body.Kind == Eq {
+		a, b, ok := Linear(Minus(body.Args[0], body.Args[1]), V(k))
+		if ok {
+			// body is a*(lo + j*step) + b > 0 (or == 0), i.e. aStep*j + c > 0
+			aStep, c := Times(a, step), Plus(Times(a, lo), b)
+			av, aConst := aStep.IsConst()
+			switch {
+			case body.Kind == Gt && aConst && av > 0:
+				return IfThenElse(Greater(c, zero), zero, Plus(Quo(Times(c, C(-1)), aStep), one))
+			case body.Kind == Eq && aConst && av != 0:
+				// the program is assumed to terminate, so the division must be exact
+				return Quo(Times(c, C(-1)), aStep)
+			}
+		}
+	}
+	return &Expr{Kind: First, Val: k, Args: []*Expr{lo, step, body}}
+}
+
+// maxVar returns the largest variable number used in the expression, or -1 if there are none.
+func (e *Expr) maxVar() int {
+	m := -1
+	if e.Kind == Var || e.Kind == Sum || e.Kind == First {
+		m = e.Val
+	}
+	for _, arg := range e.Args {
+		m = max(m, arg.maxVar())
+	}
+	return m
+}
+
+// Eval returns the value of the expression, when the variables have the values in vars.
+func Eval(e *Expr, vars []int) int {
+	env := vars
+	if n := e.maxVar() + 1; n > len(vars) {
+		env = make([]int, n)
+		copy(env, vars)
+	}
+	return eval(e, env)
+}
+
+func eval(e *Expr, env []int) int {
+	switch e.Kind {
+	case Const:
+		return e.Val
+	case Var:
+		return env[e.Val]
+	case Add:
+		s := 0
+		for _, arg := range e.Args {
+			s += eval(arg, env)
+		}
+		return s
+	case Mul:
+		p := 1
+		for _, arg := range e.Args {
+			p *= eval(arg, env)
+		}
+		return p
+	case Div:
+		return eval(e.Args[0], env) / eval(e.Args[1], env)
+	case Mod:
+		return eval(e.Args[0], env) % eval(e.Args[1], env)
+	case BitAnd:
+		return eval(e.Args[0], env) & eval(e.Args[1], env)
+	case BitOr:
+		return eval(e.Args[0], env) | eval(e.Args[1], env)
+	case Eq:
+		return b2i(eval(e.Args[0], env) == eval(e.Args[1], env))
+	case Gt:
+		return b2i(eval(e.Args[0], env) > eval(e.Args[1], env))
+	case And:
+		for _, arg := range e.Args {
+			if eval(arg, env) == 0 {
+				return 0
+			}
+		}
+		return 1
+	case Or:
+		for _, arg := range e.Args {
+			if eval(arg, env) != 0 {
+				return 1
+			}
+		}
+		return 0
+	case Ite:
+		if eval(e.Args[0], env) != 0 {
+			return eval(e.Args[1], env)
+		}
+		return eval(e.Args[2], env)
+	case Sum:
+		return evalSum(e, env)
+	case First:
+		return evalFirst(e, env)
+	}
+	panic(fmt.Sprintf("symex: bad expression kind: %d", e.Kind))
+}
+
+func evalSum(e *Expr, env []int) int {
+	k, body := e.Val, e.Args[3]
+	lo, step, n := eval(e.Args[0], env), eval(e.Args[1], env), eval(e.Args[2], env)
+	s := 0
+	if cands, ok := candidates(body, k, env); ok {
+		seen := make(map[int]struct{}, len(cands))
+		for _, x := range cands {
+			if _, dup := seen[x]; dup {
+				continue
+			}
+			seen[x] = struct{}{}
+			if j, ok := index(x, lo, step); ok && j < n {
+				env[k] = x
+				s += eval(body, env)
+			}
+		}
+		return s
+	}
+	for j, x := 0, lo; j < n; j, x = j+1, x+step {
+		env[k] = x
+		s += eval(body, env)
+	}
+	return s
+}
+
+func evalFirst(e *Expr, env []int) int {
+	k, body := e.Val, e.Args[2]
+	lo, step := eval(e.Args[0], env), eval(e.Args[1], env)
+	if cands, ok := candidates(body, k, env); ok {
+		best := math.MaxInt
+		for _, x := range cands {
+			if j, ok := index(x, lo, step); ok && j < best {
+				env[k] = x
+				if eval(body, env) != 0 {
+					best = j
+				}
+			}
+		}
+		if best == math.MaxInt {
+			panic("symex: loop never terminates")
+		}
+		return best
+	}
+	for j, x := 0, lo; ; j, x = j+1, x+step {
+		env[k] = x
+		if eval(body, env) != 0 {
+			return j
+		}
+	}
+}
+
+// index returns the j >= 0 for which x = lo + j*step, if any.
+func index(x, lo, step int) (int, bool) {
+	d := x - lo
+	if step == 0 || d%step != 0 || d/step < 0 {
+		return 0, false
+	}
+	return d / step, true
+}
+
+// candidates returns a set of values for the variable k, such that e can only be nonzero if k is
+// one of them. It returns false if no such (finite) set can be found.
+func candidates(e *Expr, k int, env []int) ([]int, bool) {
+	if !e.Uses(k) {
+		return nil, false
+	}
+	switch e.Kind {
+	case Eq:
+		if m := e.Args[0]; m.Kind == Mod && e.Args[1].isConst(0) && m.Args[1].Kind == Var && m.Args[1].Val == k && !m.Args[0].Uses(k) {
+			t := eval(m.Args[0], env)
+			if t == 0 {
+				return nil, false
+			}
+			return divisors(t), true
+		}
+		a, b, ok := Linear(Minus(e.Args[0], e.Args[1]), V(k))
+		if !ok {
+			return nil, false
+		}
+		av, bv := eval(a, env), eval(b, env)
+		switch {
+		case av == 0 && bv == 0:
+			return nil, false
+		case av == 0 || bv%av != 0:
+			return nil, true
+		}
+		return []int{-bv / av}, true
+	case And, Mul:
+		for _, arg := range e.Args {
+			if c, ok := candidates(arg, k, env); ok {
+				return c, true
+			}
+		}
+	case Or:
+		var all []int
+		for _, arg := range e.Args {
+			c, ok := candidates(arg, k, env)
+			if !ok {
+				return nil, false
+			}
+			all = append(all, c...)
+		}
+		return all, true
+	case Ite:
+		c1, ok := candidates(e.Args[0], k, env)
+		if !ok {
+			c1, ok = candidates(e.Args[1], k, env)
+		}
+		if !ok || e.Args[2].isConst(0) {
+			return c1, ok
+		}
+		c2, ok := candidates(e.Args[2], k, env)
+		return append(c1, c2...), ok
+	case Gt:
+		// x > 0 implies x != 0
+		if e.Args[1].isConst(0) {
+			return candidates(e.Args[0], k, env)
+		}
+	}
+	return nil, false
+}
+
+// divisors returns all the (positive and negative) divisors of t.
+func divisors(t int) []int {
+	if t < 0 {
+		t = -t
+	}
+	var out []int
+	for d := 1; d*d <= t; d++ {
+		if t%d == 0 {
+			out = append(out, d, -d)
+			if d*d != t {
+				out = append(out, t/d, -t/d)
+			}
+		}
+	}
+	return out
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -24,9 +24,11 @@ import (
 	"math"
 	"os"
 	"regexp"
+	"regexp/syntax"
 	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/fis/aoc/util/ix"
 )
@@ -163,13 +165,75 @@ func ScanAllRegexp(r io.Reader, pattern string) ([][]string, error) {
 	for i, line := range lines {
 		parts := re.FindStringSubmatch(line)
 		if parts == nil {
-			return nil, fmt.Errorf("line %q does not match pattern %s", line, pattern)
+			return nil, &ParseError{Line: i + 1, Col: mismatchCol(pattern, line), Msg: fmt.Sprintf("%q does not match pattern %s", line, pattern)}
 		}
 		parsed[i] = parts[1:]
 	}
 	return parsed, nil
 }
 
+// mismatchCol guesses the column where a line stops matching a regular expression, for error
+// messages. If the expression is a concatenation, like the usual line patterns are, it finds the
+// longest run of leading terms that match the start of the line, continuing into a literal term
+// that only partially matches.
+func mismatchCol(pattern, line string) int {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil || re.Op != syntax.OpConcat {
+		return 1
+	}
+	end := 0
+	for k, sub := range re.Sub {
+		prefix := &syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags, Sub: re.Sub[:k+1]}
+		m, err := regexp.Compile(`^(?:` + prefix.String() + `)`)
+		if err != nil {
+			break
+		}
+		loc := m.FindStringIndex(line)
+		if loc == nil {
+			if sub.Op == syntax.OpLiteral && sub.Flags&syntax.FoldCase == 0 {
+				for _, r := range sub.Rune {
+					next, size := utf8.DecodeRuneInString(line[end:])
+					if size == 0 || next != r {
+						break
+					}
+					end += size
+				}
+			}
+			break
+		}
+		end = loc[1]
+	}
+	return end + 1
+}
+
+// ParseError describes malformed input at a specific position. Line and Col are 1-based, and zero
+// if not known: for example, ParseP only knows the column within the string it's given.
+type ParseError struct {
+	Line, Col int
+	Msg       string
+	Err       error // underlying error, if any
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	switch {
+	case e.Line > 0 && e.Col > 0:
+		fmt.Fprintf(&sb, "line %d, col %d: ", e.Line, e.Col)
+	case e.Line > 0:
+		fmt.Fprintf(&sb, "line %d: ", e.Line)
+	case e.Col > 0:
+		fmt.Fprintf(&sb, "col %d: ", e.Col)
+	}
+	sb.WriteString(e.Msg)
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
 // ScanChunks implements a bufio.SplitFunc for scanning paragraphs delimited by a blank line
 // (i.e., two consecutive '\n' bytes).
 func ScanChunks(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -241,12 +305,80 @@ func NextWord(s string) (word, tail string) {
 
 // NextInt parses the leading decimal digits of s as a (nonnegative) decimal number,
 // and returns both the parsed number and the remainder of the input. If there are
-// no decimal digits, `ok` will be false.
-func NextInt(s string) (n int, ok bool, tail string) {
+// no decimal digits, the error is a *ParseError at column 1 of s; LineParser.Int
+// reports the column in the whole line instead.
+func NextInt(s string) (n int, tail string, err error) {
+	if len(s) == 0 || s[0] < '0' || s[0] > '9' {
+		return 0, s, &ParseError{Col: 1, Msg: "expected an integer"}
+	}
 	for len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
-		n, ok, s = n*10+int(s[0]-'0'), true, s[1:]
+		n, s = n*10+int(s[0]-'0'), s[1:]
+	}
+	return n, s, nil
+}
+
+// LineParser reads a line of input from left to right, and reports malformed input as a
+// *ParseError with the position of the problem.
+type LineParser struct {
+	// Line is the 1-based line number used in errors, or 0 if not known.
+	Line int
+	s    string
+	pos  int
+}
+
+// NewLineParser returns a parser for the given line of input, starting from its first column.
+func NewLineParser(line string) *LineParser {
+	return &LineParser{s: line}
+}
+
+// Rest returns the unparsed remainder of the line.
+func (p *LineParser) Rest() string {
+	return p.s[p.pos:]
+}
+
+// Done returns true if the whole line has been parsed.
+func (p *LineParser) Done() bool {
+	return p.pos == len(p.s)
+}
+
+// Errorf returns a *ParseError for the current position.
+func (p *LineParser) Errorf(format string, args ...any) error {
+	return &ParseError{Line: p.Line, Col: p.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Consume skips over the prefix, if the rest of the line starts with it, and returns true.
+// Otherwise the parser is left unchanged, and false is returned.
+func (p *LineParser) Consume(prefix string) bool {
+	if !strings.HasPrefix(p.Rest(), prefix) {
+		return false
+	}
+	p.pos += len(prefix)
+	return true
+}
+
+// Expect is like Consume, except a missing prefix is an error.
+func (p *LineParser) Expect(prefix string) error {
+	if !p.Consume(prefix) {
+		return p.Errorf("expected %q", prefix)
+	}
+	return nil
+}
+
+// Word returns the rest of the line up to the next space character, like NextWord.
+func (p *LineParser) Word() string {
+	word, _ := NextWord(p.Rest())
+	p.pos += len(word)
+	return word
+}
+
+// Int parses a nonnegative decimal number, like NextInt.
+func (p *LineParser) Int() (int, error) {
+	n, tail, err := NextInt(p.Rest())
+	if err != nil {
+		return 0, p.Errorf("expected an integer")
 	}
-	return n, ok, s
+	p.pos = len(p.s) - len(tail)
+	return n, nil
 }
 
 // SortBy is like slices.SortFunc except applies an accessor function to the objects.
@@ -387,15 +519,15 @@ func Bounds(points []P) (min, max P) {
 func ParseP(s string) (P, error) {
 	comma := strings.IndexByte(s, ',')
 	if comma < 0 {
-		return P{}, fmt.Errorf("no , in point: %q", s)
+		return P{}, &ParseError{Col: len(s) + 1, Msg: fmt.Sprintf("no , in point: %q", s)}
 	}
 	x, err := strconv.Atoi(s[:comma])
 	if err != nil {
-		return P{}, fmt.Errorf("bad X coordinate: %q: %w", s[:comma], err)
+		return P{}, &ParseError{Col: 1, Msg: fmt.Sprintf("bad X coordinate: %q", s[:comma]), Err: err}
 	}
 	y, err := strconv.Atoi(s[comma+1:])
 	if err != nil {
-		return P{}, fmt.Errorf("bad Y coordinate: %q: %w", s[comma+1:], err)
+		return P{}, &ParseError{Col: comma + 2, Msg: fmt.Sprintf("bad Y coordinate: %q", s[comma+1:]), Err: err}
 	}
 	return P{x, y}, nil
 }
@@ -15,6 +15,7 @@
 package util
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -89,3 +90,97 @@ func TestScanInts(t *testing.T) {
 		}
 	}
 }
+
+func TestScanAllRegexpError(t *testing.T) {
+	const pattern = `^(\d+)-(\d+) ([a-z]): ([a-z]+)$`
+	tests := []struct {
+		input             string
+		wantLine, wantCol int
+	}{
+		{input: "1-3 a: abcde\n1-3 b cdefg\n", wantLine: 2, wantCol: 6},
+		{input: "1-3 a: abcde\nx-3 b: cdefg\n", wantLine: 2, wantCol: 1},
+		{input: "1-3 a: abcde\n2-9 c: ccccc\n2-9 C: ccccc\n", wantLine: 3, wantCol: 5},
+		{input: "1-3 a: abcde!\n", wantLine: 1, wantCol: 13},
+	}
+	for _, test := range tests {
+		_, err := ScanAllRegexp(strings.NewReader(test.input), pattern)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("ScanAllRegexp(%q): got error %v, want a ParseError", test.input, err)
+		} else if pe.Line != test.wantLine || pe.Col != test.wantCol {
+			t.Errorf("ScanAllRegexp(%q): error at %d:%d, want %d:%d", test.input, pe.Line, pe.Col, test.wantLine, test.wantCol)
+		}
+	}
+}
+
+func TestParsePError(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantCol int
+	}{
+		{input: "123 456", wantCol: 8},
+		{input: "x,456", wantCol: 1},
+		{input: "123,4x6", wantCol: 5},
+	}
+	for _, test := range tests {
+		_, err := ParseP(test.input)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("ParseP(%q): got error %v, want a ParseError", test.input, err)
+		} else if pe.Col != test.wantCol {
+			t.Errorf("ParseP(%q): error at col %d, want %d", test.input, pe.Col, test.wantCol)
+		}
+	}
+}
+
+func TestNextInt(t *testing.T) {
+	if n, tail, err := NextInt("123abc"); n != 123 || tail != "abc" || err != nil {
+		t.Errorf("NextInt(123abc) = %d, %q, %v, want 123, abc, nil", n, tail, err)
+	}
+	var pe *ParseError
+	if _, _, err := NextInt("abc"); !errors.As(err, &pe) || pe.Col != 1 {
+		t.Errorf("NextInt(abc): got error %v, want a ParseError at col 1", err)
+	}
+}
+
+func TestLineParser(t *testing.T) {
+	parse := func(line string) (word string, n int, err error) {
+		p := NewLineParser(line)
+		p.Line = 7
+		if err := p.Expect("Valve "); err != nil {
+			return "", 0, err
+		}
+		word = p.Word()
+		if err := p.Expect(" rate="); err != nil {
+			return "", 0, err
+		}
+		if n, err = p.Int(); err != nil {
+			return "", 0, err
+		}
+		if !p.Consume(";") && !p.Done() {
+			return "", 0, p.Errorf("trailing input: %q", p.Rest())
+		}
+		return word, n, nil
+	}
+	if word, n, err := parse("Valve AA rate=13;"); word != "AA" || n != 13 || err != nil {
+		t.Errorf("parse = %q, %d, %v, want AA, 13, nil", word, n, err)
+	}
+	tests := []struct {
+		input   string
+		wantCol int
+	}{
+		{input: "Value AA rate=13", wantCol: 1},
+		{input: "Valve AA flow=13", wantCol: 9},
+		{input: "Valve AA rate=x", wantCol: 15},
+		{input: "Valve AA rate=13x", wantCol: 17},
+	}
+	for _, test := range tests {
+		_, _, err := parse(test.input)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("parse(%q): got error %v, want a ParseError", test.input, err)
+		} else if pe.Line != 7 || pe.Col != test.wantCol {
+			t.Errorf("parse(%q): error at %d:%d, want 7:%d", test.input, pe.Line, pe.Col, test.wantCol)
+		}
+	}
+}
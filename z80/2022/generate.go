@@ -1,6 +1,6 @@
 // Package y2022 contains Z80 solutions for AoC 2022.
 package y2022
 
-//go:generate -command asm z80asm -I ../lib
+//go:generate -command asm go run ../cmd/z80asm -I ../lib
 //go:generate asm -o day01-1.bin day01-1.z80
 //go:generate asm -o day01-2.bin day01-2.z80
@@ -1,7 +1,7 @@
 // Package y2023 contains Z80 solutions for AoC 2023.
 package y2023
 
-//go:generate -command asm z80asm -I ../lib
+//go:generate -command asm go run ../cmd/z80asm -I ../lib
 //go:generate asm -o day01-1.bin day01-1.z80
 //go:generate asm -o day01-2.bin day01-2.z80
 //go:generate asm -o day02-1.bin day02-1.z80
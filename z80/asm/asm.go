@@ -0,0 +1,649 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package asm implements a Z80 assembler.
+//
+// The accepted syntax is that of the z80asm assembler, or at least the subset
+// of it that the solutions in this directory use. Each line consists of an
+// optional label (terminated by a colon), an optional instruction or directive,
+// and an optional comment starting with a semicolon. Labels starting with a
+// period are local: they are only visible until the next non-local label.
+//
+// Expressions use C-like operators and precedence. `$` is the address of the
+// current statement, and `?label` is 1 if the label has been defined before
+// that point, and 0 otherwise; together with `if` ... `endif` this is used to
+// write include guards.
+//
+// The supported directives are org, equ, defb (db, defm, dm), defw (dw), defs
+// (ds), include, incbin, if, else, endif and end.
+//
+// Instead of including library routines explicitly, a program can leave them
+// undefined and have them linked in automatically from the directories listed
+// in Options.Library. Each non-local label that is not defined with equ starts
+// a routine; the assembler tracks references between routines, and reports
+// their sizes and any library routines that were included but never used.
+package asm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Options holds the optional settings for the assembler.
+type Options struct {
+	// IncludePath lists the directories searched for included files, after the
+	// directory of the including file.
+	IncludePath []string
+	// Library lists directories of library routines. Any label the program
+	// leaves undefined is looked up from the .z80 files in these directories,
+	// and the file defining it is appended to the program.
+	Library []string
+}
+
+// Program is the result of assembling a source file.
+type Program struct {
+	// Code is the assembled binary.
+	Code []byte
+	// Symbols maps all labels to their values. Local labels are qualified with
+	// the non-local label they belong to, as in `solve.loop`.
+	Symbols map[string]int
+	// Listing has an entry for each source line that was assembled.
+	Listing []Line
+	// Routines lists the routines of the program in the order they appear.
+	Routines []Routine
+	// Files lists the source files the program was assembled from, in the
+	// order they were first read, starting from the main file.
+	Files []string
+	// Warnings holds non-fatal problems, like unused library routines.
+	Warnings []*Error
+}
+
+// Line is a listing entry for a single line of source code.
+type Line struct {
+	File  string // name of the source file
+	Num   int    // line number, starting from 1
+	Addr  int    // address of the first byte of the line
+	Bytes []byte // bytes generated by the line (a subslice of Code)
+	Text  string // source text of the line
+}
+
+// Error is an assembly error associated with a source location.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// maxPasses bounds the number of passes made while label values are changing.
+const maxPasses = 16
+
+// maxIncludeDepth bounds the nesting of included files.
+const maxIncludeDepth = 32
+
+// AssembleFile assembles the named source file.
+func AssembleFile(path string, opts *Options) (*Program, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Assemble(path, src, opts)
+}
+
+// Assemble assembles the given source code. The name is used for error
+// messages and to resolve included files.
+//
+// If there are errors, the returned error is a join of one or more *Error
+// values.
+func Assemble(name string, src []byte, opts *Options) (*Program, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	a := &assembler{opts: opts, main: name, files: map[string][]byte{name: src}, order: []string{name}}
+	prev := map[string]int{}
+	for i := 0; i < maxPasses; i++ {
+		p := a.pass(prev)
+		linked, err := a.link(p.undefined())
+		if err != nil {
+			return nil, err
+		}
+		if linked {
+			i = 0
+		} else if maps.Equal(p.syms, prev) {
+			if len(p.errs) > 0 {
+				return nil, errors.Join(p.errs...)
+			}
+			return p.program(), nil
+		}
+		prev = p.syms
+	}
+	return nil, fmt.Errorf("%s: label values did not settle after %d passes", name, maxPasses)
+}
+
+// assembler holds the state shared between passes.
+type assembler struct {
+	opts   *Options
+	main   string              // name of the main source file
+	files  map[string][]byte   // contents of all source files read so far
+	index  map[string][]string // library files defining each label
+	linked []string            // library files linked in so far
+	order  []string            // names of files in the order they were read
+}
+
+func (a *assembler) readFile(path string) ([]byte, error) {
+	if data, ok := a.files[path]; ok {
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a.files[path] = data
+	a.order = append(a.order, path)
+	return data, nil
+}
+
+// findFile resolves the name of an included file.
+func (a *assembler) findFile(from, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return name, nil
+	}
+	dirs := append([]string{filepath.Dir(from)}, a.opts.IncludePath...)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if _, ok := a.files[path]; ok {
+			return path, nil
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("file not found: %s", name)
+}
+
+func (a *assembler) pass(prev map[string]int) *pass {
+	p := &pass{
+		a:        a,
+		prev:     prev,
+		syms:     map[string]int{},
+		undef:    map[string]bool{},
+		owner:    map[string]int{},
+		routines: []Routine{{File: a.main}},
+		refs:     map[int][]string{},
+	}
+	p.source(a.main, a.files[a.main])
+	if len(p.conds) > 0 {
+		p.errorf("missing endif")
+	}
+	for _, path := range a.linked {
+		p.source(path, a.files[path])
+	}
+	return p
+}
+
+// pass holds the state of a single pass over the source code.
+type pass struct {
+	a     *assembler
+	prev  map[string]int // symbol values from the previous pass
+	syms  map[string]int // symbols defined so far in this pass
+	scope string         // most recent non-local label
+	addr  int            // current address
+	start int            // address of the current statement
+	code  []byte
+	list  []listEntry
+	conds []cond
+	depth int
+	ended bool
+	file  string
+	line  int
+	errs  []error
+
+	undef    map[string]bool  // labels that were not found when looked up
+	owner    map[string]int   // routine containing each (non-equ) label
+	routines []Routine        // routines defined so far
+	refs     map[int][]string // labels referenced by each routine
+}
+
+type listEntry struct {
+	file string
+	num  int
+	addr int
+	off  int
+	size int
+	text string
+}
+
+// cond is the state of an if ... else ... endif block.
+type cond struct {
+	outer bool // whether the enclosing block is active
+	taken bool // whether the if condition was true
+	else_ bool // whether the else directive has been seen
+}
+
+func (p *pass) active() bool {
+	if len(p.conds) == 0 {
+		return true
+	}
+	c := p.conds[len(p.conds)-1]
+	return c.outer && c.taken != c.else_
+}
+
+func (p *pass) errorf(format string, args ...any) {
+	p.errs = append(p.errs, &Error{File: p.file, Line: p.line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (p *pass) source(name string, src []byte) {
+	if p.depth >= maxIncludeDepth {
+		p.errorf("include nesting too deep")
+		return
+	}
+	p.depth++
+	savedFile, savedLine := p.file, p.line
+	lines := strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+	for i, text := range lines {
+		if p.ended {
+			break
+		}
+		p.file, p.line = name, i+1
+		if err := p.statement(strings.TrimSuffix(text, "\r")); err != nil {
+			p.errorf("%v", err)
+		}
+	}
+	p.file, p.line = savedFile, savedLine
+	p.depth--
+}
+
+func (p *pass) statement(text string) error {
+	toks, err := lex(text)
+	if err != nil {
+		if p.active() {
+			return err
+		}
+		return nil
+	}
+
+	var label string
+	if len(toks) >= 2 && toks[0].kind == tIdent && (toks[1].is(":") || isKeyword(toks[1], "equ")) {
+		label = toks[0].text
+		if toks[1].is(":") {
+			toks = toks[1:]
+		}
+		toks = toks[1:]
+	}
+	var mnemonic string
+	if len(toks) > 0 {
+		if toks[0].kind != tIdent {
+			return fmt.Errorf("expected instruction, got %v", toks[0])
+		}
+		mnemonic, toks = strings.ToLower(toks[0].text), toks[1:]
+	}
+
+	p.start = p.addr
+	switch mnemonic {
+	case "if", "else", "endif":
+		if label != "" {
+			return fmt.Errorf("label not allowed on %s", mnemonic)
+		}
+		return p.conditional(mnemonic, toks)
+	}
+	if !p.active() {
+		return nil
+	}
+
+	entry := len(p.list)
+	p.list = append(p.list, listEntry{file: p.file, num: p.line, addr: p.addr, off: len(p.code), text: text})
+	defer func() {
+		if mnemonic != "include" {
+			p.list[entry].size = len(p.code) - p.list[entry].off
+		}
+	}()
+
+	if label != "" {
+		v := p.addr
+		if mnemonic == "equ" {
+			if v, err = eval(toks, p); err != nil {
+				return err
+			}
+		}
+		if err := p.define(label, v, mnemonic != "equ"); err != nil {
+			return err
+		}
+	}
+	if mnemonic == "" {
+		return nil
+	}
+
+	args, err := splitArgs(toks)
+	if err != nil {
+		return err
+	}
+	switch mnemonic {
+	case "equ":
+		if label == "" {
+			return fmt.Errorf("equ without a label")
+		}
+		return nil
+	case "org":
+		if len(args) != 1 {
+			return fmt.Errorf("org needs one argument")
+		}
+		v, err := eval(args[0], p)
+		if err != nil {
+			return err
+		}
+		p.addr = v
+		return nil
+	case "defb", "db", "defm", "dm":
+		return p.defb(args)
+	case "defw", "dw":
+		for _, arg := range args {
+			v := p.value(arg, -0x8000, 0xffff)
+			p.emit(byte(v), byte(v>>8))
+		}
+		return nil
+	case "defs", "ds":
+		return p.defs(args)
+	case "include", "incbin":
+		if len(args) != 1 || len(args[0]) != 1 || args[0][0].kind != tStr {
+			return fmt.Errorf("%s needs a quoted file name", mnemonic)
+		}
+		path, err := p.a.findFile(p.file, args[0][0].text)
+		if err != nil {
+			return err
+		}
+		data, err := p.a.readFile(path)
+		if err != nil {
+			return err
+		}
+		if mnemonic == "incbin" {
+			p.emit(data...)
+		} else {
+			p.source(path, data)
+		}
+		return nil
+	case "end":
+		p.ended = true
+		return nil
+	}
+
+	ops := make([]operand, len(args))
+	for i, arg := range args {
+		ops[i] = parseOperand(arg)
+	}
+	return p.instruction(mnemonic, ops)
+}
+
+func (p *pass) conditional(directive string, args []token) error {
+	switch directive {
+	case "if":
+		c := cond{outer: p.active()}
+		if c.outer {
+			v, err := eval(args, p)
+			if err != nil {
+				return err
+			}
+			c.taken = v != 0
+		}
+		p.conds = append(p.conds, c)
+		return nil
+	case "else", "endif":
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected arguments to %s", directive)
+		}
+		if len(p.conds) == 0 {
+			return fmt.Errorf("%s without if", directive)
+		}
+		c := &p.conds[len(p.conds)-1]
+		if directive == "endif" {
+			p.conds = p.conds[:len(p.conds)-1]
+		} else if c.else_ {
+			return fmt.Errorf("duplicate else")
+		} else {
+			c.else_ = true
+		}
+	}
+	return nil
+}
+
+func (p *pass) defb(args [][]token) error {
+	for _, arg := range args {
+		if len(arg) == 1 && arg[0].kind == tStr {
+			p.emit([]byte(arg[0].text)...)
+			continue
+		}
+		p.emit(byte(p.value(arg, -0x80, 0xff)))
+	}
+	return nil
+}
+
+func (p *pass) defs(args [][]token) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("defs needs one or two arguments")
+	}
+	n, err := eval(args[0], p)
+	if err != nil {
+		return err
+	}
+	if n < 0 || p.addr+n > 0x10000 {
+		return fmt.Errorf("bad defs size: %d", n)
+	}
+	fill := 0
+	if len(args) == 2 {
+		fill = p.value(args[1], -0x80, 0xff)
+	}
+	for i := 0; i < n; i++ {
+		p.emit(byte(fill))
+	}
+	return nil
+}
+
+// splitArgs splits the tokens of an argument list at top-level commas.
+func splitArgs(toks []token) ([][]token, error) {
+	if len(toks) == 0 {
+		return nil, nil
+	}
+	var args [][]token
+	depth, start := 0, 0
+	for i, t := range toks {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case t.is(",") && depth == 0:
+			args = append(args, toks[start:i])
+			start = i + 1
+		}
+	}
+	args = append(args, toks[start:])
+	for _, arg := range args {
+		if len(arg) == 0 {
+			return nil, fmt.Errorf("empty argument")
+		}
+	}
+	return args, nil
+}
+
+func isKeyword(t token, kw string) bool {
+	return t.kind == tIdent && strings.EqualFold(t.text, kw)
+}
+
+// qualify returns the full name of a label, taking local labels into account.
+func (p *pass) qualify(name string) string {
+	if strings.HasPrefix(name, ".") {
+		return p.scope + name
+	}
+	return name
+}
+
+// define defines a label. Address labels (as opposed to equ) that are not
+// local also start a new routine.
+func (p *pass) define(name string, v int, addr bool) error {
+	full := p.qualify(name)
+	local := strings.HasPrefix(name, ".")
+	if !local {
+		p.scope = name
+	}
+	if _, ok := p.syms[full]; ok {
+		return fmt.Errorf("label %s redefined", full)
+	}
+	p.syms[full] = v
+	if addr {
+		if !local {
+			p.routines = append(p.routines, Routine{Name: full, File: p.file, Line: p.line, Addr: v})
+		}
+		p.owner[full] = len(p.routines) - 1
+	}
+	return nil
+}
+
+func (p *pass) here() int {
+	return p.start
+}
+
+func (p *pass) lookup(name string) (int, error) {
+	full := p.qualify(name)
+	cur := len(p.routines) - 1
+	p.refs[cur] = append(p.refs[cur], full)
+	if v, ok := p.syms[full]; ok {
+		return v, nil
+	}
+	if v, ok := p.prev[full]; ok {
+		return v, nil
+	}
+	p.undef[full] = true
+	return 0, fmt.Errorf("undefined label: %s", full)
+}
+
+// undefined returns the labels that were referenced but not defined anywhere
+// during the pass, in sorted order.
+func (p *pass) undefined() []string {
+	var names []string
+	for name := range p.undef {
+		if _, ok := p.syms[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *pass) defined(name string) bool {
+	_, ok := p.syms[p.qualify(name)]
+	return ok
+}
+
+// value evaluates an expression that must fall within the given range. Errors
+// are recorded, and 0 returned, so that the instruction size is unaffected.
+func (p *pass) value(toks []token, lo, hi int) int {
+	v, err := eval(toks, p)
+	if err != nil {
+		p.errorf("%v", err)
+		return 0
+	}
+	if v < lo || v > hi {
+		p.errorf("value out of range: %d", v)
+		return 0
+	}
+	return v
+}
+
+func (p *pass) emit(b ...byte) {
+	if p.addr+len(b) > 0x10000 {
+		p.errorf("program does not fit in memory")
+	}
+	p.code = append(p.code, b...)
+	p.addr += len(b)
+	p.routines[len(p.routines)-1].Size += len(b)
+}
+
+func (p *pass) program() *Program {
+	prog := &Program{Code: p.code, Symbols: p.syms, Files: p.a.sourceFiles()}
+	prog.Routines, prog.Warnings = p.routineGraph()
+	for _, e := range p.list {
+		prog.Listing = append(prog.Listing, Line{
+			File:  e.file,
+			Num:   e.num,
+			Addr:  e.addr,
+			Bytes: p.code[e.off : e.off+e.size : e.off+e.size],
+			Text:  e.text,
+		})
+	}
+	return prog
+}
+
+// WriteSymbols writes the symbol table in the z80asm label file format, one
+// `name: equ $xxxx` line per label, sorted by value.
+func (prog *Program) WriteSymbols(w io.Writer) error {
+	names := make([]string, 0, len(prog.Symbols))
+	for name := range prog.Symbols {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		vi, vj := prog.Symbols[names[i]], prog.Symbols[names[j]]
+		return vi < vj || vi == vj && names[i] < names[j]
+	})
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bw, "%s:\tequ $%04x\n", name, prog.Symbols[name]&0xffff)
+	}
+	return bw.Flush()
+}
+
+// listBytes is the number of bytes shown on each line of the listing.
+const listBytes = 4
+
+// WriteListing writes a listing showing the address and generated bytes of
+// each assembled source line. Long data directives are abbreviated.
+func (prog *Program) WriteListing(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	file := ""
+	for _, l := range prog.Listing {
+		if l.File != file {
+			fmt.Fprintf(bw, "# File %s\n", l.File)
+			file = l.File
+		}
+		fmt.Fprintf(bw, "%04x  %-*s  %s\n", l.Addr&0xffff, 3*listBytes, hexBytes(l.Bytes), l.Text)
+		if len(l.Bytes) > 2*listBytes {
+			fmt.Fprintf(bw, "%04x  %-*s\n", (l.Addr+listBytes)&0xffff, 3*listBytes, "...")
+		} else if len(l.Bytes) > listBytes {
+			fmt.Fprintf(bw, "%04x  %s\n", (l.Addr+listBytes)&0xffff, hexBytes(l.Bytes[listBytes:]))
+		}
+	}
+	return bw.Flush()
+}
+
+func hexBytes(b []byte) string {
+	var s strings.Builder
+	for i, c := range b {
+		if i == listBytes {
+			break
+		}
+		if i > 0 {
+			s.WriteByte(' ')
+		}
+		fmt.Fprintf(&s, "%02x", c)
+	}
+	return s.String()
+}
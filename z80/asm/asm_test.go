@@ -0,0 +1,404 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestInstructions(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []byte
+	}{
+		{"ld a, b", []byte{0x78}},
+		{"ld (hl), 5", []byte{0x36, 0x05}},
+		{"ld (ix+3), 1", []byte{0xdd, 0x36, 0x03, 0x01}},
+		{"ld (iy-2), a", []byte{0xfd, 0x77, 0xfe}},
+		{"ld a, iyl", []byte{0xfd, 0x7d}},
+		{"ld ixh, b", []byte{0xdd, 0x60}},
+		{"ld h, (ix+1)", []byte{0xdd, 0x66, 0x01}},
+		{"ld bc, 0x1234", []byte{0x01, 0x34, 0x12}},
+		{"ld ix, 0x1234", []byte{0xdd, 0x21, 0x34, 0x12}},
+		{"ld hl, (0x1234)", []byte{0x2a, 0x34, 0x12}},
+		{"ld de, (0x1234)", []byte{0xed, 0x5b, 0x34, 0x12}},
+		{"ld (0x1234), sp", []byte{0xed, 0x73, 0x34, 0x12}},
+		{"ld (0x1234), iy", []byte{0xfd, 0x22, 0x34, 0x12}},
+		{"ld a, (0x1234)", []byte{0x3a, 0x34, 0x12}},
+		{"ld a, (1+2)*3", []byte{0x3e, 0x09}},
+		{"ld (de), a", []byte{0x12}},
+		{"ld a, i", []byte{0xed, 0x57}},
+		{"ld r, a", []byte{0xed, 0x4f}},
+		{"ld sp, ix", []byte{0xdd, 0xf9}},
+		{"add a, (ix+5)", []byte{0xdd, 0x86, 0x05}},
+		{"add l", []byte{0x85}},
+		{"sub 10", []byte{0xd6, 0x0a}},
+		{"cp '9'+1", []byte{0xfe, 0x3a}},
+		{"adc hl, de", []byte{0xed, 0x5a}},
+		{"sbc hl, bc", []byte{0xed, 0x42}},
+		{"add ix, sp", []byte{0xdd, 0x39}},
+		{"add iy, iy", []byte{0xfd, 0x29}},
+		{"inc (iy+2)", []byte{0xfd, 0x34, 0x02}},
+		{"dec sp", []byte{0x3b}},
+		{"inc ixl", []byte{0xdd, 0x2c}},
+		{"push af", []byte{0xf5}},
+		{"pop iy", []byte{0xfd, 0xe1}},
+		{"ex af, af'", []byte{0x08}},
+		{"ex (sp), ix", []byte{0xdd, 0xe3}},
+		{"ex de, hl", []byte{0xeb}},
+		{"rl (ix+1)", []byte{0xdd, 0xcb, 0x01, 0x16}},
+		{"srl h", []byte{0xcb, 0x3c}},
+		{"sll a", []byte{0xcb, 0x37}},
+		{"bit 0, (hl)", []byte{0xcb, 0x46}},
+		{"set 7, (iy+0)", []byte{0xfd, 0xcb, 0x00, 0xfe}},
+		{"res 3, b", []byte{0xcb, 0x98}},
+		{"jp (hl)", []byte{0xe9}},
+		{"jp (ix)", []byte{0xdd, 0xe9}},
+		{"jp nz, 0x1234", []byte{0xc2, 0x34, 0x12}},
+		{"jp m, 0", []byte{0xfa, 0x00, 0x00}},
+		{"call c, 0x10", []byte{0xdc, 0x10, 0x00}},
+		{"ret pe", []byte{0xe8}},
+		{"rst 0x38", []byte{0xff}},
+		{"in a, (1)", []byte{0xdb, 0x01}},
+		{"in e, (c)", []byte{0xed, 0x58}},
+		{"in f, (c)", []byte{0xed, 0x70}},
+		{"out (1), a", []byte{0xd3, 0x01}},
+		{"out (c), h", []byte{0xed, 0x61}},
+		{"out (c), 0", []byte{0xed, 0x71}},
+		{"im 2", []byte{0xed, 0x5e}},
+		{"ldir", []byte{0xed, 0xb0}},
+		{"jr $", []byte{0x18, 0xfe}},
+		{"jr nc, $+5", []byte{0x30, 0x03}},
+		{"djnz $-2", []byte{0x10, 0xfc}},
+		{"defb \"ab\", 0, 'c'", []byte{'a', 'b', 0, 'c'}},
+		{"defw 0x1234, -1", []byte{0x34, 0x12, 0xff, 0xff}},
+		{"defs 3, 0xaa", []byte{0xaa, 0xaa, 0xaa}},
+	}
+	for _, test := range tests {
+		prog, err := Assemble("test.z80", []byte("  "+test.src+"\n"), nil)
+		if err != nil {
+			t.Errorf("Assemble(%q): %v", test.src, err)
+			continue
+		}
+		if diff := cmp.Diff(test.want, prog.Code); diff != "" {
+			t.Errorf("Assemble(%q) mismatch (-want +got):\n%s", test.src, diff)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"ld (hl), (hl)", "invalid operands"},
+		{"ld h, ixl", "invalid operands"},
+		{"ld ixh, iyl", "invalid operands"},
+		{"sla ixh", "invalid operands"},
+		{"jr pe, 0", "invalid operands"},
+		{"ld a, (ix+200)", "value out of range: 200"},
+		{"jr 200", "relative jump out of range: 198"},
+		{"frob a", "unknown instruction: frob"},
+		{"jp nowhere", "undefined label: nowhere"},
+		{"x: nop\nx: nop", "test.z80:2: label x redefined"},
+		{"if 1\nnop", "missing endif"},
+		{"endif", "endif without if"},
+	}
+	for _, test := range tests {
+		_, err := Assemble("test.z80", []byte(test.src+"\n"), nil)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Assemble(%q) = %v, want error containing %q", test.src, err, test.want)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	src := `
+  jp main
+if 1-?helper
+helper:
+.loop:
+  djnz .loop
+  ret
+endif
+if 1-?helper
+helper:
+  nop
+endif
+main:
+  call helper
+.loop:
+  jr .loop
+  jr helper.loop
+value: equ end-main
+  defs (4-($&3))&3
+end:
+`
+	prog, err := Assemble("test.z80", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xc3, 0x06, 0x00, // jp main
+		0x10, 0xfe, // helper.loop: djnz helper.loop
+		0xc9,             // ret
+		0xcd, 0x03, 0x00, // main: call helper
+		0x18, 0xfe, // main.loop: jr main.loop
+		0x18, 0xf6, // jr helper.loop
+		0x00, 0x00, 0x00, // defs
+	}
+	if diff := cmp.Diff(want, prog.Code); diff != "" {
+		t.Errorf("code mismatch (-want +got):\n%s", diff)
+	}
+	wantSyms := map[string]int{"helper": 3, "helper.loop": 3, "main": 6, "main.loop": 9, "value": 10, "end": 16}
+	if diff := cmp.Diff(wantSyms, prog.Symbols); diff != "" {
+		t.Errorf("symbols mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadBack(t *testing.T) {
+	src := `
+start:
+  ld hl, data
+.loop:
+  ld a, (hl)
+  halt
+data:
+  defb 1, 2, 3, 4, 5, 6
+  defs 10
+`
+	prog, err := Assemble("test.z80", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var syms, list bytes.Buffer
+	if err := prog.WriteSymbols(&syms); err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.WriteListing(&list); err != nil {
+		t.Fatal(err)
+	}
+
+	gotSyms, err := ReadSymbols(&syms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(prog.Symbols, gotSyms); diff != "" {
+		t.Errorf("ReadSymbols mismatch (-want +got):\n%s", diff)
+	}
+
+	gotList, err := ReadListing(&list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := slices.Clone(prog.Listing)
+	for i := range want {
+		want[i].Num = 0
+		if len(want[i].Bytes) > 2*listBytes {
+			want[i].Bytes = want[i].Bytes[:listBytes]
+		}
+	}
+	if diff := cmp.Diff(want, gotList, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("ReadListing mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEval(t *testing.T) {
+	syms := map[string]int{"hl": 0x1234, "main.loop": 10}
+	lookup := func(name string) (int, bool) {
+		v, ok := syms[name]
+		return v, ok
+	}
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"hl & 0xff", 0x34},
+		{"main.loop + $", 110},
+		{"?main.loop + ?foo", 1},
+		{"'a' - 1", 0x60},
+	}
+	for _, test := range tests {
+		if got, err := Eval(test.expr, 100, lookup); err != nil || got != test.want {
+			t.Errorf("Eval(%q) = %d, %v; want %d", test.expr, got, err, test.want)
+		}
+	}
+	if _, err := Eval("foo", 0, lookup); err == nil || !strings.Contains(err.Error(), "undefined symbol: foo") {
+		t.Errorf("Eval(foo) = %v, want undefined symbol", err)
+	}
+}
+
+func TestLinking(t *testing.T) {
+	dir := t.TempDir()
+	lib := map[string]string{
+		"a.z80": "fa:\n  call fb\n  ret\n",
+		"b.z80": "fb:\n  ld a, bv\n  ret\nbv: equ 7\n",
+		"c.z80": "fc:\n  ret\n",
+	}
+	for name, src := range lib {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Routines included from outside the library are part of the program, and
+	// never reported as unused.
+	incDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(incDir, "d.z80"), []byte("fd:\n  ret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `
+main:
+  call fa
+  halt
+include 'c.z80'
+include 'd.z80'
+`
+	prog, err := Assemble("main.z80", []byte(src), &Options{IncludePath: []string{dir, incDir}, Library: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xcd, 0x06, 0x00, // main: call fa
+		0x76,             // halt
+		0xc9,             // fc: ret
+		0xc9,             // fd: ret
+		0xcd, 0x0a, 0x00, // fa: call fb
+		0xc9,       // ret
+		0x3e, 0x07, // fb: ld a, bv
+		0xc9, // ret
+	}
+	if diff := cmp.Diff(want, prog.Code); diff != "" {
+		t.Errorf("code mismatch (-want +got):\n%s", diff)
+	}
+	a, b, c := filepath.Join(dir, "a.z80"), filepath.Join(dir, "b.z80"), filepath.Join(dir, "c.z80")
+	d := filepath.Join(incDir, "d.z80")
+	wantRoutines := []Routine{
+		{Name: "main", File: "main.z80", Line: 2, Addr: 0, Size: 4, Refs: []string{"fa"}},
+		{Name: "fc", File: c, Line: 1, Addr: 4, Size: 1},
+		{Name: "fd", File: d, Line: 1, Addr: 5, Size: 1},
+		{Name: "fa", File: a, Line: 1, Addr: 6, Size: 4, Refs: []string{"fb"}},
+		{Name: "fb", File: b, Line: 1, Addr: 10, Size: 3},
+	}
+	if diff := cmp.Diff(wantRoutines, prog.Routines); diff != "" {
+		t.Errorf("routines mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"main.z80", c, d, a, b}, prog.Files); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+	wantWarnings := []*Error{{File: c, Line: 1, Msg: "unused routine: fc"}}
+	if diff := cmp.Diff(wantWarnings, prog.Warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLinkingGuarded checks that library files wrapped in include guards link
+// in alongside the same files included by hand, without being added twice.
+func TestLinkingGuarded(t *testing.T) {
+	dir := t.TempDir()
+	lib := map[string]string{
+		"a.z80": "if 1-?fa\n\ninclude 'b.z80'\n\nfa:\n  call fb\n  ret\n\nendif\n",
+		"b.z80": "if 1-?fb\n\nfb:\n  ret\n\nendif\n",
+	}
+	for name, src := range lib {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := `
+main:
+  call fa
+  call fb
+  halt
+include 'b.z80'
+`
+	prog, err := Assemble("main.z80", []byte(src), &Options{IncludePath: []string{dir}, Library: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xcd, 0x08, 0x00, // main: call fa
+		0xcd, 0x07, 0x00, // call fb
+		0x76,             // halt
+		0xc9,             // fb: ret
+		0xcd, 0x07, 0x00, // fa: call fb
+		0xc9, // ret
+	}
+	if diff := cmp.Diff(want, prog.Code); diff != "" {
+		t.Errorf("code mismatch (-want +got):\n%s", diff)
+	}
+	if len(prog.Warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", prog.Warnings)
+	}
+}
+
+// TestLibrary checks that each library routine assembles on its own, with
+// its dependencies linked in.
+func TestLibrary(t *testing.T) {
+	sources, err := filepath.Glob("../lib/*.z80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{Library: []string{"../lib"}}
+	for _, src := range sources {
+		prog, err := AssembleFile(src, opts)
+		if err != nil {
+			t.Errorf("%s: %v", src, err)
+			continue
+		}
+		for _, w := range prog.Warnings {
+			t.Errorf("%s: %v", src, w)
+		}
+	}
+}
+
+// TestSolutions checks that the checked-in binaries are up to date with the
+// solution sources, and that the sources link without warnings.
+func TestSolutions(t *testing.T) {
+	sources, err := filepath.Glob("../20*/*.z80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) == 0 {
+		t.Fatal("no solutions found")
+	}
+	opts := &Options{IncludePath: []string{"../lib"}, Library: []string{"../lib"}}
+	for _, src := range sources {
+		prog, err := AssembleFile(src, opts)
+		if err != nil {
+			t.Errorf("%s: %v", src, err)
+			continue
+		}
+		for _, w := range prog.Warnings {
+			t.Errorf("%s: %v", src, w)
+		}
+		want, err := os.ReadFile(strings.TrimSuffix(src, ".z80") + ".bin")
+		if err != nil {
+			t.Errorf("%s: %v", src, err)
+			continue
+		}
+		if diff := cmp.Diff(want, prog.Code); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", src, diff)
+		}
+	}
+}
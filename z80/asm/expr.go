@@ -0,0 +1,234 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// symbols is the view of the symbol table that expression evaluation needs.
+type symbols interface {
+	// here returns the value of `$`, the address of the current statement.
+	here() int
+	// lookup returns the value of the named symbol.
+	lookup(name string) (int, error)
+	// defined tells whether the named symbol has been defined so far.
+	defined(name string) bool
+}
+
+// binOps lists the binary operators by precedence level, loosest first.
+var binOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "=", "!="},
+	{"<", "<=", ">", ">="},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// eval evaluates an expression consisting of the given tokens.
+func eval(toks []token, syms symbols) (int, error) {
+	p := exprParser{toks: toks, syms: syms}
+	v, err := p.binary(0)
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(toks) {
+		return 0, fmt.Errorf("unexpected %v in expression", toks[p.pos])
+	}
+	return v, nil
+}
+
+type exprParser struct {
+	toks []token
+	pos  int
+	syms symbols
+}
+
+func (p *exprParser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *exprParser) binary(level int) (int, error) {
+	if level == len(binOps) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tOp || !slices.Contains(binOps[level], t.text) {
+			return x, nil
+		}
+		p.pos++
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		if x, err = apply(t.text, x, y); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func apply(op string, x, y int) (int, error) {
+	switch op {
+	case "||":
+		return b2i(x != 0 || y != 0), nil
+	case "&&":
+		return b2i(x != 0 && y != 0), nil
+	case "|":
+		return x | y, nil
+	case "^":
+		return x ^ y, nil
+	case "&":
+		return x & y, nil
+	case "==", "=":
+		return b2i(x == y), nil
+	case "!=":
+		return b2i(x != y), nil
+	case "<":
+		return b2i(x < y), nil
+	case "<=":
+		return b2i(x <= y), nil
+	case ">":
+		return b2i(x > y), nil
+	case ">=":
+		return b2i(x >= y), nil
+	case "<<":
+		return x << uint(y&31), nil
+	case ">>":
+		return x >> uint(y&31), nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/", "%":
+		if y == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		if op == "/" {
+			return x / y, nil
+		}
+		return x % y, nil
+	}
+	panic("unknown operator: " + op)
+}
+
+func (p *exprParser) unary() (int, error) {
+	t, ok := p.peek()
+	if !ok {
+		return 0, fmt.Errorf("missing operand in expression")
+	}
+	p.pos++
+	switch {
+	case t.is("-"), t.is("+"), t.is("~"), t.is("!"):
+		x, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch t.text {
+		case "-":
+			return -x, nil
+		case "~":
+			return ^x, nil
+		case "!":
+			return b2i(x == 0), nil
+		}
+		return x, nil
+	case t.is("?"):
+		name, ok := p.peek()
+		if !ok || name.kind != tIdent {
+			return 0, fmt.Errorf("expected label after ?")
+		}
+		p.pos++
+		return b2i(p.syms.defined(name.text)), nil
+	case t.is("$"):
+		return p.syms.here(), nil
+	case t.is("("):
+		x, err := p.binary(0)
+		if err != nil {
+			return 0, err
+		}
+		if t, ok := p.peek(); !ok || !t.is(")") {
+			return 0, fmt.Errorf("missing ) in expression")
+		}
+		p.pos++
+		return x, nil
+	case t.kind == tNum:
+		return t.val, nil
+	case t.kind == tStr:
+		if len(t.text) != 1 {
+			return 0, fmt.Errorf("string %q used as a number", t.text)
+		}
+		return int(t.text[0]), nil
+	case t.kind == tIdent:
+		return p.syms.lookup(t.text)
+	}
+	return 0, fmt.Errorf("unexpected %v in expression", t)
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Eval evaluates an expression in the assembler syntax outside of a program,
+// as used by tools like debuggers. The value of `$` is here, and identifiers
+// are resolved with lookup, which also reports whether the name is known.
+func Eval(expr string, here int, lookup func(name string) (int, bool)) (int, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(toks) == 0 {
+		return 0, fmt.Errorf("empty expression")
+	}
+	return eval(toks, funcSymbols{at: here, lookupFunc: lookup})
+}
+
+// funcSymbols adapts a lookup function to the symbols interface.
+type funcSymbols struct {
+	at         int
+	lookupFunc func(name string) (int, bool)
+}
+
+func (s funcSymbols) here() int { return s.at }
+
+func (s funcSymbols) lookup(name string) (int, error) {
+	if v, ok := s.lookupFunc(name); ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("undefined symbol: %s", name)
+}
+
+func (s funcSymbols) defined(name string) bool {
+	_, ok := s.lookupFunc(name)
+	return ok
+}
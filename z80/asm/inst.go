@@ -0,0 +1,588 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// operand is a parsed instruction operand.
+type operand struct {
+	reg  string  // lower-case register name, or "" for a plain expression
+	ind  bool    // whether the operand is enclosed in parentheses
+	idx  bool    // whether the operand is (ix+d) or (iy+d)
+	toks []token // expression for a value, address or displacement
+}
+
+// registers lists all the register names recognized as operands.
+var registers = map[string]bool{
+	"a": true, "b": true, "c": true, "d": true, "e": true, "h": true, "l": true,
+	"i": true, "r": true, "ixh": true, "ixl": true, "iyh": true, "iyl": true,
+	"af": true, "af'": true, "bc": true, "de": true, "hl": true, "sp": true, "ix": true, "iy": true,
+}
+
+func parseOperand(toks []token) operand {
+	if name, ok := regName(toks); ok {
+		return operand{reg: name}
+	}
+	if !enclosed(toks) {
+		return operand{toks: toks}
+	}
+	inner := toks[1 : len(toks)-1]
+	if name, ok := regName(inner); ok {
+		return operand{reg: name, ind: true}
+	}
+	if len(inner) >= 3 && (inner[1].is("+") || inner[1].is("-")) {
+		if name, ok := regName(inner[:1]); ok && (name == "ix" || name == "iy") {
+			return operand{reg: name, ind: true, idx: true, toks: inner[1:]}
+		}
+	}
+	return operand{ind: true, toks: inner}
+}
+
+func regName(toks []token) (string, bool) {
+	if len(toks) != 1 || toks[0].kind != tIdent {
+		return "", false
+	}
+	name := strings.ToLower(toks[0].text)
+	return name, registers[name]
+}
+
+// enclosed tells whether the tokens are a single parenthesized group, as
+// opposed to something like `(1+2)*(3+4)`.
+func enclosed(toks []token) bool {
+	if len(toks) < 2 || !toks[0].is("(") || !toks[len(toks)-1].is(")") {
+		return false
+	}
+	depth := 0
+	for _, t := range toks[:len(toks)-1] {
+		if t.is("(") {
+			depth++
+		} else if t.is(")") {
+			if depth--; depth == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// impliedOps maps the instructions without operands to their encodings.
+var impliedOps = map[string][]byte{
+	"nop": {0x00}, "halt": {0x76}, "di": {0xf3}, "ei": {0xfb}, "exx": {0xd9},
+	"daa": {0x27}, "cpl": {0x2f}, "scf": {0x37}, "ccf": {0x3f},
+	"rlca": {0x07}, "rrca": {0x0f}, "rla": {0x17}, "rra": {0x1f},
+	"neg": {0xed, 0x44}, "retn": {0xed, 0x45}, "reti": {0xed, 0x4d},
+	"rrd": {0xed, 0x67}, "rld": {0xed, 0x6f},
+	"ldi": {0xed, 0xa0}, "cpi": {0xed, 0xa1}, "ini": {0xed, 0xa2}, "outi": {0xed, 0xa3},
+	"ldd": {0xed, 0xa8}, "cpd": {0xed, 0xa9}, "ind": {0xed, 0xaa}, "outd": {0xed, 0xab},
+	"ldir": {0xed, 0xb0}, "cpir": {0xed, 0xb1}, "inir": {0xed, 0xb2}, "otir": {0xed, 0xb3},
+	"lddr": {0xed, 0xb8}, "cpdr": {0xed, 0xb9}, "indr": {0xed, 0xba}, "otdr": {0xed, 0xbb},
+}
+
+// aluOps lists the 8-bit arithmetic instructions in opcode order.
+var aluOps = []string{"add", "adc", "sub", "sbc", "and", "xor", "or", "cp"}
+
+// shiftOps lists the CB-prefixed rotate and shift instructions in opcode order.
+var shiftOps = []string{"rlc", "rrc", "rl", "rr", "sla", "sra", "sll", "srl"}
+
+// bitOps lists the CB-prefixed bit instructions in opcode order, starting at 1.
+var bitOps = []string{"bit", "res", "set"}
+
+// conds maps condition names to their codes.
+var conds = map[string]int{"nz": 0, "z": 1, "nc": 2, "c": 3, "po": 4, "pe": 5, "p": 6, "m": 7}
+
+// reg8 is an operand that fits the 3-bit register field of an opcode.
+type reg8 struct {
+	code   int     // 0-7, with 6 meaning (hl)
+	prefix byte    // 0xdd or 0xfd when using an index register, 0 otherwise
+	half   bool    // whether this is one of ixh, ixl, iyh, iyl
+	disp   []token // displacement for (ix+d), or nil for zero
+}
+
+var reg8Codes = map[string]int{"b": 0, "c": 1, "d": 2, "e": 3, "h": 4, "l": 5, "a": 7}
+
+func (op operand) reg8() (reg8, bool) {
+	switch {
+	case op.ind && op.reg == "hl":
+		return reg8{code: 6}, true
+	case op.ind && (op.reg == "ix" || op.reg == "iy"):
+		return reg8{code: 6, prefix: indexPrefix(op.reg), disp: op.toks}, true
+	case op.ind:
+		return reg8{}, false
+	}
+	if code, ok := reg8Codes[op.reg]; ok {
+		return reg8{code: code}, true
+	}
+	if strings.HasPrefix(op.reg, "ix") || strings.HasPrefix(op.reg, "iy") {
+		if len(op.reg) == 3 {
+			return reg8{code: 4 + strings.Index("hl", op.reg[2:]), prefix: indexPrefix(op.reg[:2]), half: true}, true
+		}
+	}
+	return reg8{}, false
+}
+
+// reg16 returns the code of a register pair, treating ix and iy as hl with a
+// prefix. The alt register is accepted in place of sp.
+func (op operand) reg16(alt string) (code int, prefix byte, ok bool) {
+	if op.ind {
+		return 0, 0, false
+	}
+	switch op.reg {
+	case "bc":
+		return 0, 0, true
+	case "de":
+		return 1, 0, true
+	case "hl":
+		return 2, 0, true
+	case "ix", "iy":
+		return 2, indexPrefix(op.reg), true
+	case alt:
+		return 3, 0, true
+	}
+	return 0, 0, false
+}
+
+func (op operand) isValue() bool { return op.reg == "" && !op.ind }
+func (op operand) isMem() bool   { return op.reg == "" && op.ind }
+
+func indexPrefix(reg string) byte {
+	if reg == "ix" {
+		return 0xdd
+	}
+	return 0xfd
+}
+
+var errOperands = errors.New("invalid operands")
+
+// instruction encodes a single machine instruction.
+func (p *pass) instruction(mn string, args []operand) error {
+	if enc, ok := impliedOps[mn]; ok {
+		if len(args) != 0 {
+			return fmt.Errorf("%s takes no operands", mn)
+		}
+		p.emit(enc...)
+		return nil
+	}
+	if mn == "sli" {
+		mn = "sll"
+	}
+	if op := slices.Index(aluOps, mn); op >= 0 {
+		return p.alu(op, args)
+	}
+	if op := slices.Index(shiftOps, mn); op >= 0 {
+		if len(args) != 1 {
+			return errOperands
+		}
+		r, ok := args[0].reg8()
+		if !ok || r.half {
+			return errOperands
+		}
+		p.emitCB(byte(op<<3), r)
+		return nil
+	}
+	if op := slices.Index(bitOps, mn); op >= 0 {
+		if len(args) != 2 || !args[0].isValue() {
+			return errOperands
+		}
+		r, ok := args[1].reg8()
+		if !ok || r.half {
+			return errOperands
+		}
+		b := p.value(args[0].toks, 0, 7)
+		p.emitCB(byte((op+1)<<6|b<<3), r)
+		return nil
+	}
+
+	switch mn {
+	case "ld":
+		if len(args) != 2 {
+			return errOperands
+		}
+		return p.ld(args[0], args[1])
+	case "push", "pop":
+		if len(args) != 1 {
+			return errOperands
+		}
+		rp, prefix, ok := args[0].reg16("af")
+		if !ok {
+			return errOperands
+		}
+		op := byte(0xc5)
+		if mn == "pop" {
+			op = 0xc1
+		}
+		p.emitPrefixed(prefix, op|byte(rp<<4))
+		return nil
+	case "ex":
+		if len(args) != 2 {
+			return errOperands
+		}
+		switch a, b := args[0], args[1]; {
+		case a.reg == "de" && b.reg == "hl" && !a.ind && !b.ind:
+			p.emit(0xeb)
+		case a.reg == "af" && b.reg == "af'":
+			p.emit(0x08)
+		case a.ind && a.reg == "sp" && !b.ind && (b.reg == "hl" || b.reg == "ix" || b.reg == "iy"):
+			_, prefix, _ := b.reg16("")
+			p.emitPrefixed(prefix, 0xe3)
+		default:
+			return errOperands
+		}
+		return nil
+	case "inc", "dec":
+		if len(args) != 1 {
+			return errOperands
+		}
+		dec := b2i(mn == "dec")
+		if rp, prefix, ok := args[0].reg16("sp"); ok {
+			p.emitPrefixed(prefix, byte(0x03|dec<<3|rp<<4))
+			return nil
+		}
+		r, ok := args[0].reg8()
+		if !ok {
+			return errOperands
+		}
+		p.emitR8(byte(0x04|dec|r.code<<3), r)
+		return nil
+	case "jp":
+		if len(args) == 1 && args[0].ind && !args[0].idx {
+			switch args[0].reg {
+			case "hl":
+				p.emit(0xe9)
+				return nil
+			case "ix", "iy":
+				p.emit(indexPrefix(args[0].reg), 0xe9)
+				return nil
+			}
+		}
+		return p.jump(args, 0xc3, 0xc2, 8, false)
+	case "call":
+		return p.jump(args, 0xcd, 0xc4, 8, false)
+	case "jr":
+		return p.jump(args, 0x18, 0x20, 4, true)
+	case "djnz":
+		return p.jump(args, 0x10, 0, 0, true)
+	case "ret":
+		switch len(args) {
+		case 0:
+			p.emit(0xc9)
+		case 1:
+			cc, ok := args[0].cond(8)
+			if !ok {
+				return errOperands
+			}
+			p.emit(byte(0xc0 | cc<<3))
+		default:
+			return errOperands
+		}
+		return nil
+	case "rst":
+		if len(args) != 1 || !args[0].isValue() {
+			return errOperands
+		}
+		v := p.value(args[0].toks, 0, 0x38)
+		if v&7 != 0 {
+			return fmt.Errorf("bad rst target: %d", v)
+		}
+		p.emit(byte(0xc7 | v))
+		return nil
+	case "im":
+		if len(args) != 1 || !args[0].isValue() {
+			return errOperands
+		}
+		p.emit(0xed, []byte{0x46, 0x56, 0x5e}[p.value(args[0].toks, 0, 2)])
+		return nil
+	case "in":
+		return p.in(args)
+	case "out":
+		return p.out(args)
+	}
+	return fmt.Errorf("unknown instruction: %s", mn)
+}
+
+// condName returns the name of a condition operand that is not also a register.
+func condName(op operand) string {
+	if op.reg == "" && !op.ind && len(op.toks) == 1 && op.toks[0].kind == tIdent {
+		name := strings.ToLower(op.toks[0].text)
+		if _, ok := conds[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// cond returns the condition code of an operand, if it is one of the first
+// limit conditions. The c condition is parsed as a register.
+func (op operand) cond(limit int) (int, bool) {
+	name := condName(op)
+	if op.reg == "c" && !op.ind {
+		name = "c"
+	}
+	cc, ok := conds[name]
+	return cc, ok && cc < limit
+}
+
+func (p *pass) alu(op int, args []operand) error {
+	if len(args) == 2 && !args[0].ind && (args[0].reg == "hl" || args[0].reg == "ix" || args[0].reg == "iy") {
+		dst, prefix, _ := args[0].reg16("")
+		src, srcPrefix, ok := args[1].reg16("sp")
+		if !ok || src == 2 && srcPrefix != prefix {
+			return errOperands
+		}
+		switch {
+		case aluOps[op] == "add":
+			p.emitPrefixed(prefix, byte(0x09|src<<4))
+		case (aluOps[op] == "adc" || aluOps[op] == "sbc") && dst == 2 && prefix == 0:
+			p.emit(0xed, byte(0x42|b2i(aluOps[op] == "adc")<<3|src<<4))
+		default:
+			return errOperands
+		}
+		return nil
+	}
+	if len(args) == 2 && args[0].reg == "a" && !args[0].ind {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return errOperands
+	}
+	if r, ok := args[0].reg8(); ok {
+		p.emitR8(byte(0x80|op<<3|r.code), r)
+		return nil
+	}
+	if !args[0].isValue() {
+		return errOperands
+	}
+	p.emit(byte(0xc6|op<<3), byte(p.value(args[0].toks, -0x80, 0xff)))
+	return nil
+}
+
+func (p *pass) ld(dst, src operand) error {
+	switch {
+	case dst.reg == "a" && !dst.ind && !src.ind && (src.reg == "i" || src.reg == "r"):
+		p.emit(0xed, byte(0x57|b2i(src.reg == "r")<<3))
+		return nil
+	case src.reg == "a" && !src.ind && !dst.ind && (dst.reg == "i" || dst.reg == "r"):
+		p.emit(0xed, byte(0x47|b2i(dst.reg == "r")<<3))
+		return nil
+	case dst.reg == "a" && !dst.ind && src.ind && (src.reg == "bc" || src.reg == "de"):
+		p.emit(byte(0x0a | b2i(src.reg == "de")<<4))
+		return nil
+	case src.reg == "a" && !src.ind && dst.ind && (dst.reg == "bc" || dst.reg == "de"):
+		p.emit(byte(0x02 | b2i(dst.reg == "de")<<4))
+		return nil
+	case dst.reg == "a" && !dst.ind && src.isMem():
+		p.emit(0x3a)
+		p.emitWord(src.toks)
+		return nil
+	case src.reg == "a" && !src.ind && dst.isMem():
+		p.emit(0x32)
+		p.emitWord(dst.toks)
+		return nil
+	}
+
+	if rp, prefix, ok := dst.reg16("sp"); ok {
+		switch {
+		case rp == 3 && !src.ind && (src.reg == "hl" || src.reg == "ix" || src.reg == "iy"):
+			_, prefix, _ := src.reg16("")
+			p.emitPrefixed(prefix, 0xf9)
+		case src.isValue():
+			p.emitPrefixed(prefix, byte(0x01|rp<<4))
+			p.emitWord(src.toks)
+		case src.isMem() && rp == 2:
+			p.emitPrefixed(prefix, 0x2a)
+			p.emitWord(src.toks)
+		case src.isMem():
+			p.emit(0xed, byte(0x4b|rp<<4))
+			p.emitWord(src.toks)
+		default:
+			return errOperands
+		}
+		return nil
+	}
+	if dst.isMem() {
+		rp, prefix, ok := src.reg16("sp")
+		switch {
+		case !ok:
+			return errOperands
+		case rp == 2:
+			p.emitPrefixed(prefix, 0x22)
+		default:
+			p.emit(0xed, byte(0x43|rp<<4))
+		}
+		p.emitWord(dst.toks)
+		return nil
+	}
+
+	d, ok := dst.reg8()
+	if !ok {
+		return errOperands
+	}
+	if src.isValue() {
+		n := byte(p.value(src.toks, -0x80, 0xff))
+		p.emitR8(byte(0x06|d.code<<3), d, n)
+		return nil
+	}
+	s, ok := src.reg8()
+	if !ok || d.code == 6 && s.code == 6 {
+		return errOperands
+	}
+	// Index prefixes apply to both operands, so at most one of them can use an
+	// index register, unless both are halves of the same one. The (ix+d) forms
+	// refer to the plain h and l registers.
+	switch {
+	case d.half && s.half && d.prefix != s.prefix,
+		d.half && s.prefix == 0 && s.code >= 4 && s.code <= 6,
+		s.half && d.prefix == 0 && d.code >= 4 && d.code <= 6,
+		d.prefix != 0 && s.prefix != 0 && !(d.half && s.half),
+		d.code == 6 && s.half, s.code == 6 && d.half:
+		return errOperands
+	}
+	r := s
+	if d.prefix != 0 {
+		r = d
+	}
+	p.emitR8(byte(0x40|d.code<<3|s.code), r)
+	return nil
+}
+
+// jump encodes a jump or call, with an optional condition if ncc > 0. Only the
+// first ncc conditions are allowed.
+func (p *pass) jump(args []operand, op, ccOp byte, ncc int, rel bool) error {
+	var target operand
+	switch len(args) {
+	case 1:
+		target = args[0]
+	case 2:
+		cc, ok := args[0].cond(ncc)
+		if !ok {
+			return errOperands
+		}
+		op, target = ccOp|byte(cc<<3), args[1]
+	default:
+		return errOperands
+	}
+	if !target.isValue() {
+		return errOperands
+	}
+	if !rel {
+		p.emit(op)
+		p.emitWord(target.toks)
+		return nil
+	}
+	v, err := eval(target.toks, p)
+	if err != nil {
+		p.errorf("%v", err)
+	}
+	d := v - (p.start + 2)
+	if err == nil && (d < -128 || d > 127) {
+		p.errorf("relative jump out of range: %d", d)
+	}
+	p.emit(op, byte(d))
+	return nil
+}
+
+func (p *pass) in(args []operand) error {
+	switch {
+	case len(args) == 1 && args[0].ind && args[0].reg == "c":
+		p.emit(0xed, 0x70)
+	case len(args) != 2:
+		return errOperands
+	case args[0].reg == "a" && !args[0].ind && args[1].isMem():
+		p.emit(0xdb, byte(p.value(args[1].toks, 0, 0xff)))
+	case args[1].ind && args[1].reg == "c":
+		if isFlagReg(args[0]) {
+			p.emit(0xed, 0x70)
+			return nil
+		}
+		r, ok := args[0].reg8()
+		if !ok || r.prefix != 0 || r.code == 6 {
+			return errOperands
+		}
+		p.emit(0xed, byte(0x40|r.code<<3))
+	default:
+		return errOperands
+	}
+	return nil
+}
+
+func isFlagReg(op operand) bool {
+	return !op.ind && len(op.toks) == 1 && isKeyword(op.toks[0], "f")
+}
+
+func (p *pass) out(args []operand) error {
+	if len(args) != 2 {
+		return errOperands
+	}
+	switch dst, src := args[0], args[1]; {
+	case dst.isMem() && src.reg == "a" && !src.ind:
+		p.emit(0xd3, byte(p.value(dst.toks, 0, 0xff)))
+	case dst.ind && dst.reg == "c" && src.isValue():
+		if p.value(src.toks, 0, 0) == 0 {
+			p.emit(0xed, 0x71)
+		}
+	case dst.ind && dst.reg == "c":
+		r, ok := src.reg8()
+		if !ok || r.prefix != 0 || r.code == 6 {
+			return errOperands
+		}
+		p.emit(0xed, byte(0x41|r.code<<3))
+	default:
+		return errOperands
+	}
+	return nil
+}
+
+func (p *pass) emitPrefixed(prefix byte, op ...byte) {
+	if prefix != 0 {
+		p.emit(prefix)
+	}
+	p.emit(op...)
+}
+
+// emitR8 emits an instruction that has a reg8 operand encoded in the opcode,
+// followed by the displacement (if any) and the extra bytes.
+func (p *pass) emitR8(op byte, r reg8, extra ...byte) {
+	p.emitPrefixed(r.prefix, op)
+	if r.prefix != 0 && r.code == 6 {
+		p.emit(p.disp(r.disp))
+	}
+	p.emit(extra...)
+}
+
+// emitCB emits a CB-prefixed instruction, which has the displacement before
+// the opcode in the indexed forms.
+func (p *pass) emitCB(op byte, r reg8) {
+	if r.prefix != 0 {
+		p.emit(r.prefix, 0xcb, p.disp(r.disp), op|6)
+		return
+	}
+	p.emit(0xcb, op|byte(r.code))
+}
+
+func (p *pass) disp(toks []token) byte {
+	if toks == nil {
+		return 0
+	}
+	return byte(p.value(toks, -0x80, 0x7f))
+}
+
+func (p *pass) emitWord(toks []token) {
+	v := p.value(toks, -0x8000, 0xffff)
+	p.emit(byte(v), byte(v>>8))
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokKind is the type of a lexical token.
+type tokKind int
+
+const (
+	tIdent tokKind = iota // identifier, mnemonic or register name
+	tNum                  // numeric literal
+	tStr                  // quoted string or character literal
+	tOp                   // punctuation or operator
+)
+
+// token is a single lexical token from a source line.
+type token struct {
+	kind tokKind
+	text string // identifier name, operator, or decoded string contents
+	val  int    // value of a numeric literal
+}
+
+func (t token) is(op string) bool {
+	return t.kind == tOp && t.text == op
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tNum:
+		return strconv.Itoa(t.val)
+	case tStr:
+		return strconv.Quote(t.text)
+	}
+	return t.text
+}
+
+// multiOps lists the operators longer than a single character.
+var multiOps = []string{"<<", ">>", "<=", ">=", "==", "!=", "&&", "||"}
+
+// lex splits a source line into tokens, stopping at a comment.
+func lex(line string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ';':
+			return toks, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(line) && isIdentChar(line[j]) {
+				j++
+			}
+			name := line[i:j]
+			if strings.EqualFold(name, "af") && j < len(line) && line[j] == '\'' {
+				name, j = name+"'", j+1
+			}
+			toks = append(toks, token{kind: tIdent, text: name})
+			i = j
+		case isDigit(c) || (c == '$' && i+1 < len(line) && isHexDigit(line[i+1])):
+			j := i + 1
+			for j < len(line) && isIdentChar(line[j]) {
+				j++
+			}
+			v, err := parseNum(line[i:j])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tNum, text: line[i:j], val: v})
+			i = j
+		case c == '"' || c == '\'':
+			s, n, err := parseStr(line[i:])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tStr, text: s})
+			i += n
+		default:
+			op := line[i : i+1]
+			for _, m := range multiOps {
+				if strings.HasPrefix(line[i:], m) {
+					op = m
+					break
+				}
+			}
+			if !strings.Contains("+-*/%&|^~!<>=()$,:?", op[:1]) {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			toks = append(toks, token{kind: tOp, text: op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+// parseNum decodes a numeric literal. Supported forms are decimal, 0x1f, $1f,
+// 1fh (which must start with a digit), 0b101 and 101b.
+func parseNum(s string) (int, error) {
+	lower := strings.ToLower(s)
+	digits, base := lower, 10
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		digits, base = lower[2:], 16
+	case strings.HasPrefix(lower, "$"):
+		digits, base = lower[1:], 16
+	case strings.HasSuffix(lower, "h"):
+		digits, base = lower[:len(lower)-1], 16
+	case strings.HasPrefix(lower, "0b"):
+		digits, base = lower[2:], 2
+	case strings.HasSuffix(lower, "b"):
+		digits, base = lower[:len(lower)-1], 2
+	}
+	v, err := strconv.ParseInt(digits, base, 32)
+	if err != nil || digits == "" {
+		return 0, fmt.Errorf("bad number: %s", s)
+	}
+	return int(v), nil
+}
+
+// parseStr decodes a quoted string at the start of s, returning its contents
+// and the number of bytes consumed.
+func parseStr(s string) (string, int, error) {
+	q := s[0]
+	var out strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == q {
+			return out.String(), i + 1, nil
+		}
+		if c != '\\' {
+			out.WriteByte(c)
+			continue
+		}
+		if i++; i == len(s) {
+			break
+		}
+		switch c = s[i]; c {
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case '0':
+			out.WriteByte(0)
+		case 'a':
+			out.WriteByte(7)
+		case 'e':
+			out.WriteByte(27)
+		case 'x':
+			if i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2]) {
+				return "", 0, fmt.Errorf("bad \\x escape in string")
+			}
+			v, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			out.WriteByte(byte(v))
+			i += 2
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '.' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
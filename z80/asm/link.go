@@ -0,0 +1,196 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Routine is a section of a program, starting from a non-local address label
+// and extending up to the next one.
+type Routine struct {
+	Name string   // label of the routine, or "" for code before the first label
+	File string   // name of the source file
+	Line int      // line number of the label
+	Addr int      // address of the label
+	Size int      // number of bytes in the routine
+	Refs []string // names of the other routines this one refers to, sorted
+}
+
+// loadIndex finds the labels defined by each file in the library directories.
+func (a *assembler) loadIndex() error {
+	a.index = map[string][]string{}
+	for _, dir := range a.opts.Library {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.z80"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			seen := map[string]bool{}
+			for _, line := range strings.Split(string(data), "\n") {
+				toks, err := lex(line)
+				if err != nil || len(toks) < 2 || toks[0].kind != tIdent || strings.HasPrefix(toks[0].text, ".") {
+					continue
+				}
+				if name := toks[0].text; (toks[1].is(":") || isKeyword(toks[1], "equ")) && !seen[name] {
+					seen[name] = true
+					a.index[name] = append(a.index[name], path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// link adds the library files that define any of the given labels to the
+// program. It reports whether any new files were added.
+func (a *assembler) link(undef []string) (bool, error) {
+	if len(a.opts.Library) == 0 || len(undef) == 0 {
+		return false, nil
+	}
+	if a.index == nil {
+		if err := a.loadIndex(); err != nil {
+			return false, err
+		}
+	}
+	added := false
+	for _, name := range undef {
+		paths := a.index[name]
+		if len(paths) == 0 {
+			continue
+		}
+		if len(paths) > 1 {
+			return false, fmt.Errorf("label %s is defined in several library files: %s", name, strings.Join(paths, ", "))
+		}
+		if paths[0] == a.main || slices.Contains(a.linked, paths[0]) {
+			continue
+		}
+		if _, err := a.readFile(paths[0]); err != nil {
+			return false, err
+		}
+		a.linked = append(a.linked, paths[0])
+		added = true
+	}
+	return added, nil
+}
+
+// inLibrary tells whether a source file is in one of the library directories.
+func (a *assembler) inLibrary(path string) bool {
+	dir := filepath.Clean(filepath.Dir(path))
+	for _, lib := range a.opts.Library {
+		if filepath.Clean(lib) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceFiles returns the names of all files used by the program, in the order
+// they were first read.
+func (a *assembler) sourceFiles() []string {
+	return append([]string(nil), a.order...)
+}
+
+// routineGraph resolves the references between routines, and finds library
+// routines not reachable from the program's own source: the main file, and any
+// files it includes from outside the library directories.
+func (p *pass) routineGraph() ([]Routine, []*Error) {
+	routines := p.routines
+	edges := make([][]int, len(routines))
+	for from, names := range p.refs {
+		seen := map[int]bool{}
+		for _, name := range names {
+			to, ok := p.owner[name]
+			if !ok || to == from || seen[to] {
+				continue
+			}
+			seen[to] = true
+			edges[from] = append(edges[from], to)
+			routines[from].Refs = append(routines[from].Refs, routines[to].Name)
+		}
+		sort.Strings(routines[from].Refs)
+	}
+
+	reached := make([]bool, len(routines))
+	var visit func(int)
+	visit = func(r int) {
+		if reached[r] {
+			return
+		}
+		reached[r] = true
+		for _, to := range edges[r] {
+			visit(to)
+		}
+	}
+	for r, routine := range routines {
+		if routine.File == p.a.main || !p.a.inLibrary(routine.File) {
+			visit(r)
+		}
+	}
+	var warnings []*Error
+	for r, routine := range routines {
+		if !reached[r] {
+			warnings = append(warnings, &Error{File: routine.File, Line: routine.Line, Msg: "unused routine: " + routine.Name})
+		}
+	}
+
+	if routines[0].Size == 0 {
+		routines = routines[1:]
+	}
+	return routines, warnings
+}
+
+// WriteMap writes a table of the routines of the program, with their address,
+// size and source file, followed by the total size.
+func (prog *Program) WriteMap(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range prog.Routines {
+		name := r.Name
+		if name == "" {
+			name = "(start)"
+		}
+		fmt.Fprintf(bw, "%04x  %5d  %-20s %s\n", r.Addr&0xffff, r.Size, name, r.File)
+	}
+	fmt.Fprintf(bw, "      %5d  total\n", len(prog.Code))
+	return bw.Flush()
+}
+
+// WriteDeps writes the dependency graph of the program's routines, one
+// `routine: dep1 dep2 ...` line per routine.
+func (prog *Program) WriteDeps(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range prog.Routines {
+		if r.Name == "" {
+			continue
+		}
+		fmt.Fprintf(bw, "%s:", r.Name)
+		for _, dep := range r.Refs {
+			fmt.Fprintf(bw, " %s", dep)
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asm
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadSymbols parses a label file in the format written by WriteSymbols (and
+// z80asm), returning a map from label names to their values.
+func ReadSymbols(r io.Reader) (map[string]int, error) {
+	syms := make(map[string]int)
+	s := bufio.NewScanner(r)
+	for num := 1; s.Scan(); num++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		if !ok || !strings.HasPrefix(value, "equ") {
+			return nil, fmt.Errorf("line %d: expected `name: equ value`: %q", num, line)
+		}
+		v, err := parseNum(strings.TrimSpace(value[len("equ"):]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		syms[name] = v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return syms, nil
+}
+
+// ReadListing parses a listing in the format written by WriteListing. The file
+// format does not record line numbers, so the Num field of the returned lines
+// is left zero, and Bytes only holds the bytes actually shown: long data
+// directives are truncated.
+func ReadListing(r io.Reader) ([]Line, error) {
+	var (
+		lines []Line
+		file  string
+	)
+	s := bufio.NewScanner(r)
+	for num := 1; s.Scan(); num++ {
+		text := s.Text()
+		if f, ok := strings.CutPrefix(text, "# File "); ok {
+			file = f
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		addr, err := strconv.ParseUint(text[:min(4, len(text))], 16, 16)
+		if err != nil || len(text) < 6 {
+			return nil, fmt.Errorf("line %d: expected an address: %q", num, text)
+		}
+		field := text[6:min(6+3*listBytes, len(text))]
+		bytes, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(field), " ", ""))
+		if err != nil && strings.TrimSpace(field) != "..." {
+			return nil, fmt.Errorf("line %d: bad bytes: %q", num, field)
+		}
+		if len(text) < 8+3*listBytes {
+			// Continuation of a line that generated more than listBytes bytes.
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without a line", num)
+			}
+			prev := &lines[len(lines)-1]
+			prev.Bytes = append(prev.Bytes, bytes...)
+			continue
+		}
+		lines = append(lines, Line{File: file, Addr: int(addr), Bytes: bytes, Text: text[8+3*listBytes:]})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary z80asm assembles a Z80 program.
+//
+// The command line is compatible with the z80asm assembler, as far as the
+// options used in this repository are concerned. Additionally, the -lib flag
+// enables automatic linking of library routines, and the -map and -deps flags
+// report the size of each routine and the dependencies between them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fis/aoc/z80/asm"
+)
+
+var (
+	input   = flag.String("i", "", "read source code from `file`")
+	output  = flag.String("o", "a.bin", "write the binary to `file`")
+	listing = flag.String("l", "", "write a listing to `file`")
+	labels  = flag.String("L", "", "write the label table to `file`")
+	symMap  = flag.String("map", "", "write the size of each routine to `file`")
+	deps    = flag.String("deps", "", "write the routine dependency graph to `file`")
+	include []string
+	library []string
+)
+
+func init() {
+	flag.StringVar(input, "input", "", "alias for -i")
+	flag.StringVar(output, "output", "a.bin", "alias for -o")
+	flag.StringVar(listing, "list", "", "alias for -l")
+	flag.StringVar(labels, "label", "", "alias for -L")
+	addInclude := func(dir string) error {
+		include = append(include, dir)
+		return nil
+	}
+	flag.Func("I", "search `dir` for included files (may be repeated)", addInclude)
+	flag.Func("includepath", "alias for -I", addInclude)
+	flag.Func("lib", "link undefined labels from the library in `dir` (may be repeated)", func(dir string) error {
+		library = append(library, dir)
+		return nil
+	})
+}
+
+const usage = `usage: z80asm [flags] prog.z80
+Optional flags:
+`
+
+func main() {
+	flag.Parse()
+	src := *input
+	if src == "" && flag.NArg() == 1 {
+		src = flag.Arg(0)
+	}
+	if src == "" || flag.NArg() > 1 || (*input != "" && flag.NArg() > 0) {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	prog, err := asm.AssembleFile(src, &asm.Options{IncludePath: include, Library: library})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, w := range prog.Warnings {
+		fmt.Fprintf(os.Stderr, "%v (warning)\n", w)
+	}
+	if err := os.WriteFile(*output, prog.Code, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *listing != "" {
+		writeFile(*listing, prog.WriteListing)
+	}
+	if *labels != "" {
+		writeFile(*labels, prog.WriteSymbols)
+	}
+	if *symMap != "" {
+		writeFile(*symMap, prog.WriteMap)
+	}
+	if *deps != "" {
+		writeFile(*deps, prog.WriteDeps)
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
+	if err == nil {
+		err = write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
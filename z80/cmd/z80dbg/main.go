@@ -0,0 +1,183 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Binary z80dbg is a source-level debugger for AoC Z80 programs.
+//
+// It loads an assembled program, and optionally the listing and label files
+// written with the -l and -L flags of z80asm, to show source lines and allow
+// referring to locations by their labels. The program reads its input from the
+// file given with -input, and the machine profile is the one declared by the
+// program file name, unless overridden with -profile.
+//
+// On a terminal, the debugger shows the source, registers, watch expressions,
+// the program's output on the I/O port and the output of the last commands in
+// separate panes, redrawing the screen after each command. Otherwise, or with
+// -plain, it just prints the output of each command. An empty command repeats
+// the previous one, and an interrupt (^C) stops a running program.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+	"github.com/fis/aoc/z80/debug"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	listing = flag.String("l", "", "read the listing from `file`")
+	profile = flag.String("profile", "", "run on the machine profile `name` (default: from the program file name)")
+	labels  = flag.String("L", "", "read the label table from `file`")
+	input   = flag.String("input", "", "read the program's input from `file`")
+	limit   = flag.Uint64("limit", debug.DefaultLimit, "stop any single command after `N` T-states")
+	plain   = flag.Bool("plain", false, "use the line-oriented interface even on a terminal")
+)
+
+const usage = `usage: z80dbg [flags] prog.bin
+Optional flags:
+`
+
+const prompt = "(z80dbg) "
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	d, err := load(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	d.Limit = *limit
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		for range sig {
+			d.Interrupt()
+		}
+	}()
+
+	var scr *screen
+	if !*plain && isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		scr = newScreen(d, os.Stdout)
+	}
+	if scr != nil {
+		scr.draw()
+		defer scr.close()
+	} else {
+		fmt.Print(prompt)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	last := ""
+	for in.Scan() {
+		cmd := strings.TrimSpace(in.Text())
+		if cmd == "" {
+			cmd = last
+		}
+		last = cmd
+		if cmd == "quit" || cmd == "q" {
+			break
+		}
+		var out strings.Builder
+		if err := d.Exec(&out, cmd); err != nil {
+			fmt.Fprintf(&out, "error: %v\n", err)
+		}
+		if scr != nil {
+			scr.log(prompt+cmd, out.String())
+			scr.draw()
+		} else {
+			fmt.Print(out.String())
+			fmt.Print(prompt)
+		}
+	}
+	if scr == nil {
+		fmt.Println()
+	}
+}
+
+// load reads the program, and the listing, labels and input files if given.
+func load(prog string) (*debug.Debugger, error) {
+	bin, err := os.ReadFile(prog)
+	if err != nil {
+		return nil, err
+	}
+	if len(bin) > 65536 {
+		return nil, fmt.Errorf("%s: too large: %d bytes", prog, len(bin))
+	}
+	var (
+		syms  map[string]int
+		lines []asm.Line
+		data  []byte
+	)
+	if *labels != "" {
+		if syms, err = readFile(*labels, asm.ReadSymbols); err != nil {
+			return nil, err
+		}
+	}
+	if *listing != "" {
+		if lines, err = readFile(*listing, asm.ReadListing); err != nil {
+			return nil, err
+		}
+	}
+	if *input != "" {
+		if data, err = os.ReadFile(*input); err != nil {
+			return nil, err
+		}
+	}
+	mp, err := machineProfile(prog)
+	if err != nil {
+		return nil, err
+	}
+	return debug.New(bin, mp, syms, lines, data), nil
+}
+
+func readFile[T any](path string, parse func(io.Reader) (T, error)) (T, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer f.Close()
+	v, err := parse(f)
+	if err != nil {
+		return v, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// machineProfile returns the profile selected with -profile, or declared by
+// the program file name.
+func machineProfile(prog string) (z80.Profile, error) {
+	if *profile != "" {
+		return z80.ParseProfile(*profile)
+	}
+	return z80.FileProfile(prog)
+}
+
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fis/aoc/z80/debug"
+	"golang.org/x/sys/unix"
+)
+
+// Pane sizes, in lines of content.
+const (
+	regsLines   = 6 // the banked profile adds a line for the memory mapping
+	outputLines = 6
+	logLines    = 8
+	sideWidth   = 44 // including the borders
+)
+
+// screen draws the full-screen terminal interface with ANSI escape codes.
+type screen struct {
+	d      *debug.Debugger
+	w      *bufio.Writer
+	cmdLog []string
+	width  int
+	height int
+}
+
+func newScreen(d *debug.Debugger, out *os.File) *screen {
+	s := &screen{d: d, w: bufio.NewWriter(out), width: 80, height: 24}
+	if ws, err := unix.IoctlGetWinsize(int(out.Fd()), unix.TIOCGWINSZ); err == nil && ws.Col > 0 && ws.Row > 0 {
+		s.width, s.height = int(ws.Col), int(ws.Row)
+	}
+	return s
+}
+
+// log records the output of a command, to be shown in the log pane.
+func (s *screen) log(cmd, out string) {
+	s.cmdLog = append(s.cmdLog, cmd)
+	s.cmdLog = append(s.cmdLog, strings.Split(strings.TrimSuffix(out, "\n"), "\n")...)
+	if len(s.cmdLog) > logLines {
+		s.cmdLog = s.cmdLog[len(s.cmdLog)-logLines:]
+	}
+}
+
+// draw redraws the whole screen, leaving the cursor at the command prompt.
+func (s *screen) draw() {
+	// Rows: top panes, output pane, log pane, prompt line.
+	topHeight := s.height - (outputLines + 2) - (logLines + 2) - 1
+	mainWidth := s.width - sideWidth
+	if topHeight < regsLines+2+3 || mainWidth < 20 {
+		fmt.Fprintf(s.w, "\x1b[H\x1b[2J%s", prompt)
+		s.w.Flush()
+		return
+	}
+
+	d := s.d
+	pc := d.M.CPU.PC
+	src := d.Source(pc, topHeight-2)
+	if src == nil {
+		src = []string{"no source for " + d.Where(pc)}
+	}
+	left := box("Source", src, mainWidth, topHeight)
+	right := append(
+		box("Registers", d.Registers(), sideWidth, regsLines+2),
+		box("Watches", d.Watches(), sideWidth, topHeight-regsLines-2)...)
+
+	var rows []string
+	for i := range left {
+		rows = append(rows, left[i]+right[i])
+	}
+	rows = append(rows, box("Output", tail(outputText(d.Output()), outputLines), s.width, outputLines+2)...)
+	rows = append(rows, box("Commands", s.cmdLog, s.width, logLines+2)...)
+
+	s.w.WriteString("\x1b[H\x1b[2J")
+	for _, row := range rows {
+		s.w.WriteString(row)
+		s.w.WriteString("\r\n")
+	}
+	s.w.WriteString(prompt)
+	s.w.Flush()
+}
+
+// close leaves the terminal with a clear screen.
+func (s *screen) close() {
+	s.w.WriteString("\x1b[H\x1b[2J")
+	s.w.Flush()
+}
+
+// box draws a pane with a border and a title, w columns wide and h rows high.
+// Content that does not fit is cut off.
+func box(title string, lines []string, w, h int) []string {
+	inner := w - 2
+	top := "┌─ " + title + " " + strings.Repeat("─", max(0, inner-len(title)-3)) + "┐"
+	out := []string{top}
+	for i := 0; i < h-2; i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		out = append(out, "│"+fit(line, inner)+"│")
+	}
+	return append(out, "└"+strings.Repeat("─", inner)+"┘")
+}
+
+// fit expands tabs, and pads or truncates a line to exactly w columns.
+func fit(line string, w int) string {
+	var out strings.Builder
+	col := 0
+	for _, r := range line {
+		if col == w {
+			break
+		}
+		switch {
+		case r == '\t':
+			for n := 8 - col%8; n > 0 && col < w; n-- {
+				out.WriteByte(' ')
+				col++
+			}
+			continue
+		case r < ' ' || r == utf8.RuneError:
+			r = '.'
+		}
+		out.WriteRune(r)
+		col++
+	}
+	out.WriteString(strings.Repeat(" ", w-col))
+	return out.String()
+}
+
+// outputText splits program output into lines, replacing unprintable bytes.
+func outputText(data []byte) []string {
+	text := strings.Map(func(r rune) rune {
+		if r != '\n' && (r < ' ' || r > '~') {
+			return '.'
+		}
+		return r
+	}, string(data))
+	return strings.Split(text, "\n")
+}
+
+func tail(lines []string, n int) []string {
+	return lines[max(0, len(lines)-n):]
+}
@@ -13,21 +13,42 @@
 // limitations under the License.
 
 // Binary z80run executes an AoC Z80 program.
+//
+// The program runs on the machine model of package z80, with the I/O port
+// bound to the standard input and output streams. The number of T-states taken
+// is printed at the end. The machine profile is the one declared by the file
+// name of the program, unless overridden with -profile.
+//
+// Given the label file written by `z80asm -L`, the -report and -pprof flags
+// profile the program, attributing the T-states and instructions executed to
+// its routines; see package prof for details.
 package main
 
 import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
 
-	"github.com/fis/aoc/z80/z80ex"
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+	"github.com/fis/aoc/z80/prof"
 )
 
 var (
-	bound = flag.Int64("bound", -1, "only emulate up to (approximately) N t-states")
+	bound   = flag.Int64("bound", -1, "only emulate up to (approximately) N t-states")
+	profile = flag.String("profile", "", "run on the machine profile `name` (default: from the program file name)")
+	labels  = flag.String("L", "", "read the label table from `file`, for profiling")
+	report  = flag.String("report", "", "write a per-routine profile report to `file` (- for stderr)")
+	pprof   = flag.String("pprof", "", "write a pprof format profile to `file`")
 )
 
+// runZ80ex runs the program on the z80ex library instead of the built-in
+// emulator, if enabled by the -z80ex flag; see z80ex.go.
+var runZ80ex func(bin []byte, r io.Reader, w io.Writer) (steps uint64, ok bool)
+
 const usage = `usage: z80run [flags] prog.bin
 Optional flags:
 `
@@ -43,25 +64,104 @@ func main() {
 
 	bin, err := os.ReadFile(prog)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fail(err)
 	}
 	if len(bin) > 65536 {
-		fmt.Fprintf(os.Stderr, "%s: too large: %d bytes\n", prog, len(bin))
-		os.Exit(1)
+		fail(fmt.Errorf("%s: too large: %d bytes", prog, len(bin)))
+	}
+	profiling := *report != "" || *pprof != ""
+	if profiling && *labels == "" {
+		fail(fmt.Errorf("profiling needs the label table (-L)"))
+	}
+
+	mp, err := machineProfile(prog)
+	if err != nil {
+		fail(err)
 	}
 
 	bufIn, bufOut := bufio.NewReader(os.Stdin), bufio.NewWriter(os.Stdout)
-	cpu := z80ex.NewCPU()
-	cpu.WriteMem(bin, 0)
 
-	var steps uint64
-	if *bound <= 0 {
-		steps = cpu.Run(bufIn, bufOut)
+	if runZ80ex != nil && !profiling && mp == z80.Flat {
+		if steps, ok := runZ80ex(bin, bufIn, bufOut); ok {
+			bufOut.Flush()
+			fmt.Printf("# T = %d\n", steps)
+			return
+		}
+	}
+
+	limit := uint64(math.MaxUint64)
+	if *bound > 0 {
+		limit = uint64(*bound)
+	}
+	m := z80.NewMachine(mp)
+	m.Load(bin)
+	m.Attach(bufIn, bufOut)
+	var p *prof.Profiler
+	if profiling {
+		syms, err := readSymbols(*labels)
+		if err != nil {
+			fail(err)
+		}
+		p = prof.New(m.CPU, syms, len(bin))
+		p.Run(limit)
 	} else {
-		steps, _ = cpu.RunBounded(bufIn, bufOut, uint64(*bound))
+		m.CPU.Run(limit)
 	}
 
 	bufOut.Flush()
-	fmt.Printf("# T = %d\n", steps)
+	fmt.Printf("# T = %d\n", m.CPU.T)
+
+	if *report != "" {
+		if err := writeFile(*report, p.WriteReport); err != nil {
+			fail(err)
+		}
+	}
+	if *pprof != "" {
+		err := writeFile(*pprof, func(w io.Writer) error { return p.WritePprof(w, prog) })
+		if err != nil {
+			fail(err)
+		}
+	}
+}
+
+func readSymbols(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	syms, err := asm.ReadSymbols(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return syms, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stderr)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// machineProfile returns the profile selected with -profile, or declared by
+// the program file name.
+func machineProfile(prog string) (z80.Profile, error) {
+	if *profile != "" {
+		return z80.ParseProfile(*profile)
+	}
+	return z80.FileProfile(prog)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
@@ -0,0 +1,43 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build z80ex
+
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/fis/aoc/z80/z80ex"
+)
+
+var useZ80ex = flag.Bool("z80ex", false, "run on the z80ex library instead of the built-in emulator")
+
+func init() {
+	runZ80ex = func(bin []byte, r io.Reader, w io.Writer) (steps uint64, ok bool) {
+		if !*useZ80ex {
+			return 0, false
+		}
+		cpu := z80ex.NewCPU()
+		defer cpu.Destroy()
+		cpu.WriteMem(bin, 0)
+		if *bound <= 0 {
+			steps = cpu.Run(r, w)
+		} else {
+			steps, _ = cpu.RunBounded(r, w, uint64(*bound))
+		}
+		return steps, true
+	}
+}
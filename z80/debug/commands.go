@@ -0,0 +1,396 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/emu"
+)
+
+// command is a debugger command.
+type command struct {
+	names []string
+	args  string
+	help  string
+	run   func(d *Debugger, w io.Writer, args string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{[]string{"help", "h", "?"}, "", "list the commands", (*Debugger).help},
+		{[]string{"break", "b"}, "[loc]", "set a breakpoint, or list breakpoints", (*Debugger).breakCmd},
+		{[]string{"delete", "d"}, "[loc]", "delete a breakpoint, or all of them", (*Debugger).deleteCmd},
+		{[]string{"step", "s"}, "[n]", "execute n (default 1) instructions", (*Debugger).stepCmd},
+		{[]string{"next", "n"}, "[n]", "like step, but run called routines to completion", (*Debugger).nextCmd},
+		{[]string{"finish", "f"}, "", "run until the current routine returns", (*Debugger).finishCmd},
+		{[]string{"continue", "c"}, "", "run until a breakpoint or halt", (*Debugger).continueCmd},
+		{[]string{"regs", "r"}, "", "show the registers and flags", (*Debugger).regsCmd},
+		{[]string{"mem", "x"}, "addr [len]", "dump len (default 64) bytes of memory", (*Debugger).memCmd},
+		{[]string{"print", "p"}, "expr", "evaluate an expression", (*Debugger).printCmd},
+		{[]string{"watch", "w"}, "[expr]", "add a watch expression, or show watches", (*Debugger).watchCmd},
+		{[]string{"unwatch", "uw"}, "[n]", "remove watch n, or all of them", (*Debugger).unwatchCmd},
+		{[]string{"list", "l"}, "[loc]", "show the source around loc (default pc)", (*Debugger).listCmd},
+		{[]string{"output", "o"}, "", "show the program's output so far", (*Debugger).outputCmd},
+		{[]string{"reset"}, "", "restart the program from the beginning", (*Debugger).resetCmd},
+	}
+}
+
+// Exec runs a single debugger command, writing its output to w. Commands are
+// words followed by arguments, like `break solve.loop` or `mem hl 16`; run
+// the `help` command for the full list. Blank lines are ignored.
+func (d *Debugger) Exec(w io.Writer, line string) error {
+	name, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if name == "" {
+		return nil
+	}
+	for _, c := range commands {
+		for _, n := range c.names {
+			if n == name {
+				return c.run(d, w, strings.TrimSpace(args))
+			}
+		}
+	}
+	return fmt.Errorf("unknown command: %s (try help)", name)
+}
+
+func (d *Debugger) help(w io.Writer, args string) error {
+	for _, c := range commands {
+		usage := strings.Join(c.names, ", ")
+		if c.args != "" {
+			usage += " " + c.args
+		}
+		fmt.Fprintf(w, "%-24s %s\n", usage, c.help)
+	}
+	return nil
+}
+
+func (d *Debugger) breakCmd(w io.Writer, args string) error {
+	if args == "" {
+		if len(d.breaks) == 0 {
+			fmt.Fprintln(w, "no breakpoints")
+		}
+		for _, addr := range d.Breakpoints() {
+			fmt.Fprintf(w, "breakpoint at %s\n", d.Where(addr))
+		}
+		return nil
+	}
+	addr, err := d.address(args)
+	if err != nil {
+		return err
+	}
+	d.breaks[addr] = true
+	fmt.Fprintf(w, "breakpoint at %s\n", d.Where(addr))
+	return nil
+}
+
+func (d *Debugger) deleteCmd(w io.Writer, args string) error {
+	if args == "" {
+		clear(d.breaks)
+		fmt.Fprintln(w, "deleted all breakpoints")
+		return nil
+	}
+	addr, err := d.address(args)
+	if err != nil {
+		return err
+	}
+	if !d.breaks[addr] {
+		return fmt.Errorf("no breakpoint at %s", d.Where(addr))
+	}
+	delete(d.breaks, addr)
+	fmt.Fprintf(w, "deleted breakpoint at %s\n", d.Where(addr))
+	return nil
+}
+
+// Breakpoints returns the addresses of all breakpoints, in increasing order.
+func (d *Debugger) Breakpoints() []uint16 {
+	addrs := make([]uint16, 0, len(d.breaks))
+	for addr := range d.breaks {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+func (d *Debugger) stepCmd(w io.Writer, args string) error {
+	n, err := count(args)
+	if err != nil {
+		return err
+	}
+	d.stopped(w, d.run(func() bool {
+		n--
+		return n == 0
+	}))
+	return nil
+}
+
+func (d *Debugger) nextCmd(w io.Writer, args string) error {
+	n, err := count(args)
+	if err != nil {
+		return err
+	}
+	for ; n > 0; n-- {
+		cpu := d.M.CPU
+		ret, sp := d.stepOver(cpu.PC), cpu.SP
+		why := d.run(func() bool { return ret < 0 || int(cpu.PC) == ret && cpu.SP == sp })
+		if why != "" {
+			d.stopped(w, why)
+			return nil
+		}
+	}
+	d.stopped(w, "")
+	return nil
+}
+
+func (d *Debugger) finishCmd(w io.Writer, args string) error {
+	cpu := d.M.CPU
+	sp := cpu.SP
+	// The routine has returned once the stack has unwound past its starting
+	// point, taking into account wrapping around the top of memory.
+	d.stopped(w, d.run(func() bool { return int16(cpu.SP-sp) > 0 }))
+	return nil
+}
+
+func (d *Debugger) continueCmd(w io.Writer, args string) error {
+	d.stopped(w, d.run(func() bool { return false }))
+	return nil
+}
+
+// stopped reports where the program stopped, and why.
+func (d *Debugger) stopped(w io.Writer, why string) {
+	pc := d.M.CPU.PC
+	if why != "" {
+		fmt.Fprintf(w, "%s: ", why)
+	}
+	fmt.Fprint(w, d.Where(pc))
+	if src, ok := d.sourceLine(pc); ok {
+		fmt.Fprintf(w, ": %s", src)
+	}
+	fmt.Fprintln(w)
+}
+
+func (d *Debugger) regsCmd(w io.Writer, args string) error {
+	for _, line := range d.Registers() {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// Registers formats the register contents and flags, and in the banked
+// profile the pages mapped into each slot.
+func (d *Debugger) Registers() []string {
+	cpu := d.M.CPU
+	alt := func(hi, lo int) uint16 { return uint16(cpu.Alt[hi])<<8 | uint16(cpu.Alt[lo]) }
+	lines := []string{
+		fmt.Sprintf("AF  %04x  BC  %04x  DE  %04x  HL  %04x", cpu.AF(), cpu.BC(), cpu.DE(), cpu.HL()),
+		fmt.Sprintf("AF' %04x  BC' %04x  DE' %04x  HL' %04x", alt(7, 6), alt(0, 1), alt(2, 3), alt(4, 5)),
+		fmt.Sprintf("IX  %04x  IY  %04x  SP  %04x  PC  %04x", cpu.IX, cpu.IY, cpu.SP, cpu.PC),
+		fmt.Sprintf("I   %02x    R   %02x    IM  %d     IFF %d%d", cpu.I, cpu.R, cpu.IM, b2i(cpu.IFF1), b2i(cpu.IFF2)),
+		fmt.Sprintf("F   %s        T   %d", emu.FlagString(cpu.Reg[emu.RegF]), cpu.T),
+	}
+	if d.M.Profile == z80.Banked {
+		mp := d.M.Mapping()
+		lines = append(lines, fmt.Sprintf("MAP %02x %02x %02x %02x", mp[0], mp[1], mp[2], mp[3]))
+	}
+	return lines
+}
+
+func (d *Debugger) memCmd(w io.Writer, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		return errors.New("usage: mem addr [len]")
+	}
+	addr, err := d.address(fields[0])
+	if err != nil {
+		return err
+	}
+	n := 64
+	if len(fields) == 2 {
+		if n, err = d.eval(fields[1]); err != nil {
+			return err
+		}
+		n = min(max(n, 1), 0x10000)
+	}
+	d.hexdump(w, addr, n)
+	return nil
+}
+
+// hexdump writes n bytes of memory starting from addr, 16 bytes per line.
+func (d *Debugger) hexdump(w io.Writer, addr uint16, n int) {
+	for n > 0 {
+		row := min(n, 16)
+		var hex, text strings.Builder
+		for i := 0; i < 16; i++ {
+			if i < row {
+				b := d.M.Read(addr + uint16(i))
+				fmt.Fprintf(&hex, "%02x ", b)
+				if b < ' ' || b > '~' {
+					b = '.'
+				}
+				text.WriteByte(b)
+			} else {
+				hex.WriteString("   ")
+			}
+			if i == 7 {
+				hex.WriteByte(' ')
+			}
+		}
+		fmt.Fprintf(w, "%04x  %s |%s|\n", addr, hex.String(), text.String())
+		addr += uint16(row)
+		n -= row
+	}
+}
+
+func (d *Debugger) printCmd(w io.Writer, args string) error {
+	v, err := d.eval(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, formatValue(v))
+	return nil
+}
+
+func formatValue(v int) string {
+	if v < 0 {
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprintf("$%04x (%d)", v, v)
+}
+
+func (d *Debugger) watchCmd(w io.Writer, args string) error {
+	if args != "" {
+		if _, err := d.eval(args); err != nil {
+			return err
+		}
+		d.watches = append(d.watches, args)
+	}
+	if len(d.watches) == 0 {
+		fmt.Fprintln(w, "no watch expressions")
+	}
+	for _, line := range d.Watches() {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+func (d *Debugger) unwatchCmd(w io.Writer, args string) error {
+	if args == "" {
+		d.watches = nil
+		return nil
+	}
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 || n > len(d.watches) {
+		return fmt.Errorf("no such watch: %s", args)
+	}
+	d.watches = append(d.watches[:n-1], d.watches[n:]...)
+	return nil
+}
+
+// Watches formats the current values of the watch expressions, numbered from
+// 1. Expressions that fail to evaluate show the error instead.
+func (d *Debugger) Watches() []string {
+	lines := make([]string, len(d.watches))
+	for i, expr := range d.watches {
+		if v, err := d.eval(expr); err != nil {
+			lines[i] = fmt.Sprintf("%d: %s = <%v>", i+1, expr, err)
+		} else {
+			lines[i] = fmt.Sprintf("%d: %s = %s", i+1, expr, formatValue(v))
+		}
+	}
+	return lines
+}
+
+func (d *Debugger) listCmd(w io.Writer, args string) error {
+	addr := d.M.CPU.PC
+	if args != "" {
+		var err error
+		if addr, err = d.address(args); err != nil {
+			return err
+		}
+	}
+	lines := d.Source(addr, 11)
+	if lines == nil {
+		return fmt.Errorf("no source for %s", d.Where(addr))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// Source formats up to n lines of the listing around the instruction at addr.
+// Each line is marked with `>` if it is the current instruction and `*` if it
+// has a breakpoint. If there is no source for the address, it returns nil.
+func (d *Debugger) Source(addr uint16, n int) []string {
+	at, ok := d.lineAt[int(addr)]
+	if !ok {
+		return nil
+	}
+	start := max(0, min(at-n/2, len(d.listing)-n))
+	end := min(start+n, len(d.listing))
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		l := d.listing[i]
+		mark := []byte("  ")
+		if len(l.Bytes) > 0 && d.lineAt[l.Addr] == i {
+			if d.breaks[uint16(l.Addr)] {
+				mark[0] = '*'
+			}
+			if uint16(l.Addr) == d.M.CPU.PC {
+				mark[1] = '>'
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s%04x  %s", mark, l.Addr, l.Text))
+	}
+	return lines
+}
+
+func (d *Debugger) outputCmd(w io.Writer, args string) error {
+	_, err := w.Write(d.Output())
+	return err
+}
+
+func (d *Debugger) resetCmd(w io.Writer, args string) error {
+	d.Reset()
+	d.stopped(w, "")
+	return nil
+}
+
+// count parses the optional repeat count argument of a command.
+func count(args string) (int, error) {
+	if args == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("bad count: %s", args)
+	}
+	return n, nil
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,299 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debug implements a source-level debugger for Z80 programs running on
+// the machine model of package z80.
+//
+// The debugger is driven by textual commands (see Exec), which makes the same
+// interface usable from the z80dbg terminal UI and from scripted tests. Source
+// lines and label names come from the listing and label files written by the
+// assembler; both are optional, though without them locations can only be
+// given as numbers.
+//
+// Locations and watch expressions use the assembler's expression syntax, with
+// a few additions: register names (a, hl, sp, pc, af' and so on) evaluate to
+// the current register contents, `$` is the program counter, labels starting
+// with a period are looked up in the scope of the routine being executed, and
+// `[expr]` and `{expr}` read a byte and a (little-endian) word of memory.
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+)
+
+// DefaultLimit is the default number of T-states a single command is allowed
+// to run for before the debugger stops the program.
+const DefaultLimit = 10_000_000_000
+
+// Debugger holds the state of a debugging session.
+type Debugger struct {
+	// M is the machine the program runs on.
+	M *z80.Machine
+	// Limit bounds the number of T-states a single command may run for.
+	Limit uint64
+
+	prog    []byte
+	syms    map[string]int
+	labels  []label // sorted by address
+	listing []asm.Line
+	lineAt  map[int]int // address of an instruction to its listing index
+
+	breaks  map[uint16]bool
+	watches []string
+
+	input  []byte
+	in     *bytes.Reader
+	output bytes.Buffer
+
+	interrupted atomic.Bool
+}
+
+type label struct {
+	name string
+	addr int
+}
+
+// New returns a debugger for a program running on a machine with the given
+// profile, with the program loaded and ready to run. The symbol table and
+// listing may be nil. The input is what the program reads from the I/O port.
+func New(prog []byte, profile z80.Profile, syms map[string]int, listing []asm.Line, input []byte) *Debugger {
+	d := &Debugger{
+		M:       z80.NewMachine(profile),
+		Limit:   DefaultLimit,
+		prog:    prog,
+		syms:    syms,
+		listing: listing,
+		lineAt:  make(map[int]int),
+		breaks:  make(map[uint16]bool),
+		input:   input,
+	}
+	for name, v := range syms {
+		d.labels = append(d.labels, label{name: name, addr: v & 0xffff})
+	}
+	sort.Slice(d.labels, func(i, j int) bool {
+		li, lj := d.labels[i], d.labels[j]
+		return li.addr < lj.addr || li.addr == lj.addr && li.name < lj.name
+	})
+	for i, l := range listing {
+		if len(l.Bytes) > 0 {
+			if _, ok := d.lineAt[l.Addr]; !ok {
+				d.lineAt[l.Addr] = i
+			}
+		}
+	}
+	d.Reset()
+	return d
+}
+
+// Reset reloads the program and rewinds its input and output. Breakpoints and
+// watch expressions are kept.
+func (d *Debugger) Reset() {
+	d.M.Load(d.prog)
+	d.in = bytes.NewReader(d.input)
+	d.output.Reset()
+	d.M.Attach(d.in, &d.output)
+}
+
+// Interrupt stops a running command at the next instruction boundary. It is
+// safe to call from another goroutine, such as a signal handler.
+func (d *Debugger) Interrupt() {
+	d.interrupted.Store(true)
+}
+
+// Output returns everything the program has written to the I/O port so far.
+func (d *Debugger) Output() []byte {
+	return d.output.Bytes()
+}
+
+// run steps the program until done returns true, a breakpoint is reached, or
+// the program halts, runs out of its T-state budget or is interrupted. At
+// least one instruction is executed. The returned string describes why the
+// program stopped, or is empty if done returned true.
+func (d *Debugger) run(done func() bool) string {
+	cpu := d.M.CPU
+	if cpu.Halted {
+		return "the program has halted"
+	}
+	d.interrupted.Store(false)
+	limit := cpu.T + d.Limit
+	for {
+		cpu.Step()
+		switch {
+		case cpu.Halted:
+			return "halted"
+		case done():
+			return ""
+		case d.breaks[cpu.PC]:
+			return "breakpoint"
+		case cpu.T >= limit:
+			return "T-state limit reached"
+		case d.interrupted.Load():
+			return "interrupted"
+		}
+	}
+}
+
+// stepOver returns the address of the instruction following the one at pc if
+// it is a call, a restart or a repeating block instruction; stepping over it
+// means running until execution returns there. Otherwise it returns -1.
+func (d *Debugger) stepOver(pc uint16) int {
+	op := d.M.Read(pc)
+	switch {
+	case op == 0xcd || op&0xc7 == 0xc4: // call nn, call cc,nn
+		return int(pc+3) & 0xffff
+	case op&0xc7 == 0xc7: // rst p
+		return int(pc+1) & 0xffff
+	case op == 0xed && d.M.Read(pc+1)&0xf4 == 0xb0: // ldir, cpir, inir, otir and the decrementing forms
+		return int(pc+2) & 0xffff
+	}
+	return -1
+}
+
+// lookup resolves an identifier in an expression: a register name or a label.
+func (d *Debugger) lookup(name string) (int, bool) {
+	if v, ok := d.register(strings.ToLower(name)); ok {
+		return v, true
+	}
+	if strings.HasPrefix(name, ".") {
+		if scope := d.scope(d.M.CPU.PC); scope != "" {
+			if v, ok := d.syms[scope+name]; ok {
+				return v, true
+			}
+		}
+	}
+	v, ok := d.syms[name]
+	return v, ok
+}
+
+func (d *Debugger) register(name string) (int, bool) {
+	cpu := d.M.CPU
+	alt := func(hi, lo int) int { return int(cpu.Alt[hi])<<8 | int(cpu.Alt[lo]) }
+	if len(name) == 1 {
+		if i := strings.Index("bcdehlfa", name); i >= 0 {
+			return int(cpu.Reg[i]), true
+		}
+	}
+	switch name {
+	case "af":
+		return int(cpu.AF()), true
+	case "bc":
+		return int(cpu.BC()), true
+	case "de":
+		return int(cpu.DE()), true
+	case "hl":
+		return int(cpu.HL()), true
+	case "af'":
+		return alt(7, 6), true
+	case "ix":
+		return int(cpu.IX), true
+	case "iy":
+		return int(cpu.IY), true
+	case "sp":
+		return int(cpu.SP), true
+	case "pc":
+		return int(cpu.PC), true
+	case "i":
+		return int(cpu.I), true
+	case "r":
+		return int(cpu.R), true
+	}
+	return 0, false
+}
+
+// eval evaluates a debugger expression.
+func (d *Debugger) eval(expr string) (int, error) {
+	// Resolve the innermost memory reference until none remain.
+	for {
+		end := strings.IndexAny(expr, "]}")
+		if end < 0 {
+			break
+		}
+		start := strings.LastIndexAny(expr[:end], "[{")
+		if start < 0 || (expr[start] == '[') != (expr[end] == ']') {
+			return 0, fmt.Errorf("unbalanced %c in expression", expr[end])
+		}
+		addr, err := d.eval(expr[start+1 : end])
+		if err != nil {
+			return 0, err
+		}
+		v := int(d.M.Read(uint16(addr)))
+		if expr[start] == '{' {
+			v |= int(d.M.Read(uint16(addr+1))) << 8
+		}
+		expr = fmt.Sprintf("%s(%d)%s", expr[:start], v, expr[end+1:])
+	}
+	if strings.ContainsAny(expr, "[{") {
+		return 0, fmt.Errorf("unbalanced brackets in expression")
+	}
+	return asm.Eval(expr, int(d.M.CPU.PC), d.lookup)
+}
+
+// address evaluates an expression giving a location in memory.
+func (d *Debugger) address(expr string) (uint16, error) {
+	v, err := d.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+	if v < -0x8000 || v > 0xffff {
+		return 0, fmt.Errorf("address out of range: %d", v)
+	}
+	return uint16(v), nil
+}
+
+// scope returns the non-local label of the routine containing an address.
+func (d *Debugger) scope(addr uint16) string {
+	i := sort.Search(len(d.labels), func(i int) bool { return d.labels[i].addr > int(addr) })
+	for i--; i >= 0; i-- {
+		if !strings.Contains(d.labels[i].name, ".") {
+			return d.labels[i].name
+		}
+	}
+	return ""
+}
+
+// Where describes an address symbolically, as the closest preceding label plus
+// an offset, along with the address itself.
+func (d *Debugger) Where(addr uint16) string {
+	i := sort.Search(len(d.labels), func(i int) bool { return d.labels[i].addr > int(addr) })
+	if i == 0 {
+		return fmt.Sprintf("$%04x", addr)
+	}
+	// Among several labels at the same address, prefer the first by name.
+	l := d.labels[i-1]
+	for i > 1 && d.labels[i-2].addr == l.addr {
+		i--
+		l = d.labels[i-1]
+	}
+	if l.addr == int(addr) {
+		return fmt.Sprintf("$%04x %s", addr, l.name)
+	}
+	return fmt.Sprintf("$%04x %s+%d", addr, l.name, int(addr)-l.addr)
+}
+
+// sourceLine returns the listing text of the instruction at an address, if
+// known.
+func (d *Debugger) sourceLine(addr uint16) (string, bool) {
+	i, ok := d.lineAt[int(addr)]
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(d.listing[i].Text), true
+}
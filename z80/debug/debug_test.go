@@ -0,0 +1,122 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testProg = `
+main:
+  ld hl, 0
+.loop:
+  in a, (1)
+  or a
+  jr z, .done
+  call succ
+  out (1), a
+  inc hl
+  jr .loop
+.done:
+  halt
+
+succ:
+  inc a
+  ret
+`
+
+// TestSession scripts a debugging session. Each step is a command, followed by
+// its expected output.
+func TestSession(t *testing.T) {
+	prog, err := asm.Assemble("test.z80", []byte(testProg), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(prog.Code, z80.Flat, prog.Symbols, prog.Listing, []byte("abc"))
+	script := []struct{ cmd, want string }{
+		{"break succ", "breakpoint at $0011 succ\n"},
+		{"watch hl", "1: hl = $0000 (0)\n"},
+		{"watch [sp] + 256*[sp+1]", "1: hl = $0000 (0)\n2: [sp] + 256*[sp+1] = $0021 (33)\n"},
+		{"continue", "breakpoint: $0011 succ: inc a\n"},
+		{"watch", "1: hl = $0000 (0)\n2: [sp] + 256*[sp+1] = $000b (11)\n"},
+		{"print {sp} == main.loop+8 && a == 'a'", "$0001 (1)\n"},
+		{"finish", "$000b main.loop+8: out (1), a\n"},
+		{"step 2", "$000e main.loop+11: jr .loop\n"},
+		{"print hl", "$0001 (1)\n"},
+		{"delete succ", "deleted breakpoint at $0011 succ\n"},
+		{"next 5", "$000b main.loop+8: out (1), a\n"},
+		{"print a", "$0063 (99)\n"},
+		{"list", "" +
+			"  0003  .loop:\n" +
+			"  0003    in a, (1)\n" +
+			"  0005    or a\n" +
+			"  0006    jr z, .done\n" +
+			"  0008    call succ\n" +
+			" >000b    out (1), a\n" +
+			"  000d    inc hl\n" +
+			"  000e    jr .loop\n" +
+			"  0010  .done:\n" +
+			"  0010    halt\n" +
+			"  0011  \n"},
+		{"output", "b"},
+		{"break .done", "breakpoint at $0010 main.done\n"},
+		{"c", "breakpoint: $0010 main.done: halt\n"},
+		{"unwatch 2", ""},
+		{"w", "1: hl = $0003 (3)\n"},
+		{"mem main 20", "" +
+			"0000  21 00 00 db 01 b7 28 08  cd 11 00 d3 01 23 18 f3  |!.....(......#..|\n" +
+			"0010  76 3c c9 00                                       |v<..|\n"},
+		{"c", "halted: $0011 succ: inc a\n"},
+		{"s", "the program has halted: $0011 succ: inc a\n"},
+		{"output", "bcd"},
+		{"reset", "$0000 main: ld hl, 0\n"},
+		{"output", ""},
+	}
+	for _, s := range script {
+		var out strings.Builder
+		if err := d.Exec(&out, s.cmd); err != nil {
+			t.Fatalf("%s: %v", s.cmd, err)
+		}
+		if diff := cmp.Diff(s.want, out.String()); diff != "" {
+			t.Errorf("%s: output mismatch (-want +got):\n%s", s.cmd, diff)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	d := New([]byte{0x76}, z80.Flat, map[string]int{"main": 0}, nil, nil)
+	tests := []struct{ cmd, want string }{
+		{"frobnicate", "unknown command: frobnicate"},
+		{"break nowhere", "undefined symbol: nowhere"},
+		{"delete main", "no breakpoint at $0000 main"},
+		{"step -1", "bad count: -1"},
+		{"mem", "usage: mem addr [len]"},
+		{"print [hl", "unbalanced brackets"},
+		{"print hl}", "unbalanced }"},
+		{"unwatch 1", "no such watch: 1"},
+		{"list", "no source for $0000 main"},
+	}
+	for _, test := range tests {
+		var out strings.Builder
+		if err := d.Exec(&out, test.cmd); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want error containing %q", test.cmd, err, test.want)
+		}
+	}
+}
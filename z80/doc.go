@@ -1,5 +1,6 @@
 // Package z80 is the parent package for all Z80-releated code.
 //
-// At the moment there is no actual code directly in this package, but it holds
-// the Go test that can be used to validate all the Z80 solutions.
+// It contains the machine model the solutions run on, and the glue to register
+// the assembled solutions (embedded by the per-year packages) as alternative
+// implementations, selectable with `aoc solve -impl=z80`.
 package z80
@@ -0,0 +1,234 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emu
+
+// parity is FlagPV for bytes with an even number of set bits.
+var parity [256]byte
+
+func init() {
+	for i := range parity {
+		p := byte(FlagPV)
+		for v := i; v != 0; v >>= 1 {
+			p ^= byte(v&1) * FlagPV
+		}
+		parity[i] = p
+	}
+}
+
+// sz returns the sign, zero and undocumented flags for a result.
+func sz(v byte) byte {
+	f := v & (FlagS | flagXY)
+	if v == 0 {
+		f |= FlagZ
+	}
+	return f
+}
+
+// szp is sz plus the parity flag.
+func szp(v byte) byte {
+	return sz(v) | parity[v]
+}
+
+func (c *CPU) carry() byte {
+	return c.Reg[RegF] & FlagC
+}
+
+// alu performs one of the eight 8-bit arithmetic operations on A, in opcode
+// order: ADD, ADC, SUB, SBC, AND, XOR, OR, CP.
+func (c *CPU) alu(op, v byte) {
+	a := c.Reg[RegA]
+	switch op {
+	case 0:
+		c.Reg[RegA] = c.add8(a, v, 0)
+	case 1:
+		c.Reg[RegA] = c.add8(a, v, c.carry())
+	case 2:
+		c.Reg[RegA] = c.sub8(a, v, 0)
+	case 3:
+		c.Reg[RegA] = c.sub8(a, v, c.carry())
+	case 4:
+		c.Reg[RegA] = a & v
+		c.Reg[RegF] = szp(a&v) | FlagH
+	case 5:
+		c.Reg[RegA] = a ^ v
+		c.Reg[RegF] = szp(a ^ v)
+	case 6:
+		c.Reg[RegA] = a | v
+		c.Reg[RegF] = szp(a | v)
+	case 7:
+		c.sub8(a, v, 0)
+		c.Reg[RegF] = c.Reg[RegF]&^flagXY | v&flagXY
+	}
+}
+
+func (c *CPU) add8(a, b, carry byte) byte {
+	r := uint16(a) + uint16(b) + uint16(carry)
+	v := byte(r)
+	f := sz(v) | (a^b^v)&FlagH
+	if (a^b)&0x80 == 0 && (a^v)&0x80 != 0 {
+		f |= FlagPV
+	}
+	if r > 0xff {
+		f |= FlagC
+	}
+	c.Reg[RegF] = f
+	return v
+}
+
+func (c *CPU) sub8(a, b, carry byte) byte {
+	r := uint16(a) - uint16(b) - uint16(carry)
+	v := byte(r)
+	f := sz(v) | (a^b^v)&FlagH | FlagN
+	if (a^b)&0x80 != 0 && (a^v)&0x80 != 0 {
+		f |= FlagPV
+	}
+	if r > 0xff {
+		f |= FlagC
+	}
+	c.Reg[RegF] = f
+	return v
+}
+
+func (c *CPU) inc8(v byte) byte {
+	v++
+	f := c.carry() | sz(v)
+	if v == 0x80 {
+		f |= FlagPV
+	}
+	if v&0x0f == 0 {
+		f |= FlagH
+	}
+	c.Reg[RegF] = f
+	return v
+}
+
+func (c *CPU) dec8(v byte) byte {
+	v--
+	f := c.carry() | sz(v) | FlagN
+	if v == 0x7f {
+		f |= FlagPV
+	}
+	if v&0x0f == 0x0f {
+		f |= FlagH
+	}
+	c.Reg[RegF] = f
+	return v
+}
+
+// add16 implements ADD HL,rp, which leaves the S, Z and P/V flags alone.
+func (c *CPU) add16(a, b uint16) uint16 {
+	r := uint32(a) + uint32(b)
+	f := c.Reg[RegF]&(FlagS|FlagZ|FlagPV) | byte(r>>8)&flagXY | byte((uint32(a)^uint32(b)^r)>>8)&FlagH
+	if r > 0xffff {
+		f |= FlagC
+	}
+	c.Reg[RegF] = f
+	return uint16(r)
+}
+
+// adc16 implements ADC HL,rp and SBC HL,rp, which set all flags.
+func (c *CPU) adc16(a, b uint16, sub bool) uint16 {
+	carry := uint32(c.carry())
+	var r uint32
+	var f byte
+	if sub {
+		r = uint32(a) - uint32(b) - carry
+		f = FlagN
+		if (a^b)&0x8000 != 0 && (a^uint16(r))&0x8000 != 0 {
+			f |= FlagPV
+		}
+	} else {
+		r = uint32(a) + uint32(b) + carry
+		if (a^b)&0x8000 == 0 && (a^uint16(r))&0x8000 != 0 {
+			f |= FlagPV
+		}
+	}
+	v := uint16(r)
+	f |= byte(v>>8)&(FlagS|flagXY) | byte((a^b^v)>>8)&FlagH
+	if v == 0 {
+		f |= FlagZ
+	}
+	if r > 0xffff {
+		f |= FlagC
+	}
+	c.Reg[RegF] = f
+	return v
+}
+
+// rot performs one of the CB-prefixed rotate and shift operations, in opcode
+// order: RLC, RRC, RL, RR, SLA, SRA, SLL, SRL.
+func (c *CPU) rot(op, v byte) byte {
+	var r, out byte
+	switch op {
+	case 0:
+		r, out = v<<1|v>>7, v>>7
+	case 1:
+		r, out = v>>1|v<<7, v&1
+	case 2:
+		r, out = v<<1|c.carry(), v>>7
+	case 3:
+		r, out = v>>1|c.carry()<<7, v&1
+	case 4:
+		r, out = v<<1, v>>7
+	case 5:
+		r, out = v>>1|v&0x80, v&1
+	case 6:
+		r, out = v<<1|1, v>>7
+	case 7:
+		r, out = v>>1, v&1
+	}
+	c.Reg[RegF] = szp(r) | out
+	return r
+}
+
+// rotA performs the accumulator rotations RLCA, RRCA, RLA and RRA, which only
+// affect the carry flag (and clear H and N).
+func (c *CPU) rotA(op byte) {
+	f := c.Reg[RegF]
+	a := c.rot(op, c.Reg[RegA])
+	c.Reg[RegA] = a
+	c.Reg[RegF] = f&(FlagS|FlagZ|FlagPV) | a&flagXY | c.Reg[RegF]&FlagC
+}
+
+func (c *CPU) bit(b, v byte) {
+	f := c.carry() | FlagH | v&flagXY
+	if v&(1<<b) == 0 {
+		f |= FlagZ | FlagPV
+	} else if b == 7 {
+		f |= FlagS
+	}
+	c.Reg[RegF] = f
+}
+
+func (c *CPU) daa() {
+	a, f := c.Reg[RegA], c.Reg[RegF]
+	var corr byte
+	carry := f & FlagC
+	if f&FlagH != 0 || a&0x0f > 9 {
+		corr |= 0x06
+	}
+	if carry != 0 || a > 0x99 {
+		corr |= 0x60
+		carry = FlagC
+	}
+	var r byte
+	if f&FlagN != 0 {
+		r = a - corr
+	} else {
+		r = a + corr
+	}
+	c.Reg[RegA] = r
+	c.Reg[RegF] = szp(r) | f&FlagN | carry | (a^r)&FlagH
+}
@@ -0,0 +1,323 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package emu implements a Z80 CPU emulator.
+//
+// The emulator executes one instruction at a time, and keeps count of the
+// T-states (clock cycles) taken, using the documented instruction timings. All
+// documented instructions are supported, as well as the common undocumented
+// ones (the IXH/IXL/IYH/IYL halves, SLL and the DDCB forms that also store
+// into a register). The undocumented flag bits 3 and 5 are set from the
+// result in the usual way. Interrupts are not emulated.
+package emu
+
+import (
+	"fmt"
+)
+
+// Bus connects the CPU to memory and I/O devices.
+type Bus interface {
+	Read(addr uint16) byte
+	Write(addr uint16, v byte)
+	In(port uint16) byte
+	Out(port uint16, v byte)
+}
+
+// Register codes, as used for indexing CPU.Reg. These are the 3-bit codes that
+// identify 8-bit registers in instruction opcodes, except that code 6 (which
+// stands for the (HL) memory operand) is used for the flags register.
+const (
+	RegB = iota
+	RegC
+	RegD
+	RegE
+	RegH
+	RegL
+	RegF
+	RegA
+)
+
+// Flag bits of the F register.
+const (
+	FlagC  = 0x01 // carry
+	FlagN  = 0x02 // add/subtract
+	FlagPV = 0x04 // parity/overflow
+	FlagX  = 0x08 // undocumented copy of bit 3
+	FlagH  = 0x10 // half carry
+	FlagY  = 0x20 // undocumented copy of bit 5
+	FlagZ  = 0x40 // zero
+	FlagS  = 0x80 // sign
+)
+
+const flagXY = FlagX | FlagY
+
+// CPU is the state of an emulated Z80 processor.
+type CPU struct {
+	// Reg holds the main 8-bit registers, indexed by the Reg* constants.
+	Reg [8]byte
+	// Alt holds the alternate register set (as switched by EXX and EX AF,AF'),
+	// in the same order.
+	Alt [8]byte
+
+	IX, IY, SP, PC uint16
+	I, R           byte
+	IFF1, IFF2     bool
+	IM             byte
+
+	// Halted is set when the CPU executes a HALT instruction. It stays halted
+	// until the flag is cleared.
+	Halted bool
+	// T is the total number of T-states taken so far.
+	T uint64
+
+	Bus Bus
+}
+
+// New returns a CPU in its reset state, attached to the given bus.
+func New(bus Bus) *CPU {
+	c := &CPU{Bus: bus}
+	c.Reset()
+	return c
+}
+
+// Reset sets the CPU to its initial state. All registers are cleared, except
+// that AF and SP are set to 0xffff, as on real hardware.
+func (c *CPU) Reset() {
+	*c = CPU{Bus: c.Bus}
+	c.SetAF(0xffff)
+	c.SP = 0xffff
+}
+
+func (c *CPU) pair(hi, lo int) uint16 { return uint16(c.Reg[hi])<<8 | uint16(c.Reg[lo]) }
+
+func (c *CPU) setPair(hi, lo int, v uint16) {
+	c.Reg[hi], c.Reg[lo] = byte(v>>8), byte(v)
+}
+
+// AF returns the combined value of the A and F registers.
+func (c *CPU) AF() uint16 { return c.pair(RegA, RegF) }
+
+// BC returns the combined value of the B and C registers.
+func (c *CPU) BC() uint16 { return c.pair(RegB, RegC) }
+
+// DE returns the combined value of the D and E registers.
+func (c *CPU) DE() uint16 { return c.pair(RegD, RegE) }
+
+// HL returns the combined value of the H and L registers.
+func (c *CPU) HL() uint16 { return c.pair(RegH, RegL) }
+
+// SetAF sets the A and F registers.
+func (c *CPU) SetAF(v uint16) { c.setPair(RegA, RegF, v) }
+
+// SetBC sets the B and C registers.
+func (c *CPU) SetBC(v uint16) { c.setPair(RegB, RegC, v) }
+
+// SetDE sets the D and E registers.
+func (c *CPU) SetDE(v uint16) { c.setPair(RegD, RegE, v) }
+
+// SetHL sets the H and L registers.
+func (c *CPU) SetHL(v uint16) { c.setPair(RegH, RegL, v) }
+
+// String formats the register contents on a single line.
+func (c *CPU) String() string {
+	return fmt.Sprintf("AF=%04x BC=%04x DE=%04x HL=%04x IX=%04x IY=%04x SP=%04x PC=%04x %s",
+		c.AF(), c.BC(), c.DE(), c.HL(), c.IX, c.IY, c.SP, c.PC, FlagString(c.Reg[RegF]))
+}
+
+// FlagString formats the flags register as a string of letters, with a letter
+// shown in lower case if the flag is not set.
+func FlagString(f byte) string {
+	const names = "SZYHXPNC"
+	var out [8]byte
+	for i := range out {
+		out[i] = names[i]
+		if f&(0x80>>i) == 0 {
+			out[i] += 'a' - 'A'
+		}
+	}
+	return string(out[:])
+}
+
+// Run executes instructions until the CPU halts or the T-state counter reaches
+// the limit. It reports whether the CPU halted.
+func (c *CPU) Run(limit uint64) bool {
+	for !c.Halted && c.T < limit {
+		c.Step()
+	}
+	return c.Halted
+}
+
+// Step executes a single instruction, and returns the number of T-states it
+// took. A halted CPU does nothing.
+func (c *CPU) Step() int {
+	if c.Halted {
+		return 0
+	}
+	t0 := c.T
+	c.exec(c.fetchOp(), 0)
+	return int(c.T - t0)
+}
+
+// fetchOp reads an opcode byte, incrementing the R register.
+func (c *CPU) fetchOp() byte {
+	c.R = c.R&0x80 | (c.R+1)&0x7f
+	return c.fetch()
+}
+
+func (c *CPU) fetch() byte {
+	v := c.Bus.Read(c.PC)
+	c.PC++
+	return v
+}
+
+func (c *CPU) fetch16() uint16 {
+	lo := c.fetch()
+	return uint16(c.fetch())<<8 | uint16(lo)
+}
+
+func (c *CPU) read16(addr uint16) uint16 {
+	return uint16(c.Bus.Read(addr+1))<<8 | uint16(c.Bus.Read(addr))
+}
+
+func (c *CPU) write16(addr, v uint16) {
+	c.Bus.Write(addr, byte(v))
+	c.Bus.Write(addr+1, byte(v>>8))
+}
+
+func (c *CPU) push(v uint16) {
+	c.SP -= 2
+	c.write16(c.SP, v)
+}
+
+func (c *CPU) pop() uint16 {
+	v := c.read16(c.SP)
+	c.SP += 2
+	return v
+}
+
+// Index register modes, selecting what takes the place of HL.
+const (
+	useHL = iota
+	useIX
+	useIY
+)
+
+// hl returns HL, IX or IY, depending on the index mode.
+func (c *CPU) hl(idx int) uint16 {
+	switch idx {
+	case useIX:
+		return c.IX
+	case useIY:
+		return c.IY
+	}
+	return c.HL()
+}
+
+func (c *CPU) setHL(idx int, v uint16) {
+	switch idx {
+	case useIX:
+		c.IX = v
+	case useIY:
+		c.IY = v
+	default:
+		c.SetHL(v)
+	}
+}
+
+// rp returns a register pair by its opcode code: BC, DE, HL (or an index
+// register), SP.
+func (c *CPU) rp(p byte, idx int) uint16 {
+	switch p {
+	case 0:
+		return c.BC()
+	case 1:
+		return c.DE()
+	case 2:
+		return c.hl(idx)
+	}
+	return c.SP
+}
+
+func (c *CPU) setRP(p byte, idx int, v uint16) {
+	switch p {
+	case 0:
+		c.SetBC(v)
+	case 1:
+		c.SetDE(v)
+	case 2:
+		c.setHL(idx, v)
+	default:
+		c.SP = v
+	}
+}
+
+// get8 reads an 8-bit register by code, with H and L replaced by the halves of
+// the index register if one is in use. Code 6 is not valid here.
+func (c *CPU) get8(r byte, idx int) byte {
+	if idx != useHL && (r == RegH || r == RegL) {
+		v := c.hl(idx)
+		if r == RegH {
+			return byte(v >> 8)
+		}
+		return byte(v)
+	}
+	return c.Reg[r]
+}
+
+func (c *CPU) set8(r byte, idx int, v byte) {
+	if idx != useHL && (r == RegH || r == RegL) {
+		x := c.hl(idx)
+		if r == RegH {
+			x = x&0x00ff | uint16(v)<<8
+		} else {
+			x = x&0xff00 | uint16(v)
+		}
+		c.setHL(idx, x)
+		return
+	}
+	c.Reg[r] = v
+}
+
+// addr returns the address of the (HL) operand, which is (IX+d) or (IY+d) in
+// the indexed modes. Fetching and adding the displacement takes 8 T-states.
+func (c *CPU) addr(idx int) uint16 {
+	if idx == useHL {
+		return c.HL()
+	}
+	d := int8(c.fetch())
+	c.T += 8
+	return c.hl(idx) + uint16(d)
+}
+
+// cond evaluates one of the eight condition codes.
+func (c *CPU) cond(cc byte) bool {
+	f := c.Reg[RegF]
+	switch cc {
+	case 0:
+		return f&FlagZ == 0
+	case 1:
+		return f&FlagZ != 0
+	case 2:
+		return f&FlagC == 0
+	case 3:
+		return f&FlagC != 0
+	case 4:
+		return f&FlagPV == 0
+	case 5:
+		return f&FlagPV != 0
+	case 6:
+		return f&FlagS == 0
+	}
+	return f&FlagS != 0
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emu
+
+import (
+	"testing"
+
+	"github.com/fis/aoc/z80/asm"
+)
+
+type testBus struct {
+	mem [0x10000]byte
+	out []byte
+}
+
+func (b *testBus) Read(addr uint16) byte     { return b.mem[addr] }
+func (b *testBus) Write(addr uint16, v byte) { b.mem[addr] = v }
+func (b *testBus) In(port uint16) byte       { return byte(port) }
+func (b *testBus) Out(port uint16, v byte)   { b.out = append(b.out, v) }
+
+func run(t *testing.T, src string) (*CPU, *testBus) {
+	t.Helper()
+	prog, err := asm.Assemble("test.z80", []byte(src+"\n  halt\n"), nil)
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	bus := &testBus{}
+	copy(bus.mem[:], prog.Code)
+	c := New(bus)
+	c.SetAF(0)
+	c.SP = 0
+	if !c.Run(1_000_000) {
+		t.Fatalf("%q: did not halt", src)
+	}
+	return c, bus
+}
+
+func TestInstructions(t *testing.T) {
+	tests := []struct {
+		src   string
+		af    uint16
+		check func(c *CPU, bus *testBus) bool
+	}{
+		{src: "  ld a, 0x7f\n  add a, 1", af: 0x8094},
+		{src: "  ld a, 0xff\n  add a, 1", af: 0x0051},
+		{src: "  ld a, 0x10\n  sub 0x20", af: 0xf0a3},
+		{src: "  ld a, 0x80\n  sub 1", af: 0x7f3e},
+		{src: "  ld a, 0x42\n  cp 0x42", af: 0x4242},
+		{src: "  ld a, 0x15\n  add a, 0x27\n  daa", af: 0x4214},
+		{src: "  ld a, 0x42\n  sub 0x15\n  daa", af: 0x2726},
+		{src: "  ld a, 0x81\n  rlca", af: 0x0301},
+		{src: "  ld a, 0x81\n  and a\n  rla", af: 0x0285},
+		{src: "  ld a, 0x0f\n  inc a", af: 0x1010},
+		{src: "  ld b, 0x80\n  dec b\n  ld a, b", af: 0x7f3e},
+		{src: "  ld a, 5\n  neg", af: 0xfbbb},
+		{src: "  ld a, 0x12\n  ld hl, 0x100\n  ld (hl), 0x34\n  rrd", af: 0x1404, check: func(c *CPU, bus *testBus) bool {
+			return bus.mem[0x100] == 0x23
+		}},
+		{src: "  ld hl, 0x7fff\n  ld de, 1\n  and a\n  adc hl, de", af: 0x0094, check: func(c *CPU, bus *testBus) bool {
+			return c.HL() == 0x8000
+		}},
+		{src: "  ld hl, 0\n  ld de, 1\n  and a\n  sbc hl, de", af: 0x00bb, check: func(c *CPU, bus *testBus) bool {
+			return c.HL() == 0xffff
+		}},
+		{src: "  ld ix, 0x200\n  ld (ix+5), 0x80\n  ld a, 0\n  bit 7, (ix+5)", af: 0x0090},
+		{src: "  ld ix, 0x200\n  ld (ix-1), 3\n  sla (ix-1)\n  ld a, (0x1ff)", af: 0x0604},
+		{src: "  ld ix, 0x1234\n  ld a, ixh\n  add a, ixl", af: 0x4600},
+		{src: "  ld bc, 0x1234\n  push bc\n  pop af", af: 0x1234},
+		{src: "  ld hl, 0x100\n  ld (hl), 1\n  ld de, 0x101\n  ld bc, 4\n  ldir\n  ld a, (0x104)", af: 0x0100},
+		{src: "  ld hl, 0x100\n  ld (hl), 9\n  ld bc, 8\n  ld a, 9\n  cpir\n  ld a, c", af: 0x0746},
+		{src: "  ld bc, 0x0301\n  in a, (c)", af: 0x0100, check: func(c *CPU, bus *testBus) bool {
+			return c.Reg[RegF]&FlagZ == 0
+		}},
+		{src: "  ld a, 'x'\n  out (1), a\n  exx\n  ex af, af'", af: 0x0000, check: func(c *CPU, bus *testBus) bool {
+			return string(bus.out) == "x" && c.Alt[RegA] == 'x'
+		}},
+		{src: "  ld sp, 0x1000\n  call sub\n  jr end\nsub:\n  ld a, 1\n  ret\nend:", af: 0x0100, check: func(c *CPU, bus *testBus) bool {
+			return c.SP == 0x1000
+		}},
+	}
+	for _, test := range tests {
+		c, bus := run(t, test.src)
+		if c.AF() != test.af {
+			t.Errorf("%q: AF = %04x (%s), want %04x (%s)", test.src, c.AF(), FlagString(c.Reg[RegF]), test.af, FlagString(byte(test.af)))
+		}
+		if test.check != nil && !test.check(c, bus) {
+			t.Errorf("%q: check failed: %v", test.src, c)
+		}
+	}
+}
+
+func TestTiming(t *testing.T) {
+	tests := []struct {
+		src  string
+		want uint64
+	}{
+		{"  nop", 4},
+		{"  ld a, (ix+1)", 19},
+		{"  ld (iy+1), 2", 19},
+		{"  inc (ix+0)", 23},
+		{"  ld ixh, 1", 11},
+		{"  rl (ix+1)", 23},
+		{"  bit 0, (iy+1)", 20},
+		{"  bit 0, (hl)", 12},
+		{"  set 0, (hl)", 15},
+		{"  ld b, 3\n.l:\n  djnz .l", 7 + 13 + 13 + 8},
+		{"  ld bc, 3\n  ldir", 10 + 21 + 21 + 16},
+		{"  xor a\n  jr z, $+2\n  jr nz, $+2", 4 + 12 + 7},
+		{"  call f\n  jr done\nf:\n  ret nz\n  ret\ndone:", 17 + 11 + 12},
+		{"  xor a\n  ret nz\n  call nz, 0", 4 + 5 + 10},
+		{"  ld hl, (0)\n  ld de, (0)\n  ld ix, (0)", 16 + 20 + 20},
+		{"  push ix\n  pop iy\n  ex (sp), hl", 15 + 14 + 19},
+	}
+	for _, test := range tests {
+		c, _ := run(t, test.src)
+		if got := c.T - 4; got != test.want {
+			t.Errorf("%q: T = %d, want %d", test.src, got, test.want)
+		}
+	}
+}
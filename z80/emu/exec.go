@@ -0,0 +1,503 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emu
+
+// The instruction decoding follows the usual x/y/z/p/q breakdown of opcode
+// bits: x = bits 7-6, y = bits 5-3, z = bits 2-0, p = bits 5-4, q = bit 3.
+
+// exec executes an unprefixed opcode, or one following a DD or FD prefix if
+// idx is not useHL. The prefix itself is accounted for by the caller.
+func (c *CPU) exec(op byte, idx int) {
+	x, y, z := op>>6, op>>3&7, op&7
+	p, q := y>>1, y&1
+
+	switch x {
+	case 1:
+		switch {
+		case op == 0x76:
+			c.Halted = true
+			c.T += 4
+		case z == 6:
+			a := c.addr(idx)
+			c.Reg[y] = c.Bus.Read(a)
+			c.T += 7
+		case y == 6:
+			a := c.addr(idx)
+			c.Bus.Write(a, c.Reg[z])
+			c.T += 7
+		default:
+			c.set8(y, idx, c.get8(z, idx))
+			c.T += 4
+		}
+		return
+	case 2:
+		if z == 6 {
+			c.alu(y, c.Bus.Read(c.addr(idx)))
+			c.T += 7
+		} else {
+			c.alu(y, c.get8(z, idx))
+			c.T += 4
+		}
+		return
+	case 0:
+		c.exec0(y, z, p, q, idx)
+		return
+	}
+
+	switch z {
+	case 0: // RET cc
+		if c.cond(y) {
+			c.PC = c.pop()
+			c.T += 11
+		} else {
+			c.T += 5
+		}
+	case 1:
+		switch {
+		case q == 0: // POP rp2
+			v := c.pop()
+			if p == 3 {
+				c.SetAF(v)
+			} else {
+				c.setRP(p, idx, v)
+			}
+			c.T += 10
+		case p == 0: // RET
+			c.PC = c.pop()
+			c.T += 10
+		case p == 1: // EXX
+			for r := RegB; r <= RegL; r++ {
+				c.Reg[r], c.Alt[r] = c.Alt[r], c.Reg[r]
+			}
+			c.T += 4
+		case p == 2: // JP (HL)
+			c.PC = c.hl(idx)
+			c.T += 4
+		default: // LD SP,HL
+			c.SP = c.hl(idx)
+			c.T += 6
+		}
+	case 2: // JP cc,nn
+		nn := c.fetch16()
+		if c.cond(y) {
+			c.PC = nn
+		}
+		c.T += 10
+	case 3:
+		switch y {
+		case 0: // JP nn
+			c.PC = c.fetch16()
+			c.T += 10
+		case 1:
+			c.execCB(idx)
+		case 2: // OUT (n),A
+			n := c.fetch()
+			c.Bus.Out(uint16(c.Reg[RegA])<<8|uint16(n), c.Reg[RegA])
+			c.T += 11
+		case 3: // IN A,(n)
+			n := c.fetch()
+			c.Reg[RegA] = c.Bus.In(uint16(c.Reg[RegA])<<8 | uint16(n))
+			c.T += 11
+		case 4: // EX (SP),HL
+			v := c.read16(c.SP)
+			c.write16(c.SP, c.hl(idx))
+			c.setHL(idx, v)
+			c.T += 19
+		case 5: // EX DE,HL
+			de := c.DE()
+			c.SetDE(c.HL())
+			c.SetHL(de)
+			c.T += 4
+		case 6: // DI
+			c.IFF1, c.IFF2 = false, false
+			c.T += 4
+		case 7: // EI
+			c.IFF1, c.IFF2 = true, true
+			c.T += 4
+		}
+	case 4: // CALL cc,nn
+		nn := c.fetch16()
+		if c.cond(y) {
+			c.push(c.PC)
+			c.PC = nn
+			c.T += 17
+		} else {
+			c.T += 10
+		}
+	case 5:
+		switch {
+		case q == 0: // PUSH rp2
+			if p == 3 {
+				c.push(c.AF())
+			} else {
+				c.push(c.rp(p, idx))
+			}
+			c.T += 11
+		case p == 0: // CALL nn
+			nn := c.fetch16()
+			c.push(c.PC)
+			c.PC = nn
+			c.T += 17
+		case p == 1:
+			c.prefix(useIX)
+		case p == 2:
+			c.execED()
+		case p == 3:
+			c.prefix(useIY)
+		}
+	case 6: // alu n
+		c.alu(y, c.fetch())
+		c.T += 7
+	case 7: // RST
+		c.push(c.PC)
+		c.PC = uint16(y) * 8
+		c.T += 11
+	}
+}
+
+// prefix handles a DD or FD prefix. The prefix takes 4 T-states, and affects
+// the following instruction only if that uses HL.
+func (c *CPU) prefix(idx int) {
+	c.T += 4
+	c.exec(c.fetchOp(), idx)
+}
+
+func (c *CPU) exec0(y, z, p, q byte, idx int) {
+	switch z {
+	case 0:
+		switch y {
+		case 0: // NOP
+			c.T += 4
+		case 1: // EX AF,AF'
+			c.Reg[RegA], c.Alt[RegA] = c.Alt[RegA], c.Reg[RegA]
+			c.Reg[RegF], c.Alt[RegF] = c.Alt[RegF], c.Reg[RegF]
+			c.T += 4
+		case 2: // DJNZ d
+			d := int8(c.fetch())
+			c.Reg[RegB]--
+			if c.Reg[RegB] != 0 {
+				c.PC += uint16(d)
+				c.T += 13
+			} else {
+				c.T += 8
+			}
+		case 3: // JR d
+			d := int8(c.fetch())
+			c.PC += uint16(d)
+			c.T += 12
+		default: // JR cc,d
+			d := int8(c.fetch())
+			if c.cond(y - 4) {
+				c.PC += uint16(d)
+				c.T += 12
+			} else {
+				c.T += 7
+			}
+		}
+	case 1:
+		if q == 0 { // LD rp,nn
+			c.setRP(p, idx, c.fetch16())
+			c.T += 10
+		} else { // ADD HL,rp
+			c.setHL(idx, c.add16(c.hl(idx), c.rp(p, idx)))
+			c.T += 11
+		}
+	case 2:
+		switch p<<1 | q {
+		case 0: // LD (BC),A
+			c.Bus.Write(c.BC(), c.Reg[RegA])
+			c.T += 7
+		case 1: // LD A,(BC)
+			c.Reg[RegA] = c.Bus.Read(c.BC())
+			c.T += 7
+		case 2: // LD (DE),A
+			c.Bus.Write(c.DE(), c.Reg[RegA])
+			c.T += 7
+		case 3: // LD A,(DE)
+			c.Reg[RegA] = c.Bus.Read(c.DE())
+			c.T += 7
+		case 4: // LD (nn),HL
+			c.write16(c.fetch16(), c.hl(idx))
+			c.T += 16
+		case 5: // LD HL,(nn)
+			c.setHL(idx, c.read16(c.fetch16()))
+			c.T += 16
+		case 6: // LD (nn),A
+			c.Bus.Write(c.fetch16(), c.Reg[RegA])
+			c.T += 13
+		case 7: // LD A,(nn)
+			c.Reg[RegA] = c.Bus.Read(c.fetch16())
+			c.T += 13
+		}
+	case 3: // INC rp, DEC rp
+		if q == 0 {
+			c.setRP(p, idx, c.rp(p, idx)+1)
+		} else {
+			c.setRP(p, idx, c.rp(p, idx)-1)
+		}
+		c.T += 6
+	case 4, 5: // INC r, DEC r
+		op := c.inc8
+		if z == 5 {
+			op = c.dec8
+		}
+		if y == 6 {
+			a := c.addr(idx)
+			c.Bus.Write(a, op(c.Bus.Read(a)))
+			c.T += 11
+		} else {
+			c.set8(y, idx, op(c.get8(y, idx)))
+			c.T += 4
+		}
+	case 6: // LD r,n
+		if y == 6 {
+			a := c.addr(idx)
+			c.Bus.Write(a, c.fetch())
+			c.T += 10
+			if idx != useHL {
+				c.T -= 3 // the displacement and immediate fetches overlap
+			}
+		} else {
+			c.set8(y, idx, c.fetch())
+			c.T += 7
+		}
+	case 7:
+		a, f := c.Reg[RegA], c.Reg[RegF]
+		switch y {
+		case 4: // DAA
+			c.daa()
+		case 5: // CPL
+			a = ^a
+			c.Reg[RegA] = a
+			c.Reg[RegF] = f&(FlagS|FlagZ|FlagPV|FlagC) | FlagH | FlagN | a&flagXY
+		case 6: // SCF
+			c.Reg[RegF] = f&(FlagS|FlagZ|FlagPV) | FlagC | a&flagXY
+		case 7: // CCF
+			c.Reg[RegF] = f&(FlagS|FlagZ|FlagPV) | (f&FlagC)<<4 | ^f&FlagC | a&flagXY
+		default: // RLCA, RRCA, RLA, RRA
+			c.rotA(y)
+		}
+		c.T += 4
+	}
+}
+
+// execCB executes a CB-prefixed instruction. In the indexed forms, the
+// displacement comes before the final opcode.
+func (c *CPU) execCB(idx int) {
+	if idx != useHL {
+		a := c.hl(idx) + uint16(int8(c.fetch()))
+		op := c.fetch()
+		x, y, z := op>>6, op>>3&7, op&7
+		v := c.Bus.Read(a)
+		if x == 1 {
+			c.bit(y, v)
+			c.T += 16
+			return
+		}
+		v = c.cbOp(x, y, v)
+		c.Bus.Write(a, v)
+		if z != 6 {
+			c.Reg[z] = v
+		}
+		c.T += 19
+		return
+	}
+
+	op := c.fetchOp()
+	x, y, z := op>>6, op>>3&7, op&7
+	if z == 6 {
+		v := c.Bus.Read(c.HL())
+		if x == 1 {
+			c.bit(y, v)
+			c.T += 12
+			return
+		}
+		c.Bus.Write(c.HL(), c.cbOp(x, y, v))
+		c.T += 15
+		return
+	}
+	if x == 1 {
+		c.bit(y, c.Reg[z])
+	} else {
+		c.Reg[z] = c.cbOp(x, y, c.Reg[z])
+	}
+	c.T += 8
+}
+
+// cbOp computes the result of a CB-prefixed rotate, shift, RES or SET.
+func (c *CPU) cbOp(x, y, v byte) byte {
+	switch x {
+	case 0:
+		return c.rot(y, v)
+	case 2:
+		return v &^ (1 << y)
+	}
+	return v | 1<<y
+}
+
+// execED executes an ED-prefixed instruction. Undefined ones act as an 8
+// T-state NOP.
+func (c *CPU) execED() {
+	op := c.fetchOp()
+	x, y, z := op>>6, op>>3&7, op&7
+	p, q := y>>1, y&1
+
+	if x == 2 && z <= 3 && y >= 4 {
+		c.block(y, z)
+		return
+	}
+	if x != 1 {
+		c.T += 8
+		return
+	}
+
+	switch z {
+	case 0: // IN r,(C)
+		v := c.Bus.In(c.BC())
+		if y != 6 {
+			c.Reg[y] = v
+		}
+		c.Reg[RegF] = szp(v) | c.carry()
+		c.T += 12
+	case 1: // OUT (C),r
+		var v byte
+		if y != 6 {
+			v = c.Reg[y]
+		}
+		c.Bus.Out(c.BC(), v)
+		c.T += 12
+	case 2: // SBC HL,rp, ADC HL,rp
+		c.SetHL(c.adc16(c.HL(), c.rp(p, useHL), q == 0))
+		c.T += 15
+	case 3: // LD (nn),rp, LD rp,(nn)
+		nn := c.fetch16()
+		if q == 0 {
+			c.write16(nn, c.rp(p, useHL))
+		} else {
+			c.setRP(p, useHL, c.read16(nn))
+		}
+		c.T += 20
+	case 4: // NEG
+		c.Reg[RegA] = c.sub8(0, c.Reg[RegA], 0)
+		c.T += 8
+	case 5: // RETN, RETI
+		c.PC = c.pop()
+		c.IFF1 = c.IFF2
+		c.T += 14
+	case 6: // IM
+		c.IM = [8]byte{0, 0, 1, 2, 0, 0, 1, 2}[y]
+		c.T += 8
+	case 7:
+		a := c.Reg[RegA]
+		switch y {
+		case 0: // LD I,A
+			c.I = a
+			c.T += 9
+		case 1: // LD R,A
+			c.R = a
+			c.T += 9
+		case 2, 3: // LD A,I, LD A,R
+			v := c.I
+			if y == 3 {
+				v = c.R
+			}
+			c.Reg[RegA] = v
+			f := sz(v) | c.carry()
+			if c.IFF2 {
+				f |= FlagPV
+			}
+			c.Reg[RegF] = f
+			c.T += 9
+		case 4, 5: // RRD, RLD
+			m := c.Bus.Read(c.HL())
+			if y == 4 {
+				c.Bus.Write(c.HL(), a<<4|m>>4)
+				a = a&0xf0 | m&0x0f
+			} else {
+				c.Bus.Write(c.HL(), m<<4|a&0x0f)
+				a = a&0xf0 | m>>4
+			}
+			c.Reg[RegA] = a
+			c.Reg[RegF] = szp(a) | c.carry()
+			c.T += 18
+		default:
+			c.T += 8
+		}
+	}
+}
+
+// block executes the block transfer, search and I/O instructions. Bit 0 of y
+// selects decrementing, and bit 1 repeating. A repeating instruction that has
+// not finished moves PC back to itself, so that it is executed again.
+func (c *CPU) block(y, z byte) {
+	delta := uint16(1)
+	if y&1 != 0 {
+		delta = 0xffff
+	}
+	hl := c.HL()
+	c.SetHL(hl + delta)
+	f := c.Reg[RegF]
+	var again bool
+
+	switch z {
+	case 0: // LDI, LDD, LDIR, LDDR
+		v := c.Bus.Read(hl)
+		de := c.DE()
+		c.Bus.Write(de, v)
+		c.SetDE(de + delta)
+		bc := c.BC() - 1
+		c.SetBC(bc)
+		n := v + c.Reg[RegA]
+		f = f&(FlagS|FlagZ|FlagC) | n&FlagX | n<<4&FlagY
+		if bc != 0 {
+			f |= FlagPV
+			again = true
+		}
+	case 1: // CPI, CPD, CPIR, CPDR
+		a, v := c.Reg[RegA], c.Bus.Read(hl)
+		r := a - v
+		bc := c.BC() - 1
+		c.SetBC(bc)
+		f = sz(r)&^flagXY | (a^v^r)&FlagH | FlagN | f&FlagC
+		n := r
+		if f&FlagH != 0 {
+			n--
+		}
+		f |= n&FlagX | n<<4&FlagY
+		if bc != 0 {
+			f |= FlagPV
+			again = r != 0
+		}
+	case 2: // INI, IND, INIR, INDR
+		c.Bus.Write(hl, c.Bus.In(c.BC()))
+		c.Reg[RegB]--
+		f = sz(c.Reg[RegB]) | FlagN
+		again = c.Reg[RegB] != 0
+	case 3: // OUTI, OUTD, OTIR, OTDR
+		v := c.Bus.Read(hl)
+		c.Reg[RegB]--
+		c.Bus.Out(c.BC(), v)
+		f = sz(c.Reg[RegB]) | FlagN
+		again = c.Reg[RegB] != 0
+	}
+	c.Reg[RegF] = f
+
+	if y >= 6 && again {
+		c.PC -= 2
+		c.T += 21
+	} else {
+		c.T += 16
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package z80
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/fis/aoc/z80/emu"
+)
+
+// The I/O port map of the machine. Ports not listed here read as 0 and ignore
+// writes, as do the slot ports in the flat profile.
+const (
+	// IOPort is the I/O port connected to the input and output streams.
+	IOPort = 0x01
+	// SlotPort is the first of four consecutive ports, one for each slot of
+	// the address space, which select the page of RAM mapped into the slot in
+	// the banked profile. Reading the port returns the current page.
+	SlotPort = 0x10
+)
+
+// Memory geometry of the banked profile.
+const (
+	// PageSize is the size of a page of RAM, and of a slot of the address
+	// space it can be mapped into.
+	PageSize = 0x4000
+	// NumPages is the number of pages of RAM (4 MiB in total).
+	NumPages = 256
+)
+
+// Profile selects the memory system of the machine.
+type Profile int
+
+const (
+	// Flat is the default profile, with 64 KiB of RAM filling the address
+	// space.
+	Flat Profile = iota
+	// Banked extends the flat profile with paged RAM. The address space is
+	// divided into four 16 KiB slots, and writing a page number to the slot
+	// port (SlotPort+n) of a slot maps that page of RAM into it. Initially
+	// slot n is mapped to page n, which makes the machine look exactly like
+	// the flat one until the mapping is changed.
+	//
+	// The library routines (see far_map) use slot 1 (0x4000-0x7fff) as their
+	// window into the paged memory, leaving slot 0 for the program and slot
+	// 3 for the stack.
+	Banked
+)
+
+var profileNames = []string{Flat: "flat", Banked: "banked"}
+
+func (p Profile) String() string {
+	if int(p) < len(profileNames) {
+		return profileNames[p]
+	}
+	return fmt.Sprintf("Profile(%d)", int(p))
+}
+
+// ParseProfile returns the profile with the given name.
+func ParseProfile(name string) (Profile, error) {
+	for p, n := range profileNames {
+		if n == name {
+			return Profile(p), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown machine profile: %s", name)
+}
+
+// FileProfile returns the profile a program declares by its file name. Files
+// named like "prog.banked.bin" (or "prog.banked.z80") are meant to run on the
+// banked profile; other names, with no profile before the extension, on the
+// flat one.
+func FileProfile(name string) (Profile, error) {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if ext := path.Ext(base); ext != "" {
+		return ParseProfile(ext[1:])
+	}
+	return Flat, nil
+}
+
+// Machine is the machine model the solutions run on. In the flat profile, it
+// has 64 KiB of RAM, filling the address space; in the banked profile, it has
+// NumPages pages of RAM, mapped into the address space through the slot ports.
+// Reading from I/O port 1 reads a byte of input (0 at end of file), and
+// writing to it writes a byte of output.
+type Machine struct {
+	CPU     *emu.CPU
+	Profile Profile
+
+	pages   [NumPages]*[PageSize]byte // allocated when first mapped
+	slots   [4]*[PageSize]byte
+	mapping [4]byte
+
+	in  io.ByteReader
+	out io.ByteWriter
+}
+
+// NewMachine returns a new machine with the given profile, with all memory
+// cleared.
+func NewMachine(p Profile) *Machine {
+	m := &Machine{Profile: p}
+	m.CPU = emu.New(m)
+	m.Load(nil)
+	return m
+}
+
+// Load resets the machine, clears its memory and loads a program at address 0.
+// The stack pointer is set so that the stack grows down from the top of the
+// address space.
+func (m *Machine) Load(prog []byte) {
+	m.CPU.Reset()
+	m.CPU.SP = 0
+	m.pages = [NumPages]*[PageSize]byte{}
+	for slot := range m.slots {
+		m.mapPage(slot, byte(slot))
+	}
+	for i, b := range prog {
+		m.Write(uint16(i), b)
+	}
+}
+
+// mapPage maps a page of RAM into a slot.
+func (m *Machine) mapPage(slot int, page byte) {
+	if m.pages[page] == nil {
+		m.pages[page] = new([PageSize]byte)
+	}
+	m.slots[slot] = m.pages[page]
+	m.mapping[slot] = page
+}
+
+// Mapping returns the page of RAM mapped into each slot of the address space.
+func (m *Machine) Mapping() [4]byte {
+	return m.mapping
+}
+
+// Run runs the loaded program until it halts, or until the CPU T-state count
+// reaches the limit. It reports whether the program halted.
+func (m *Machine) Run(r io.Reader, w io.Writer, limit uint64) bool {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	bw := bufio.NewWriter(w)
+	m.Attach(br, bw)
+	defer func() {
+		bw.Flush()
+		m.Attach(nil, nil)
+	}()
+	return m.CPU.Run(limit)
+}
+
+// Attach connects the I/O port to the given input and output streams, for
+// callers that drive the CPU directly rather than through Run. Either may be
+// nil, making reads return 0 or writes be ignored.
+func (m *Machine) Attach(in io.ByteReader, out io.ByteWriter) {
+	m.in, m.out = in, out
+}
+
+// Read implements emu.Bus.
+func (m *Machine) Read(addr uint16) byte { return m.slots[addr/PageSize][addr%PageSize] }
+
+// Write implements emu.Bus.
+func (m *Machine) Write(addr uint16, v byte) { m.slots[addr/PageSize][addr%PageSize] = v }
+
+// In implements emu.Bus.
+func (m *Machine) In(port uint16) byte {
+	switch p := port & 0xff; {
+	case p == IOPort && m.in != nil:
+		v, err := m.in.ReadByte()
+		if err != nil {
+			return 0
+		}
+		return v
+	case m.Profile == Banked && p >= SlotPort && p < SlotPort+4:
+		return m.mapping[p-SlotPort]
+	}
+	return 0
+}
+
+// Out implements emu.Bus.
+func (m *Machine) Out(port uint16, v byte) {
+	switch p := port & 0xff; {
+	case p == IOPort && m.out != nil:
+		m.out.WriteByte(v)
+	case m.Profile == Banked && p >= SlotPort && p < SlotPort+4:
+		m.mapPage(int(p-SlotPort), v)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package z80_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+)
+
+func TestBanking(t *testing.T) {
+	src := `
+  ld a, 0x10
+  ld hl, 0x0000
+  ld c, 'a'
+  call far_put_u8
+  ld a, 0x00
+  ld hl, 0x4000
+  ld c, 'b'
+  call far_put_u8
+  ld a, 0x10
+  ld hl, 0x3fff
+  ld de, 1
+  call far_add
+  ld c, 'c'
+  call far_put_u8
+  ld a, 0x10
+  ld hl, 0x0000
+  call far_get_u8
+  out (1), a
+  ld a, 0x10
+  ld hl, 0x4000
+  call far_get_u8
+  out (1), a
+  ld a, 1
+  out (0x11), a
+  ld a, (0x4000)
+  out (1), a
+  in a, (0x11)
+  add a, '0'
+  out (1), a
+  halt
+`
+	prog, err := asm.Assemble("test.z80", []byte(src), &asm.Options{Library: []string{"lib"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		profile z80.Profile
+		want    string
+	}{
+		{profile: z80.Flat, want: "ccc0"},
+		{profile: z80.Banked, want: "acb1"},
+	}
+	for _, test := range tests {
+		m := z80.NewMachine(test.profile)
+		m.Load(prog.Code)
+		var out strings.Builder
+		if !m.Run(strings.NewReader(""), &out, 1_000_000) {
+			t.Errorf("%v: did not halt", test.profile)
+			continue
+		}
+		if got := out.String(); got != test.want {
+			t.Errorf("%v: output %q, want %q", test.profile, got, test.want)
+		}
+	}
+}
+
+func TestFileProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		want z80.Profile
+		err  bool
+	}{
+		{name: "day01-1.bin", want: z80.Flat},
+		{name: "2022/day09-1.banked.bin", want: z80.Banked},
+		{name: "day09-1.banked.z80", want: z80.Banked},
+		{name: "day09-1.huge.bin", err: true},
+	}
+	for _, test := range tests {
+		got, err := z80.FileProfile(test.name)
+		if test.err {
+			if err == nil {
+				t.Errorf("FileProfile(%q) = %v, want error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("FileProfile(%q) = %v, %v, want %v", test.name, got, err, test.want)
+		}
+	}
+}
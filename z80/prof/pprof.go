@@ -0,0 +1,192 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prof
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Field numbers of the pprof profile.proto messages used here.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileMapping           = 3
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	mappingID           = 1
+	mappingMemoryStart  = 2
+	mappingMemoryLimit  = 3
+	mappingFilename     = 5
+	mappingHasFunctions = 7
+
+	locationID        = 1
+	locationMappingID = 2
+	locationAddress   = 3
+	locationLine      = 4
+
+	lineFunctionID = 1
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+)
+
+// WritePprof writes the profile in the gzip-compressed protocol buffer format
+// read by pprof. There is a sample for each instruction address in each call
+// stack, with the number of instructions and T-states spent there; the callers
+// are identified by the addresses of their call instructions. The program name
+// is shown as the name of the mapped binary.
+func (p *Profiler) WritePprof(w io.Writer, program string) error {
+	var (
+		strs     = map[string]uint64{"": 0}
+		strTable = []string{""}
+		funcs    = make(map[string]uint64)
+		locs     = make(map[uint16]uint64)
+		out      pbuf
+	)
+	str := func(s string) uint64 {
+		if i, ok := strs[s]; ok {
+			return i
+		}
+		strs[s] = uint64(len(strTable))
+		strTable = append(strTable, s)
+		return strs[s]
+	}
+	loc := func(addr uint16) uint64 {
+		if id, ok := locs[addr]; ok {
+			return id
+		}
+		name := p.routineName(addr)
+		fn, ok := funcs[name]
+		if !ok {
+			fn = uint64(len(funcs) + 1)
+			funcs[name] = fn
+			out.msg(profileFunction, func(m *pbuf) {
+				m.uint(functionID, fn)
+				m.uint(functionName, str(name))
+				m.uint(functionSystemName, str(name))
+			})
+		}
+		id := uint64(len(locs) + 1)
+		locs[addr] = id
+		out.msg(profileLocation, func(m *pbuf) {
+			m.uint(locationID, id)
+			m.uint(locationMappingID, 1)
+			m.uint(locationAddress, uint64(addr))
+			m.msg(locationLine, func(l *pbuf) { l.uint(lineFunctionID, fn) })
+		})
+		return id
+	}
+
+	for _, t := range [][2]string{{"instructions", "count"}, {"cycles", "t-states"}} {
+		out.msg(profileSampleType, func(m *pbuf) {
+			m.uint(valueTypeType, str(t[0]))
+			m.uint(valueTypeUnit, str(t[1]))
+		})
+	}
+	out.uint(profileDefaultSampleType, str("cycles"))
+	out.msg(profileMapping, func(m *pbuf) {
+		m.uint(mappingID, 1)
+		m.uint(mappingMemoryStart, 0)
+		m.uint(mappingMemoryLimit, 0x10000)
+		m.uint(mappingFilename, str(program))
+		m.uint(mappingHasFunctions, 1)
+	})
+
+	keys := make([]sampleKey, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].node < keys[j].node || keys[i].node == keys[j].node && keys[i].pc < keys[j].pc
+	})
+	for _, key := range keys {
+		stack := []uint64{loc(key.pc)}
+		for n := key.node; n > 0; n = p.nodes[n].parent {
+			stack = append(stack, loc(p.nodes[n].callPC))
+		}
+		c := p.samples[key]
+		out.msg(profileSample, func(m *pbuf) {
+			m.packed(sampleLocationID, stack)
+			m.packed(sampleValue, []uint64{c.instrs, c.t})
+		})
+	}
+	for _, s := range strTable {
+		out.bytes(profileStringTable, []byte(s))
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(out); err != nil {
+		return fmt.Errorf("writing profile: %w", err)
+	}
+	return zw.Close()
+}
+
+// pbuf is a minimal protocol buffer encoder.
+type pbuf []byte
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *pbuf) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *pbuf) tag(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *pbuf) uint(field int, v uint64) {
+	b.tag(field, wireVarint)
+	b.varint(v)
+}
+
+func (b *pbuf) bytes(field int, data []byte) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+func (b *pbuf) packed(field int, vs []uint64) {
+	var m pbuf
+	for _, v := range vs {
+		m.varint(v)
+	}
+	b.bytes(field, m)
+}
+
+func (b *pbuf) msg(field int, f func(m *pbuf)) {
+	var m pbuf
+	f(&m)
+	b.bytes(field, m)
+}
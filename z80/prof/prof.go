@@ -0,0 +1,265 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prof implements a profiler for Z80 programs.
+//
+// The profiler steps the emulated CPU itself, and attributes the T-states and
+// instructions executed to routines, identified by the non-local labels of the
+// program's symbol table. It tracks calls (CALL and RST instructions) on a
+// shadow call stack, which lets it report both the exclusive cost of each
+// routine and the inclusive cost of it and everything it calls. A call frame
+// ends when the stack pointer rises above the return address pushed by the
+// call, which covers returns and the less conventional ways of dropping it.
+//
+// The collected profile can be written as a text report, or in the pprof
+// format for analysis with `go tool pprof`.
+package prof
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fis/aoc/z80/emu"
+)
+
+// Profiler collects a profile of a program as it runs.
+type Profiler struct {
+	cpu      *emu.CPU
+	routines []routine
+	calls    []uint64 // number of calls made to each routine
+
+	// nodes is the tree of distinct call stacks seen; node 0 is the root.
+	nodes    []node
+	children map[node]int32
+	// stack holds the active call frames, as node IDs and the stack pointer
+	// just after the call.
+	stack []frame
+	// samples is the cost of each instruction address in each call stack.
+	samples map[sampleKey]*cost
+}
+
+type routine struct {
+	name string
+	addr uint16
+}
+
+type node struct {
+	parent int32
+	callPC uint16
+}
+
+type frame struct {
+	node int32
+	sp   uint16
+}
+
+type sampleKey struct {
+	node int32
+	pc   uint16
+}
+
+type cost struct {
+	instrs, t uint64
+}
+
+// New returns a profiler for a program running on the given CPU. Routines are
+// taken from the non-local labels (those without a period) of the symbol
+// table, which only point within the first size bytes of memory; this filters
+// out most labels that are defined with equ to point at data.
+func New(cpu *emu.CPU, syms map[string]int, size int) *Profiler {
+	p := &Profiler{
+		cpu:      cpu,
+		nodes:    []node{{parent: -1}},
+		children: make(map[node]int32),
+		samples:  make(map[sampleKey]*cost),
+	}
+	for name, addr := range syms {
+		if !strings.Contains(name, ".") && addr >= 0 && addr < size {
+			p.routines = append(p.routines, routine{name: name, addr: uint16(addr)})
+		}
+	}
+	sort.Slice(p.routines, func(i, j int) bool {
+		ri, rj := p.routines[i], p.routines[j]
+		return ri.addr < rj.addr || ri.addr == rj.addr && ri.name < rj.name
+	})
+	p.calls = make([]uint64, len(p.routines))
+	return p
+}
+
+// Run executes instructions until the CPU halts or the T-state counter reaches
+// the limit, like emu.CPU.Run. It reports whether the CPU halted.
+func (p *Profiler) Run(limit uint64) bool {
+	for !p.cpu.Halted && p.cpu.T < limit {
+		p.Step()
+	}
+	return p.cpu.Halted
+}
+
+// Step executes and profiles a single instruction, like emu.CPU.Step.
+func (p *Profiler) Step() int {
+	cpu := p.cpu
+	if cpu.Halted {
+		return 0
+	}
+	pc, sp := cpu.PC, cpu.SP
+	top := int32(0)
+	if len(p.stack) > 0 {
+		top = p.stack[len(p.stack)-1].node
+	}
+	t := cpu.Step()
+
+	key := sampleKey{node: top, pc: pc}
+	c, ok := p.samples[key]
+	if !ok {
+		c = &cost{}
+		p.samples[key] = c
+	}
+	c.instrs++
+	c.t += uint64(t)
+
+	for len(p.stack) > 0 && int16(cpu.SP-p.stack[len(p.stack)-1].sp) > 0 {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	if isCall(cpu.Bus.Read(pc)) && cpu.SP == sp-2 {
+		n := node{parent: top, callPC: pc}
+		id, ok := p.children[n]
+		if !ok {
+			id = int32(len(p.nodes))
+			p.nodes = append(p.nodes, n)
+			p.children[n] = id
+		}
+		p.stack = append(p.stack, frame{node: id, sp: cpu.SP})
+		if r := p.routineAt(cpu.PC); r >= 0 {
+			p.calls[r]++
+		}
+	}
+	return t
+}
+
+// isCall tells whether an opcode is a CALL or RST instruction.
+func isCall(op byte) bool {
+	return op == 0xcd || op&0xc7 == 0xc4 || op&0xc7 == 0xc7
+}
+
+// routineAt returns the index of the routine containing an address, or -1 if
+// the address precedes all routines.
+func (p *Profiler) routineAt(addr uint16) int {
+	return sort.Search(len(p.routines), func(i int) bool { return p.routines[i].addr > addr }) - 1
+}
+
+// routineName returns the name of the routine containing an address.
+func (p *Profiler) routineName(addr uint16) string {
+	if r := p.routineAt(addr); r >= 0 {
+		return p.routines[r].name
+	}
+	return fmt.Sprintf("$%04x", addr)
+}
+
+// Routine holds the profile of a single routine.
+type Routine struct {
+	Name string
+	Addr int
+	// Calls is the number of times the routine was called.
+	Calls uint64
+	// Instrs and T are the number of instructions and T-states spent
+	// executing the routine itself.
+	Instrs, T uint64
+	// TotalInstrs and TotalT also include the routines it called.
+	TotalInstrs, TotalT uint64
+}
+
+// Routines returns the profile of each routine that was executed, in order of
+// decreasing exclusive cost. Code that precedes all labels is attributed to a
+// routine named after its address.
+func (p *Profiler) Routines() []Routine {
+	byIdx := make(map[int]*Routine)
+	get := func(r int, addr uint16) *Routine {
+		if rt, ok := byIdx[r]; ok {
+			return rt
+		}
+		rt := &Routine{Name: p.routineName(addr), Addr: int(addr)}
+		if r >= 0 {
+			rt.Addr, rt.Calls = int(p.routines[r].addr), p.calls[r]
+		}
+		byIdx[r] = rt
+		return rt
+	}
+	for key, c := range p.samples {
+		leaf := p.routineAt(key.pc)
+		rt := get(leaf, key.pc)
+		rt.Instrs += c.instrs
+		rt.T += c.t
+		// Count the inclusive cost only once for each routine on the stack,
+		// in case of recursion.
+		seen := map[int]bool{leaf: true}
+		rt.TotalInstrs += c.instrs
+		rt.TotalT += c.t
+		for n := key.node; n > 0; n = p.nodes[n].parent {
+			callPC := p.nodes[n].callPC
+			r := p.routineAt(callPC)
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			caller := get(r, callPC)
+			caller.TotalInstrs += c.instrs
+			caller.TotalT += c.t
+		}
+	}
+	out := make([]Routine, 0, len(byIdx))
+	for _, rt := range byIdx {
+		out = append(out, *rt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].T != out[j].T {
+			return out[i].T > out[j].T
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// WriteReport writes a table of the cost of each routine, as returned by
+// Routines, followed by the totals.
+func (p *Profiler) WriteReport(w io.Writer) error {
+	routines := p.Routines()
+	var total cost
+	for _, rt := range routines {
+		total.instrs += rt.Instrs
+		total.t += rt.T
+	}
+	pct := func(t uint64) float64 {
+		if total.t == 0 {
+			return 0
+		}
+		return 100 * float64(t) / float64(total.t)
+	}
+	width := len("routine")
+	for _, rt := range routines {
+		width = max(width, len(rt.Name))
+	}
+	bw := bufio.NewWriter(w)
+	const row = "%-*s  %4s  %10s  %12s  %14s  %6s  %12s  %14s  %6s\n"
+	fmt.Fprintf(bw, row, width, "routine", "addr", "calls", "instrs", "T", "%", "total instrs", "total T", "%")
+	for _, rt := range routines {
+		fmt.Fprintf(bw, row, width, rt.Name, fmt.Sprintf("%04x", rt.Addr), fmt.Sprint(rt.Calls),
+			fmt.Sprint(rt.Instrs), fmt.Sprint(rt.T), fmt.Sprintf("%.2f", pct(rt.T)),
+			fmt.Sprint(rt.TotalInstrs), fmt.Sprint(rt.TotalT), fmt.Sprintf("%.2f", pct(rt.TotalT)))
+	}
+	fmt.Fprintf(bw, row, width, "total", "", "", fmt.Sprint(total.instrs), fmt.Sprint(total.t), "", "", "", "")
+	return bw.Flush()
+}
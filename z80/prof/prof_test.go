@@ -0,0 +1,116 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/fis/aoc/z80"
+	"github.com/fis/aoc/z80/asm"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRoutines(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Routine
+	}{
+		{
+			name: "nested",
+			src: `
+main:
+  call f
+  call f
+  halt
+f:
+  call g
+  ret
+g:
+  nop
+  ret
+`,
+			want: []Routine{
+				{Name: "f", Addr: 7, Calls: 2, Instrs: 4, T: 54, TotalInstrs: 8, TotalT: 82},
+				{Name: "main", Addr: 0, Calls: 0, Instrs: 3, T: 38, TotalInstrs: 11, TotalT: 120},
+				{Name: "g", Addr: 11, Calls: 2, Instrs: 4, T: 28, TotalInstrs: 4, TotalT: 28},
+			},
+		},
+		{
+			name: "recursive",
+			src: `
+main:
+  ld a, 2
+  call rec
+  halt
+rec:
+  dec a
+  call nz, rec
+  ret
+`,
+			want: []Routine{
+				{Name: "rec", Addr: 6, Calls: 2, Instrs: 6, T: 55, TotalInstrs: 6, TotalT: 55},
+				{Name: "main", Addr: 0, Calls: 0, Instrs: 3, T: 28, TotalInstrs: 9, TotalT: 83},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := run(t, test.src)
+			if diff := cmp.Diff(test.want, p.Routines()); diff != "" {
+				t.Errorf("Routines mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	p := run(t, "main:\n  call f\n  halt\nf:\n  ret\n")
+	var buf bytes.Buffer
+	if err := p.WritePprof(&buf, "test.bin"); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"main", "f", "test.bin", "cycles", "t-states"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("profile does not contain %q", s)
+		}
+	}
+}
+
+func run(t *testing.T, src string) *Profiler {
+	t.Helper()
+	prog, err := asm.Assemble("test.z80", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := z80.NewMachine(z80.Flat)
+	m.Load(prog.Code)
+	p := New(m.CPU, prog.Symbols, len(prog.Code))
+	if !p.Run(1000) {
+		t.Fatal("program did not halt")
+	}
+	return p
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package z80
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strconv"
+
+	"github.com/fis/aoc/glue"
+	"github.com/fis/aoc/util"
+)
+
+// Impl is the implementation language name the Z80 solutions are registered
+// under in the glue package.
+const Impl = "z80"
+
+// MaxT is the number of T-states after which a solution is considered to be
+// stuck.
+var MaxT uint64 = 100_000_000_000
+
+// Solver runs the Z80 programs of a puzzle solution. Each program is one part
+// of the solution; they are run in order, each on a freshly loaded machine but
+// the same input, and their outputs concatenated.
+type Solver struct {
+	Parts []Program
+}
+
+// Program is an assembled program, along with the machine profile it needs.
+type Program struct {
+	Code    []byte
+	Profile Profile
+}
+
+// Solve implements glue.Solver.
+func (s Solver) Solve(r io.Reader) ([]string, error) {
+	out, _, err := s.SolveMeasured(r)
+	return out, err
+}
+
+// SolveMeasured implements glue.MeasuredSolver, with the number of T-states
+// of each part as the metrics.
+func (s Solver) SolveMeasured(r io.Reader) ([]string, []glue.Metric, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		out     bytes.Buffer
+		metrics []glue.Metric
+	)
+	for i, prog := range s.Parts {
+		m := NewMachine(prog.Profile)
+		m.Load(prog.Code)
+		if !m.Run(bytes.NewReader(input), &out, MaxT) {
+			return nil, nil, fmt.Errorf("part %d: did not halt after %d T-states", i+1, m.CPU.T)
+		}
+		metrics = append(metrics, glue.Metric{Name: fmt.Sprintf("T(part %d)", i+1), Value: int64(m.CPU.T)})
+	}
+	return util.Lines(out.String()), metrics, nil
+}
+
+var reProgName = regexp.MustCompile(`^day(\d\d)-(\d)(\.[a-z]+)?\.bin$`)
+
+// Register registers the Z80 programs in fsys, named like "dayDD-P.bin", as
+// the solutions of the given year. Programs that need a machine profile other
+// than the flat one declare it in their name, as in "dayDD-P.banked.bin".
+func Register(year int, fsys fs.FS) {
+	solvers, err := loadSolvers(fsys)
+	if err != nil {
+		panic(err)
+	}
+	for day, s := range solvers {
+		glue.RegisterImpl(Impl, year, day, s)
+	}
+}
+
+// loadSolvers reads the programs in fsys, and returns the solvers they make up,
+// by day. Each part must have exactly one program, whatever its profile, and
+// the parts must be numbered from 1 without gaps.
+func loadSolvers(fsys fs.FS) (map[int]Solver, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	parts := make(map[int]map[int]string)
+	for _, e := range entries {
+		m := reProgName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		day, _ := strconv.Atoi(m[1])
+		part, _ := strconv.Atoi(m[2])
+		if parts[day] == nil {
+			parts[day] = make(map[int]string)
+		}
+		if prev, ok := parts[day][part]; ok {
+			return nil, fmt.Errorf("day %d part %d: both %s and %s", day, part, prev, e.Name())
+		}
+		parts[day][part] = e.Name()
+	}
+	solvers := make(map[int]Solver)
+	for day, names := range parts {
+		var s Solver
+		for part := 1; part <= len(names); part++ {
+			name, ok := names[part]
+			if !ok {
+				return nil, fmt.Errorf("day %d: no program for part %d", day, part)
+			}
+			code, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			profile, err := FileProfile(name)
+			if err != nil {
+				return nil, err
+			}
+			s.Parts = append(s.Parts, Program{Code: code, Profile: profile})
+		}
+		solvers[day] = s
+	}
+	return solvers, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package z80
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadSolvers(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte{0x76}}
+	tests := []struct {
+		files   []string
+		want    map[int][]Profile
+		wantErr string
+	}{
+		{
+			files: []string{"day01-1.bin", "day01-2.bin", "day09-1.banked.bin", "day09-1.z80", "readme.md"},
+			want:  map[int][]Profile{1: {Flat, Flat}, 9: {Banked}},
+		},
+		{
+			files:   []string{"day09-1.bin", "day09-1.banked.bin"},
+			wantErr: "day 9 part 1: both",
+		},
+		{
+			files:   []string{"day09-2.bin"},
+			wantErr: "day 9: no program for part 1",
+		},
+	}
+	for _, test := range tests {
+		fsys := fstest.MapFS{}
+		for _, name := range test.files {
+			fsys[name] = file
+		}
+		solvers, err := loadSolvers(fsys)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("loadSolvers(%v) = %v, want error containing %q", test.files, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("loadSolvers(%v): %v", test.files, err)
+			continue
+		}
+		got := make(map[int][]Profile)
+		for day, s := range solvers {
+			for _, p := range s.Parts {
+				got[day] = append(got[day], p.Profile)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("loadSolvers(%v) = %v, want %v", test.files, got, test.want)
+			continue
+		}
+		for day, want := range test.want {
+			if !slices.Equal(got[day], want) {
+				t.Errorf("loadSolvers(%v) = %v, want %v", test.files, got, test.want)
+			}
+		}
+	}
+}